@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"fmt"
 	"time"
 
 	"go.uber.org/zap/zapcore"
@@ -99,6 +100,39 @@ func Strings(key string, ss []string) Field {
 	return Array(key, stringArray(ss))
 }
 
+// Stringers constructs a field that carries a slice of fmt.Stringers. Each
+// element's String method is called lazily, only if the entry is encoded.
+func Stringers(key string, ss []fmt.Stringer) Field {
+	return Array(key, stringers(ss))
+}
+
+// Objects constructs a field that carries a slice of ObjectMarshalers, encoding
+// each element as a nested object.
+func Objects(key string, objs []zapcore.ObjectMarshaler) Field {
+	return Array(key, objects(objs))
+}
+
+// StringsN constructs a field that carries at most the first max elements of
+// a slice of strings, keeping the log entry bounded even if the slice is
+// large. If any elements are omitted, their number is added under
+// key+"_truncated".
+func StringsN(key string, ss []string, max int) Field {
+	n := truncatedLen(len(ss), max)
+	return truncatedArray(key, stringArray(ss[:n]), len(ss)-n)
+}
+
+// IntsN is like StringsN, but for a slice of ints.
+func IntsN(key string, nums []int, max int) Field {
+	n := truncatedLen(len(nums), max)
+	return truncatedArray(key, ints(nums[:n]), len(nums)-n)
+}
+
+// Float64sN is like StringsN, but for a slice of floats.
+func Float64sN(key string, nums []float64, max int) Field {
+	n := truncatedLen(len(nums), max)
+	return truncatedArray(key, float64s(nums[:n]), len(nums)-n)
+}
+
 // Times constructs a field that carries a slice of time.Times.
 func Times(key string, ts []time.Time) Field {
 	return Array(key, times(ts))
@@ -256,6 +290,27 @@ func (ss stringArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
 	return nil
 }
 
+type stringers []fmt.Stringer
+
+func (ss stringers) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for i := range ss {
+		arr.AppendString(ss[i].String())
+	}
+	return nil
+}
+
+type objects []zapcore.ObjectMarshaler
+
+func (objs objects) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	var err error
+	for i := range objs {
+		if appendErr := arr.AppendObject(objs[i]); appendErr != nil && err == nil {
+			err = appendErr
+		}
+	}
+	return err
+}
+
 type times []time.Time
 
 func (ts times) MarshalLogArray(arr zapcore.ArrayEncoder) error {
@@ -318,3 +373,34 @@ func (nums uintptrs) MarshalLogArray(arr zapcore.ArrayEncoder) error {
 	}
 	return nil
 }
+
+func truncatedLen(n, max int) int {
+	if max < 0 {
+		max = 0
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
+
+func truncatedArray(key string, arr zapcore.ArrayMarshaler, omitted int) Field {
+	if omitted == 0 {
+		return Array(key, arr)
+	}
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: truncated{key: key, arr: arr, omitted: omitted}}
+}
+
+// truncated adds a truncated array and the number of elements omitted from
+// it to the enclosing object.
+type truncated struct {
+	key     string
+	arr     zapcore.ArrayMarshaler
+	omitted int
+}
+
+func (t truncated) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	err := enc.AddArray(t.key, t.arr)
+	enc.AddInt(t.key+"_truncated", t.omitted)
+	return err
+}
@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -68,6 +69,8 @@ func TestArrayWrappers(t *testing.T) {
 		{"empty int16s", Int16s("", []int16{}), []interface{}{}},
 		{"empty int8s", Int8s("", []int8{}), []interface{}{}},
 		{"empty strings", Strings("", []string{}), []interface{}{}},
+		{"empty stringers", Stringers("", []fmt.Stringer{}), []interface{}{}},
+		{"empty objects", Objects("", []zapcore.ObjectMarshaler{}), []interface{}{}},
 		{"empty times", Times("", []time.Time{}), []interface{}{}},
 		{"empty uints", Uints("", []uint{}), []interface{}{}},
 		{"empty uint64s", Uint64s("", []uint64{}), []interface{}{}},
@@ -88,6 +91,8 @@ func TestArrayWrappers(t *testing.T) {
 		{"int16s", Int16s("", []int16{1, 2}), []interface{}{int16(1), int16(2)}},
 		{"int8s", Int8s("", []int8{1, 2}), []interface{}{int8(1), int8(2)}},
 		{"strings", Strings("", []string{"foo", "bar"}), []interface{}{"foo", "bar"}},
+		{"stringers", Stringers("", []fmt.Stringer{time.Second, time.Minute}), []interface{}{"1s", "1m0s"}},
+		{"objects", Objects("", []zapcore.ObjectMarshaler{username("jane")}), []interface{}{map[string]interface{}{"username": "jane"}}},
 		{"times", Times("", []time.Time{time.Unix(0, 0), time.Unix(0, 0)}), []interface{}{time.Unix(0, 0), time.Unix(0, 0)}},
 		{"uints", Uints("", []uint{1, 2}), []interface{}{uint(1), uint(2)}},
 		{"uint64s", Uint64s("", []uint64{1, 2}), []interface{}{uint64(1), uint64(2)}},
@@ -105,3 +110,74 @@ func TestArrayWrappers(t *testing.T) {
 		assert.Equal(t, 1, len(enc.Fields), "%s: found extra keys in map: %v", tt.desc, enc.Fields)
 	}
 }
+
+func TestTruncatedArrays(t *testing.T) {
+	strs := []string{"a", "b", "c"}
+	tests := []struct {
+		desc     string
+		field    Field
+		expected map[string]interface{}
+	}{
+		{"strings under limit", StringsN("k", strs, 4), map[string]interface{}{"k": []interface{}{"a", "b", "c"}}},
+		{"strings at limit", StringsN("k", strs, 3), map[string]interface{}{"k": []interface{}{"a", "b", "c"}}},
+		{
+			"strings over limit",
+			StringsN("k", strs, 2),
+			map[string]interface{}{"k": []interface{}{"a", "b"}, "k_truncated": 1},
+		},
+		{
+			"strings with zero limit",
+			StringsN("k", strs, 0),
+			map[string]interface{}{"k": []interface{}{}, "k_truncated": 3},
+		},
+		{
+			"strings with negative limit",
+			StringsN("k", strs, -1),
+			map[string]interface{}{"k": []interface{}{}, "k_truncated": 3},
+		},
+		{"nil strings", StringsN("k", nil, 2), map[string]interface{}{"k": []interface{}{}}},
+		{"ints at limit", IntsN("k", []int{1, 2}, 2), map[string]interface{}{"k": []interface{}{1, 2}}},
+		{
+			"ints over limit",
+			IntsN("k", []int{1, 2, 3, 4}, 1),
+			map[string]interface{}{"k": []interface{}{1}, "k_truncated": 3},
+		},
+		{"float64s under limit", Float64sN("k", []float64{1.5}, 2), map[string]interface{}{"k": []interface{}{1.5}}},
+		{
+			"float64s over limit",
+			Float64sN("k", []float64{1.5, 2.5, 3.5}, 2),
+			map[string]interface{}{"k": []interface{}{1.5, 2.5}, "k_truncated": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		tt.field.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields, "%s: unexpected map contents.", tt.desc)
+		assertCanBeReused(t, tt.field)
+	}
+}
+
+func TestArrayWrappersJSON(t *testing.T) {
+	tests := []struct {
+		desc     string
+		field    Field
+		expected string
+	}{
+		{"ints", Ints("ids", []int{1, 2, 3}), `"ids":[1,2,3]`},
+		{"strings", Strings("ids", []string{"a", "b"}), `"ids":["a","b"]`},
+		{"float64s", Float64s("ids", []float64{1.5, 2}), `"ids":[1.5,2]`},
+		{"empty ints", Ints("ids", []int{}), `"ids":[]`},
+		{"nil ints", Ints("ids", nil), `"ids":[]`},
+		{"nil strings", Strings("ids", nil), `"ids":[]`},
+		{"nil float64s", Float64s("ids", nil), `"ids":[]`},
+	}
+
+	for _, tt := range tests {
+		buf, err := zapcore.NewJSONEncoder(zapcore.EncoderConfig{}).EncodeEntry(zapcore.Entry{}, []Field{tt.field})
+		if assert.NoError(t, err, "%s: unexpected error encoding entry.", tt.desc) {
+			assert.Equal(t, "{"+tt.expected+"}\n", buf.String(), "%s: unexpected JSON.", tt.desc)
+			buf.Free()
+		}
+	}
+}
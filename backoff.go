@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Backoff constructs a field that logs a retry attempt and the delay before
+// the next one as a nested object, like
+//   {"attempt":3,"delay":0.8}
+// The delay follows the usual exponential formula, base * 2^attempt, capped
+// at max; attempt 0 (or a negative attempt) waits for base. The delay is
+// added with AddDuration, so it's serialized by the encoder's
+// EncodeDuration. Backoff doesn't add jitter.
+func Backoff(key string, attempt int, base, max time.Duration) Field {
+	return Object(key, backoff{attempt: attempt, delay: BackoffDelay(attempt, base, max)})
+}
+
+// BackoffDelay returns the delay logged by Backoff: base * 2^attempt, capped
+// at max.
+func BackoffDelay(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 0; i < attempt; i++ {
+		if delay >= max || delay > max/2 {
+			// Doubling would exceed the cap (or overflow).
+			return max
+		}
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+type backoff struct {
+	attempt int
+	delay   time.Duration
+}
+
+func (b backoff) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("attempt", b.attempt)
+	enc.AddDuration("delay", b.delay)
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		attempt   int
+		base, max time.Duration
+		expected  time.Duration
+	}{
+		{0, 100 * time.Millisecond, time.Second, 100 * time.Millisecond},
+		{-1, 100 * time.Millisecond, time.Second, 100 * time.Millisecond},
+		{1, 100 * time.Millisecond, time.Second, 200 * time.Millisecond},
+		{3, 100 * time.Millisecond, time.Second, 800 * time.Millisecond},
+		{4, 100 * time.Millisecond, time.Second, time.Second},
+		{100, 100 * time.Millisecond, time.Second, time.Second},
+		{100, time.Second, math.MaxInt64, math.MaxInt64},
+		{0, 2 * time.Second, time.Second, time.Second},
+		{5, 0, time.Second, 0},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, BackoffDelay(tt.attempt, tt.base, tt.max), "Unexpected delay for attempt %d with base %v and max %v.", tt.attempt, tt.base, tt.max)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	f := Backoff("retry", 3, 100*time.Millisecond, 500*time.Millisecond)
+	f.AddTo(enc)
+	assert.Equal(t, map[string]interface{}{"attempt": 3, "delay": 500 * time.Millisecond}, enc.Fields["retry"], "Expected the delay to be capped.")
+	assertCanBeReused(t, f)
+
+	json := zapcore.NewJSONEncoder(zapcore.EncoderConfig{EncodeDuration: zapcore.StringDurationEncoder})
+	buf, err := json.EncodeEntry(zapcore.Entry{}, []Field{Backoff("retry", 0, time.Second, time.Minute)})
+	if assert.NoError(t, err, "Unexpected error encoding entry.") {
+		assert.Equal(t, `{"retry":{"attempt":0,"delay":"1s"}}`+"\n", buf.String(), "Expected the encoder's duration formatter to be used.")
+		buf.Free()
+	}
+}
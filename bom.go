@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"os"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// _utf8BOM is the UTF-8 encoding of the byte order mark, U+FEFF.
+var _utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// WriteBOM wraps a WriteSyncer so that a newly-created log file starts with
+// a UTF-8 byte order mark, which some Windows log viewers and ingestion tools
+// expect. (Loggers built from a Config with WriteBOM set wrap each of their
+// outputs this way.) The BOM is written at most once, along with the first write, and
+// only if the WriteSyncer is a regular file (or anything else with a Stat
+// method that reports one) that's empty at the time; appending to an existing
+// log, or writing to a terminal or pipe, never adds a BOM mid-stream.
+//
+// Like the wrapped WriteSyncer, the result isn't safe for concurrent use
+// unless it's protected with zapcore.Lock.
+func WriteBOM(ws zapcore.WriteSyncer) zapcore.WriteSyncer {
+	return &bomWriter{WriteSyncer: ws}
+}
+
+type bomWriter struct {
+	zapcore.WriteSyncer
+	once sync.Once
+}
+
+func (w *bomWriter) Write(p []byte) (int, error) {
+	var bom bool
+	w.once.Do(func() { bom = needsBOM(w.WriteSyncer) })
+	if !bom {
+		return w.WriteSyncer.Write(p)
+	}
+
+	// Write the BOM and the first entry together, so that they can't be
+	// separated by concurrent writers sharing the file.
+	buf := make([]byte, 0, len(_utf8BOM)+len(p))
+	buf = append(buf, _utf8BOM...)
+	n, err := w.WriteSyncer.Write(append(buf, p...))
+	n -= len(_utf8BOM)
+	if n < 0 {
+		n = 0
+	}
+	return n, err
+}
+
+func needsBOM(ws zapcore.WriteSyncer) bool {
+	f, ok := ws.(interface {
+		Stat() (os.FileInfo, error)
+	})
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode().IsRegular() && fi.Size() == 0
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap/internal/ztest"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBOMTestLogger(ws zapcore.WriteSyncer) *Logger {
+	return New(zapcore.NewCore(zapcore.NewJSONEncoder(NewProductionEncoderConfig()), ws, DebugLevel))
+}
+
+func TestWriteBOM(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zap-bom-test")
+	require.NoError(t, err, "Failed to create temp dir.")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "log.txt")
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{path}
+	cfg.WriteBOM = true
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+	logger.Info("one")
+	logger.Info("two")
+
+	contents, err := ioutil.ReadFile(path)
+	require.NoError(t, err, "Failed to read log file.")
+	assert.True(t, bytes.HasPrefix(contents, _utf8BOM), "Expected a new file to start with a BOM.")
+	assert.Equal(t, 1, bytes.Count(contents, _utf8BOM), "Expected exactly one BOM.")
+	assert.Equal(t, 2, bytes.Count(contents, []byte("\n")), "Unexpected number of lines.")
+
+	// Appending to the existing file shouldn't add another BOM.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err, "Failed to reopen log file.")
+	newBOMTestLogger(WriteBOM(f)).Info("three")
+	f.Close()
+
+	contents, err = ioutil.ReadFile(path)
+	require.NoError(t, err, "Failed to read log file.")
+	assert.Equal(t, 1, bytes.Count(contents, _utf8BOM), "Expected appends not to add a BOM.")
+	assert.Equal(t, 3, bytes.Count(contents, []byte("\n")), "Unexpected number of lines.")
+}
+
+func TestWriteBOMNonFiles(t *testing.T) {
+	buf := &ztest.Buffer{}
+	ws := WriteBOM(buf)
+	n, err := ws.Write([]byte("hello\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, 6, n, "Unexpected number of bytes written.")
+	assert.Equal(t, "hello\n", buf.String(), "Expected no BOM for writers that aren't files.")
+}
+
+func TestWriteBOMByteCount(t *testing.T) {
+	f, err := ioutil.TempFile("", "zap-bom-count-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	n, err := WriteBOM(f).Write([]byte("hello\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, 6, n, "Expected the BOM not to count toward the bytes written.")
+}
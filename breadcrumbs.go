@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Breadcrumbs is a bounded ring of recent, lightweight events (breadcrumbs)
+// that can be attached to a later log entry to show what led up to it. Once
+// the ring is full, recording a breadcrumb discards the oldest one.
+//
+// Go doesn't offer goroutine-local storage, so rather than sharing one ring
+// across a whole program, create one per goroutine or request and attach it
+// to that unit of work's logger with the WithBreadcrumbs option. Breadcrumbs
+// are nevertheless safe for concurrent use.
+type Breadcrumbs struct {
+	mu     sync.Mutex
+	crumbs []breadcrumb
+	next   int
+	full   bool
+}
+
+type breadcrumb struct {
+	time time.Time
+	msg  string
+}
+
+// NewBreadcrumbs creates a ring that holds the most recent size breadcrumbs.
+// A non-positive size holds none.
+func NewBreadcrumbs(size int) *Breadcrumbs {
+	if size < 0 {
+		size = 0
+	}
+	return &Breadcrumbs{crumbs: make([]breadcrumb, size)}
+}
+
+// Record adds a breadcrumb with the current system time. Logger.Crumb uses the
+// Logger's clock instead; see WithClock.
+func (b *Breadcrumbs) Record(msg string) {
+	b.record(time.Now(), msg)
+}
+
+func (b *Breadcrumbs) record(t time.Time, msg string) {
+	b.mu.Lock()
+	if len(b.crumbs) > 0 {
+		b.crumbs[b.next] = breadcrumb{time: t, msg: msg}
+		b.next++
+		if b.next == len(b.crumbs) {
+			b.next = 0
+			b.full = true
+		}
+	}
+	b.mu.Unlock()
+}
+
+// drain removes and returns the recorded breadcrumbs, oldest first.
+func (b *Breadcrumbs) drain() breadcrumbArray {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var out breadcrumbArray
+	if b.full {
+		out = append(out, b.crumbs[b.next:]...)
+	}
+	out = append(out, b.crumbs[:b.next]...)
+	for i := range b.crumbs {
+		b.crumbs[i] = breadcrumb{}
+	}
+	b.next, b.full = 0, false
+	return out
+}
+
+// Crumbs constructs a field that flushes the breadcrumbs recorded so far into
+// an array under the key "breadcrumbs", oldest first, like
+//   [{"ts":1528987361.5,"msg":"cache miss"},{"ts":1528987361.7,"msg":"retrying"}]
+// It's typically attached to an error log. The ring is emptied when an entry
+// carrying the field is first encoded, so each breadcrumb is logged at most
+// once, and entries that are dropped (for example, because their level is
+// disabled) leave the ring untouched. If b is nil or empty at that point, the
+// field is a no-op.
+func Crumbs(b *Breadcrumbs) Field {
+	if b == nil {
+		return Skip()
+	}
+	return Lazy("breadcrumbs", func() Field {
+		crumbs := b.drain()
+		if len(crumbs) == 0 {
+			return Skip()
+		}
+		return Array("breadcrumbs", crumbs)
+	})
+}
+
+// Crumb records a lightweight breadcrumb in the ring attached to the logger
+// with the WithBreadcrumbs option; nothing is written to the logger's output.
+// If the logger has no breadcrumbs attached, Crumb is a no-op.
+func (log *Logger) Crumb(msg string) {
+	if log.crumbs != nil {
+		log.crumbs.record(log.clock.Now(), msg)
+	}
+}
+
+type breadcrumbArray []breadcrumb
+
+func (bs breadcrumbArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for i := range bs {
+		arr.AppendObject(bs[i])
+	}
+	return nil
+}
+
+func (b breadcrumb) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddTime("ts", b.time)
+	enc.AddString("msg", b.msg)
+	return nil
+}
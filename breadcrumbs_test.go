@@ -0,0 +1,145 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeCrumbs(f Field) interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	return enc.Fields["breadcrumbs"]
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	epoch := time.Unix(0, 0)
+	crumb := func(i int) map[string]interface{} {
+		return map[string]interface{}{"ts": epoch.Add(time.Duration(i)), "msg": fmt.Sprint(i)}
+	}
+
+	tests := []struct {
+		desc     string
+		size     int
+		records  int
+		expected []interface{}
+	}{
+		{"empty", 3, 0, nil},
+		{"partially full", 3, 2, []interface{}{crumb(0), crumb(1)}},
+		{"exactly full", 3, 3, []interface{}{crumb(0), crumb(1), crumb(2)}},
+		{"wrapped", 3, 5, []interface{}{crumb(2), crumb(3), crumb(4)}},
+		{"zero size", 0, 5, nil},
+		{"negative size", -1, 5, nil},
+	}
+
+	for _, tt := range tests {
+		b := NewBreadcrumbs(tt.size)
+		for i := 0; i < tt.records; i++ {
+			b.record(epoch.Add(time.Duration(i)), fmt.Sprint(i))
+		}
+		f := Crumbs(b)
+		if tt.expected == nil {
+			assert.Nil(t, encodeCrumbs(f), "%s: expected a no-op field.", tt.desc)
+			continue
+		}
+		assert.Equal(t, tt.expected, encodeCrumbs(f), "%s: unexpected breadcrumbs.", tt.desc)
+		assert.Equal(t, tt.expected, encodeCrumbs(f), "%s: unexpected breadcrumbs when re-using field.", tt.desc)
+		assertCanBeReused(t, f)
+		assert.Nil(t, encodeCrumbs(Crumbs(b)), "%s: expected breadcrumbs to be flushed.", tt.desc)
+	}
+
+	assert.Equal(t, Skip(), Crumbs(nil), "Expected nil breadcrumbs to produce a no-op field.")
+}
+
+func TestBreadcrumbsDrainedOnEncode(t *testing.T) {
+	b := NewBreadcrumbs(2)
+	withLogger(t, InfoLevel, opts(WithBreadcrumbs(b)), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Crumb("first")
+		f := Crumbs(b)
+		logger.Debug("dropped", f)
+		assert.Equal(t, 0, logs.Len(), "Expected debug entry to be dropped.")
+
+		logger.Error("failed", Crumbs(b))
+		require.Equal(t, 1, logs.Len(), "Expected one entry.")
+		crumbs, ok := logs.AllUntimed()[0].ContextMap()["breadcrumbs"].([]interface{})
+		require.True(t, ok, "Expected breadcrumbs to be logged as an array.")
+		assert.Equal(t, 1, len(crumbs), "Expected breadcrumbs to survive a dropped entry.")
+		assert.Nil(t, encodeCrumbs(f), "Expected breadcrumbs to be flushed by the written entry.")
+	})
+}
+
+func TestLoggerCrumbUsesClock(t *testing.T) {
+	clock := &stubClock{now: time.Unix(1528987361, 0)}
+	b := NewBreadcrumbs(1)
+	logger := New(zapcore.NewNopCore(), WithClock(clock), WithBreadcrumbs(b))
+	logger.Crumb("tick")
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"ts": clock.now, "msg": "tick"},
+	}, encodeCrumbs(Crumbs(b)), "Expected breadcrumbs to use the logger's clock.")
+}
+
+func TestLoggerCrumb(t *testing.T) {
+	b := NewBreadcrumbs(2)
+	withLogger(t, DebugLevel, opts(WithBreadcrumbs(b)), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Crumb("first")
+		logger.With(String("k", "v")).Crumb("second")
+		logger.Named("child").Crumb("third")
+		assert.Equal(t, 0, logs.Len(), "Expected breadcrumbs not to be logged.")
+
+		logger.Error("failed", Crumbs(b))
+		require.Equal(t, 1, logs.Len(), "Expected one entry.")
+		crumbs, ok := logs.AllUntimed()[0].ContextMap()["breadcrumbs"].([]interface{})
+		require.True(t, ok, "Expected breadcrumbs to be logged as an array.")
+		require.Equal(t, 2, len(crumbs), "Expected the ring to keep only the latest breadcrumbs.")
+		assert.Equal(t, "second", crumbs[0].(map[string]interface{})["msg"], "Unexpected oldest breadcrumb.")
+		assert.Equal(t, "third", crumbs[1].(map[string]interface{})["msg"], "Unexpected newest breadcrumb.")
+	})
+
+	assert.NotPanics(t, func() { NewNop().Crumb("ignored") }, "Expected Crumb without breadcrumbs to be a no-op.")
+}
+
+func TestBreadcrumbsConcurrent(t *testing.T) {
+	b := NewBreadcrumbs(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Record("crumb")
+				if j%10 == 0 {
+					Crumbs(b)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.True(t, len(b.drain()) <= 10, "Expected the ring to stay bounded.")
+}
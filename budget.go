@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Budget constructs a field that records how much of a time budget (for
+// example, a request timeout or latency SLA) was consumed. It's encoded as a
+// nested object, like
+//   {"used_ns":1500000000,"limit_ns":1000000000,"over":true}
+// The budget is only exceeded if used is strictly greater than limit.
+//
+// The durations are added with AddDuration, so they're serialized by the
+// encoder's EncodeDuration; the "_ns" keys reflect the default nanosecond
+// encoding.
+func Budget(key string, used, limit time.Duration) Field {
+	return Object(key, budget{used: used, limit: limit})
+}
+
+type budget struct {
+	used  time.Duration
+	limit time.Duration
+}
+
+func (b budget) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddDuration("used_ns", b.used)
+	enc.AddDuration("limit_ns", b.limit)
+	enc.AddBool("over", b.used > b.limit)
+	return nil
+}
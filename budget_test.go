@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBudget(t *testing.T) {
+	tests := []struct {
+		desc        string
+		used, limit time.Duration
+		over        bool
+	}{
+		{"under", 500 * time.Millisecond, time.Second, false},
+		{"exactly at limit", time.Second, time.Second, false},
+		{"over", time.Second + time.Nanosecond, time.Second, true},
+		{"zero limit", time.Nanosecond, 0, true},
+	}
+
+	for _, tt := range tests {
+		field := Budget("budget", tt.used, tt.limit)
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		assert.Equal(t, map[string]interface{}{
+			"budget": map[string]interface{}{
+				"used_ns":  tt.used,
+				"limit_ns": tt.limit,
+				"over":     tt.over,
+			},
+		}, enc.Fields, "%s: unexpected encoded fields.", tt.desc)
+		assertCanBeReused(t, field)
+	}
+}
+
+func TestBudgetEncodeDuration(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{EncodeDuration: zapcore.StringDurationEncoder})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{Budget("budget", 1500*time.Millisecond, time.Second)})
+	if assert.NoError(t, err, "Unexpected error encoding entry.") {
+		assert.Equal(
+			t,
+			`{"budget":{"used_ns":"1.5s","limit_ns":"1s","over":true}}`+"\n",
+			buf.String(),
+			"Expected durations to honor the configured DurationEncoder.",
+		)
+		buf.Free()
+	}
+}
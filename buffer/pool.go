@@ -25,15 +25,36 @@ import "sync"
 // A Pool is a type-safe wrapper around a sync.Pool.
 type Pool struct {
 	p *sync.Pool
+	// maxRetained is the largest capacity of the buffers returned to the
+	// pool; zero means no limit.
+	maxRetained int
 }
 
 // NewPool constructs a new Pool.
 func NewPool() Pool {
-	return Pool{p: &sync.Pool{
-		New: func() interface{} {
-			return &Buffer{bs: make([]byte, 0, _size)}
+	return NewSizedPool(_size, 0)
+}
+
+// NewSizedPool constructs a Pool whose new Buffers start with the given
+// capacity, and which doesn't retain freed Buffers that have grown beyond
+// maxRetained bytes; a maxRetained of zero or less retains every Buffer.
+//
+// Sizing the initial capacity to fit typical entries saves the copying that
+// repeatedly growing small buffers would otherwise cost, and bounding the
+// retained size keeps a burst of unusually large entries from pinning large
+// buffers in memory, where they'd be handed out for small entries too.
+func NewSizedPool(initialSize, maxRetained int) Pool {
+	if initialSize < 0 {
+		initialSize = 0
+	}
+	return Pool{
+		p: &sync.Pool{
+			New: func() interface{} {
+				return &Buffer{bs: make([]byte, 0, initialSize)}
+			},
 		},
-	}}
+		maxRetained: maxRetained,
+	}
 }
 
 // Get retrieves a Buffer from the pool, creating one if necessary.
@@ -45,5 +66,9 @@ func (p Pool) Get() *Buffer {
 }
 
 func (p Pool) put(buf *Buffer) {
+	if p.maxRetained > 0 && buf.Cap() > p.maxRetained {
+		// Let the garbage collector reclaim oversized buffers.
+		return
+	}
 	p.p.Put(buf)
 }
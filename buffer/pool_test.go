@@ -50,3 +50,22 @@ func TestBuffers(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestSizedPool(t *testing.T) {
+	p := NewSizedPool(16, 64)
+	buf := p.Get()
+	assert.Equal(t, 16, buf.Cap(), "Expected new buffers to have the initial capacity.")
+	buf.Free()
+
+	buf = p.Get()
+	buf.Write(make([]byte, 128))
+	assert.True(t, buf.Cap() > 64, "Expected the buffer to grow.")
+	buf.Free()
+	for i := 0; i < 10; i++ {
+		// sync.Pool may drop buffers, but it must never return the oversized
+		// one.
+		assert.True(t, p.Get().Cap() <= 64, "Expected oversized buffers not to be retained.")
+	}
+
+	assert.Equal(t, 0, NewSizedPool(-1, 0).Get().Cap(), "Expected negative sizes to be treated as zero.")
+}
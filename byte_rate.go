@@ -0,0 +1,94 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// A ByteRateOption configures ByteRate.
+type ByteRateOption interface {
+	applyByteRate(*byteRateOptions)
+}
+
+type byteRateOptions struct {
+	human bool
+}
+
+type byteRateOptionFunc func(*byteRateOptions)
+
+func (f byteRateOptionFunc) applyByteRate(opts *byteRateOptions) {
+	f(opts)
+}
+
+// HumanByteRates configures ByteRate to log rates as human-readable strings,
+// like "15.3 MB/s", using decimal (SI) units.
+func HumanByteRates() ByteRateOption {
+	return byteRateOptionFunc(func(opts *byteRateOptions) {
+		opts.human = true
+	})
+}
+
+// ByteRate constructs a field that logs the throughput of transferring bytes
+// in the duration per. By default, the rate is logged as an integer number of
+// bytes per second, which is easy for machines to parse; the HumanByteRates
+// option logs it as a string instead. A non-positive duration logs a rate of
+// zero.
+func ByteRate(key string, bytes int64, per time.Duration, opts ...ByteRateOption) Field {
+	var o byteRateOptions
+	for _, opt := range opts {
+		opt.applyByteRate(&o)
+	}
+
+	var rate float64
+	if per > 0 {
+		rate = float64(bytes) / per.Seconds()
+	}
+	if o.human {
+		return String(key, humanByteRate(rate))
+	}
+	return Int64(key, int64(round(rate)))
+}
+
+var _byteRateUnits = []string{"kB/s", "MB/s", "GB/s", "TB/s", "PB/s", "EB/s"}
+
+// humanByteRate formats a rate in bytes per second with decimal units.
+func humanByteRate(rate float64) string {
+	if math.Abs(rate) < 1000 {
+		return strconv.FormatFloat(round(rate), 'f', -1, 64) + " B/s"
+	}
+	unit := -1
+	for math.Abs(rate) >= 999.95 && unit < len(_byteRateUnits)-1 {
+		rate /= 1000
+		unit++
+	}
+	return strconv.FormatFloat(rate, 'f', 1, 64) + " " + _byteRateUnits[unit]
+}
+
+// round rounds half away from zero, like math.Round (which requires Go 1.10).
+func round(x float64) float64 {
+	if x < 0 {
+		return -math.Floor(-x + 0.5)
+	}
+	return math.Floor(x + 0.5)
+}
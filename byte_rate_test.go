@@ -0,0 +1,52 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteRate(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		per   time.Duration
+		raw   Field
+		human Field
+	}{
+		{0, time.Second, Int64("rate", 0), String("rate", "0 B/s")},
+		{512, time.Second, Int64("rate", 512), String("rate", "512 B/s")},
+		{512, 2 * time.Second, Int64("rate", 256), String("rate", "256 B/s")},
+		{1500, time.Second, Int64("rate", 1500), String("rate", "1.5 kB/s")},
+		{15300000, time.Second, Int64("rate", 15300000), String("rate", "15.3 MB/s")},
+		{7650000, 500 * time.Millisecond, Int64("rate", 15300000), String("rate", "15.3 MB/s")},
+		{999999, time.Second, Int64("rate", 999999), String("rate", "1.0 MB/s")},
+		{3 << 40, time.Second, Int64("rate", 3<<40), String("rate", "3.3 TB/s")},
+		{100, 0, Int64("rate", 0), String("rate", "0 B/s")},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.raw, ByteRate("rate", tt.bytes, tt.per), "Unexpected raw rate for %d bytes in %v.", tt.bytes, tt.per)
+		assert.Equal(t, tt.human, ByteRate("rate", tt.bytes, tt.per, HumanByteRates()), "Unexpected human rate for %d bytes in %v.", tt.bytes, tt.per)
+	}
+}
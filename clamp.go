@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "go.uber.org/zap/zapcore"
+
+// ClampInt constructs a field that logs val clamped to the range [min, max],
+// which makes out-of-range inputs easy to spot when validating
+// configuration or user input. If val is in range, ClampInt is just
+// Int64(key, val). Otherwise, the clamped value is logged under key, along
+// with key+"_clamped" set to true and the original value under
+// key+"_original":
+//   {"retries":10,"retries_clamped":true,"retries_original":250}
+func ClampInt(key string, val, min, max int64) Field {
+	clamped := val
+	if clamped < min {
+		clamped = min
+	}
+	if clamped > max {
+		clamped = max
+	}
+	if clamped == val {
+		return Int64(key, val)
+	}
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: clampedInt{
+		key:      key,
+		val:      clamped,
+		original: val,
+	}}
+}
+
+type clampedInt struct {
+	key      string
+	val      int64
+	original int64
+}
+
+func (c clampedInt) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64(c.key, c.val)
+	enc.AddBool(c.key+"_clamped", true)
+	enc.AddInt64(c.key+"_original", c.original)
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClampInt(t *testing.T) {
+	tests := []struct {
+		desc     string
+		val      int64
+		expected map[string]interface{}
+	}{
+		{
+			desc: "below min",
+			val:  -5,
+			expected: map[string]interface{}{
+				"n":          int64(0),
+				"n_clamped":  true,
+				"n_original": int64(-5),
+			},
+		},
+		{
+			desc:     "at min",
+			val:      0,
+			expected: map[string]interface{}{"n": int64(0)},
+		},
+		{
+			desc:     "in range",
+			val:      5,
+			expected: map[string]interface{}{"n": int64(5)},
+		},
+		{
+			desc:     "at max",
+			val:      10,
+			expected: map[string]interface{}{"n": int64(10)},
+		},
+		{
+			desc: "above max",
+			val:  250,
+			expected: map[string]interface{}{
+				"n":          int64(10),
+				"n_clamped":  true,
+				"n_original": int64(250),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		f := ClampInt("n", tt.val, 0, 10)
+		f.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields, "Unexpected output %s.", tt.desc)
+		assertCanBeReused(t, f)
+	}
+	assert.Equal(t, Int64("n", 5), ClampInt("n", 5, 0, 10), "Expected in-range values to be plain Int64 fields.")
+}
@@ -23,6 +23,7 @@ package zap
 import (
 	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
@@ -55,3 +56,64 @@ func runConcurrently(goroutines, iterations int, wg *sync.WaitGroup, f func()) {
 		}()
 	}
 }
+
+// stubClock is a zapcore.Clock whose time only moves when a test changes it.
+// Functions scheduled with AfterFunc run only when the test calls fire.
+type stubClock struct {
+	sync.Mutex
+	now    time.Time
+	timers []*stubTimer
+}
+
+type stubTimer struct {
+	f    func()
+	done bool
+}
+
+func (c *stubClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *stubClock) AfterFunc(_ time.Duration, f func()) func() bool {
+	c.Lock()
+	defer c.Unlock()
+	t := &stubTimer{f: f}
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.Lock()
+		defer c.Unlock()
+		stopped := !t.done
+		t.done = true
+		return stopped
+	}
+}
+
+// NewTicker returns a ticker that never ticks.
+func (c *stubClock) NewTicker(time.Duration) (<-chan time.Time, func()) {
+	return nil, func() {}
+}
+
+func (c *stubClock) add(d time.Duration) {
+	c.Lock()
+	c.now = c.now.Add(d)
+	c.Unlock()
+}
+
+// fire runs the pending functions scheduled with AfterFunc.
+func (c *stubClock) fire() {
+	c.Lock()
+	var due []*stubTimer
+	for _, t := range c.timers {
+		if !t.done {
+			t.done = true
+			due = append(due, t)
+		}
+	}
+	c.timers = nil
+	c.Unlock()
+	for _, t := range due {
+		t.f()
+	}
+}
@@ -21,9 +21,12 @@
 package zap
 
 import (
+	"fmt"
+	"os"
 	"sort"
 	"time"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -35,12 +38,45 @@ import (
 type SamplingConfig struct {
 	Initial    int `json:"initial" yaml:"initial"`
 	Thereafter int `json:"thereafter" yaml:"thereafter"`
+	// Hook, if set, is called with each sampling decision, which makes it
+	// easy to count dropped entries. See zapcore.SamplerHook for details.
+	Hook func(zapcore.Entry, zapcore.SamplingDecision) `json:"-" yaml:"-"`
+}
+
+// BufferingConfig sets a buffering strategy for the logger's output. Writes
+// are collected in memory and written out when the buffer fills, when the
+// flush interval elapses, and whenever the logger is synced. Since loggers
+// sync before exiting on Fatal and Panic entries, those entries are written
+// out before the process exits.
+//
+// Zero values use the defaults of 256 kB and 30 seconds. See
+// zapcore.NewBufferedWriteSyncer for details.
+type BufferingConfig struct {
+	// Size is the size of the buffer, in bytes.
+	Size int `json:"size" yaml:"size"`
+	// FlushInterval is the longest time buffered data waits to be written.
+	FlushInterval time.Duration `json:"flushInterval" yaml:"flushInterval"`
+}
+
+// SplitOutputConfig sends severe entries to separate outputs. Entries at or
+// above Level are written to OutputPaths instead of the Config's
+// OutputPaths, which get the rest; for example, a Level of "error" with an
+// OutputPaths of ["stderr"] keeps errors on standard error and everything
+// else on standard out, as container orchestrators often expect.
+//
+// Both sets of outputs use the Config's encoding and buffering. See
+// zapcore.NewSplitCore for details.
+type SplitOutputConfig struct {
+	Level       zapcore.Level `json:"level" yaml:"level"`
+	OutputPaths []string      `json:"outputPaths" yaml:"outputPaths"`
 }
 
 // Config offers a declarative way to construct a logger. It doesn't do
 // anything that can't be done with New, Options, and the various
 // zapcore.WriteSyncer and zapcore.Core wrappers, but it's a simpler way to
-// toggle common options.
+// toggle common options. Since its fields are tagged for both JSON and YAML,
+// a Config can be unmarshaled from a deployment's configuration files and
+// turned into a Logger with Build, without wiring up any options in code.
 //
 // Note that Config intentionally supports only the most common options. More
 // unusual logging setups (logging to network connections or message queues,
@@ -55,6 +91,14 @@ type Config struct {
 	// level, so calling Config.Level.SetLevel will atomically change the log
 	// level of all loggers descended from this config.
 	Level AtomicLevel `json:"level" yaml:"level"`
+	// NameLevels overrides Level for named loggers (see Logger.Named). Each
+	// entry uses the level of the longest configured prefix of its logger's
+	// name, so {"rpc": "debug", "db": "warn"} enables debug logs from "rpc"
+	// and "rpc.client", but only warnings and above from "db". The name "*"
+	// sets the level of entries whose names match no other prefix, including
+	// unnamed loggers; without it, they use Level. Like Level, these are
+	// dynamic levels that can be changed at runtime. See WithNameLevels.
+	NameLevels map[string]AtomicLevel `json:"nameLevels" yaml:"nameLevels"`
 	// Development puts the logger in development mode, which changes the
 	// behavior of DPanicLevel and takes stacktraces more liberally.
 	Development bool `json:"development" yaml:"development"`
@@ -65,18 +109,53 @@ type Config struct {
 	// default, stacktraces are captured for WarnLevel and above logs in
 	// development and ErrorLevel and above in production.
 	DisableStacktrace bool `json:"disableStacktrace" yaml:"disableStacktrace"`
+	// StacktraceLevel overrides the level at and above which stacktraces are
+	// captured. A nil StacktraceLevel keeps the defaults described above.
+	StacktraceLevel *zapcore.Level `json:"stacktraceLevel" yaml:"stacktraceLevel"`
 	// Sampling sets a sampling policy. A nil SamplingConfig disables sampling.
 	Sampling *SamplingConfig `json:"sampling" yaml:"sampling"`
-	// Encoding sets the logger's encoding. Valid values are "json" and
-	// "console", as well as any third-party encodings registered via
-	// RegisterEncoder.
+	// DeduplicateKeys makes later fields replace earlier fields with the same
+	// key, including InitialFields and fields added via With. See Dedup for
+	// details.
+	DeduplicateKeys bool `json:"deduplicateKeys" yaml:"deduplicateKeys"`
+	// Encoding sets the logger's encoding. Valid values are "json",
+	// "prettyjson", "console", "msgpack", "text", and "logfmt", as well as any
+	// third-party encodings registered via RegisterEncoder.
 	Encoding string `json:"encoding" yaml:"encoding"`
+	// Color controls whether level names are colored, which is helpful when
+	// reading console or text output in a terminal. Valid values are
+	// "always", "never", and "auto", which colors levels only if every output
+	// path is "stdout" or "stderr" and connected to a terminal. The empty
+	// string is the same as "never". Coloring wraps the EncoderConfig's
+	// EncodeLevel with zapcore.ColorLevels, so it's meant for encoders that
+	// aren't already colored.
+	Color string `json:"color" yaml:"color"`
 	// EncoderConfig sets options for the chosen encoder. See
 	// zapcore.EncoderConfig for details.
 	EncoderConfig zapcore.EncoderConfig `json:"encoderConfig" yaml:"encoderConfig"`
+	// Schema names a registered Schema, like "ecs" or "cloudLogging", that
+	// replaces EncoderConfig and adds the schema's static fields to
+	// InitialFields. The whole EncoderConfig is replaced, including its
+	// LineEnding and encoders, except that a BufferPool is kept. See
+	// RegisterSchema for details.
+	Schema string `json:"schema" yaml:"schema"`
+	// SortKeys writes each entry's fields, including InitialFields and
+	// fields added via With, sorted by key rather than in the order they were
+	// added, which keeps output stable for diffs and golden tests. See
+	// zapcore.NewSortedEncoder for details.
+	SortKeys bool `json:"sortKeys" yaml:"sortKeys"`
 	// OutputPaths is a list of URLs or file paths to write logging output to.
 	// See Open for details.
 	OutputPaths []string `json:"outputPaths" yaml:"outputPaths"`
+	// SplitOutput routes entries at or above a level to separate outputs. A
+	// nil SplitOutputConfig writes every entry to OutputPaths.
+	SplitOutput *SplitOutputConfig `json:"splitOutput" yaml:"splitOutput"`
+	// Buffering buffers writes to OutputPaths in memory, trading a little
+	// latency for far fewer writes. A nil BufferingConfig disables buffering.
+	Buffering *BufferingConfig `json:"buffering" yaml:"buffering"`
+	// WriteBOM starts newly-created output files with a UTF-8 byte order
+	// mark. See WriteBOM for details.
+	WriteBOM bool `json:"writeBOM" yaml:"writeBOM"`
 	// ErrorOutputPaths is a list of URLs to write internal logger errors to.
 	// The default is standard error.
 	//
@@ -164,20 +243,36 @@ func NewDevelopmentConfig() Config {
 
 // Build constructs a logger from the Config and Options.
 func (cfg Config) Build(opts ...Option) (*Logger, error) {
+	cfg, schema, err := cfg.applySchema()
+	if err != nil {
+		return nil, err
+	}
+
 	enc, err := cfg.buildEncoder()
 	if err != nil {
 		return nil, err
 	}
 
-	sink, errSink, err := cfg.openSinks()
+	sink, highSink, errSink, closeSinks, err := cfg.openSinks()
 	if err != nil {
 		return nil, err
 	}
 
-	log := New(
-		zapcore.NewCore(enc, sink, cfg.Level),
-		cfg.buildOptions(errSink)...,
-	)
+	var core zapcore.Core
+	if split := cfg.SplitOutput; split != nil {
+		core = zapcore.NewSplitCore(enc, sink, highSink, split.Level, cfg.levelEnabler())
+	} else {
+		core = zapcore.NewCore(enc, sink, cfg.levelEnabler())
+	}
+	if callerFields := schema.CallerFields; callerFields != nil {
+		core = zapcore.RegisterFieldHooks(core, func(ent zapcore.Entry) []Field {
+			if !ent.Caller.Defined {
+				return nil
+			}
+			return callerFields(ent.Caller)
+		})
+	}
+	log := New(core, append(cfg.buildOptions(errSink), OnClose(closeSinks))...)
 	if len(opts) > 0 {
 		log = log.WithOptions(opts...)
 	}
@@ -199,16 +294,42 @@ func (cfg Config) buildOptions(errSink zapcore.WriteSyncer) []Option {
 	if cfg.Development {
 		stackLevel = WarnLevel
 	}
+	if cfg.StacktraceLevel != nil {
+		stackLevel = *cfg.StacktraceLevel
+	}
 	if !cfg.DisableStacktrace {
 		opts = append(opts, AddStacktrace(stackLevel))
 	}
 
+	if len(cfg.NameLevels) > 0 {
+		levels := make(map[string]zapcore.LevelEnabler, len(cfg.NameLevels)+1)
+		levels["*"] = cfg.Level
+		for name, lvl := range cfg.NameLevels {
+			levels[name] = lvl
+		}
+		opts = append(opts, WithNameLevels(levels))
+	}
+
 	if cfg.Sampling != nil {
 		opts = append(opts, WrapCore(func(core zapcore.Core) zapcore.Core {
-			return zapcore.NewSampler(core, time.Second, int(cfg.Sampling.Initial), int(cfg.Sampling.Thereafter))
+			var samplerOpts []zapcore.SamplerOption
+			if cfg.Sampling.Hook != nil {
+				samplerOpts = append(samplerOpts, zapcore.SamplerHook(cfg.Sampling.Hook))
+			}
+			return zapcore.NewSamplerWithOptions(core, time.Second, int(cfg.Sampling.Initial), int(cfg.Sampling.Thereafter), samplerOpts...)
 		}))
 	}
 
+	// Make sure that fields can't clobber the entry's metadata. This must
+	// wrap the core before any fields are added to it.
+	if keys := cfg.EncoderConfig.ReservedKeys(); len(keys) > 0 {
+		opts = append(opts, OnReservedKeyCollision(zapcore.RenameReservedKeys, keys...))
+	}
+
+	if cfg.DeduplicateKeys {
+		opts = append(opts, Dedup())
+	}
+
 	if len(cfg.InitialFields) > 0 {
 		fs := make([]Field, 0, len(cfg.InitialFields))
 		keys := make([]string, 0, len(cfg.InitialFields))
@@ -225,19 +346,131 @@ func (cfg Config) buildOptions(errSink zapcore.WriteSyncer) []Option {
 	return opts
 }
 
-func (cfg Config) openSinks() (zapcore.WriteSyncer, zapcore.WriteSyncer, error) {
-	sink, closeOut, err := Open(cfg.OutputPaths...)
+// levelEnabler returns the level of the Config's Core. Since NameLevels may
+// enable levels that Level doesn't, the Core enables every level enabled by
+// any of them, leaving it to WithNameLevels to pick the right one for each
+// entry.
+func (cfg Config) levelEnabler() zapcore.LevelEnabler {
+	if len(cfg.NameLevels) == 0 {
+		return cfg.Level
+	}
+	enabs := make([]zapcore.LevelEnabler, 0, len(cfg.NameLevels)+1)
+	enabs = append(enabs, cfg.Level)
+	for _, lvl := range cfg.NameLevels {
+		enabs = append(enabs, lvl)
+	}
+	return anyLevelEnabler(enabs)
+}
+
+// anyLevelEnabler enables the levels enabled by any of its LevelEnablers.
+type anyLevelEnabler []zapcore.LevelEnabler
+
+func (enabs anyLevelEnabler) Enabled(lvl zapcore.Level) bool {
+	for _, enab := range enabs {
+		if enab.Enabled(lvl) {
+			return true
+		}
+	}
+	return false
+}
+
+// openSinks opens the outputs for entries, the outputs for entries split off
+// by SplitOutput (nil if there's no split), and the error outputs. It also
+// returns a function that closes all of them.
+func (cfg Config) openSinks() (zapcore.WriteSyncer, zapcore.WriteSyncer, zapcore.WriteSyncer, func() error, error) {
+	sink, closeOut, err := cfg.openOutput(cfg.OutputPaths)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, nil, err
 	}
-	errSink, _, err := Open(cfg.ErrorOutputPaths...)
+	var highSink zapcore.WriteSyncer
+	closeHigh := func() error { return nil }
+	if cfg.SplitOutput != nil {
+		highSink, closeHigh, err = cfg.openOutput(cfg.SplitOutput.OutputPaths)
+		if err != nil {
+			closeOut()
+			return nil, nil, nil, nil, err
+		}
+	}
+	errWriters, closeErr, err := open(cfg.ErrorOutputPaths)
 	if err != nil {
 		closeOut()
+		closeHigh()
+		return nil, nil, nil, nil, err
+	}
+	closeAll := func() error {
+		return multierr.Combine(closeOut(), closeHigh(), closeErr())
+	}
+	return sink, highSink, CombineWriteSyncers(errWriters...), closeAll, nil
+}
+
+func (cfg Config) openOutput(paths []string) (zapcore.WriteSyncer, func() error, error) {
+	writers, closeOut, err := open(paths)
+	if err != nil {
 		return nil, nil, err
 	}
-	return sink, errSink, nil
+	if cfg.WriteBOM {
+		for i := range writers {
+			writers[i] = WriteBOM(writers[i])
+		}
+	}
+	sink := CombineWriteSyncers(writers...)
+	if b := cfg.Buffering; b != nil {
+		buffered := zapcore.NewBufferedWriteSyncer(sink, b.Size, b.FlushInterval, zapcore.DefaultClock)
+		closeWriters := closeOut
+		closeOut = func() error {
+			// Stop the buffer's scheduled flushes before closing the sinks
+			// it writes to.
+			return multierr.Append(buffered.Stop(), closeWriters())
+		}
+		sink = buffered
+	}
+	return sink, closeOut, nil
 }
 
 func (cfg Config) buildEncoder() (zapcore.Encoder, error) {
-	return newEncoder(cfg.Encoding, cfg.EncoderConfig)
+	color, err := cfg.colorLevels()
+	if err != nil {
+		return nil, err
+	}
+	encCfg := cfg.EncoderConfig
+	if color {
+		encCfg.EncodeLevel = zapcore.ColorLevels(encCfg.EncodeLevel)
+	}
+	enc, err := newEncoder(cfg.Encoding, encCfg)
+	if err != nil || !cfg.SortKeys {
+		return enc, err
+	}
+	return zapcore.NewSortedEncoder(enc), nil
+}
+
+func (cfg Config) colorLevels() (bool, error) {
+	switch cfg.Color {
+	case "", "never":
+		return false, nil
+	case "always":
+		return true, nil
+	case "auto":
+		return outputsAreTerminals(cfg.OutputPaths), nil
+	}
+	return false, fmt.Errorf("unknown color mode %q: must be always, never, or auto", cfg.Color)
+}
+
+// outputsAreTerminals reports whether there's at least one output and every
+// output is a standard stream connected to a terminal.
+func outputsAreTerminals(paths []string) bool {
+	for _, path := range paths {
+		switch path {
+		case "stdout":
+			if !zapcore.IsTerminal(os.Stdout) {
+				return false
+			}
+		case "stderr":
+			if !zapcore.IsTerminal(os.Stderr) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return len(paths) > 0
 }
@@ -0,0 +1,376 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const _redacted = "[REDACTED]"
+
+var (
+	_durationType = reflect.TypeOf(time.Duration(0))
+	_timeType     = reflect.TypeOf(time.Time{})
+)
+
+// ConfigSnapshot constructs a field that logs the effective configuration of
+// a program, typically once at startup, as a nested object under the key
+// "config". The configuration is walked with reflection: values that
+// implement encoding.TextMarshaler or fmt.Stringer (like Level and
+// AtomicLevel) are logged as strings, functions by their name, structs (and
+// pointers to them), slices, arrays, and maps as nested objects and arrays,
+// and everything else as a single value. Channels are omitted, and values
+// that refer back to one of their parents are logged as "<cycle>". Nil
+// configurations are skipped.
+//
+// Struct fields are keyed by the name in their json tag, if any, and
+// otherwise by their Go name; unexported fields and fields tagged `json:"-"`
+// or `zap:"-"` are omitted. Fields tagged `zap:"secret"` (like passwords and
+// API keys) are logged as "[REDACTED]", or as an empty string if they're
+// unset. For example,
+//   type Config struct {
+//     Addr     string `json:"addr"`
+//     Password string `json:"password" zap:"secret"`
+//   }
+// is logged as
+//   {"addr":":8080","password":"[REDACTED]"}
+//
+// Since it relies on reflection, ConfigSnapshot is slow; it's meant for
+// startup logging, not hot paths.
+func ConfigSnapshot(cfg interface{}) Field {
+	v := indirect(reflect.ValueOf(cfg))
+	if !v.IsValid() {
+		return Skip()
+	}
+	if v.Kind() != reflect.Struct && v.Kind() != reflect.Map {
+		return Reflect("config", cfg)
+	}
+	return Object("config", configSnapshot{ref: configRef(reflect.ValueOf(cfg)), v: v})
+}
+
+// configSnapshot logs the root of a configuration, tracking the values on the
+// path from it so that cycles end.
+type configSnapshot struct {
+	ref uintptr
+	v   reflect.Value
+}
+
+func (c configSnapshot) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	seen := make(configPath)
+	if c.ref != 0 {
+		seen[c.ref] = true
+	}
+	return configObject{c.v, seen}.MarshalLogObject(enc)
+}
+
+// configPath holds the addresses of the values being logged, from the root of
+// the configuration down to the current value.
+type configPath map[uintptr]bool
+
+// configObject logs a struct or map.
+type configObject struct {
+	v    reflect.Value
+	seen configPath
+}
+
+func (c configObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	v := c.v
+	if v.Kind() == reflect.Map {
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		byName := make(map[string]reflect.Value, len(keys))
+		for i, k := range keys {
+			names[i] = configMapKey(k)
+			byName[names[i]] = v.MapIndex(k)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := addConfigValue(enc, name, byName[name], c.seen); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue // unexported
+		}
+		name, ok := configFieldName(sf)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if hasTagOption(sf.Tag.Get("zap"), "secret") {
+			if isZero(fv) {
+				enc.AddString(name, "")
+			} else {
+				enc.AddString(name, _redacted)
+			}
+			continue
+		}
+		if err := addConfigValue(enc, name, fv, c.seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configArray logs a slice or array.
+type configArray struct {
+	v    reflect.Value
+	seen configPath
+}
+
+func (c configArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for i := 0; i < c.v.Len(); i++ {
+		if err := appendConfigValue(arr, c.v.Index(i), c.seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addConfigValue(enc zapcore.ObjectEncoder, key string, v reflect.Value, seen configPath) error {
+	ref := configRef(v)
+	v = indirect(v)
+	if !v.IsValid() {
+		return enc.AddReflected(key, nil)
+	}
+	if seen[ref] {
+		enc.AddString(key, "<cycle>")
+		return nil
+	}
+	if text, ok, err := configText(v); ok {
+		if err != nil {
+			return err
+		}
+		enc.AddString(key, text)
+		return nil
+	}
+	switch {
+	case v.Type() == _durationType:
+		enc.AddDuration(key, time.Duration(v.Int()))
+	case v.Type() == _timeType:
+		enc.AddTime(key, v.Interface().(time.Time))
+	case v.Kind() == reflect.Struct, v.Kind() == reflect.Map:
+		defer seen.enter(ref)()
+		return enc.AddObject(key, configObject{v, seen})
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+		enc.AddBinary(key, v.Bytes())
+	case v.Kind() == reflect.Slice, v.Kind() == reflect.Array:
+		defer seen.enter(ref)()
+		return enc.AddArray(key, configArray{v, seen})
+	case v.Kind() == reflect.Func:
+		if v.IsNil() {
+			return enc.AddReflected(key, nil)
+		}
+		enc.AddString(key, configFuncName(v))
+	case v.Kind() == reflect.Chan, v.Kind() == reflect.UnsafePointer:
+		// Can't be logged meaningfully.
+	case v.Kind() == reflect.Complex64, v.Kind() == reflect.Complex128:
+		enc.AddComplex128(key, v.Complex())
+	case v.Kind() == reflect.Bool:
+		enc.AddBool(key, v.Bool())
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		enc.AddInt64(key, v.Int())
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr:
+		enc.AddUint64(key, v.Uint())
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		enc.AddFloat64(key, v.Float())
+	case v.Kind() == reflect.String:
+		enc.AddString(key, v.String())
+	default:
+		return enc.AddReflected(key, v.Interface())
+	}
+	return nil
+}
+
+func appendConfigValue(arr zapcore.ArrayEncoder, v reflect.Value, seen configPath) error {
+	ref := configRef(v)
+	v = indirect(v)
+	if !v.IsValid() {
+		return arr.AppendReflected(nil)
+	}
+	if seen[ref] {
+		arr.AppendString("<cycle>")
+		return nil
+	}
+	if text, ok, err := configText(v); ok {
+		if err != nil {
+			return err
+		}
+		arr.AppendString(text)
+		return nil
+	}
+	switch {
+	case v.Type() == _durationType:
+		arr.AppendDuration(time.Duration(v.Int()))
+	case v.Type() == _timeType:
+		arr.AppendTime(v.Interface().(time.Time))
+	case v.Kind() == reflect.Struct, v.Kind() == reflect.Map:
+		defer seen.enter(ref)()
+		return arr.AppendObject(configObject{v, seen})
+	case v.Kind() == reflect.Slice, v.Kind() == reflect.Array:
+		defer seen.enter(ref)()
+		return arr.AppendArray(configArray{v, seen})
+	case v.Kind() == reflect.Func:
+		if v.IsNil() {
+			return arr.AppendReflected(nil)
+		}
+		arr.AppendString(configFuncName(v))
+	case v.Kind() == reflect.Chan, v.Kind() == reflect.UnsafePointer:
+		// Can't be logged meaningfully, but keep the array's length.
+		return arr.AppendReflected(nil)
+	case v.Kind() == reflect.Complex64, v.Kind() == reflect.Complex128:
+		arr.AppendComplex128(v.Complex())
+	case v.Kind() == reflect.Bool:
+		arr.AppendBool(v.Bool())
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		arr.AppendInt64(v.Int())
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uintptr:
+		arr.AppendUint64(v.Uint())
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		arr.AppendFloat64(v.Float())
+	case v.Kind() == reflect.String:
+		arr.AppendString(v.String())
+	default:
+		return arr.AppendReflected(v.Interface())
+	}
+	return nil
+}
+
+// indirect follows pointers and interfaces, returning the zero Value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// configRef returns the address of the value that v refers to through a
+// pointer, slice, or map, or zero if it doesn't refer to one.
+func configRef(v reflect.Value) uintptr {
+	var ref uintptr
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 0
+		}
+		if v.Kind() == reflect.Ptr {
+			ref = v.Pointer()
+		}
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() > 0 {
+		ref = v.Pointer()
+	}
+	return ref
+}
+
+// enter adds ref to the path, returning a function that removes it again.
+func (p configPath) enter(ref uintptr) func() {
+	if ref == 0 || p[ref] {
+		return func() {}
+	}
+	p[ref] = true
+	return func() { delete(p, ref) }
+}
+
+// configText reports whether v (or a pointer to it) implements
+// encoding.TextMarshaler or fmt.Stringer, and if so returns its text. Times
+// and durations are left to the encoder.
+func configText(v reflect.Value) (string, bool, error) {
+	if v.Type() == _durationType || v.Type() == _timeType || !v.CanInterface() {
+		return "", false, nil
+	}
+	candidates := []reflect.Value{v}
+	if v.CanAddr() {
+		candidates = append(candidates, v.Addr())
+	}
+	for _, c := range candidates {
+		switch m := c.Interface().(type) {
+		case encoding.TextMarshaler:
+			text, err := m.MarshalText()
+			return string(text), true, err
+		case fmt.Stringer:
+			return m.String(), true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func configFuncName(v reflect.Value) string {
+	if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return v.Type().String()
+}
+
+func configFieldName(sf reflect.StructField) (string, bool) {
+	if sf.Tag.Get("zap") == "-" {
+		return "", false
+	}
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name, true
+	}
+	return sf.Name, true
+}
+
+func configMapKey(k reflect.Value) string {
+	k = indirect(k)
+	if !k.IsValid() {
+		return "<nil>"
+	}
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+func hasTagOption(tag, opt string) bool {
+	for _, o := range strings.Split(tag, ",") {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func isZero(v reflect.Value) bool {
+	return reflect.DeepEqual(v.Interface(), reflect.Zero(v.Type()).Interface())
+}
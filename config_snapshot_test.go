@@ -0,0 +1,167 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dbConfig struct {
+	Host     string `json:"host"`
+	Password string `json:"password" zap:"secret"`
+}
+
+type serverConfig struct {
+	Addr         string            `json:"addr"`
+	Timeout      time.Duration     `json:"timeout"`
+	Debug        bool              `json:"debug,omitempty"`
+	Workers      int               `json:"workers"`
+	Ratio        float64           `json:"ratio"`
+	APIKey       string            `json:"apiKey" zap:"secret"`
+	Token        string            `zap:"secret"`
+	Untagged     uint              ``
+	Skipped      string            `json:"-"`
+	AlsoSkipped  string            `zap:"-"`
+	DB           *dbConfig         `json:"db"`
+	Replicas     []dbConfig        `json:"replicas"`
+	Labels       map[string]string `json:"labels"`
+	Missing      *dbConfig         `json:"missing"`
+	unexportable string
+}
+
+func TestConfigSnapshot(t *testing.T) {
+	cfg := serverConfig{
+		Addr:         ":8080",
+		Timeout:      time.Second,
+		Workers:      4,
+		Ratio:        0.5,
+		APIKey:       "hunter2",
+		Untagged:     7,
+		Skipped:      "skipped",
+		AlsoSkipped:  "skipped",
+		DB:           &dbConfig{Host: "db1", Password: "secret"},
+		Replicas:     []dbConfig{{Host: "db2", Password: "secret"}, {Host: "db3"}},
+		Labels:       map[string]string{"region": "us-east"},
+		unexportable: "hidden",
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	f := ConfigSnapshot(&cfg)
+	f.AddTo(enc)
+	assert.Equal(t, map[string]interface{}{
+		"addr":     ":8080",
+		"timeout":  time.Second,
+		"debug":    false,
+		"workers":  int64(4),
+		"ratio":    0.5,
+		"apiKey":   "[REDACTED]",
+		"Token":    "",
+		"Untagged": uint64(7),
+		"db":       map[string]interface{}{"host": "db1", "password": "[REDACTED]"},
+		"replicas": []interface{}{
+			map[string]interface{}{"host": "db2", "password": "[REDACTED]"},
+			map[string]interface{}{"host": "db3", "password": ""},
+		},
+		"labels":  map[string]interface{}{"region": "us-east"},
+		"missing": nil,
+	}, enc.Fields["config"], "Unexpected config snapshot.")
+	assertCanBeReused(t, f)
+}
+
+func TestConfigSnapshotNonStructs(t *testing.T) {
+	assert.Equal(t, Skip(), ConfigSnapshot(nil), "Expected nil config to be skipped.")
+	assert.Equal(t, Skip(), ConfigSnapshot((*serverConfig)(nil)), "Expected nil pointer config to be skipped.")
+	assert.Equal(t, Reflect("config", "flat"), ConfigSnapshot("flat"), "Expected non-struct config to be reflected.")
+
+	enc := zapcore.NewMapObjectEncoder()
+	ConfigSnapshot(map[string]interface{}{"b": []int{1, 2}, "a": nil}).AddTo(enc)
+	assert.Equal(t, map[string]interface{}{
+		"config": map[string]interface{}{"a": nil, "b": []interface{}{int64(1), int64(2)}},
+	}, enc.Fields, "Unexpected map config snapshot.")
+}
+
+func TestConfigSnapshotJSON(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{ConfigSnapshot(dbConfig{Host: "db1", Password: "secret"})})
+	if assert.NoError(t, err, "Unexpected error encoding entry.") {
+		assert.Equal(t, `{"config":{"host":"db1","password":"[REDACTED]"}}`+"\n", buf.String(), "Unexpected JSON output.")
+		buf.Free()
+	}
+}
+
+func TestConfigSnapshotZapConfigs(t *testing.T) {
+	tests := []struct {
+		desc         string
+		cfg          Config
+		level        string
+		levelEncoder string
+	}{
+		{"production", NewProductionConfig(), "info", "go.uber.org/zap/zapcore.LowercaseLevelEncoder"},
+		{"development", NewDevelopmentConfig(), "debug", "go.uber.org/zap/zapcore.CapitalLevelEncoder"},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		ConfigSnapshot(tt.cfg).AddTo(enc)
+		assert.NotContains(t, enc.Fields, "configError", "%s: unexpected error.", tt.desc)
+		snap, ok := enc.Fields["config"].(map[string]interface{})
+		if !assert.True(t, ok, "%s: expected a nested object.", tt.desc) {
+			continue
+		}
+		assert.Equal(t, tt.level, snap["level"], "%s: unexpected level.", tt.desc)
+		assert.Equal(t, []interface{}{"stderr"}, snap["outputPaths"], "%s: unexpected output paths.", tt.desc)
+		assert.Equal(t, []interface{}{"stderr"}, snap["errorOutputPaths"], "%s: unexpected error output paths.", tt.desc)
+		encoderConfig, ok := snap["encoderConfig"].(map[string]interface{})
+		if assert.True(t, ok, "%s: expected a nested encoder config.", tt.desc) {
+			assert.Equal(t, tt.levelEncoder, encoderConfig["levelEncoder"], "%s: unexpected level encoder.", tt.desc)
+			assert.Equal(t, "\n", encoderConfig["lineEnding"], "%s: unexpected line ending.", tt.desc)
+		}
+	}
+}
+
+type cyclicConfig struct {
+	Name   string        `json:"name"`
+	Self   *cyclicConfig `json:"self"`
+	Shared *dbConfig     `json:"shared"`
+	Other  *dbConfig     `json:"other"`
+	Notify chan struct{} `json:"notify"`
+}
+
+func TestConfigSnapshotCycles(t *testing.T) {
+	db := &dbConfig{Host: "db1"}
+	cfg := &cyclicConfig{Name: "root", Shared: db, Other: db, Notify: make(chan struct{})}
+	cfg.Self = cfg
+
+	enc := zapcore.NewMapObjectEncoder()
+	ConfigSnapshot(cfg).AddTo(enc)
+	shared := map[string]interface{}{"host": "db1", "password": ""}
+	assert.Equal(t, map[string]interface{}{
+		"name":   "root",
+		"self":   "<cycle>",
+		"shared": shared,
+		"other":  shared,
+	}, enc.Fields["config"], "Expected cycles to end, and values shared without a cycle to be logged.")
+}
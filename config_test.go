@@ -21,9 +21,15 @@
 package zap
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strconv"
 	"testing"
+	"time"
+
+	"go.uber.org/zap/internal/ztest"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -85,6 +91,274 @@ func TestConfig(t *testing.T) {
 	}
 }
 
+func TestConfigFromJSON(t *testing.T) {
+	temp, err := ioutil.TempFile("", "zap-json-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(temp.Name())
+
+	raw := []byte(`{
+	  "level": "warn",
+	  "encoding": "logfmt",
+	  "disableCaller": true,
+	  "outputPaths": [` + strconv.Quote(temp.Name()) + `],
+	  "errorOutputPaths": ["stderr"],
+	  "initialFields": {"service": "api"},
+	  "encoderConfig": {
+	    "messageKey": "msg",
+	    "levelKey": "level",
+	    "nameKey": "logger",
+	    "levelEncoder": "capital",
+	    "durationEncoder": "string"
+	  }
+	}`)
+	var cfg Config
+	require.NoError(t, json.Unmarshal(raw, &cfg), "Failed to unmarshal config.")
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	logger.Info("dropped")
+	logger.Named("db").Warn("slow query", Duration("took", 1500*time.Millisecond))
+	assert.Equal(t, WarnLevel, cfg.Level.Level(), "Unexpected level.")
+
+	contents, err := ioutil.ReadAll(temp)
+	require.NoError(t, err, "Couldn't read log contents from temp file.")
+	assert.Equal(
+		t,
+		`level=WARN logger=db msg="slow query" service=api took=1.5s`+"\n",
+		string(contents),
+		"Unexpected log output.",
+	)
+}
+
+func TestConfigStacktraceLevel(t *testing.T) {
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(`{"level": "debug", "stacktraceLevel": "info"}`), &cfg), "Failed to unmarshal config.")
+	require.NotNil(t, cfg.StacktraceLevel, "Expected a stacktrace level.")
+	assert.Equal(t, InfoLevel, *cfg.StacktraceLevel, "Unexpected stacktrace level.")
+
+	for _, disable := range []bool{false, true} {
+		sink := &ztest.Buffer{}
+		cfg.DisableStacktrace = disable
+		logger := New(
+			zapcore.NewCore(zapcore.NewJSONEncoder(NewProductionEncoderConfig()), sink, cfg.Level),
+			cfg.buildOptions(sink)...,
+		)
+		logger.Debug("debug")
+		logger.Info("info")
+		lines := sink.Lines()
+		require.Equal(t, 2, len(lines), "Unexpected number of lines.")
+		assert.NotContains(t, lines[0], "stacktrace", "Expected no stacktrace below the configured level.")
+		if disable {
+			assert.NotContains(t, lines[1], "stacktrace", "Expected DisableStacktrace to take precedence.")
+		} else {
+			assert.Contains(t, lines[1], "stacktrace", "Expected a stacktrace at the configured level.")
+		}
+	}
+}
+
+func TestConfigNameLevels(t *testing.T) {
+	tests := []struct {
+		desc     string
+		json     string
+		expected []string
+	}{
+		{
+			desc: "no wildcard",
+			json: `{"level": "info", "nameLevels": {"rpc": "debug", "db": "warn"}}`,
+			expected: []string{
+				"root info", "root warn",
+				"rpc debug", "rpc info", "rpc warn",
+				"rpc.client debug", "rpc.client info", "rpc.client warn",
+				"db warn",
+				"other info", "other warn",
+			},
+		},
+		{
+			desc: "wildcard",
+			json: `{"level": "info", "nameLevels": {"rpc": "debug", "*": "error"}}`,
+			expected: []string{
+				"rpc debug", "rpc info", "rpc warn",
+				"rpc.client debug", "rpc.client info", "rpc.client warn",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		var cfg Config
+		require.NoError(t, json.Unmarshal([]byte(tt.json), &cfg), "%s: failed to unmarshal config.", tt.desc)
+		cfg.DisableCaller = true
+		cfg.DisableStacktrace = true
+
+		sink := &ztest.Buffer{}
+		logger := New(
+			zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), sink, cfg.levelEnabler()),
+			cfg.buildOptions(sink)...,
+		)
+		for _, name := range []string{"", "rpc", "rpc.client", "db", "other"} {
+			log := logger.Named(name)
+			if name == "" {
+				name = "root"
+			}
+			log.Debug(name + " debug")
+			log.Info(name + " info")
+			log.Warn(name + " warn")
+		}
+
+		var msgs []string
+		for _, line := range sink.Lines() {
+			var entry struct{ Msg string }
+			require.NoError(t, json.Unmarshal([]byte(line), &entry), "%s: failed to unmarshal output.", tt.desc)
+			msgs = append(msgs, entry.Msg)
+		}
+		assert.Equal(t, tt.expected, msgs, "%s: unexpected entries.", tt.desc)
+	}
+}
+
+func TestConfigNameLevelsRuntimeChange(t *testing.T) {
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(`{"level": "info", "nameLevels": {"db": "warn"}}`), &cfg), "Failed to unmarshal config.")
+	cfg.DisableCaller = true
+
+	sink := &ztest.Buffer{}
+	logger := New(
+		zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), sink, cfg.levelEnabler()),
+		cfg.buildOptions(sink)...,
+	).Named("db")
+	logger.Debug("before")
+	cfg.NameLevels["db"].SetLevel(DebugLevel)
+	logger.Debug("after")
+	assert.Equal(t, []string{`{"msg":"after"}`}, sink.Lines(), "Expected changes to a name's level to apply to running loggers.")
+}
+
+func TestConfigRenamesReservedKeys(t *testing.T) {
+	temp, err := ioutil.TempFile("", "zap-reserved-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(temp.Name())
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{temp.Name()}
+	cfg.EncoderConfig.TimeKey = ""
+	cfg.DisableCaller = true
+	cfg.InitialFields = map[string]interface{}{"msg": "initial"}
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	logger.Info("info", String("level", "debug"))
+
+	byteContents, err := ioutil.ReadAll(temp)
+	require.NoError(t, err, "Couldn't read log contents from temp file.")
+	assert.Equal(t, `{"level":"info","msg":"info","msg_":"initial","level_":"debug"}`+"\n", string(byteContents), "Unexpected log output.")
+}
+
+func TestConfigDeduplicateKeys(t *testing.T) {
+	temp, err := ioutil.TempFile("", "zap-dedup-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(temp.Name())
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{temp.Name()}
+	cfg.EncoderConfig.TimeKey = ""
+	cfg.DisableCaller = true
+	cfg.DeduplicateKeys = true
+	cfg.InitialFields = map[string]interface{}{"service": "initial", "env": "prod"}
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	logger.With(String("service", "with")).Info("info", String("env", "site"))
+
+	byteContents, err := ioutil.ReadAll(temp)
+	require.NoError(t, err, "Couldn't read log contents from temp file.")
+	assert.Equal(t, `{"level":"info","msg":"info","service":"with","env":"site"}`+"\n", string(byteContents), "Unexpected log output.")
+}
+
+func TestConfigBuffering(t *testing.T) {
+	temp, err := ioutil.TempFile("", "zap-buffering-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(temp.Name())
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{temp.Name()}
+	cfg.EncoderConfig.TimeKey = ""
+	cfg.DisableCaller = true
+	cfg.DisableStacktrace = true
+	cfg.Buffering = &BufferingConfig{Size: 1024, FlushInterval: time.Hour}
+	logger, err := cfg.Build(OnFatal(func() {}))
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	read := func() string {
+		contents, err := ioutil.ReadFile(temp.Name())
+		require.NoError(t, err, "Couldn't read log contents from temp file.")
+		return string(contents)
+	}
+
+	logger.Info("buffered")
+	assert.Equal(t, "", read(), "Expected output to be buffered.")
+	require.NoError(t, logger.Sync(), "Unexpected error syncing logger.")
+	assert.Equal(t, `{"level":"info","msg":"buffered"}`+"\n", read(), "Expected Sync to flush the buffer.")
+
+	logger.Info("before fatal")
+	logger.Fatal("fatal")
+	assert.Equal(
+		t,
+		`{"level":"info","msg":"buffered"}`+"\n"+`{"level":"info","msg":"before fatal"}`+"\n"+`{"level":"fatal","msg":"fatal"}`+"\n",
+		read(),
+		"Expected Fatal to flush the buffer.",
+	)
+}
+
+func TestConfigColor(t *testing.T) {
+	tests := []struct {
+		color    string
+		expected string
+	}{
+		{"", "INFO\tinfo\n"},
+		{"never", "INFO\tinfo\n"},
+		{"auto", "INFO\tinfo\n"}, // files aren't terminals
+		{"always", "\x1b[34mINFO\x1b[0m\tinfo\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.color, func(t *testing.T) {
+			temp, err := ioutil.TempFile("", "zap-color-config-test")
+			require.NoError(t, err, "Failed to create temp file.")
+			defer os.Remove(temp.Name())
+
+			cfg := NewDevelopmentConfig()
+			cfg.OutputPaths = []string{temp.Name()}
+			cfg.EncoderConfig.TimeKey = ""
+			cfg.DisableCaller = true
+			cfg.Color = tt.color
+			logger, err := cfg.Build()
+			require.NoError(t, err, "Unexpected error constructing logger.")
+
+			logger.Info("info")
+
+			byteContents, err := ioutil.ReadAll(temp)
+			require.NoError(t, err, "Couldn't read log contents from temp file.")
+			assert.Equal(t, tt.expected, string(byteContents), "Unexpected log output.")
+		})
+	}
+}
+
+func TestConfigInvalidColor(t *testing.T) {
+	cfg := NewDevelopmentConfig()
+	cfg.Color = "sometimes"
+	_, err := cfg.Build()
+	assert.Error(t, err, "Expected an error for an unknown color mode.")
+	assert.Contains(t, err.Error(), `"sometimes"`, "Expected the color mode in the error message.")
+}
+
+func TestOutputsAreTerminals(t *testing.T) {
+	assert.False(t, outputsAreTerminals(nil), "Expected no outputs not to count as terminals.")
+	assert.False(t, outputsAreTerminals([]string{"/tmp/foo.log"}), "Expected files not to count as terminals.")
+	assert.Equal(
+		t,
+		zapcore.IsTerminal(os.Stdout),
+		outputsAreTerminals([]string{"stdout"}),
+		"Expected stdout to count as a terminal only if it is one.",
+	)
+}
+
 func TestConfigWithInvalidPaths(t *testing.T) {
 	tests := []struct {
 		desc      string
@@ -106,3 +380,109 @@ func TestConfigWithInvalidPaths(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigSamplingHook(t *testing.T) {
+	var dropped int
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{}
+	cfg.Sampling = &SamplingConfig{
+		Initial:    1,
+		Thereafter: 100,
+		Hook: func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+			if dec&zapcore.LogDropped > 0 {
+				dropped++
+			}
+		},
+	}
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	for i := 0; i < 3; i++ {
+		logger.Info("sampled")
+	}
+	assert.Equal(t, 2, dropped, "Expected the hook to see dropped entries.")
+}
+
+func TestConfigSortKeys(t *testing.T) {
+	temp, err := ioutil.TempFile("", "zap-sort-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(temp.Name())
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{temp.Name()}
+	cfg.EncoderConfig.TimeKey = ""
+	cfg.DisableCaller = true
+	cfg.SortKeys = true
+	cfg.InitialFields = map[string]interface{}{"service": "api"}
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	logger.With(String("zone", "a")).Info("info", String("attempt", "1"))
+
+	byteContents, err := ioutil.ReadAll(temp)
+	require.NoError(t, err, "Couldn't read log contents from temp file.")
+	assert.Equal(t, `{"level":"info","msg":"info","attempt":"1","service":"api","zone":"a"}`+"\n", string(byteContents), "Unexpected log output.")
+}
+
+func TestConfigSplitOutput(t *testing.T) {
+	low, err := ioutil.TempFile("", "zap-split-low-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(low.Name())
+	high, err := ioutil.TempFile("", "zap-split-high-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(high.Name())
+
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"level": "debug",
+		"encoding": "json",
+		"encoderConfig": {"messageKey": "msg"},
+		"outputPaths": ["`+low.Name()+`"],
+		"splitOutput": {"level": "error", "outputPaths": ["`+high.Name()+`"]}
+	}`), &cfg), "Failed to unmarshal config.")
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	logger.Debug("debug")
+	logger.Warn("warn")
+	logger.Error("error")
+	require.NoError(t, logger.Sync(), "Unexpected error syncing.")
+
+	read := func(f *os.File) string {
+		contents, err := ioutil.ReadAll(f)
+		require.NoError(t, err, "Couldn't read log contents from temp file.")
+		return string(contents)
+	}
+	assert.Equal(t, `{"msg":"debug"}`+"\n"+`{"msg":"warn"}`+"\n", read(low), "Unexpected low-severity output.")
+	assert.Equal(t, `{"msg":"error"}`+"\n", read(high), "Unexpected high-severity output.")
+
+	cfg.SplitOutput.OutputPaths = []string{"/foo/bar/baz"}
+	_, err = cfg.Build()
+	assert.Error(t, err, "Expected an error opening invalid split outputs.")
+}
+
+func TestConfigClose(t *testing.T) {
+	temp, err := ioutil.TempFile("", "zap-close-config-test")
+	require.NoError(t, err, "Failed to create temp file.")
+	defer os.Remove(temp.Name())
+
+	cfg := NewProductionConfig()
+	cfg.EncoderConfig.TimeKey = ""
+	cfg.DisableCaller = true
+	cfg.OutputPaths = []string{temp.Name()}
+	cfg.Buffering = &BufferingConfig{Size: 1024, FlushInterval: time.Hour}
+	logger, err := cfg.Build()
+	require.NoError(t, err, "Unexpected error constructing logger.")
+
+	logger.Named("child").Info("buffered")
+	contents, err := ioutil.ReadFile(temp.Name())
+	require.NoError(t, err, "Couldn't read log contents from temp file.")
+	assert.Empty(t, contents, "Expected the entry to be buffered.")
+
+	require.NoError(t, logger.With(String("k", "v")).Close(), "Unexpected error closing logger.")
+	contents, err = ioutil.ReadFile(temp.Name())
+	require.NoError(t, err, "Couldn't read log contents from temp file.")
+	assert.Equal(t, `{"level":"info","logger":"child","msg":"buffered"}`+"\n", string(contents), "Expected Close to flush buffered entries.")
+
+	assert.Error(t, logger.Sync(), "Expected an error syncing a closed file.")
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context that carries the Logger,
+// so that code further down the call stack can retrieve it with FromContext
+// instead of taking a Logger parameter.
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the Logger carried by the context. If the context
+// doesn't carry a Logger, it returns the global Logger (see L).
+//
+// If the Logger has context extractors (see WithContextExtractor),
+// FromContext returns a child Logger with the fields they extract from ctx.
+// The child keeps the extractors, so fields added to the context later are
+// still extracted once it's stored with NewContext, but it skips fields equal
+// to ones it already has rather than duplicating them.
+func FromContext(ctx context.Context) *Logger {
+	logger, ok := ctx.Value(contextKey{}).(*Logger)
+	if !ok {
+		logger = L()
+	}
+	return logger.withContextFields(ctx)
+}
+
+// WithContextExtractor registers a function that extracts fields, like
+// request or user IDs, from a context. The fields are added to the Logger
+// returned by FromContext, so values stashed in the context by middleware
+// appear in every entry logged while handling the request:
+//
+//   logger := zap.New(core, zap.WithContextExtractor(func(ctx context.Context) []zap.Field {
+//     if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+//       return []zap.Field{zap.String("request_id", id)}
+//     }
+//     return nil
+//   }))
+//
+// A Logger may have several extractors, which run in the order they were
+// registered.
+func WithContextExtractor(extract func(context.Context) []Field) Option {
+	return optionFunc(func(log *Logger) {
+		extractors := make([]func(context.Context) []Field, 0, len(log.ctxExtractors)+1)
+		extractors = append(extractors, log.ctxExtractors...)
+		log.ctxExtractors = append(extractors, extract)
+	})
+}
+
+func (log *Logger) withContextFields(ctx context.Context) *Logger {
+	if len(log.ctxExtractors) == 0 {
+		return log
+	}
+	var fields []Field
+	for _, extract := range log.ctxExtractors {
+		for _, f := range extract(ctx) {
+			if !containsField(log.ctxFields, f) {
+				fields = append(fields, f)
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return log
+	}
+	l := log.With(fields...)
+	n := len(log.ctxFields)
+	l.ctxFields = append(log.ctxFields[:n:n], fields...)
+	return l
+}
+
+func containsField(fields []Field, f Field) bool {
+	for _, existing := range fields {
+		if existing.Equals(f) {
+			return true
+		}
+	}
+	return false
+}
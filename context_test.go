@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testContextKey string
+
+func TestContextLogger(t *testing.T) {
+	assert.Equal(t, L(), FromContext(context.Background()), "Expected the global Logger without a Logger in the context.")
+
+	logger := NewNop().Named("ctx")
+	ctx := NewContext(context.Background(), logger)
+	assert.Equal(t, logger, FromContext(ctx), "Expected the context's Logger.")
+}
+
+func TestContextExtractor(t *testing.T) {
+	extract := func(key string) Option {
+		return WithContextExtractor(func(ctx context.Context) []Field {
+			if v, ok := ctx.Value(testContextKey(key)).(string); ok {
+				return []Field{String(key, v)}
+			}
+			return nil
+		})
+	}
+
+	withLogger(t, DebugLevel, opts(extract("request_id"), extract("user")), func(logger *Logger, logs *observer.ObservedLogs) {
+		ctx := context.WithValue(context.Background(), testContextKey("request_id"), "abc")
+		ctx = NewContext(ctx, logger)
+		FromContext(ctx).Info("no user")
+
+		ctx = context.WithValue(ctx, testContextKey("user"), "jane")
+		child := FromContext(ctx)
+		child.Info("with user")
+		FromContext(NewContext(ctx, child)).Info("stored child")
+
+		FromContext(NewContext(context.Background(), logger)).Info("nothing to extract")
+		FromContext(ctx).Info("extracts again")
+
+		entries := logs.AllUntimed()
+		assert.Equal(t, 5, len(entries), "Unexpected number of entries.")
+		assert.Equal(t, map[string]interface{}{"request_id": "abc"}, entries[0].ContextMap(), "Unexpected fields.")
+		assert.Equal(t, map[string]interface{}{"request_id": "abc", "user": "jane"}, entries[1].ContextMap(), "Unexpected fields.")
+		assert.Equal(t, entries[1].Context, entries[2].Context, "Expected stored children not to extract fields again.")
+		assert.Empty(t, entries[3].Context, "Expected no fields from an empty context.")
+		assert.Equal(t, entries[1].Context, entries[4].Context, "Expected extractors to survive contexts without fields.")
+	})
+}
+
+func TestContextExtractorStoredLogger(t *testing.T) {
+	extract := WithContextExtractor(func(ctx context.Context) []Field {
+		var fields []Field
+		for _, key := range []string{"req", "user"} {
+			if v, ok := ctx.Value(testContextKey(key)).(string); ok {
+				fields = append(fields, String(key, v))
+			}
+		}
+		return fields
+	})
+
+	withLogger(t, DebugLevel, opts(extract), func(logger *Logger, logs *observer.ObservedLogs) {
+		ctx := NewContext(context.Background(), logger)
+		ctx = context.WithValue(ctx, testContextKey("req"), "r1")
+		ctx = NewContext(ctx, FromContext(ctx))
+		ctx = context.WithValue(ctx, testContextKey("user"), "u1")
+		FromContext(ctx).Info("later fields")
+		FromContext(NewContext(ctx, FromContext(ctx))).Info("stored again")
+
+		entries := logs.AllUntimed()
+		assert.Equal(t, 2, len(entries), "Unexpected number of entries.")
+		for _, ent := range entries {
+			assert.Equal(t, []Field{String("req", "r1"), String("user", "u1")}, ent.Context, "%s: unexpected fields.", ent.Message)
+		}
+	})
+}
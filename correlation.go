@@ -0,0 +1,51 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+// CorrelationID is shorthand for the common idiom
+// CorrelationIDWith(existing, NewCorrelationID).
+func CorrelationID(existing string) (Field, string) {
+	return CorrelationIDWith(existing, NewCorrelationID)
+}
+
+// CorrelationIDWith resolves the ID that correlates the logs of a unit of
+// work, like a request, across services: it uses the existing ID (typically
+// taken from an incoming header) if there is one, and otherwise mints a new
+// one with generate. It returns a field that logs the ID under
+// "correlation_id", along with the ID itself so that the caller can
+// propagate it downstream.
+//
+//   field, id := zap.CorrelationID(r.Header.Get("X-Correlation-Id"))
+//   logger := logger.With(field)
+//   outgoing.Header.Set("X-Correlation-Id", id)
+func CorrelationIDWith(existing string, generate func() string) (Field, string) {
+	id := existing
+	if id == "" {
+		id = generate()
+	}
+	return String("correlation_id", id), id
+}
+
+// NewCorrelationID generates a random 128-bit ID, hex-encoded. It's the
+// generator used by CorrelationID.
+func NewCorrelationID() string {
+	return randomID(16)
+}
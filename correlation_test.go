@@ -0,0 +1,59 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelationID(t *testing.T) {
+	field, id := CorrelationID("incoming")
+	assert.Equal(t, "incoming", id, "Expected an existing ID to be passed through.")
+	assert.Equal(t, String("correlation_id", "incoming"), field, "Unexpected field.")
+
+	field, id = CorrelationID("")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{32}$`), id, "Expected a generated 128-bit hex ID.")
+	assert.Equal(t, String("correlation_id", id), field, "Expected the field to log the generated ID.")
+
+	_, other := CorrelationID("")
+	assert.NotEqual(t, id, other, "Expected generated IDs to differ.")
+}
+
+func TestCorrelationIDWith(t *testing.T) {
+	calls := 0
+	generate := func() string {
+		calls++
+		return "generated"
+	}
+
+	field, id := CorrelationIDWith("incoming", generate)
+	assert.Equal(t, "incoming", id, "Expected an existing ID to be passed through.")
+	assert.Equal(t, String("correlation_id", "incoming"), field, "Unexpected field.")
+	assert.Equal(t, 0, calls, "Expected the generator not to be called for an existing ID.")
+
+	field, id = CorrelationIDWith("", generate)
+	assert.Equal(t, "generated", id, "Expected the custom generator to mint the ID.")
+	assert.Equal(t, String("correlation_id", "generated"), field, "Unexpected field.")
+	assert.Equal(t, 1, calls, "Expected the generator to be called once.")
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "go.uber.org/atomic"
+
+// A CounterField is a running count, safe for concurrent use, that can be
+// logged as a field. It suits long-lived loggers that periodically report
+// their own counts, like the number of requests a worker has handled, without
+// any external metrics machinery:
+//   handled := zap.NewCounterField("handled")
+//   ...
+//   handled.Inc()
+//   ...
+//   logger.Info("Worker status.", handled.Field())
+type CounterField struct {
+	name  string
+	count atomic.Int64
+}
+
+// NewCounterField creates a counter, starting at zero, that's logged under
+// the supplied key.
+func NewCounterField(name string) *CounterField {
+	return &CounterField{name: name}
+}
+
+// Inc increments the counter. It doesn't allocate.
+func (c *CounterField) Inc() {
+	c.count.Inc()
+}
+
+// Add adds n, which may be negative, to the counter. It doesn't allocate.
+func (c *CounterField) Add(n int64) {
+	c.count.Add(n)
+}
+
+// Field snapshots the counter's current value as an Int64 field.
+func (c *CounterField) Field() Field {
+	return Int64(c.name, c.count.Load())
+}
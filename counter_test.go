@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterField(t *testing.T) {
+	c := NewCounterField("handled")
+	assert.Equal(t, Int64("handled", 0), c.Field(), "Expected counters to start at zero.")
+
+	c.Inc()
+	c.Add(41)
+	snapshot := c.Field()
+	c.Add(-2)
+	assert.Equal(t, Int64("handled", 42), snapshot, "Expected fields to snapshot the count.")
+	assert.Equal(t, Int64("handled", 40), c.Field(), "Unexpected count after a negative Add.")
+}
+
+func TestCounterFieldConcurrent(t *testing.T) {
+	c := NewCounterField("handled")
+	var wg sync.WaitGroup
+	runConcurrently(10, 100, &wg, func() {
+		c.Inc()
+		c.Add(2)
+		c.Field()
+	})
+	wg.Wait()
+	assert.Equal(t, Int64("handled", 3000), c.Field(), "Unexpected count after concurrent updates.")
+}
+
+func TestCounterFieldAllocs(t *testing.T) {
+	c := NewCounterField("handled")
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Inc()
+		c.Add(2)
+	})
+	assert.Equal(t, float64(0), allocs, "Expected Inc and Add not to allocate.")
+}
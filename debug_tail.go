@@ -0,0 +1,159 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// A TailEntry is an entry kept by a DebugTail, along with all of its fields.
+type TailEntry struct {
+	zapcore.Entry
+	Context []Field
+}
+
+// DebugTail keeps the most recent entries written by a Logger in memory, so
+// that operators can inspect them over HTTP without access to the Logger's
+// regular output. Once it's full, each new entry discards the oldest one, so
+// its memory use is bounded by its size (and the size of the entries' fields).
+//
+// Attach a DebugTail to a Logger with the AddDebugTail option, and serve it
+// from an admin endpoint:
+//   tail := zap.NewDebugTail(1000)
+//   logger := zap.New(core, zap.AddDebugTail(tail, zap.DebugLevel))
+//   http.Handle("/debug/logs", tail)
+type DebugTail struct {
+	mu      sync.Mutex
+	entries []TailEntry
+	next    int
+	full    bool
+}
+
+// NewDebugTail creates a DebugTail that keeps the most recent size entries. A
+// non-positive size keeps none.
+func NewDebugTail(size int) *DebugTail {
+	if size < 0 {
+		size = 0
+	}
+	return &DebugTail{entries: make([]TailEntry, size)}
+}
+
+// AddDebugTail configures the Logger to keep the entries enabled by enab in
+// the supplied DebugTail, in addition to writing them as usual.
+func AddDebugTail(t *DebugTail, enab zapcore.LevelEnabler) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &tailCore{LevelEnabler: enab, tail: t})
+	})
+}
+
+// Entries returns the kept entries, oldest first.
+func (t *DebugTail) Entries() []TailEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return append([]TailEntry(nil), t.entries[:t.next]...)
+	}
+	out := make([]TailEntry, 0, len(t.entries))
+	out = append(out, t.entries[t.next:]...)
+	return append(out, t.entries[:t.next]...)
+}
+
+func (t *DebugTail) add(ent TailEntry) {
+	t.mu.Lock()
+	if len(t.entries) > 0 {
+		t.entries[t.next] = ent
+		t.next++
+		if t.next == len(t.entries) {
+			t.next = 0
+			t.full = true
+		}
+	}
+	t.mu.Unlock()
+}
+
+// ServeHTTP is a simple JSON endpoint that reports the kept entries, oldest
+// first, as newline-delimited JSON. GET requests may filter the entries by
+// level with a query parameter like "?level=warn", which excludes entries
+// below WarnLevel.
+func (t *DebugTail) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET is supported.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	min := zapcore.DebugLevel
+	if s := r.URL.Query().Get("level"); s != "" {
+		if err := min.UnmarshalText([]byte(s)); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid level: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	enc := zapcore.NewJSONEncoder(NewProductionEncoderConfig())
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	for _, ent := range t.Entries() {
+		if ent.Level < min {
+			continue
+		}
+		buf, err := enc.EncodeEntry(ent.Entry, ent.Context)
+		if err != nil {
+			continue
+		}
+		w.Write(buf.Bytes())
+		buf.Free()
+	}
+}
+
+type tailCore struct {
+	zapcore.LevelEnabler
+	tail    *DebugTail
+	context []Field
+}
+
+func (c *tailCore) With(fields []Field) zapcore.Core {
+	return &tailCore{
+		LevelEnabler: c.LevelEnabler,
+		tail:         c.tail,
+		context:      append(c.context[:len(c.context):len(c.context)], fields...),
+	}
+}
+
+func (c *tailCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *tailCore) Write(ent zapcore.Entry, fields []Field) error {
+	all := make([]Field, 0, len(c.context)+len(fields))
+	all = append(all, c.context...)
+	c.tail.add(TailEntry{Entry: ent, Context: append(all, fields...)})
+	return nil
+}
+
+func (c *tailCore) Sync() error {
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugTail(t *testing.T) {
+	tail := NewDebugTail(3)
+	withLogger(t, DebugLevel, opts(AddDebugTail(tail, InfoLevel)), func(logger *Logger, logs *observer.ObservedLogs) {
+		child := logger.With(String("component", "db"))
+		logger.Debug("not kept")
+		for _, msg := range []string{"one", "two", "three", "four"} {
+			child.Info(msg, String("msg_field", msg))
+		}
+		assert.Equal(t, 5, logs.Len(), "Expected entries to be written as usual.")
+	})
+
+	entries := tail.Entries()
+	require.Equal(t, 3, len(entries), "Expected the tail to keep only the most recent entries.")
+	for i, msg := range []string{"two", "three", "four"} {
+		assert.Equal(t, msg, entries[i].Message, "Unexpected message.")
+		assert.Equal(t, []Field{String("component", "db"), String("msg_field", msg)}, entries[i].Context, "Unexpected context.")
+	}
+}
+
+func TestDebugTailPartiallyFull(t *testing.T) {
+	tail := NewDebugTail(3)
+	logger := New(zapcore.NewNopCore(), AddDebugTail(tail, DebugLevel))
+	logger.Info("one")
+	entries := tail.Entries()
+	require.Equal(t, 1, len(entries), "Unexpected number of entries.")
+	assert.Equal(t, "one", entries[0].Message, "Unexpected message.")
+
+	empty := NewDebugTail(-1)
+	New(zapcore.NewNopCore(), AddDebugTail(empty, DebugLevel)).Info("dropped")
+	assert.Empty(t, empty.Entries(), "Expected a non-positive size to keep nothing.")
+}
+
+func TestDebugTailServeHTTP(t *testing.T) {
+	tail := NewDebugTail(10)
+	for _, lvl := range []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		tail.add(TailEntry{
+			Entry:   zapcore.Entry{Level: lvl, Message: lvl.String(), Time: time.Unix(0, 0)},
+			Context: []Field{String("k", "v")},
+		})
+	}
+
+	tests := []struct {
+		method, query string
+		status        int
+		expected      string
+	}{
+		{
+			method: http.MethodGet,
+			status: http.StatusOK,
+			expected: `{"level":"debug","ts":0,"msg":"debug","k":"v"}` + "\n" +
+				`{"level":"info","ts":0,"msg":"info","k":"v"}` + "\n" +
+				`{"level":"warn","ts":0,"msg":"warn","k":"v"}` + "\n" +
+				`{"level":"error","ts":0,"msg":"error","k":"v"}` + "\n",
+		},
+		{
+			method: http.MethodGet,
+			query:  "?level=warn",
+			status: http.StatusOK,
+			expected: `{"level":"warn","ts":0,"msg":"warn","k":"v"}` + "\n" +
+				`{"level":"error","ts":0,"msg":"error","k":"v"}` + "\n",
+		},
+		{
+			method:   http.MethodGet,
+			query:    "?level=bogus",
+			status:   http.StatusBadRequest,
+			expected: "Invalid level",
+		},
+		{
+			method:   http.MethodPost,
+			status:   http.StatusMethodNotAllowed,
+			expected: "Only GET is supported.",
+		},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		tail.ServeHTTP(rec, httptest.NewRequest(tt.method, "/debug/logs"+tt.query, nil))
+		assert.Equal(t, tt.status, rec.Code, "Unexpected status for %s %q.", tt.method, tt.query)
+		if tt.status == http.StatusOK {
+			assert.Equal(t, tt.expected, rec.Body.String(), "Unexpected body for %s %q.", tt.method, tt.query)
+			assert.Equal(t, "application/x-ndjson", rec.Header().Get("Content-Type"), "Unexpected content type.")
+		} else {
+			assert.True(t, strings.HasPrefix(rec.Body.String(), tt.expected), "Unexpected body for %s %q: %q.", tt.method, tt.query, rec.Body.String())
+		}
+	}
+}
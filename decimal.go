@@ -0,0 +1,73 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Decimal constructs a field that carries an exact decimal number, such as an
+// amount of money, represented as an integer number of units and a scale.
+// The value is units * 10^-scale, and it's serialized as a string so that no
+// floating-point rounding ever takes place. For example, Decimal("price",
+// 1234, 2) is encoded as "12.34", and Decimal("price", -5, 3) as "-0.005".
+//
+// A negative scale appends zeros, so Decimal("k", 12, -2) is encoded as
+// "1200". Like Stringer, Decimal is formatted lazily.
+func Decimal(key string, units int64, scale int) Field {
+	return Field{Key: key, Type: zapcore.StringerType, Interface: decimal{units: units, scale: scale}}
+}
+
+type decimal struct {
+	units int64
+	scale int
+}
+
+func (d decimal) String() string {
+	// Work with the magnitude as a uint64, since math.MinInt64 can't be
+	// negated as an int64.
+	mag := uint64(d.units)
+	if d.units < 0 {
+		mag = -mag
+	}
+	digits := strconv.FormatUint(mag, 10)
+
+	switch {
+	case d.scale < 0:
+		digits += strings.Repeat("0", -d.scale)
+	case d.scale > 0:
+		if len(digits) <= d.scale {
+			// Pad with leading zeros so that there's at least one digit before
+			// the decimal point.
+			digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+		}
+		point := len(digits) - d.scale
+		digits = digits[:point] + "." + digits[point:]
+	}
+
+	if d.units < 0 {
+		return "-" + digits
+	}
+	return digits
+}
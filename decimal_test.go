@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		units    int64
+		scale    int
+		expected string
+	}{
+		{1234, 2, "12.34"},
+		{-1234, 2, "-12.34"},
+		{1234, 0, "1234"},
+		{-1234, 0, "-1234"},
+		{0, 0, "0"},
+		{0, 2, "0.00"},
+		{5, 3, "0.005"},
+		{-5, 3, "-0.005"},
+		{100, 2, "1.00"},
+		{12, -2, "1200"},
+		{-12, -2, "-1200"},
+		{math.MaxInt64, 4, "922337203685477.5807"},
+		{math.MinInt64, 4, "-922337203685477.5808"},
+	}
+
+	for _, tt := range tests {
+		f := Decimal("k", tt.units, tt.scale)
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields["k"], "Unexpected output for units %d and scale %d.", tt.units, tt.scale)
+		assertCanBeReused(t, f)
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationBoth(t *testing.T) {
+	tests := []struct {
+		d        time.Duration
+		expected map[string]interface{}
+	}{
+		{0, map[string]interface{}{"ns": int64(0), "human": "0s"}},
+		{1500 * time.Microsecond, map[string]interface{}{"ns": int64(1500000), "human": "1.5ms"}},
+		{1500 * time.Millisecond, map[string]interface{}{"ns": int64(1500000000), "human": "1.5s"}},
+		{26*time.Hour + 3*time.Minute, map[string]interface{}{"ns": int64(93780000000000), "human": "26h3m0s"}},
+		{-time.Second, map[string]interface{}{"ns": int64(-1000000000), "human": "-1s"}},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		f := DurationBoth("elapsed", tt.d)
+		f.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields["elapsed"], "Unexpected output for %v.", tt.d)
+		assertCanBeReused(t, f)
+	}
+}
+
+func TestDurationBothIgnoresEncodeDuration(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{EncodeDuration: zapcore.SecondsDurationEncoder})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{DurationBoth("elapsed", 1500*time.Millisecond)})
+	if assert.NoError(t, err, "Unexpected error encoding entry.") {
+		assert.Equal(t, `{"elapsed":{"ns":1500000000,"human":"1.5s"}}`+"\n", buf.String(), "Unexpected JSON output.")
+		buf.Free()
+	}
+}
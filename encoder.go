@@ -38,13 +38,26 @@ var (
 		"json": func(encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
 			return zapcore.NewJSONEncoder(encoderConfig), nil
 		},
+		"prettyjson": func(encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return zapcore.NewIndentedJSONEncoder(encoderConfig, "", "  "), nil
+		},
+		"msgpack": func(encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return zapcore.NewMsgpackEncoder(encoderConfig), nil
+		},
+		"text": func(encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return zapcore.NewTextEncoder(encoderConfig), nil
+		},
+		"logfmt": func(encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return zapcore.NewLogfmtEncoder(encoderConfig), nil
+		},
 	}
 	_encoderMutex sync.RWMutex
 )
 
 // RegisterEncoder registers an encoder constructor, which the Config struct
-// can then reference. By default, the "json" and "console" encoders are
-// registered.
+// can then reference. By default, the "json", "prettyjson", "console",
+// "msgpack", "text", and "logfmt" encoders are registered. The "prettyjson" encoder
+// indents with two spaces; see zapcore.NewIndentedJSONEncoder.
 //
 // Attempting to register an encoder whose name is already taken returns an
 // error.
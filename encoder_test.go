@@ -29,7 +29,7 @@ import (
 )
 
 func TestRegisterDefaultEncoders(t *testing.T) {
-	testEncodersRegistered(t, "console", "json")
+	testEncodersRegistered(t, "console", "json", "prettyjson", "msgpack", "text", "logfmt")
 }
 
 func TestRegisterEncoder(t *testing.T) {
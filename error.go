@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"fmt"
 	"sync"
 
 	"go.uber.org/zap/zapcore"
@@ -30,6 +31,16 @@ var _errArrayElemPool = sync.Pool{New: func() interface{} {
 	return &errArrayElem{}
 }}
 
+// FieldError is an error that carries its own structured context, like the
+// ID of the user or resource involved. When a FieldError is logged with Error
+// or NamedError, the fields returned by LogFields are added as a nested
+// object under key+"Fields", so they can't collide with the fields supplied
+// at the log site.
+type FieldError interface {
+	error
+	LogFields() []Field
+}
+
 // Error is shorthand for the common idiom NamedError("error", err).
 func Error(err error) Field {
 	return NamedError("error", err)
@@ -38,7 +49,10 @@ func Error(err error) Field {
 // NamedError constructs a field that lazily stores err.Error() under the
 // provided key. Errors which also implement fmt.Formatter (like those produced
 // by github.com/pkg/errors) will also have their verbose representation stored
-// under key+"Verbose". If passed a nil error, the field is a no-op.
+// under key+"Verbose", and FieldErrors will have their fields stored under
+// key+"Fields". Other errors that wrap an error, via an Unwrap or Cause
+// method, will have the chain of wrapped errors nested under key+"Causes".
+// If passed a nil error, the field is a no-op.
 //
 // For the common case in which the key is simply "error", the Error function
 // is shorter and less repetitive.
@@ -49,9 +63,29 @@ func NamedError(key string, err error) Field {
 	return Field{Key: key, Type: zapcore.ErrorType, Interface: err}
 }
 
+// TypedErrors is like Errors, but each error object also records the error's
+// concrete Go type under "errorType" (for example, "*os.PathError"). It's
+// useful for batches of failures, like those collected by multierr or during
+// validation, where grouping by type is more telling than the messages alone.
+//
+// To log the errors combined by multierr, pass multierr.Errors(err).
+func TypedErrors(key string, errs []error) Field {
+	return Array(key, typedErrArray(errs))
+}
+
 type errArray []error
 
 func (errs errArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	return marshalErrArray(arr, errs, false /* typed */)
+}
+
+type typedErrArray []error
+
+func (errs typedErrArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	return marshalErrArray(arr, errs, true /* typed */)
+}
+
+func marshalErrArray(arr zapcore.ArrayEncoder, errs []error, typed bool) error {
 	for i := range errs {
 		if errs[i] == nil {
 			continue
@@ -62,6 +96,7 @@ func (errs errArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
 		// allocating, pool the wrapper type.
 		elem := _errArrayElemPool.Get().(*errArrayElem)
 		elem.error = errs[i]
+		elem.typed = typed
 		arr.AppendObject(elem)
 		elem.error = nil
 		_errArrayElemPool.Put(elem)
@@ -71,10 +106,14 @@ func (errs errArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
 
 type errArrayElem struct {
 	error
+	typed bool
 }
 
 func (e *errArrayElem) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	// Re-use the error field's logic, which supports non-standard error types.
 	Error(e.error).AddTo(enc)
+	if e.typed {
+		enc.AddString("errorType", fmt.Sprintf("%T", e.error))
+	}
 	return nil
 }
@@ -67,6 +67,19 @@ func TestErrorArrayConstructor(t *testing.T) {
 			Errors("", []error{nil, errors.New("foo"), nil, errors.New("bar")}),
 			[]interface{}{map[string]interface{}{"error": "foo"}, map[string]interface{}{"error": "bar"}},
 		},
+		{"empty typed errors", TypedErrors("", nil), []interface{}{}},
+		{
+			"typed errors",
+			TypedErrors("", []error{nil, errors.New("foo"), errQuotaExceeded{user: "alice", quota: 2}}),
+			[]interface{}{
+				map[string]interface{}{"error": "foo", "errorType": "*errors.errorString"},
+				map[string]interface{}{
+					"error":       "quota exceeded",
+					"errorFields": map[string]interface{}{"user": "alice", "quota": int64(2)},
+					"errorType":   "zap.errQuotaExceeded",
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -97,3 +110,63 @@ func TestErrorsArraysHandleRichErrors(t *testing.T) {
 	assert.Contains(t, errMap["errorVerbose"], "egad", "Verbose error string should be a superset of standard error.")
 	assert.Contains(t, errMap["errorVerbose"], "TestErrorsArraysHandleRichErrors", "Verbose error string should contain a stacktrace.")
 }
+
+type errQuotaExceeded struct {
+	user  string
+	quota int
+}
+
+func (e errQuotaExceeded) Error() string {
+	return "quota exceeded"
+}
+
+func (e errQuotaExceeded) LogFields() []Field {
+	return []Field{String("user", e.user), Int("quota", e.quota)}
+}
+
+func TestFieldError(t *testing.T) {
+	var err FieldError = errQuotaExceeded{user: "alice", quota: 10}
+
+	tests := []struct {
+		desc     string
+		field    Field
+		expected map[string]interface{}
+	}{
+		{
+			desc:  "Error",
+			field: Error(err),
+			expected: map[string]interface{}{
+				"error":       "quota exceeded",
+				"errorFields": map[string]interface{}{"user": "alice", "quota": int64(10)},
+			},
+		},
+		{
+			desc:  "NamedError",
+			field: NamedError("cause", err),
+			expected: map[string]interface{}{
+				"cause":       "quota exceeded",
+				"causeFields": map[string]interface{}{"user": "alice", "quota": int64(10)},
+			},
+		},
+		{
+			desc:  "Errors",
+			field: Errors("errs", []error{err}),
+			expected: map[string]interface{}{
+				"errs": []interface{}{map[string]interface{}{
+					"error":       "quota exceeded",
+					"errorFields": map[string]interface{}{"user": "alice", "quota": int64(10)},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		// Site fields with the same keys as the error's fields don't collide.
+		String("user", "bob").AddTo(enc)
+		tt.field.AddTo(enc)
+		tt.expected["user"] = "bob"
+		assert.Equal(t, tt.expected, enc.Fields, "%s: unexpected encoded fields.", tt.desc)
+		assertCanBeReused(t, tt.field)
+	}
+}
@@ -22,9 +22,11 @@ package zap_test
 
 import (
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -102,6 +104,64 @@ func Example_basicConfiguration() {
 	// {"level":"info","message":"logger construction succeeded","foo":"bar"}
 }
 
+func Example_humanReadableEncoders() {
+	// Besides JSON, zap ships encoders meant to be read by people. Both write
+	// the entry's metadata as tab-separated columns, followed by its fields:
+	// the console encoder writes the fields as a JSON object, and the text
+	// encoder writes them as key=value pairs. (In a Config, set Encoding to
+	// "console" or "text".)
+	cfg := zapcore.EncoderConfig{
+		MessageKey:  "msg",
+		LevelKey:    "level",
+		NameKey:     "logger",
+		EncodeLevel: zapcore.CapitalLevelEncoder,
+	}
+	console := zap.New(zapcore.NewCore(zapcore.NewConsoleEncoder(cfg), os.Stdout, zap.DebugLevel))
+	text := zap.New(zapcore.NewCore(zapcore.NewTextEncoder(cfg), os.Stdout, zap.DebugLevel))
+
+	for _, logger := range []*zap.Logger{console, text} {
+		logger.Named("http").Info("Served request.",
+			zap.String("path", "/users"),
+			zap.Int("status", 200),
+			zap.String("agent", "curl 7.54"),
+		)
+	}
+	// Output:
+	// INFO	http	Served request.	{"path": "/users", "status": 200, "agent": "curl 7.54"}
+	// INFO	http	Served request.	path=/users status=200 agent="curl 7.54"
+}
+
+func ExampleRegisterEncoder() {
+	// Third-party encoders register a constructor under a name, which Configs
+	// can then use as their Encoding. As a trivial example, this encoder is
+	// the JSON encoder with every key upper-cased.
+	err := zap.RegisterEncoder("upper-json", func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		cfg.MessageKey = strings.ToUpper(cfg.MessageKey)
+		cfg.LevelKey = strings.ToUpper(cfg.LevelKey)
+		return zapcore.NewJSONEncoder(cfg), nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	logger, err := zap.Config{
+		Level:            zap.NewAtomicLevelAt(zap.InfoLevel),
+		Encoding:         "upper-json",
+		EncoderConfig:    zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: zapcore.LowercaseLevelEncoder},
+		OutputPaths:      []string{"stdout"},
+		ErrorOutputPaths: []string{"stderr"},
+		DisableCaller:    true,
+	}.Build()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
+
+	logger.Info("registered")
+	// Output:
+	// {"LEVEL":"info","MSG":"registered"}
+}
+
 func Example_advancedConfiguration() {
 	// The bundled Config struct only supports the most common configuration
 	// options. More complex needs, like splitting logs between multiple files
@@ -165,6 +225,19 @@ func ExampleNamespace() {
 	// {"level":"info","msg":"tracked some metrics","metrics":{"counter":1}}
 }
 
+func ExampleError() {
+	logger := zap.NewExample()
+	defer logger.Sync()
+
+	// Error uses the conventional "error" key, and a nil error adds nothing,
+	// so there's no need to guard calls.
+	logger.Error("request failed", zap.Error(errors.New("connection reset")))
+	logger.Info("request succeeded", zap.Error(nil))
+	// Output:
+	// {"level":"error","msg":"request failed","error":"connection reset"}
+	// {"level":"info","msg":"request succeeded"}
+}
+
 func ExampleNewStdLog() {
 	logger := zap.NewExample()
 	defer logger.Sync()
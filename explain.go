@@ -0,0 +1,48 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "sync"
+
+// ExplainPlan constructs a field that logs a query plan (like the output of
+// SQL's EXPLAIN) as a string, calling plan only when the entry is actually
+// written. Since the plan isn't computed for disabled entries, it's cheap to
+// attach an expensive EXPLAIN to a debug-level entry that's disabled in
+// production:
+//   logger.Debug("Slow query.", zap.ExplainPlan("plan", func() string {
+//     return explain(db, query)
+//   }))
+// If the entry is written to several Cores, plan is still only called once.
+func ExplainPlan(key string, plan func() string) Field {
+	return Stringer(key, &lazyString{f: plan})
+}
+
+// lazyString is a fmt.Stringer that computes its value at most once.
+type lazyString struct {
+	once sync.Once
+	f    func() string
+	s    string
+}
+
+func (l *lazyString) String() string {
+	l.once.Do(func() { l.s = l.f() })
+	return l.s
+}
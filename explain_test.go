@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainPlan(t *testing.T) {
+	var calls int
+	plan := func() string {
+		calls++
+		return "Seq Scan on users"
+	}
+
+	withLogger(t, InfoLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Debug("Slow query.", ExplainPlan("plan", plan))
+		assert.Equal(t, 0, calls, "Expected the plan not to be computed for disabled entries.")
+
+		logger.Info("Slow query.", ExplainPlan("plan", plan))
+		require.Equal(t, 1, logs.Len(), "Unexpected number of entries.")
+		assert.Equal(t, map[string]interface{}{"plan": "Seq Scan on users"}, logs.AllUntimed()[0].ContextMap(), "Unexpected plan.")
+		assert.Equal(t, 1, calls, "Expected the plan to be computed once for an enabled entry.")
+	})
+}
+
+func TestExplainPlanMemoized(t *testing.T) {
+	var calls int
+	f := ExplainPlan("plan", func() string {
+		calls++
+		return "Index Scan"
+	})
+	for i := 0; i < 3; i++ {
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		assert.Equal(t, "Index Scan", enc.Fields["plan"], "Unexpected plan.")
+	}
+	assert.Equal(t, 1, calls, "Expected the plan to be computed only once.")
+}
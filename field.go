@@ -21,8 +21,10 @@
 package zap
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"go.uber.org/zap/zapcore"
@@ -38,11 +40,37 @@ func Skip() Field {
 	return Field{Type: zapcore.SkipType}
 }
 
+// If returns f if cond is true, and a Skip field otherwise. It lets log sites
+// include context conditionally without building separate field slices:
+//   logger.Info("served request", zap.If(verbose, zap.Stringer("headers", headers)))
+// Since the field is constructed either way, If is best paired with fields
+// that are cheap to construct, which is true of zap's lazily-marshaled fields.
+func If(cond bool, f Field) Field {
+	if cond {
+		return f
+	}
+	return Skip()
+}
+
+// Lazy constructs a field whose value is computed by f only if an entry
+// carrying it is actually encoded, which suits values that are expensive to
+// produce, like large serializations or the results of database lookups:
+//   logger.Debug("cache state", zap.Lazy("entries", func() zap.Field {
+//     return zap.Any("entries", cache.Dump())
+//   }))
+// The field returned by f is logged under key, whatever its own key. It's
+// computed at most once, even if the entry is written to several Cores, so
+// f needn't be safe for concurrent use.
+func Lazy(key string, f func() Field) Field {
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: &lazyField{key: key, f: f}}
+}
+
 // Binary constructs a field that carries an opaque binary blob.
 //
 // Binary data is serialized in an encoding-appropriate format. For example,
-// zap's JSON encoder base64-encodes binary blobs. To log UTF-8 encoded text,
-// use ByteString.
+// zap's JSON encoder base64-encodes binary blobs, writing them straight into
+// the output, and encodes nil or empty blobs as empty strings. To log UTF-8
+// encoded text, use ByteString.
 func Binary(key string, val []byte) Field {
 	return Field{Key: key, Type: zapcore.BinaryType, Interface: val}
 }
@@ -121,6 +149,23 @@ func String(key string, val string) Field {
 	return Field{Key: key, Type: zapcore.StringType, String: val}
 }
 
+// Tag constructs a field that carries a low-cardinality string meant for
+// indexing, like a region or service name. Most Cores treat tags like any
+// other string field, but backends that distinguish tags from fields can use
+// zapcore.NewTagCore to gather them into a separate nested object.
+func Tag(key string, val string) Field {
+	return Field{Key: key, Type: zapcore.TagType, String: val}
+}
+
+// Template constructs a field whose value is a template referring to other
+// fields on the same entry by key, like "{user} did {action}". Templates are
+// only resolved by Cores wrapped with zapcore.NewTemplateCore, which does so
+// at the cost of rendering the referenced fields a second time; elsewhere,
+// the raw template is logged.
+func Template(key string, tmpl string) Field {
+	return Field{Key: key, Type: zapcore.TemplateType, String: tmpl}
+}
+
 // Uint constructs a field with the given key and value.
 func Uint(key string, val uint) Field {
 	return Uint64(key, uint64(val))
@@ -156,9 +201,16 @@ func Uintptr(key string, val uintptr) Field {
 // any object into the logging context, but it's relatively slow and
 // allocation-heavy. Outside tests, Any is always a better choice.
 //
-// If encoding fails (e.g., trying to serialize a map[int]string to JSON), Reflect
-// includes the error message in the final log output.
+// If encoding fails (e.g., trying to serialize a channel to JSON), Reflect
+// omits the value and includes the error message under key+"Error" instead,
+// leaving the rest of the log output intact.
+//
+// Values whose types have an encoder registered with RegisterTypeEncoder use
+// that encoder instead of reflection.
 func Reflect(key string, val interface{}) Field {
+	if f, ok := typeEncoded(key, val); ok {
+		return f
+	}
 	return Field{Key: key, Type: zapcore.ReflectType, Interface: val}
 }
 
@@ -166,7 +218,8 @@ func Reflect(key string, val interface{}) Field {
 // subsequent fields will be added to the new namespace.
 //
 // This helps prevent key collisions when injecting loggers into sub-components
-// or third-party libraries.
+// or third-party libraries. Namespaces added via With also apply to the
+// loggers derived from the result, and further namespaces nest inside them.
 func Namespace(key string) Field {
 	return Field{Key: key, Type: zapcore.NamespaceType}
 }
@@ -177,30 +230,324 @@ func Stringer(key string, val fmt.Stringer) Field {
 	return Field{Key: key, Type: zapcore.StringerType, Interface: val}
 }
 
+// UUID constructs a field that carries a UUID, formatted lazily in the
+// canonical form, like "123e4567-e89b-12d3-a456-426614174000". Since the
+// popular UUID packages represent UUIDs as 16-byte arrays, their values can
+// be converted directly, without any dependency on those packages:
+//   zap.UUID("request_id", [16]byte(id))
+func UUID(key string, val [16]byte) Field {
+	return Stringer(key, uuid(val))
+}
+
+// uuid formats a UUID in its canonical form.
+type uuid [16]byte
+
+func (u uuid) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+var (
+	_minTimeInt64 = time.Unix(0, math.MinInt64)
+	_maxTimeInt64 = time.Unix(0, math.MaxInt64)
+)
+
 // Time constructs a Field with the given key and value. The encoder
 // controls how the time is serialized.
+//
+// Times between the years 1678 and 2262 are stored as nanoseconds since the
+// Unix epoch, which doesn't allocate. Times outside that range, including
+// the zero time.Time, can't be represented that way, so they're stored in
+// full instead.
 func Time(key string, val time.Time) Field {
+	if val.Before(_minTimeInt64) || val.After(_maxTimeInt64) {
+		return Field{Key: key, Type: zapcore.TimeFullType, Interface: val}
+	}
 	return Field{Key: key, Type: zapcore.TimeType, Integer: val.UnixNano(), Interface: val.Location()}
 }
 
+// At constructs a Field that records the time offset after base, like the
+// next run of a scheduled job. It's equivalent to Time(key, base.Add(offset)),
+// so the time keeps base's location and the encoder controls how it's
+// serialized.
+func At(key string, base time.Time, offset time.Duration) Field {
+	return Time(key, base.Add(offset))
+}
+
 // Stack constructs a field that stores a stacktrace of the current goroutine
 // under provided key. Keep in mind that taking a stacktrace is eager and
 // expensive (relatively speaking); this function both makes an allocation and
 // takes about two microseconds.
-func Stack(key string) Field {
-	// Returning the stacktrace as a string costs an allocation, but saves us
-	// from expanding the zapcore.Field union struct to include a byte slice. Since
-	// taking a stacktrace is already so expensive (~10us), the extra allocation
-	// is okay.
-	return String(key, takeStacktrace())
+//
+// Options can limit the trace's depth, skip or filter out frames, and encode
+// the frames as an array of objects rather than a single string:
+//   zap.Stack("stack", zap.MaxFrames(10), zap.FilterFrames("runtime."), zap.StructuredFrames())
+// If MaxFrames drops any frames, their number is added under
+// key+"_truncated", as in StackN.
+func Stack(key string, opts ...StackOption) Field {
+	if len(opts) == 0 {
+		// Returning the stacktrace as a string costs an allocation, but saves
+		// us from expanding the zapcore.Field union struct to include a byte
+		// slice. Since taking a stacktrace is already so expensive (~10us),
+		// the extra allocation is okay.
+		return String(key, takeStacktrace())
+	}
+
+	var so stackOptions
+	for _, opt := range opts {
+		opt.applyStack(&so)
+	}
+	frames, omitted := takeFrames(so)
+	var f Field
+	if so.structured {
+		f = Array(key, frames)
+	} else {
+		f = String(key, frames.String())
+	}
+	if omitted == 0 {
+		return f
+	}
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: truncatedFrames{field: f, omitted: omitted}}
+}
+
+// truncatedFrames adds a stacktrace field and the number of frames omitted
+// from it to the enclosing object.
+type truncatedFrames struct {
+	field   Field
+	omitted int
+}
+
+func (t truncatedFrames) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	t.field.AddTo(enc)
+	enc.AddInt(t.field.Key+"_truncated", t.omitted)
+	return nil
+}
+
+// StackSkip is like Stack, but skips the given number of frames from the top
+// of the stacktrace, after zap's own frames. It's useful for helpers and
+// frameworks that don't want their frames in the trace.
+func StackSkip(key string, skip int) Field {
+	trace, _ := takeStacktraceN(skip, 0)
+	return String(key, trace)
+}
+
+// StackN is like StackSkip, but bounds the stacktrace to at most max bytes,
+// keeping only the whole frames that fit, so that very deep stacks don't
+// produce enormous log lines. If any frames are dropped, their number is added
+// under key+"_truncated".
+func StackN(key string, skip, max int) Field {
+	trace, omitted := takeStacktraceN(skip, max)
+	if omitted == 0 {
+		return String(key, trace)
+	}
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: truncatedStack{key: key, trace: trace, omitted: omitted}}
+}
+
+// truncatedStack adds a truncated stacktrace and the number of frames
+// omitted from it to the enclosing object.
+type truncatedStack struct {
+	key     string
+	trace   string
+	omitted int
+}
+
+func (t truncatedStack) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString(t.key, t.trace)
+	enc.AddInt(t.key+"_truncated", t.omitted)
+	return nil
 }
 
 // Duration constructs a field with the given key and value. The encoder
-// controls how the duration is serialized.
+// controls how the duration is serialized: the EncoderConfig's EncodeDuration
+// renders it as nanoseconds, as floating-point seconds, or in the "1.5s" form
+// of time.Duration's String method. See zapcore.DurationEncoder.
 func Duration(key string, val time.Duration) Field {
 	return Field{Key: key, Type: zapcore.DurationType, Integer: int64(val)}
 }
 
+// nilField returns a field which will explicitly represent a nil pointer.
+func nilField(key string) Field { return Reflect(key, nil) }
+
+// Boolp constructs a field that carries a *bool. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Boolp(key string, val *bool) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Bool(key, *val)
+}
+
+// Complex128p constructs a field that carries a *complex128. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Complex128p(key string, val *complex128) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Complex128(key, *val)
+}
+
+// Complex64p constructs a field that carries a *complex64. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Complex64p(key string, val *complex64) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Complex64(key, *val)
+}
+
+// Float64p constructs a field that carries a *float64. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Float64p(key string, val *float64) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Float64(key, *val)
+}
+
+// Float32p constructs a field that carries a *float32. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Float32p(key string, val *float32) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Float32(key, *val)
+}
+
+// Intp constructs a field that carries a *int. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Intp(key string, val *int) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Int(key, *val)
+}
+
+// Int64p constructs a field that carries a *int64. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Int64p(key string, val *int64) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Int64(key, *val)
+}
+
+// Int32p constructs a field that carries a *int32. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Int32p(key string, val *int32) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Int32(key, *val)
+}
+
+// Int16p constructs a field that carries a *int16. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Int16p(key string, val *int16) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Int16(key, *val)
+}
+
+// Int8p constructs a field that carries a *int8. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Int8p(key string, val *int8) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Int8(key, *val)
+}
+
+// Stringp constructs a field that carries a *string. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Stringp(key string, val *string) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return String(key, *val)
+}
+
+// Uintp constructs a field that carries a *uint. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Uintp(key string, val *uint) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Uint(key, *val)
+}
+
+// Uint64p constructs a field that carries a *uint64. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Uint64p(key string, val *uint64) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Uint64(key, *val)
+}
+
+// Uint32p constructs a field that carries a *uint32. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Uint32p(key string, val *uint32) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Uint32(key, *val)
+}
+
+// Uint16p constructs a field that carries a *uint16. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Uint16p(key string, val *uint16) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Uint16(key, *val)
+}
+
+// Uint8p constructs a field that carries a *uint8. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Uint8p(key string, val *uint8) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Uint8(key, *val)
+}
+
+// Uintptrp constructs a field that carries a *uintptr. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Uintptrp(key string, val *uintptr) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Uintptr(key, *val)
+}
+
+// Timep constructs a field that carries a *time.Time. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Timep(key string, val *time.Time) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Time(key, *val)
+}
+
+// Durationp constructs a field that carries a *time.Duration. The returned Field will safely
+// and explicitly represent `nil` when appropriate.
+func Durationp(key string, val *time.Duration) Field {
+	if val == nil {
+		return nilField(key)
+	}
+	return Duration(key, *val)
+}
+
 // Object constructs a field with the given key and ObjectMarshaler. It
 // provides a flexible, but still type-safe and efficient, way to add map- or
 // struct-like user-defined types to the logging context. The struct's
@@ -209,14 +556,45 @@ func Object(key string, val zapcore.ObjectMarshaler) Field {
 	return Field{Key: key, Type: zapcore.ObjectMarshalerType, Interface: val}
 }
 
+// Inline constructs a Field that is similar to Object, but it will add the
+// elements of the provided ObjectMarshaler to the current namespace, rather
+// than nesting them under a key.
+func Inline(val zapcore.ObjectMarshaler) Field {
+	return Field{Type: zapcore.InlineMarshalerType, Interface: val}
+}
+
+// Dict constructs a field containing the provided key-value pairs. It acts
+// similar to Object, but with the fields specified as arguments, so callers
+// don't need to define a type just to group a few fields under one key.
+func Dict(key string, val ...Field) Field {
+	return Object(key, dictObject(val))
+}
+
+type dictObject []Field
+
+func (d dictObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range d {
+		f.AddTo(enc)
+	}
+	return nil
+}
+
 // Any takes a key and an arbitrary value and chooses the best way to represent
 // them as a field, falling back to a reflection-based approach only if
-// necessary.
+// necessary. Pointers to the primitive types and times are dereferenced, and
+// nil pointers are logged as null.
 //
 // Since byte/uint8 and rune/int32 are aliases, Any can't differentiate between
 // them. To minimize surprises, []byte values are treated as binary blobs, byte
 // values are treated as uint8, and runes are always treated as integers.
+// Slices of []byte ([][]byte) are treated as UTF-8 text, as with ByteStrings.
+//
+// Values whose types have an encoder registered with RegisterTypeEncoder use
+// that encoder instead.
 func Any(key string, value interface{}) Field {
+	if f, ok := typeEncoded(key, value); ok {
+		return f
+	}
 	switch val := value.(type) {
 	case zapcore.ObjectMarshaler:
 		return Object(key, val)
@@ -286,6 +664,8 @@ func Any(key string, value interface{}) Field {
 		return Uint8(key, val)
 	case []byte:
 		return Binary(key, val)
+	case [][]byte:
+		return ByteStrings(key, val)
 	case uintptr:
 		return Uintptr(key, val)
 	case []uintptr:
@@ -298,13 +678,72 @@ func Any(key string, value interface{}) Field {
 		return Duration(key, val)
 	case []time.Duration:
 		return Durations(key, val)
+	case *bool:
+		return Boolp(key, val)
+	case *complex128:
+		return Complex128p(key, val)
+	case *complex64:
+		return Complex64p(key, val)
+	case *float64:
+		return Float64p(key, val)
+	case *float32:
+		return Float32p(key, val)
+	case *int:
+		return Intp(key, val)
+	case *int64:
+		return Int64p(key, val)
+	case *int32:
+		return Int32p(key, val)
+	case *int16:
+		return Int16p(key, val)
+	case *int8:
+		return Int8p(key, val)
+	case *string:
+		return Stringp(key, val)
+	case *uint:
+		return Uintp(key, val)
+	case *uint64:
+		return Uint64p(key, val)
+	case *uint32:
+		return Uint32p(key, val)
+	case *uint16:
+		return Uint16p(key, val)
+	case *uint8:
+		return Uint8p(key, val)
+	case *uintptr:
+		return Uintptrp(key, val)
+	case *time.Time:
+		return Timep(key, val)
+	case *time.Duration:
+		return Durationp(key, val)
 	case error:
 		return NamedError(key, val)
 	case []error:
 		return Errors(key, val)
 	case fmt.Stringer:
 		return Stringer(key, val)
+	case []fmt.Stringer:
+		return Stringers(key, val)
+	case []zapcore.ObjectMarshaler:
+		return Objects(key, val)
 	default:
 		return Reflect(key, val)
 	}
 }
+
+// lazyField is an ObjectMarshaler that computes its field at most once.
+type lazyField struct {
+	key   string
+	f     func() Field
+	once  sync.Once
+	field Field
+}
+
+func (l *lazyField) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	l.once.Do(func() {
+		l.field = l.f()
+		l.field.Key = l.key
+	})
+	l.field.AddTo(enc)
+	return nil
+}
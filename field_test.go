@@ -21,12 +21,16 @@
 package zap
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"net"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -82,8 +86,13 @@ func TestFieldConstructors(t *testing.T) {
 		{"Int16", Field{Key: "k", Type: zapcore.Int16Type, Integer: 1}, Int16("k", 1)},
 		{"Int8", Field{Key: "k", Type: zapcore.Int8Type, Integer: 1}, Int8("k", 1)},
 		{"String", Field{Key: "k", Type: zapcore.StringType, String: "foo"}, String("k", "foo")},
+		{"Tag", Field{Key: "k", Type: zapcore.TagType, String: "foo"}, Tag("k", "foo")},
+		{"Template", Field{Key: "k", Type: zapcore.TemplateType, String: "{foo}"}, Template("k", "{foo}")},
 		{"Time", Field{Key: "k", Type: zapcore.TimeType, Integer: 0, Interface: time.UTC}, Time("k", time.Unix(0, 0).In(time.UTC))},
 		{"Time", Field{Key: "k", Type: zapcore.TimeType, Integer: 1000, Interface: time.UTC}, Time("k", time.Unix(0, 1000).In(time.UTC))},
+		{"Time:Zero", Field{Key: "k", Type: zapcore.TimeFullType, Interface: time.Time{}}, Time("k", time.Time{})},
+		{"Time:Far future", Field{Key: "k", Type: zapcore.TimeFullType, Interface: time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)}, Time("k", time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC))},
+		{"At", Field{Key: "k", Type: zapcore.TimeType, Integer: 1500, Interface: time.UTC}, At("k", time.Unix(0, 1000).In(time.UTC), 500)},
 		{"Uint", Field{Key: "k", Type: zapcore.Uint64Type, Integer: 1}, Uint("k", 1)},
 		{"Uint64", Field{Key: "k", Type: zapcore.Uint64Type, Integer: 1}, Uint64("k", 1)},
 		{"Uint32", Field{Key: "k", Type: zapcore.Uint32Type, Integer: 1}, Uint32("k", 1)},
@@ -93,13 +102,17 @@ func TestFieldConstructors(t *testing.T) {
 		{"Reflect", Field{Key: "k", Type: zapcore.ReflectType, Interface: ints}, Reflect("k", ints)},
 		{"Stringer", Field{Key: "k", Type: zapcore.StringerType, Interface: addr}, Stringer("k", addr)},
 		{"Object", Field{Key: "k", Type: zapcore.ObjectMarshalerType, Interface: name}, Object("k", name)},
+		{"Inline", Field{Type: zapcore.InlineMarshalerType, Interface: name}, Inline(name)},
 		{"Any:ObjectMarshaler", Any("k", name), Object("k", name)},
 		{"Any:ArrayMarshaler", Any("k", bools([]bool{true})), Array("k", bools([]bool{true}))},
 		{"Any:Stringer", Any("k", addr), Stringer("k", addr)},
+		{"Any:Stringers", Any("k", []fmt.Stringer{addr}), Stringers("k", []fmt.Stringer{addr})},
+		{"Any:Objects", Any("k", []zapcore.ObjectMarshaler{name}), Objects("k", []zapcore.ObjectMarshaler{name})},
 		{"Any:Bool", Any("k", true), Bool("k", true)},
 		{"Any:Bools", Any("k", []bool{true}), Bools("k", []bool{true})},
 		{"Any:Byte", Any("k", byte(1)), Uint8("k", 1)},
 		{"Any:Bytes", Any("k", []byte{1}), Binary("k", []byte{1})},
+		{"Any:ByteStrings", Any("k", [][]byte{{1}}), ByteStrings("k", [][]byte{{1}})},
 		{"Any:Complex128", Any("k", 1+2i), Complex128("k", 1+2i)},
 		{"Any:Complex128s", Any("k", []complex128{1 + 2i}), Complex128s("k", []complex128{1 + 2i})},
 		{"Any:Complex64", Any("k", complex64(1+2i)), Complex64("k", 1+2i)},
@@ -157,3 +170,234 @@ func TestStackField(t *testing.T) {
 	assert.Equal(t, takeStacktrace(), f.String, "Unexpected stack trace")
 	assertCanBeReused(t, f)
 }
+
+func TestStackFieldOptions(t *testing.T) {
+	full := takeStacktrace()
+	assert.Equal(t, String("stacktrace", full), Stack("stacktrace", MaxFrames(10)), "Expected a trace that fits to be intact.")
+	assert.Equal(t, String("stacktrace", ""), Stack("stacktrace", FilterFrames("testing.")), "Expected filtered frames to be omitted.")
+
+	f := Stack("stacktrace", StructuredFrames())
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	frames, ok := enc.Fields["stacktrace"].([]interface{})
+	require.True(t, ok, "Expected an array of frames.")
+	require.Equal(t, 1, len(frames), "Expected only the test runner's frame.")
+	frame := frames[0].(map[string]interface{})
+	assert.Equal(t, "testing.tRunner", frame["function"], "Unexpected function.")
+	assert.Contains(t, frame["file"], "testing.go", "Unexpected file.")
+	assert.True(t, frame["line"].(int) > 0, "Expected a line number.")
+	assertCanBeReused(t, f)
+
+	f = Stack("stacktrace", StructuredFrames(), SkipFrames(0), MaxFrames(0))
+	assert.Equal(t, zapcore.ArrayMarshalerType, f.Type, "Expected zero options to keep every frame.")
+
+	assertCanBeReused(t, Stack("stacktrace", MaxFrames(1)))
+}
+
+func TestStackFieldTruncated(t *testing.T) {
+	// Test stacks are too shallow to truncate, so see TestCollectFrames for
+	// the frames that MaxFrames drops.
+	f := truncatedFrames{field: String("stacktrace", "main.a"), omitted: 2}
+	enc := zapcore.NewMapObjectEncoder()
+	require.NoError(t, f.MarshalLogObject(enc), "Unexpected error marshaling.")
+	assert.Equal(t, map[string]interface{}{"stacktrace": "main.a", "stacktrace_truncated": 2}, enc.Fields, "Unexpected fields.")
+}
+
+func TestStackSkipField(t *testing.T) {
+	f := StackSkip("stacktrace", 0)
+	assert.Equal(t, "stacktrace", f.Key, "Unexpected field key.")
+	assert.Equal(t, zapcore.StringType, f.Type, "Unexpected field type.")
+	assert.Equal(t, takeStacktrace(), f.String, "Expected skipping no frames to match Stack.")
+
+	// Only the test runner's frame is left once zap's frames are filtered
+	// out, so skipping it leaves nothing.
+	assert.Equal(t, String("stacktrace", ""), StackSkip("stacktrace", 1), "Expected the skipped frame to be omitted.")
+	assertCanBeReused(t, f)
+}
+
+func TestStackNField(t *testing.T) {
+	full := takeStacktrace()
+	assert.Equal(t, String("stacktrace", full), StackN("stacktrace", 0, len(full)), "Expected a trace that fits to be intact.")
+
+	f := StackN("stacktrace", 0, len(full)-1)
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	assert.Equal(
+		t,
+		map[string]interface{}{"stacktrace": "", "stacktrace_truncated": 1},
+		enc.Fields,
+		"Expected frames that don't fit to be dropped whole.",
+	)
+	assertCanBeReused(t, f)
+}
+
+func TestAtField(t *testing.T) {
+	loc := time.FixedZone("UTC-8", -8*60*60)
+	base := time.Date(2018, 6, 19, 16, 0, 0, 0, loc)
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:    "ts",
+		EncodeTime: zapcore.ISO8601TimeEncoder,
+	})
+
+	buf, err := enc.EncodeEntry(zapcore.Entry{Time: base.Add(90 * time.Minute)}, []Field{At("next", base, 90*time.Minute)})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	defer buf.Free()
+	assert.Equal(
+		t,
+		`{"ts":"2018-06-19T17:30:00.000-0800","next":"2018-06-19T17:30:00.000-0800"}`+"\n",
+		buf.String(),
+		"Expected At to use the same time encoder and location as the entry's timestamp.",
+	)
+}
+
+func TestReflectJSON(t *testing.T) {
+	tests := []struct {
+		desc     string
+		val      interface{}
+		expected map[string]interface{}
+	}{
+		{
+			desc:     "needs escaping",
+			val:      map[string]string{"quote\"": "line\nbreak"},
+			expected: map[string]interface{}{"before": "ok", "obj": map[string]interface{}{"quote\"": "line\nbreak"}, "after": "ok"},
+		},
+		{
+			desc:     "unmarshalable",
+			val:      make(chan int),
+			expected: map[string]interface{}{"before": "ok", "objError": "json: unsupported type: chan int", "after": "ok"},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+		buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{String("before", "ok"), Reflect("obj", tt.val), String("after", "ok")})
+		require.NoError(t, err, "%s: unexpected error encoding entry.", tt.desc)
+
+		var got map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got), "%s: expected valid JSON, got %q.", tt.desc, buf.String())
+		assert.Equal(t, tt.expected, got, "%s: unexpected output.", tt.desc)
+		buf.Free()
+	}
+}
+
+func TestTimeFieldOutOfRange(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{EncodeTime: zapcore.ISO8601TimeEncoder})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{Time("zero", time.Time{}), Time("future", time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC))})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		`{"zero":"0001-01-01T00:00:00.000Z","future":"3000-01-01T00:00:00.000Z"}`+"\n",
+		buf.String(),
+		"Expected out-of-range times to be encoded correctly.",
+	)
+	buf.Free()
+}
+
+func TestUnsignedFieldsJSON(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{
+		Uint64("u64", math.MaxUint64),
+		Uint32("u32", math.MaxUint32),
+		Uint("u", uint(math.MaxUint32)),
+		Uint64s("u64s", []uint64{0, math.MaxInt64 + 1, math.MaxUint64}),
+	})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		`{"u64":18446744073709551615,"u32":4294967295,"u":4294967295,"u64s":[0,9223372036854775808,18446744073709551615]}`+"\n",
+		buf.String(),
+		"Expected unsigned values above math.MaxInt64 to be preserved exactly.",
+	)
+	buf.Free()
+}
+
+func TestIfField(t *testing.T) {
+	assert.Equal(t, String("k", "v"), If(true, String("k", "v")), "Expected If to return the field when the condition holds.")
+	assert.Equal(t, Skip(), If(false, String("k", "v")), "Expected If to skip the field when the condition fails.")
+
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{If(false, Int("a", 1)), Int("b", 2), If(false, Int("c", 3)), If(true, Int("d", 4))})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, `{"b":2,"d":4}`+"\n", buf.String(), "Expected skipped fields to leave no keys or separators.")
+	buf.Free()
+}
+
+func TestDictField(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	Dict("user", String("name", "jane"), Int("age", 42), Inline(username("jdoe"))).AddTo(enc)
+	assert.Equal(t, map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":     "jane",
+			"age":      int64(42),
+			"username": "jdoe",
+		},
+	}, enc.Fields, "Unexpected output from Dict field.")
+	assertCanBeReused(t, Dict("k", String("a", "b")))
+}
+
+func TestPointerFields(t *testing.T) {
+	var (
+		b     = true
+		i     = 42
+		s     = "foo"
+		f     = 3.14
+		u8    = uint8(8)
+		d     = time.Second
+		ts    = time.Unix(0, 0)
+		nil64 *int64
+	)
+	tests := []struct {
+		name   string
+		field  Field
+		expect Field
+	}{
+		{"Boolp", Boolp("k", &b), Bool("k", b)},
+		{"Intp", Intp("k", &i), Int("k", i)},
+		{"Stringp", Stringp("k", &s), String("k", s)},
+		{"Float64p", Float64p("k", &f), Float64("k", f)},
+		{"Uint8p", Uint8p("k", &u8), Uint8("k", u8)},
+		{"Durationp", Durationp("k", &d), Duration("k", d)},
+		{"Timep", Timep("k", &ts), Time("k", ts)},
+		{"Int64p:nil", Int64p("k", nil64), Reflect("k", nil)},
+		{"Any:Boolp", Any("k", &b), Bool("k", b)},
+		{"Any:Stringp", Any("k", &s), String("k", s)},
+		{"Any:Timep", Any("k", &ts), Time("k", ts)},
+		{"Any:Int64p:nil", Any("k", nil64), Reflect("k", nil)},
+	}
+
+	for _, tt := range tests {
+		if !assert.Equal(t, tt.expect, tt.field, "Unexpected output from convenience field constructor %s.", tt.name) {
+			t.Logf("type expected: %T\nGot: %T", tt.expect.Interface, tt.field.Interface)
+		}
+		assertCanBeReused(t, tt.field)
+	}
+}
+
+func TestLazyField(t *testing.T) {
+	var calls int
+	field := Lazy("k", func() Field {
+		calls++
+		return Int("ignored", 42)
+	})
+	assert.Equal(t, 0, calls, "Expected the value not to be computed eagerly.")
+
+	for i := 0; i < 2; i++ {
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+		assert.Equal(t, map[string]interface{}{"k": int64(42)}, enc.Fields, "Expected the value under the field's key.")
+	}
+	assert.Equal(t, 1, calls, "Expected the value to be computed once.")
+	assertCanBeReused(t, field)
+}
+
+func TestUUIDField(t *testing.T) {
+	id := [16]byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}
+	f := UUID("id", id)
+	assert.Equal(t, zapcore.StringerType, f.Type, "Expected the UUID to be formatted lazily.")
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", enc.Fields["id"], "Unexpected UUID format.")
+	assert.Equal(t, "00000000-0000-0000-0000-000000000000", uuid{}.String(), "Unexpected format for the nil UUID.")
+	assertCanBeReused(t, f)
+}
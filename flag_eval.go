@@ -0,0 +1,71 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "go.uber.org/zap/zapcore"
+
+// FlagEval is shorthand for the common idiom
+// NamedFlagEval("featureFlag", flag, variant, reason).
+func FlagEval(flag, variant, reason string) Field {
+	return NamedFlagEval("featureFlag", flag, variant, reason)
+}
+
+// NamedFlagEval constructs a field that records the outcome of a feature
+// flag evaluation: the flag's name, the variant that was served, and
+// (optionally) the reason that variant was chosen. The evaluation is encoded
+// as a nested object, like
+//   {"flag":"new-checkout","variant":"treatment","reason":"targeting-match"}
+// An empty reason is omitted. The object's keys can be renamed with the
+// encoder's zapcore.EncoderConfig.FlagEvalKeys.
+//
+// For the common case in which the key is simply "featureFlag", the FlagEval
+// function is shorter and less repetitive.
+func NamedFlagEval(key, flag, variant, reason string) Field {
+	return Object(key, flagEval{flag: flag, variant: variant, reason: reason})
+}
+
+type flagEval struct {
+	flag    string
+	variant string
+	reason  string
+}
+
+func (f flagEval) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	flagKey, variantKey, reasonKey := "flag", "variant", "reason"
+	if cfg, ok := zapcore.EncoderConfigOf(enc); ok {
+		keys := cfg.FlagEvalKeys
+		if keys.FlagKey != "" {
+			flagKey = keys.FlagKey
+		}
+		if keys.VariantKey != "" {
+			variantKey = keys.VariantKey
+		}
+		if keys.ReasonKey != "" {
+			reasonKey = keys.ReasonKey
+		}
+	}
+	enc.AddString(flagKey, f.flag)
+	enc.AddString(variantKey, f.variant)
+	if f.reason != "" {
+		enc.AddString(reasonKey, f.reason)
+	}
+	return nil
+}
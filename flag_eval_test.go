@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagEval(t *testing.T) {
+	tests := []struct {
+		desc     string
+		field    Field
+		key      string
+		expected map[string]interface{}
+	}{
+		{
+			desc:  "with reason",
+			field: FlagEval("new-checkout", "treatment", "targeting-match"),
+			key:   "featureFlag",
+			expected: map[string]interface{}{
+				"flag":    "new-checkout",
+				"variant": "treatment",
+				"reason":  "targeting-match",
+			},
+		},
+		{
+			desc:  "empty reason",
+			field: FlagEval("new-checkout", "control", ""),
+			key:   "featureFlag",
+			expected: map[string]interface{}{
+				"flag":    "new-checkout",
+				"variant": "control",
+			},
+		},
+		{
+			desc:  "custom key",
+			field: NamedFlagEval("flag", "dark-mode", "on", "default"),
+			key:   "flag",
+			expected: map[string]interface{}{
+				"flag":    "dark-mode",
+				"variant": "on",
+				"reason":  "default",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		tt.field.AddTo(enc)
+		assert.Equal(t, map[string]interface{}{tt.key: tt.expected}, enc.Fields, "%s: unexpected encoded fields.", tt.desc)
+		assertCanBeReused(t, tt.field)
+	}
+}
+
+func TestFlagEvalConfiguredKeys(t *testing.T) {
+	tests := []struct {
+		desc     string
+		keys     zapcore.FlagEvalKeys
+		expected string
+	}{
+		{
+			desc:     "defaults",
+			expected: `{"featureFlag":{"flag":"new-checkout","variant":"treatment","reason":"targeting-match"}}`,
+		},
+		{
+			desc:     "custom keys",
+			keys:     zapcore.FlagEvalKeys{FlagKey: "feature_flag.key", VariantKey: "feature_flag.variant", ReasonKey: "feature_flag.reason"},
+			expected: `{"featureFlag":{"feature_flag.key":"new-checkout","feature_flag.variant":"treatment","feature_flag.reason":"targeting-match"}}`,
+		},
+		{
+			desc:     "partially custom keys",
+			keys:     zapcore.FlagEvalKeys{VariantKey: "served"},
+			expected: `{"featureFlag":{"flag":"new-checkout","served":"treatment","reason":"targeting-match"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{FlagEvalKeys: tt.keys})
+		buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{FlagEval("new-checkout", "treatment", "targeting-match")})
+		if assert.NoError(t, err, "%s: unexpected error encoding.", tt.desc) {
+			assert.Equal(t, tt.expected+"\n", buf.String(), "%s: unexpected output.", tt.desc)
+			buf.Free()
+		}
+	}
+}
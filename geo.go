@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// A LatLonOption configures LatLon.
+type LatLonOption interface {
+	applyLatLon(*latLonOptions)
+}
+
+type latLonOptions struct {
+	geoJSON bool
+}
+
+type latLonOptionFunc func(*latLonOptions)
+
+func (f latLonOptionFunc) applyLatLon(opts *latLonOptions) {
+	f(opts)
+}
+
+// GeoJSON configures LatLon to log coordinates as a GeoJSON-style array,
+// with the longitude first:
+//   [-122.42,37.77]
+func GeoJSON() LatLonOption {
+	return latLonOptionFunc(func(opts *latLonOptions) {
+		opts.geoJSON = true
+	})
+}
+
+// LatLon constructs a field that logs geographic coordinates in degrees. By
+// default, they're logged as an object, which log backends like
+// Elasticsearch can index as a geo_point:
+//   {"lat":37.77,"lon":-122.42}
+// The GeoJSON option logs an array instead.
+//
+// The latitude must be within [-90, 90] and the longitude within
+// [-180, 180]. Out-of-range coordinates are omitted, and the problem is
+// reported under key+"Error", like any other marshaling error.
+func LatLon(key string, lat, lon float64, opts ...LatLonOption) Field {
+	var o latLonOptions
+	for _, opt := range opts {
+		opt.applyLatLon(&o)
+	}
+
+	c := coordinates{lat: lat, lon: lon}
+	if o.geoJSON {
+		return Array(key, c)
+	}
+	return Object(key, c)
+}
+
+type coordinates struct {
+	lat, lon float64
+}
+
+func (c coordinates) validate() error {
+	// Written so that NaNs are out of range.
+	if !(c.lat >= -90 && c.lat <= 90) {
+		return fmt.Errorf("latitude %v out of range [-90, 90]", c.lat)
+	}
+	if !(c.lon >= -180 && c.lon <= 180) {
+		return fmt.Errorf("longitude %v out of range [-180, 180]", c.lon)
+	}
+	return nil
+}
+
+func (c coordinates) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	enc.AddFloat64("lat", c.lat)
+	enc.AddFloat64("lon", c.lon)
+	return nil
+}
+
+func (c coordinates) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	enc.AppendFloat64(c.lon)
+	enc.AppendFloat64(c.lat)
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatLon(t *testing.T) {
+	tests := []struct {
+		desc     string
+		lat, lon float64
+		expected interface{}
+		geoJSON  interface{}
+	}{
+		{
+			desc:     "valid",
+			lat:      37.77,
+			lon:      -122.42,
+			expected: map[string]interface{}{"lat": 37.77, "lon": -122.42},
+			geoJSON:  []interface{}{-122.42, 37.77},
+		},
+		{
+			desc:     "bounds",
+			lat:      -90,
+			lon:      180,
+			expected: map[string]interface{}{"lat": -90.0, "lon": 180.0},
+			geoJSON:  []interface{}{180.0, -90.0},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, f := range []Field{LatLon("loc", tt.lat, tt.lon), LatLon("loc", tt.lat, tt.lon, GeoJSON())} {
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			expected := tt.expected
+			if f.Type == zapcore.ArrayMarshalerType {
+				expected = tt.geoJSON
+			}
+			assert.Equal(t, map[string]interface{}{"loc": expected}, enc.Fields, "Unexpected output for %s coordinates.", tt.desc)
+		}
+	}
+}
+
+func TestLatLonOutOfRange(t *testing.T) {
+	tests := []struct {
+		lat, lon float64
+		err      string
+	}{
+		{90.5, 0, "latitude 90.5 out of range [-90, 90]"},
+		{-91, 0, "latitude -91 out of range [-90, 90]"},
+		{0, 180.1, "longitude 180.1 out of range [-180, 180]"},
+		{0, -200, "longitude -200 out of range [-180, 180]"},
+		{math.NaN(), 0, "latitude NaN out of range [-90, 90]"},
+	}
+
+	for _, tt := range tests {
+		for _, f := range []Field{LatLon("loc", tt.lat, tt.lon), LatLon("loc", tt.lat, tt.lon, GeoJSON())} {
+			enc := zapcore.NewMapObjectEncoder()
+			f.AddTo(enc)
+			assert.Equal(t, tt.err, enc.Fields["locError"], "Expected an error for coordinates (%v, %v).", tt.lat, tt.lon)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"log"
 	"os"
 	"sync"
+	"unicode"
 
 	"go.uber.org/zap/zapcore"
 )
@@ -41,6 +42,9 @@ var (
 	_globalMu sync.RWMutex
 	_globalL  = NewNop()
 	_globalS  = _globalL.Sugar()
+	// _globalF backs the package-level logging functions, which add a frame
+	// between the caller and the Logger.
+	_globalF = _globalL.WithOptions(AddCallerSkip(1))
 )
 
 // L returns the global Logger, which can be reconfigured with ReplaceGlobals.
@@ -68,12 +72,51 @@ func ReplaceGlobals(logger *Logger) func() {
 	prev := _globalL
 	_globalL = logger
 	_globalS = logger.Sugar()
+	_globalF = logger.WithOptions(AddCallerSkip(1))
 	_globalMu.Unlock()
 	return func() { ReplaceGlobals(prev) }
 }
 
+func globalF() *Logger {
+	_globalMu.RLock()
+	l := _globalF
+	_globalMu.RUnlock()
+	return l
+}
+
+// Debug logs a message at DebugLevel using the global Logger. It's shorthand
+// for L().Debug, and like L, it's safe for concurrent use with
+// ReplaceGlobals.
+func Debug(msg string, fields ...Field) {
+	globalF().Debug(msg, fields...)
+}
+
+// Info logs a message at InfoLevel using the global Logger. It's shorthand
+// for L().Info.
+//
+// There's no package-level function for ErrorLevel, since Error constructs
+// a field; use L().Error instead.
+func Info(msg string, fields ...Field) {
+	globalF().Info(msg, fields...)
+}
+
+// Warn logs a message at WarnLevel using the global Logger. It's shorthand
+// for L().Warn.
+func Warn(msg string, fields ...Field) {
+	globalF().Warn(msg, fields...)
+}
+
+// Check returns a CheckedEntry if the global Logger is enabled at the
+// specified level. It's shorthand for L().Check.
+func Check(lvl zapcore.Level, msg string) *zapcore.CheckedEntry {
+	return globalF().Check(lvl, msg)
+}
+
 // NewStdLog returns a *log.Logger which writes to the supplied zap Logger at
-// InfoLevel. To redirect the standard library's package-global logging
+// InfoLevel. Each write becomes an entry whose message is the text written,
+// less any trailing newline, and whose fields are those already added to the
+// zap Logger, so libraries that accept a *log.Logger can be given structured
+// context with l.With. To redirect the standard library's package-global logging
 // functions, use RedirectStdLog instead.
 func NewStdLog(l *Logger) *log.Logger {
 	logger := l.WithOptions(AddCallerSkip(_stdLogDefaultDepth + _loggerWriterDepth))
@@ -81,8 +124,8 @@ func NewStdLog(l *Logger) *log.Logger {
 	return log.New(&loggerWriter{f}, "" /* prefix */, 0 /* flags */)
 }
 
-// NewStdLogAt returns *log.Logger which writes to supplied zap logger at
-// required level.
+// NewStdLogAt is like NewStdLog, but writes to the supplied zap Logger at the
+// specified level. It returns an error if the level isn't recognized.
 func NewStdLogAt(l *Logger, level zapcore.Level) (*log.Logger, error) {
 	logger := l.WithOptions(AddCallerSkip(_stdLogDefaultDepth + _loggerWriterDepth))
 	logFunc, err := levelToFunc(logger, level)
@@ -115,21 +158,23 @@ func RedirectStdLog(l *Logger) func() {
 // standard library's annotations and prefixing.
 //
 // It returns a function to restore the original prefix and flags and reset the
-// standard library's output to os.Stderr.
+// standard library's output to os.Stderr. If the level isn't recognized, it
+// returns an error and leaves the standard library's logger unchanged.
 func RedirectStdLogAt(l *Logger, level zapcore.Level) (func(), error) {
 	return redirectStdLogAt(l, level)
 }
 
 func redirectStdLogAt(l *Logger, level zapcore.Level) (func(), error) {
-	flags := log.Flags()
-	prefix := log.Prefix()
-	log.SetFlags(0)
-	log.SetPrefix("")
 	logger := l.WithOptions(AddCallerSkip(_stdLogDefaultDepth + _loggerWriterDepth))
 	logFunc, err := levelToFunc(logger, level)
 	if err != nil {
+		// Leave the standard library's logger untouched.
 		return nil, err
 	}
+	flags := log.Flags()
+	prefix := log.Prefix()
+	log.SetFlags(0)
+	log.SetPrefix("")
 	log.SetOutput(&loggerWriter{logFunc})
 	return func() {
 		log.SetFlags(flags)
@@ -163,7 +208,9 @@ type loggerWriter struct {
 }
 
 func (l *loggerWriter) Write(p []byte) (int, error) {
-	p = bytes.TrimSpace(p)
-	l.logFunc(string(p))
+	// The standard library terminates each message with a newline, which zap
+	// doesn't need. Leading whitespace, like indentation, is significant.
+	l.logFunc(string(bytes.TrimRightFunc(p, unicode.IsSpace)))
+	// Report the whole input as written, as io.Writer requires.
 	return len(p), nil
 }
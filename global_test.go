@@ -66,6 +66,40 @@ func TestReplaceGlobals(t *testing.T) {
 	assert.Equal(t, initialS, *S(), "Expected func returned from ReplaceGlobals to restore initial S.")
 }
 
+func TestGlobalFuncs(t *testing.T) {
+	// The default global is a no-op.
+	Info("no-op")
+
+	withLogger(t, DebugLevel, []Option{AddCaller()}, func(l *Logger, logs *observer.ObservedLogs) {
+		defer ReplaceGlobals(l)()
+
+		Debug("debug", Int("n", 1))
+		Info("info")
+		Warn("warn")
+		if ce := Check(InfoLevel, "checked"); ce != nil {
+			ce.Write()
+		}
+		assert.Nil(t, Check(DebugLevel-1, "disabled"), "Expected Check to respect the global Logger's level.")
+
+		entries := logs.AllUntimed()
+		require.Equal(t, 4, len(entries), "Unexpected number of entries.")
+		for i, tt := range []struct {
+			level zapcore.Level
+			msg   string
+		}{
+			{DebugLevel, "debug"},
+			{InfoLevel, "info"},
+			{WarnLevel, "warn"},
+			{InfoLevel, "checked"},
+		} {
+			assert.Equal(t, tt.level, entries[i].Level, "Unexpected level.")
+			assert.Equal(t, tt.msg, entries[i].Message, "Unexpected message.")
+			assert.Regexp(t, `go.uber.org/zap/global_test.go:\d+$`, entries[i].Caller.String(), "Expected the caller to be the call site.")
+		}
+		assert.Equal(t, []Field{Int("n", 1)}, entries[0].Context, "Unexpected fields.")
+	})
+}
+
 func TestGlobalsConcurrentUse(t *testing.T) {
 	var (
 		stop atomic.Bool
@@ -84,6 +118,7 @@ func TestGlobalsConcurrentUse(t *testing.T) {
 			for !stop.Load() {
 				L().With(Int("foo", 42)).Named("main").WithOptions(Development()).Info("")
 				S().Info("")
+				Info("")
 			}
 			wg.Done()
 		}()
@@ -102,6 +137,33 @@ func TestNewStdLog(t *testing.T) {
 	})
 }
 
+func TestNewStdLogFormatting(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(l *Logger, logs *observer.ObservedLogs) {
+		std := NewStdLog(l.With(String("lib", "foo")))
+		std.Printf("  indented\n\n")
+		std.Println("with newline")
+		assert.Equal(t, []observer.LoggedEntry{
+			{
+				Entry:   zapcore.Entry{Level: InfoLevel, Message: "  indented"},
+				Context: []Field{String("lib", "foo")},
+			},
+			{
+				Entry:   zapcore.Entry{Level: InfoLevel, Message: "with newline"},
+				Context: []Field{String("lib", "foo")},
+			},
+		}, logs.AllUntimed(), "Unexpected entries.")
+	})
+}
+
+func TestStdLogWriterLength(t *testing.T) {
+	var msg string
+	w := &loggerWriter{func(m string, _ ...Field) { msg = m }}
+	n, err := w.Write([]byte("foo\n"))
+	assert.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, 4, n, "Expected to report the whole input as written.")
+	assert.Equal(t, "foo", msg, "Unexpected message.")
+}
+
 func TestNewStdLogAt(t *testing.T) {
 	// include DPanicLevel here, but do not include Development in options
 	levels := []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel}
@@ -266,6 +328,22 @@ func TestRedirectStdLogAtInvalid(t *testing.T) {
 	assert.Contains(t, err.Error(), "99", "Expected level code in error message")
 }
 
+func TestRedirectStdLogAtInvalidKeepsStdLog(t *testing.T) {
+	initialFlags := log.Flags()
+	initialPrefix := log.Prefix()
+	log.SetFlags(log.Lshortfile)
+	log.SetPrefix("prefix: ")
+	defer func() {
+		log.SetFlags(initialFlags)
+		log.SetPrefix(initialPrefix)
+	}()
+
+	_, err := RedirectStdLogAt(NewNop(), zapcore.Level(99))
+	require.Error(t, err, "Expected to get error.")
+	assert.Equal(t, log.Lshortfile, log.Flags(), "Expected a failed redirect to keep the flags.")
+	assert.Equal(t, "prefix: ", log.Prefix(), "Expected a failed redirect to keep the prefix.")
+}
+
 func checkStdLogMessage(t *testing.T, msg string, logs *observer.ObservedLogs) {
 	require.Equal(t, 1, logs.Len(), "Expected exactly one entry to be logged")
 	entry := logs.AllUntimed()[0]
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"time"
+)
+
+// Heartbeat starts a goroutine that logs msg at InfoLevel once per interval,
+// with the supplied fields and a "seq" field counting up from 1, so that gaps
+// in the heartbeat are easy to spot. Like time.NewTicker, it panics if the
+// interval isn't positive. The ticks come from the Logger's clock (see
+// WithClock), so tests with a fake clock control when heartbeats are logged.
+//
+// The returned function stops the heartbeat; once it returns, the goroutine
+// has exited and no more heartbeats will be logged. It's safe to call more
+// than once.
+func Heartbeat(logger *Logger, interval time.Duration, msg string, fields ...Field) (stop func()) {
+	ticks, stopTicker := logger.clock.NewTicker(interval)
+	logger = logger.With(fields...)
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer stopTicker()
+		var seq uint64
+		for {
+			select {
+			case <-ticks:
+				seq++
+				logger.Info(msg, Uint64("seq", seq))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTicker is a clock whose single ticker is driven by the test.
+type fakeTicker struct {
+	ticks    chan time.Time
+	interval time.Duration
+	stopped  chan struct{}
+}
+
+func newFakeTicker() *fakeTicker {
+	return &fakeTicker{ticks: make(chan time.Time), stopped: make(chan struct{})}
+}
+
+func (ft *fakeTicker) Now() time.Time {
+	return time.Now()
+}
+
+func (ft *fakeTicker) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+func (ft *fakeTicker) NewTicker(d time.Duration) (<-chan time.Time, func()) {
+	ft.interval = d
+	return ft.ticks, func() { close(ft.stopped) }
+}
+
+func TestHeartbeat(t *testing.T) {
+	ft := newFakeTicker()
+	withLogger(t, DebugLevel, opts(WithClock(ft)), func(logger *Logger, logs *observer.ObservedLogs) {
+		stop := Heartbeat(logger, time.Minute, "alive", String("service", "api"))
+		assert.Equal(t, time.Minute, ft.interval, "Unexpected heartbeat interval.")
+
+		for i := 0; i < 3; i++ {
+			// The ticker is unbuffered, so each send waits for the
+			// previous heartbeat to be logged.
+			ft.ticks <- time.Now()
+		}
+		stop()
+		stop() // should be a no-op
+
+		select {
+		case <-ft.stopped:
+		default:
+			t.Fatal("Expected stopping the heartbeat to stop its ticker.")
+		}
+		select {
+		case ft.ticks <- time.Now():
+			t.Fatal("Expected the heartbeat goroutine to exit.")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		entries := logs.AllUntimed()
+		require.Equal(t, 3, len(entries), "Unexpected number of heartbeats.")
+		for i, ent := range entries {
+			assert.Equal(t, zapcore.InfoLevel, ent.Level, "Unexpected heartbeat level.")
+			assert.Equal(t, "alive", ent.Message, "Unexpected heartbeat message.")
+			assert.Equal(t, map[string]interface{}{
+				"service": "api",
+				"seq":     uint64(i + 1),
+			}, ent.ContextMap(), "Unexpected heartbeat context.")
+		}
+	})
+}
+
+func TestHeartbeatRealTicker(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		stop := Heartbeat(logger, time.Millisecond, "alive")
+		for logs.Len() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+		stop()
+		n := logs.Len()
+		time.Sleep(5 * time.Millisecond)
+		assert.Equal(t, n, logs.Len(), "Expected no heartbeats after stopping.")
+	})
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Histogram constructs a field that carries a snapshot of a histogram, such as
+// a latency distribution. Each key in buckets is a bucket's upper bound, and
+// each value is the number of observations that fell into that bucket.
+//
+// The buckets are encoded as a nested object keyed by upper bound. Keys are
+// sorted numerically (not lexically), so the output is deterministic. The
+// catch-all bucket should use math.Inf(1) as its upper bound; it's encoded
+// under the key "+Inf" and always sorts last. Buckets with a NaN upper bound
+// are dropped.
+func Histogram(key string, buckets map[float64]uint64) Field {
+	return Object(key, histogram(buckets))
+}
+
+type histogram map[float64]uint64
+
+func (h histogram) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	bounds := make([]float64, 0, len(h))
+	for b := range h {
+		if math.IsNaN(b) {
+			continue
+		}
+		bounds = append(bounds, b)
+	}
+	sort.Float64s(bounds)
+	for _, b := range bounds {
+		// FormatFloat renders positive infinity as "+Inf", which matches the
+		// convention used by Prometheus and most other metrics systems.
+		enc.AddUint64(strconv.FormatFloat(b, 'g', -1, 64), h[b])
+	}
+	return nil
+}
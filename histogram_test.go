@@ -0,0 +1,66 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"math"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		desc     string
+		buckets  map[float64]uint64
+		expected string
+	}{
+		{"nil", nil, `"k":{}`},
+		{"empty", map[float64]uint64{}, `"k":{}`},
+		{
+			desc:     "numeric sort",
+			buckets:  map[float64]uint64{10: 3, 2.5: 1, 100: 7, 0.5: 0},
+			expected: `"k":{"0.5":0,"2.5":1,"10":3,"100":7}`,
+		},
+		{
+			desc:     "infinite buckets",
+			buckets:  map[float64]uint64{math.Inf(1): 9, 1: 2, math.Inf(-1): 0},
+			expected: `"k":{"-Inf":0,"1":2,"+Inf":9}`,
+		},
+		{
+			desc:     "NaN dropped",
+			buckets:  map[float64]uint64{math.NaN(): 4, 1: 2},
+			expected: `"k":{"1":2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+		Histogram("k", tt.buckets).AddTo(enc)
+		buf, err := enc.EncodeEntry(zapcore.Entry{}, nil)
+		if assert.NoError(t, err, "%s: unexpected error encoding entry.", tt.desc) {
+			assert.Equal(t, "{"+tt.expected+"}\n", buf.String(), "%s: unexpected output.", tt.desc)
+		}
+		assertCanBeReused(t, Histogram("k", tt.buckets))
+	}
+}
@@ -32,8 +32,11 @@ import (
 // logging level.
 //
 // GET requests return a JSON description of the current logging level. PUT
-// requests change the logging level and expect a payload like:
+// and POST requests change the logging level and expect a payload like:
 //   {"level":"info"}
+// All responses, including errors, are JSON. Requests with any other method
+// are rejected with a 405 status and an Allow header listing the supported
+// methods.
 //
 // It's perfectly safe to change the logging level while a program is running.
 func (lvl AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -44,6 +47,7 @@ func (lvl AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Level *zapcore.Level `json:"level"`
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	enc := json.NewEncoder(w)
 
 	switch r.Method {
@@ -52,7 +56,7 @@ func (lvl AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		current := lvl.Level()
 		enc.Encode(payload{Level: &current})
 
-	case http.MethodPut:
+	case http.MethodPut, http.MethodPost:
 		var req payload
 
 		if errmess := func() string {
@@ -73,9 +77,10 @@ func (lvl AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		enc.Encode(req)
 
 	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		enc.Encode(errorResponse{
-			Error: "Only GET and PUT are supported.",
+			Error: "Only GET, PUT, and POST are supported.",
 		})
 	}
 }
@@ -102,6 +102,25 @@ func TestHTTPHandlerPutLevel(t *testing.T) {
 	assertResponse(t, lvl.Level(), body)
 }
 
+func TestHTTPHandlerPostLevel(t *testing.T) {
+	lvl, _ := newHandler()
+
+	code, body := makeRequest(t, "POST", lvl, strings.NewReader(`{"level":"debug"}`))
+
+	assertCodeOK(t, code)
+	assert.Equal(t, DebugLevel, lvl.Level(), "Expected POST to change the level.")
+	assertResponse(t, lvl.Level(), body)
+}
+
+func TestHTTPHandlerContentType(t *testing.T) {
+	lvl, _ := newHandler()
+	for _, method := range []string{"GET", "PUT", "DELETE"} {
+		rec := httptest.NewRecorder()
+		lvl.ServeHTTP(rec, httptest.NewRequest(method, "/", strings.NewReader(`{"level":"error"}`)))
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"), "Unexpected Content-Type for %s.", method)
+	}
+}
+
 func TestHTTPHandlerPutUnrecognizedLevel(t *testing.T) {
 	lvl, _ := newHandler()
 	code, body := makeRequest(t, "PUT", lvl, strings.NewReader(`{"level":"unrecognized-level"}`))
@@ -125,7 +144,19 @@ func TestHTTPHandlerNoLevelSpecified(t *testing.T) {
 
 func TestHTTPHandlerMethodNotAllowed(t *testing.T) {
 	lvl, _ := newHandler()
-	code, body := makeRequest(t, "POST", lvl, strings.NewReader(`{`))
+	code, body := makeRequest(t, "DELETE", lvl, strings.NewReader(`{`))
 	assertCodeMethodNotAllowed(t, code)
 	assertJSONError(t, body)
 }
+
+func TestHTTPHandlerAllowHeader(t *testing.T) {
+	lvl, _ := newHandler()
+	rec := httptest.NewRecorder()
+	lvl.ServeHTTP(rec, httptest.NewRequest("DELETE", "/", nil))
+	assertCodeMethodNotAllowed(t, rec.Code)
+	assert.Equal(t, "GET, PUT, POST", rec.Header().Get("Allow"), "Expected an Allow header listing the supported methods.")
+
+	rec = httptest.NewRecorder()
+	lvl.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "", rec.Header().Get("Allow"), "Unexpected Allow header on a supported method.")
+}
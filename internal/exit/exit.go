@@ -24,14 +24,31 @@ package exit
 
 import "os"
 
-var real = func() { os.Exit(1) }
+var (
+	real   = func() { os.Exit(1) }
+	before func()
+)
 
 // Exit normally terminates the process by calling os.Exit(1). If the package
-// is stubbed, it instead records a call in the testing spy.
+// is stubbed, it instead records a call in the testing spy. Either way, it
+// first runs the function registered with Before, if any.
 func Exit() {
+	if before != nil {
+		before()
+	}
 	real()
 }
 
+// Before registers a function to run whenever Exit is called, replacing any
+// previously-registered function. It returns a function that restores the
+// previous registration. Before isn't safe for concurrent use; it's meant to
+// be called during initialization.
+func Before(f func()) (restore func()) {
+	prev := before
+	before = f
+	return func() { before = prev }
+}
+
 // A StubbedExit is a testing fake for os.Exit.
 type StubbedExit struct {
 	Exited bool
@@ -40,3 +40,15 @@ func TestStub(t *testing.T) {
 		assert.Equal(t, tt.want, s.Exited, "Stub captured unexpected exit value.")
 	}
 }
+
+func TestBefore(t *testing.T) {
+	var calls int
+	restore := Before(func() { calls++ })
+	s := WithStub(Exit)
+	assert.True(t, s.Exited, "Expected Exit to be called.")
+	assert.Equal(t, 1, calls, "Expected the function registered with Before to run.")
+
+	restore()
+	WithStub(Exit)
+	assert.Equal(t, 1, calls, "Expected restoring to unregister the function.")
+}
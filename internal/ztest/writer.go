@@ -24,7 +24,9 @@ import (
 	"bytes"
 	"errors"
 	"io/ioutil"
+	"os"
 	"strings"
+	"time"
 )
 
 // A Syncer is a spy for the Sync portion of zapcore.WriteSyncer.
@@ -94,3 +96,20 @@ func (b *Buffer) Lines() []string {
 func (b *Buffer) Stripped() string {
 	return strings.TrimRight(b.String(), "\n")
 }
+
+// A Terminal is a Buffer that zapcore.IsTerminal mistakes for a terminal.
+type Terminal struct{ Buffer }
+
+// Stat reports that the Terminal is a character device.
+func (t *Terminal) Stat() (os.FileInfo, error) {
+	return terminalInfo{}, nil
+}
+
+type terminalInfo struct{}
+
+func (terminalInfo) Name() string       { return "tty" }
+func (terminalInfo) Size() int64        { return 0 }
+func (terminalInfo) Mode() os.FileMode  { return os.ModeDevice | os.ModeCharDevice | 0620 }
+func (terminalInfo) ModTime() time.Time { return time.Time{} }
+func (terminalInfo) IsDir() bool        { return false }
+func (terminalInfo) Sys() interface{}   { return nil }
@@ -57,6 +57,21 @@ type LevelEnablerFunc func(zapcore.Level) bool
 // Enabled calls the wrapped function.
 func (f LevelEnablerFunc) Enabled(lvl zapcore.Level) bool { return f(lvl) }
 
+// OnlyLevels returns a LevelEnabler that enables exactly the listed levels,
+// rather than a level and everything above it. For example, a Logger built
+// with WithLevel(OnlyLevels(DebugLevel, ErrorLevel)) writes debug- and
+// error-level entries, but drops those at InfoLevel and WarnLevel.
+//
+// Keep in mind that not enabling PanicLevel and FatalLevel only stops those
+// entries from being written; the Logger still panics or exits.
+func OnlyLevels(lvls ...zapcore.Level) zapcore.LevelEnabler {
+	var set [256]bool
+	for _, l := range lvls {
+		set[uint8(l)] = true
+	}
+	return LevelEnablerFunc(func(l zapcore.Level) bool { return set[uint8(l)] })
+}
+
 // An AtomicLevel is an atomically changeable, dynamic logging level. It lets
 // you safely change the log level of a tree of loggers (the root logger and
 // any children created by adding context) at runtime.
@@ -65,7 +80,10 @@ func (f LevelEnablerFunc) Enabled(lvl zapcore.Level) bool { return f(lvl) }
 // alter its level.
 //
 // AtomicLevels must be created with the NewAtomicLevel constructor to allocate
-// their internal atomic pointer.
+// their internal atomic pointer. Copies of an AtomicLevel share that pointer,
+// so it's safe to pass AtomicLevels by value: the Logger, its children, and
+// any Config or handler holding a copy all observe calls to SetLevel
+// immediately.
 type AtomicLevel struct {
 	l *atomic.Int32
 }
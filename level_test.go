@@ -48,6 +48,28 @@ func TestLevelEnablerFunc(t *testing.T) {
 	}
 }
 
+func TestOnlyLevels(t *testing.T) {
+	enab := OnlyLevels(DebugLevel, ErrorLevel, zapcore.Level(-100))
+	tests := []struct {
+		level   zapcore.Level
+		enabled bool
+	}{
+		{zapcore.Level(-100), true},
+		{DebugLevel - 1, false},
+		{DebugLevel, true},
+		{InfoLevel, false},
+		{WarnLevel, false},
+		{ErrorLevel, true},
+		{DPanicLevel, false},
+		{FatalLevel, false},
+		{zapcore.Level(100), false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.enabled, enab.Enabled(tt.level), "Unexpected result applying OnlyLevels to %s", tt.level)
+	}
+	assert.False(t, OnlyLevels().Enabled(InfoLevel), "Expected an empty set of levels to enable nothing.")
+}
+
 func TestNewAtomicLevel(t *testing.T) {
 	lvl := NewAtomicLevel()
 	assert.Equal(t, InfoLevel, lvl.Level(), "Unexpected initial level.")
@@ -75,6 +97,19 @@ func TestAtomicLevelMutation(t *testing.T) {
 	wg.Wait()
 }
 
+func TestAtomicLevelCopiesShareState(t *testing.T) {
+	lvl := NewAtomicLevel()
+	cfg := Config{Level: lvl}
+	copied := lvl
+
+	copied.SetLevel(DebugLevel)
+	assert.Equal(t, DebugLevel, lvl.Level(), "Expected the original to observe changes to a copy.")
+	assert.True(t, cfg.Level.Enabled(DebugLevel), "Expected a Config's copy to observe changes.")
+
+	cfg.Level.SetLevel(ErrorLevel)
+	assert.False(t, copied.Enabled(WarnLevel), "Expected copies to observe changes made via a Config.")
+}
+
 func TestAtomicLevelText(t *testing.T) {
 	tests := []struct {
 		text   string
@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap/zapcore"
+)
+
+// _locals holds the goroutine-local fields set by SetLocal, keyed by
+// goroutine ID.
+var _locals = struct {
+	sync.RWMutex
+	fields map[uint64][]Field
+	// n is the number of goroutines with local fields, which lets loggers
+	// skip the lookup entirely in the common case.
+	n atomic.Int64
+}{fields: make(map[uint64][]Field)}
+
+// SetLocal attaches fields to every entry logged on the current goroutine by
+// Loggers built with the AddLocalFields option, until the returned function
+// is called. It's meant to be used as a scope:
+//   defer zap.SetLocal(zap.String("trace_id", id))()
+// Nested scopes add to the fields of the enclosing scope.
+//
+// SetLocal is a migration aid for legacy code that can't pass a context or a
+// Logger around yet, and its use is discouraged. Go doesn't have
+// goroutine-local storage, so it's emulated on a best-effort basis, with the
+// following caveats:
+//   - Fields never cross goroutine boundaries: goroutines started within the
+//     scope don't inherit them.
+//   - The returned function must be called, or the fields leak; since
+//     goroutine IDs are reused, they might later be attached to an unrelated
+//     goroutine's logs.
+//   - Scopes must be closed in the reverse order they were opened.
+//   - Looking up the current goroutine costs about a microsecond per entry
+//     while any goroutine has local fields.
+// Prefer adding fields to a Logger with With and passing it along.
+func SetLocal(fields ...Field) (clear func()) {
+	id := goroutineID()
+
+	_locals.Lock()
+	prev, nested := _locals.fields[id]
+	all := make([]Field, 0, len(prev)+len(fields))
+	all = append(all, prev...)
+	_locals.fields[id] = append(all, fields...)
+	if !nested {
+		_locals.n.Inc()
+	}
+	_locals.Unlock()
+
+	return func() {
+		_locals.Lock()
+		defer _locals.Unlock()
+		if nested {
+			_locals.fields[id] = prev
+			return
+		}
+		if _, ok := _locals.fields[id]; ok {
+			delete(_locals.fields, id)
+			_locals.n.Dec()
+		}
+	}
+}
+
+// AddLocalFields configures the Logger to attach the fields set by SetLocal
+// on the logging goroutine to each entry. See SetLocal for caveats.
+func AddLocalFields() Option {
+	return optionFunc(func(log *Logger) {
+		log.core = zapcore.RegisterFieldHooks(log.core, localFields)
+	})
+}
+
+func localFields(zapcore.Entry) []Field {
+	if _locals.n.Load() == 0 {
+		return nil
+	}
+	id := goroutineID()
+	_locals.RLock()
+	fields := _locals.fields[id]
+	_locals.RUnlock()
+	return fields
+}
+
+var _goroutinePrefix = []byte("goroutine ")
+
+// goroutineID parses the current goroutine's ID from the header of its stack
+// trace, which reads like "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, _goroutinePrefix)
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLocal(t *testing.T) {
+	withLogger(t, DebugLevel, opts(AddLocalFields()), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("before")
+		func() {
+			defer SetLocal(String("trace", "abc"))()
+			logger.Info("outer")
+			func() {
+				clear := SetLocal(Int("depth", 2))
+				defer clear()
+				logger.Info("inner")
+
+				var wg sync.WaitGroup
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					logger.Info("other goroutine")
+				}()
+				wg.Wait()
+			}()
+			logger.Info("outer again")
+		}()
+		logger.Info("after")
+
+		expected := []map[string]interface{}{
+			{},
+			{"trace": "abc"},
+			{"trace": "abc", "depth": int64(2)},
+			{},
+			{"trace": "abc"},
+			{},
+		}
+		entries := logs.AllUntimed()
+		require.Equal(t, len(expected), len(entries), "Unexpected number of entries.")
+		for i, ent := range entries {
+			assert.Equal(t, expected[i], ent.ContextMap(), "Unexpected context for entry %q.", ent.Message)
+		}
+	})
+	assert.Equal(t, int64(0), _locals.n.Load(), "Expected no goroutines to have local fields.")
+}
+
+func TestSetLocalClearTwice(t *testing.T) {
+	clear := SetLocal(String("k", "v"))
+	clear()
+	clear()
+	assert.Equal(t, int64(0), _locals.n.Load(), "Expected clearing twice to be a no-op.")
+}
+
+func TestSetLocalWithoutOption(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		defer SetLocal(String("trace", "abc"))()
+		logger.Info("")
+		assert.Equal(t, map[string]interface{}{}, logs.AllUntimed()[0].ContextMap(), "Expected local fields to require AddLocalFields.")
+	})
+}
+
+func TestGoroutineID(t *testing.T) {
+	id := goroutineID()
+	assert.NotZero(t, id, "Expected a non-zero goroutine ID.")
+	assert.Equal(t, id, goroutineID(), "Expected a stable goroutine ID.")
+
+	other := make(chan uint64)
+	go func() { other <- goroutineID() }()
+	assert.NotEqual(t, id, <-other, "Expected goroutines to have distinct IDs.")
+}
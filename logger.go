@@ -21,13 +21,16 @@
 package zap
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -41,9 +44,18 @@ import (
 type Logger struct {
 	core zapcore.Core
 
-	development bool
-	name        string
-	errorOutput zapcore.WriteSyncer
+	development   bool
+	name          string
+	errorOutput   zapcore.WriteSyncer
+	pause         *pauseSwitch
+	crumbs        *Breadcrumbs
+	onFatal       func()
+	onFatalAction zapcore.CheckWriteAction // zero means WriteThenFatal
+	clock         zapcore.Clock
+
+	ctxExtractors []func(context.Context) []Field
+	ctxFields     []Field // already extracted from a context
+	closers       []*closer
 
 	addCaller bool
 	addStack  zapcore.LevelEnabler
@@ -68,7 +80,9 @@ func New(core zapcore.Core, options ...Option) *Logger {
 	log := &Logger{
 		core:        core,
 		errorOutput: zapcore.Lock(os.Stderr),
+		pause:       newPauseSwitch(nil),
 		addStack:    zapcore.FatalLevel + 1,
+		clock:       zapcore.DefaultClock,
 	}
 	return log.WithOptions(options...)
 }
@@ -82,7 +96,9 @@ func NewNop() *Logger {
 	return &Logger{
 		core:        zapcore.NewNopCore(),
 		errorOutput: zapcore.AddSync(ioutil.Discard),
+		pause:       newPauseSwitch(nil),
 		addStack:    zapcore.FatalLevel + 1,
+		clock:       zapcore.DefaultClock,
 	}
 }
 
@@ -102,6 +118,19 @@ func NewDevelopment(options ...Option) (*Logger, error) {
 	return NewDevelopmentConfig().Build(options...)
 }
 
+// Must is a helper that wraps a call to a function returning (*Logger, error)
+// and panics if the error is non-nil. It's intended for use in variable
+// initializations and main functions, where there's no sensible way to
+// recover from a misconfigured logger:
+//
+//   var logger = zap.Must(zap.NewProduction())
+func Must(logger *Logger, err error) *Logger {
+	if err != nil {
+		panic(err)
+	}
+	return logger
+}
+
 // NewExample builds a Logger that's designed for use in zap's testable
 // examples. It writes DebugLevel and above logs to standard out as JSON, but
 // omits the timestamp and calling function to keep example output
@@ -165,6 +194,21 @@ func (log *Logger) With(fields ...Field) *Logger {
 	return l
 }
 
+// WithLazy is like With, but the fields aren't added to the Logger's core
+// until the child logs its first entry or is given more fields with With.
+// Since most cores encode the fields passed to With right away, it saves that
+// cost for children that never log, like per-request Loggers for requests
+// that succeed quietly. Field values are still captured when WithLazy is called;
+// to defer computing them too, use Lazy.
+func (log *Logger) WithLazy(fields ...Field) *Logger {
+	if len(fields) == 0 {
+		return log
+	}
+	l := log.clone()
+	l.core = zapcore.NewLazyWith(l.core, fields)
+	return l
+}
+
 // Check returns a CheckedEntry if logging a message at the specified level
 // is enabled. It's a completely optional optimization; in high-performance
 // applications, Check can help avoid allocating a slice to hold fields.
@@ -172,6 +216,27 @@ func (log *Logger) Check(lvl zapcore.Level, msg string) *zapcore.CheckedEntry {
 	return log.check(lvl, msg)
 }
 
+// CheckAll checks the message at the specified level with each of the
+// supplied Loggers and chains the resulting CheckedEntries, so that an event
+// fans out to several independent Loggers, like an application log and an
+// audit log, while its fields are constructed only once, and only if at
+// least one of the Loggers is enabled:
+//   if ce := zap.CheckAll(zap.InfoLevel, "User deleted.", logger, audit); ce != nil {
+//     ce.Write(zap.String("user", name))
+//   }
+// Nil Loggers are ignored, and callers are annotated with CheckAll's call
+// site. To combine entries at different levels, chain the results of Check
+// instead; see zapcore.CheckedEntry.Chain for details.
+func CheckAll(lvl zapcore.Level, msg string, loggers ...*Logger) *zapcore.CheckedEntry {
+	var ce *zapcore.CheckedEntry
+	for _, log := range loggers {
+		if log != nil {
+			ce = ce.Chain(log.check(lvl, msg))
+		}
+	}
+	return ce
+}
+
 // Debug logs a message at DebugLevel. The message includes any fields passed
 // at the log site, as well as any fields accumulated on the logger.
 func (log *Logger) Debug(msg string, fields ...Field) {
@@ -208,8 +273,10 @@ func (log *Logger) Error(msg string, fields ...Field) {
 // passed at the log site, as well as any fields accumulated on the logger.
 //
 // If the logger is in development mode, it then panics (DPanic means
-// "development panic"). This is useful for catching errors that are
-// recoverable, but shouldn't ever happen.
+// "development panic"), even if logging at DPanicLevel is disabled. This is
+// useful for catching errors that are recoverable, but shouldn't ever happen:
+// tests built with Development fail loudly, while production processes log
+// the error and carry on.
 func (log *Logger) DPanic(msg string, fields ...Field) {
 	if ce := log.check(DPanicLevel, msg); ce != nil {
 		ce.Write(fields...)
@@ -230,7 +297,7 @@ func (log *Logger) Panic(msg string, fields ...Field) {
 // at the log site, as well as any fields accumulated on the logger.
 //
 // The logger then calls os.Exit(1), even if logging at FatalLevel is
-// disabled.
+// disabled. The OnFatal and OnFatalAction options replace the exit.
 func (log *Logger) Fatal(msg string, fields ...Field) {
 	if ce := log.check(FatalLevel, msg); ce != nil {
 		ce.Write(fields...)
@@ -243,6 +310,22 @@ func (log *Logger) Sync() error {
 	return log.core.Sync()
 }
 
+// Close syncs the Logger, flushing any buffered log entries, then releases
+// the resources registered with OnClose, like the files and network
+// connections opened by Config.Build. It returns the combined errors from
+// syncing and closing. The Logger mustn't be used after it's closed.
+//
+// Loggers derived from the same Logger (via With, Named, and the like) share
+// its resources, so it's enough to close any one of them; each resource is
+// released at most once, in the reverse order of registration.
+func (log *Logger) Close() error {
+	err := log.Sync()
+	for i := len(log.closers) - 1; i >= 0; i-- {
+		err = multierr.Append(err, log.closers[i].close())
+	}
+	return err
+}
+
 // Core returns the Logger's underlying zapcore.Core.
 func (log *Logger) Core() zapcore.Core {
 	return log.core
@@ -250,6 +333,9 @@ func (log *Logger) Core() zapcore.Core {
 
 func (log *Logger) clone() *Logger {
 	copy := *log
+	// Derived loggers can be paused on their own, without silencing the
+	// logger they were derived from.
+	copy.pause = newPauseSwitch(log.pause)
 	return &copy
 }
 
@@ -262,11 +348,14 @@ func (log *Logger) check(lvl zapcore.Level, msg string) *zapcore.CheckedEntry {
 	// log message will actually be written somewhere.
 	ent := zapcore.Entry{
 		LoggerName: log.name,
-		Time:       time.Now(),
+		Time:       log.clock.Now(),
 		Level:      lvl,
 		Message:    msg,
 	}
-	ce := log.core.Check(ent, nil)
+	var ce *zapcore.CheckedEntry
+	if !log.pause.isPaused() {
+		ce = log.core.Check(ent, nil)
+	}
 	willWrite := ce != nil
 
 	// Set up any required terminal behavior.
@@ -274,7 +363,11 @@ func (log *Logger) check(lvl zapcore.Level, msg string) *zapcore.CheckedEntry {
 	case zapcore.PanicLevel:
 		ce = ce.Should(ent, zapcore.WriteThenPanic)
 	case zapcore.FatalLevel:
-		ce = ce.Should(ent, zapcore.WriteThenFatal)
+		if log.onFatal != nil {
+			ce = ce.After(ent, log.syncThenOnFatal)
+		} else {
+			ce = ce.Should(ent, log.fatalAction())
+		}
 	case zapcore.DPanicLevel:
 		if log.development {
 			ce = ce.Should(ent, zapcore.WriteThenPanic)
@@ -303,3 +396,35 @@ func (log *Logger) check(lvl zapcore.Level, msg string) *zapcore.CheckedEntry {
 
 	return ce
 }
+
+// fatalAction returns the CheckWriteAction that follows a Fatal-level entry
+// when no OnFatal function is configured.
+func (log *Logger) fatalAction() zapcore.CheckWriteAction {
+	if log.onFatalAction == zapcore.WriteThenNoop {
+		return zapcore.WriteThenFatal
+	}
+	return log.onFatalAction
+}
+
+// syncThenOnFatal replaces the process exit after a Fatal-level entry when an
+// OnFatal function is configured, flushing the Core first just as an exiting
+// process would want.
+func (log *Logger) syncThenOnFatal() {
+	if err := log.core.Sync(); err != nil {
+		fmt.Fprintf(log.errorOutput, "%v Logger.Fatal error: failed to sync: %v\n", time.Now().UTC(), err)
+		log.errorOutput.Sync()
+	}
+	log.onFatal()
+}
+
+// A closer runs a function registered with OnClose at most once.
+type closer struct {
+	once sync.Once
+	f    func() error
+}
+
+func (c *closer) close() error {
+	var err error
+	c.once.Do(func() { err = c.f() })
+	return err
+}
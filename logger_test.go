@@ -22,8 +22,11 @@ package zap
 
 import (
 	"errors"
+	"fmt"
+	"runtime"
 	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/zap/internal/exit"
 	"go.uber.org/zap/internal/ztest"
@@ -113,6 +116,24 @@ func TestLoggerWith(t *testing.T) {
 	})
 }
 
+func TestLoggerNamespaces(t *testing.T) {
+	sink := &ztest.Buffer{}
+	logger := New(zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), sink, DebugLevel))
+
+	// A library given a logger can isolate all of its context, including its
+	// children's and the fields added at each log site, under a single key.
+	lib := logger.With(Namespace("lib"), String("k", "lib"))
+	lib.Info("lib", Int("n", 1))
+	lib.With(Namespace("sub"), String("k", "sub")).Info("sub")
+	logger.Info("app", String("k", "app"))
+
+	assert.Equal(t, []string{
+		`{"msg":"lib","lib":{"k":"lib","n":1}}`,
+		`{"msg":"sub","lib":{"k":"lib","sub":{"k":"sub"}}}`,
+		`{"msg":"app","k":"app"}`,
+	}, sink.Lines(), "Unexpected output.")
+}
+
 func TestLoggerLogPanic(t *testing.T) {
 	for _, tt := range []struct {
 		do       func(*Logger)
@@ -168,6 +189,140 @@ func TestLoggerLogFatal(t *testing.T) {
 	}
 }
 
+func TestLoggerOnFatal(t *testing.T) {
+	sink := &ztest.Buffer{}
+	var synced bool
+	logger := New(
+		zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}), sink, DebugLevel),
+		OnFatal(func() { synced = sink.Called() }),
+	)
+
+	stub := exit.WithStub(func() {
+		logger.Fatal("foo")
+		logger.Sugar().Fatal("bar")
+		logger.Check(FatalLevel, "baz").Write()
+	})
+	assert.False(t, stub.Exited, "Expected OnFatal to replace the process exit.")
+	assert.True(t, synced, "Expected the Core to be synced before running the OnFatal function.")
+	assert.Equal(t, []string{`{"msg":"foo"}`, `{"msg":"bar"}`, `{"msg":"baz"}`}, sink.Lines(), "Unexpected output.")
+
+	assert.Panics(t, func() {
+		logger.WithOptions(OnFatal(func() { panic("fatal") })).Fatal("foo")
+	}, "Expected an OnFatal function to be able to panic.")
+
+	stub = exit.WithStub(func() { logger.WithOptions(OnFatal(nil)).Fatal("foo") })
+	assert.True(t, stub.Exited, "Expected a nil OnFatal function to restore the process exit.")
+}
+
+func TestLoggerOnFatalSyncError(t *testing.T) {
+	sink := &ztest.Buffer{}
+	sink.SetError(errors.New("fail"))
+	errSink := &ztest.Buffer{}
+	var called bool
+	logger := New(
+		zapcore.NewCore(zapcore.NewJSONEncoder(zapcore.EncoderConfig{}), sink, DebugLevel),
+		OnFatal(func() { called = true }),
+		ErrorOutput(errSink),
+	)
+	logger.Fatal("foo")
+	assert.True(t, called, "Expected to run the OnFatal function despite the sync error.")
+	assert.Contains(t, errSink.Stripped(), "failed to sync: fail", "Expected to report the sync error.")
+}
+
+func TestLoggerOnFatalAction(t *testing.T) {
+	withLogger(t, DebugLevel, opts(OnFatalAction(zapcore.WriteThenPanic)), func(logger *Logger, logs *observer.ObservedLogs) {
+		stub := exit.WithStub(func() {
+			assert.Panics(t, func() { logger.Fatal("foo") }, "Expected Fatal to panic.")
+			assert.Panics(t, func() { logger.Sugar().Fatalf("bar") }, "Expected Fatalf to panic.")
+		})
+		assert.False(t, stub.Exited, "Expected OnFatalAction to replace the process exit.")
+		assert.Equal(t, []string{"foo", "bar"}, messages(logs.AllUntimed()), "Unexpected output.")
+
+		var (
+			wg       sync.WaitGroup
+			returned bool
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger.WithOptions(OnFatalAction(zapcore.WriteThenGoexit)).Fatal("baz")
+			returned = true
+		}()
+		wg.Wait()
+		assert.False(t, returned, "Expected Fatal to stop the goroutine.")
+
+		var called bool
+		logger.WithOptions(OnFatal(func() { called = true })).Fatal("qux")
+		assert.True(t, called, "Expected OnFatal to replace the action.")
+
+		stub = exit.WithStub(func() {
+			logger.WithOptions(OnFatalAction(zapcore.WriteThenNoop)).Fatal("quux")
+		})
+		assert.True(t, stub.Exited, "Expected WriteThenNoop to restore the process exit.")
+	})
+}
+
+func TestLoggerReplayOnError(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		ok := logger.WithOptions(ReplayOnError(10))
+		ok.Debug("ok: debug")
+		ok.Info("ok: done")
+
+		failed := logger.WithOptions(ReplayOnError(10))
+		failed.Debug("failed: debug")
+		failed.Error("failed: error")
+
+		assert.Equal(t, []string{"failed: debug", "failed: error"}, messages(logs.AllUntimed()), "Expected only the failed request's debug logs.")
+	})
+}
+
+func TestLoggerFilterFunc(t *testing.T) {
+	keep := FilterFunc(func(ent zapcore.Entry, _ []Field) bool {
+		return !(ent.LoggerName == "noisy" && ent.Level == WarnLevel)
+	})
+	withLogger(t, DebugLevel, opts(keep), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Named("noisy").Warn("dropped")
+		logger.Named("noisy").Error("kept")
+		logger.Warn("also kept")
+		assert.Equal(t, []string{"kept", "also kept"}, messages(logs.AllUntimed()), "Unexpected entries.")
+		assert.Panics(t, func() {
+			logger.WithOptions(FilterFunc(func(zapcore.Entry, []Field) bool { return false })).Panic("boom")
+		}, "Expected dropped Panic entries to panic.")
+	})
+}
+
+func TestLoggerCheckChain(t *testing.T) {
+	primarySink, auditSink := &ztest.Buffer{}, &ztest.Buffer{}
+	primary := New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+		primarySink,
+		InfoLevel,
+	)).Named("primary").With(String("ctx", "primary"))
+	audit := New(zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "M", NameKey: "N"}),
+		auditSink,
+		DebugLevel,
+	)).Named("audit").With(String("ctx", "audit"))
+
+	if ce := primary.Check(InfoLevel, "both").Chain(audit.Check(InfoLevel, "both")); ce != nil {
+		ce.Write(Int("n", 1))
+	}
+	if ce := primary.Check(DebugLevel, "audit only").Chain(audit.Check(DebugLevel, "audit only")); ce != nil {
+		ce.Write(Int("n", 2))
+	}
+	assert.Nil(
+		t,
+		primary.Check(DebugLevel-1, "").Chain(audit.Check(DebugLevel-1, "")),
+		"Expected a chain of disabled entries to be nil.",
+	)
+
+	assert.Equal(t, []string{`{"msg":"both","ctx":"primary","n":1}`}, primarySink.Lines(), "Unexpected primary output.")
+	assert.Equal(t, []string{
+		`{"N":"audit","M":"both","ctx":"audit","n":1}`,
+		`{"N":"audit","M":"audit only","ctx":"audit","n":2}`,
+	}, auditSink.Lines(), "Unexpected audit output.")
+}
+
 func TestLoggerLeveledMethods(t *testing.T) {
 	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
 		tests := []struct {
@@ -194,6 +349,17 @@ func TestLoggerLeveledMethods(t *testing.T) {
 	})
 }
 
+func TestMust(t *testing.T) {
+	logger := NewNop()
+	assert.Equal(t, logger, Must(logger, nil), "Expected Must to return the Logger.")
+
+	err := errors.New("bad config")
+	assert.PanicsWithValue(t, err, func() { Must(nil, err) }, "Expected Must to panic with the error.")
+
+	logger = Must(NewDevelopment())
+	assert.NotNil(t, logger, "Expected a development Logger.")
+}
+
 func TestLoggerAlwaysPanics(t *testing.T) {
 	// Users can disable writing out panic-level logs, but calls to logger.Panic()
 	// should still call panic().
@@ -206,6 +372,10 @@ func TestLoggerAlwaysPanics(t *testing.T) {
 			}
 		}, msg)
 		assert.Equal(t, 0, logs.Len(), "Panics shouldn't be written out if PanicLevel is disabled.")
+		assert.NotPanics(t, func() { logger.DPanic("foo") }, "Expected DPanic not to panic in production mode.")
+		assert.Panics(t, func() {
+			logger.WithOptions(Development()).DPanic("foo")
+		}, "Even if output is disabled, logger.DPanic should panic in development mode.")
 	})
 }
 
@@ -248,6 +418,28 @@ func TestLoggerDPanic(t *testing.T) {
 	})
 }
 
+func TestNopLogger(t *testing.T) {
+	logger := NewNop()
+	child := logger.With(String("foo", "bar")).Named("child")
+
+	for _, l := range []*Logger{logger, child} {
+		assert.Nil(t, l.Check(ErrorLevel, "ignored"), "Expected Check on a no-op Logger to return nil.")
+		assert.NotPanics(t, func() {
+			l.Debug("ignored", Int("n", 1))
+			l.Info("ignored")
+			l.Warn("ignored")
+			l.Error("ignored")
+			l.DPanic("ignored")
+			l.Sugar().Infow("ignored", "n", 1)
+		}, "Expected logging to a no-op Logger to do nothing.")
+		assert.NoError(t, l.Sync(), "Expected syncing a no-op Logger to succeed.")
+	}
+	assert.Panics(t, func() { logger.Panic("boom") }, "Expected Panic to panic even on a no-op Logger.")
+
+	allocs := testing.AllocsPerRun(100, func() { child.Info("ignored") })
+	assert.Equal(t, float64(0), allocs, "Expected logging to a no-op Logger not to allocate.")
+}
+
 func TestLoggerNoOpsDisabledLevels(t *testing.T) {
 	withLogger(t, WarnLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
 		logger.Info("silence!")
@@ -361,6 +553,39 @@ func TestLoggerAddCaller(t *testing.T) {
 	}
 }
 
+func TestLoggerAddCallerCheck(t *testing.T) {
+	withLogger(t, DebugLevel, opts(AddCaller()), func(logger *Logger, logs *observer.ObservedLogs) {
+		if ce := logger.Check(InfoLevel, ""); ce != nil {
+			ce.Write()
+		}
+		output := logs.AllUntimed()
+		require.Equal(t, 1, len(output), "Unexpected number of logs written out.")
+		assert.Regexp(t, `.+/zap/logger_test.go:[\d]+$`, output[0].Entry.Caller, "Expected Check's caller to be the call site.")
+	})
+}
+
+func TestLoggerAddCallerFunction(t *testing.T) {
+	withLogger(t, DebugLevel, opts(AddCaller()), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("")
+		func() { logger.Info("") }()
+		output := logs.AllUntimed()
+		require.Equal(t, 2, len(output), "Unexpected number of logs written out.")
+		assert.Equal(t, "go.uber.org/zap.TestLoggerAddCallerFunction.func1", output[0].Entry.Caller.Function(), "Unexpected caller function.")
+		assert.Equal(t, "go.uber.org/zap.TestLoggerAddCallerFunction.func1.1", output[1].Entry.Caller.Function(), "Unexpected caller function in a closure.")
+	})
+}
+
+func TestLoggerAddCallerSkipsDisabledLevels(t *testing.T) {
+	errBuf := &ztest.Buffer{}
+	withLogger(t, InfoLevel, opts(AddCaller(), ErrorOutput(errBuf)), func(log *Logger, logs *observer.ObservedLogs) {
+		// With an impossible skip, resolving the caller would report an error.
+		log.callerSkip = 1e3
+		log.Debug("disabled")
+		assert.Equal(t, "", errBuf.String(), "Expected disabled entries not to resolve their caller.")
+		assert.Equal(t, 0, logs.Len(), "Expected disabled entries to be dropped.")
+	})
+}
+
 func TestLoggerAddCallerFail(t *testing.T) {
 	errBuf := &ztest.Buffer{}
 	withLogger(t, DebugLevel, opts(AddCaller(), ErrorOutput(errBuf)), func(log *Logger, logs *observer.ObservedLogs) {
@@ -401,6 +626,303 @@ func TestLoggerHooks(t *testing.T) {
 	assert.Equal(t, int64(2), seen.Load(), "Hook saw an unexpected number of logs.")
 }
 
+func TestLoggerHooksErrors(t *testing.T) {
+	var order []string
+	failing := func(ent zapcore.Entry) error {
+		order = append(order, "failing:"+ent.Message)
+		return errors.New("hook failed")
+	}
+	succeeding := func(ent zapcore.Entry) error {
+		order = append(order, "succeeding:"+ent.Message)
+		return nil
+	}
+
+	errSink := &ztest.Buffer{}
+	withLogger(t, DebugLevel, opts(Hooks(failing, succeeding), ErrorOutput(errSink)), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.With(String("child", "yes")).Error("boom")
+		assert.Equal(t, 1, logs.FilterMessage("boom").Len(), "Expected hook errors not to prevent writes.")
+	})
+	assert.Equal(t, []string{"failing:boom", "succeeding:boom"}, order, "Expected hooks to run in order, including for children.")
+	assert.Contains(t, errSink.String(), "hook failed", "Expected hook errors to be sent to ErrorOutput.")
+}
+
+func TestLoggerHooksWithFields(t *testing.T) {
+	var alerts []string
+	alert := func(ent zapcore.Entry, fields []Field) error {
+		if ent.Level < ErrorLevel {
+			return nil
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		for _, f := range fields {
+			f.AddTo(enc)
+		}
+		alerts = append(alerts, fmt.Sprintf("%s %v", ent.Message, enc.Fields))
+		return errors.New("alerting is down")
+	}
+
+	errSink := &ztest.Buffer{}
+	withLogger(t, DebugLevel, opts(HooksWithFields(alert), ErrorOutput(errSink)), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("fine")
+		logger.With(String("db", "users")).Error("query failed", Int("attempt", 3))
+		assert.Equal(t, 2, logs.Len(), "Expected hook errors not to prevent writes.")
+	})
+	assert.Equal(t, []string{"query failed map[attempt:3 db:users]"}, alerts, "Unexpected alerts.")
+	assert.Contains(t, errSink.String(), "alerting is down", "Expected hook errors to be sent to ErrorOutput.")
+}
+
+func TestLoggerAddRuntimeContext(t *testing.T) {
+	withLogger(t, InfoLevel, opts(AddRuntimeContext()), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Debug("disabled")
+		logger.With(String("foo", "bar")).Info("enabled", Int("baz", 1))
+
+		entries := logs.AllUntimed()
+		require.Equal(t, 1, len(entries), "Unexpected number of logs written out.")
+		require.Equal(t, 3, len(entries[0].Context), "Expected runtime context after other fields.")
+
+		enc := zapcore.NewMapObjectEncoder()
+		entries[0].Context[2].AddTo(enc)
+		rt, ok := enc.Fields["runtime"].(map[string]interface{})
+		require.True(t, ok, "Expected runtime context to be a nested object.")
+		assert.Equal(t, runtime.GOMAXPROCS(0), rt["gomaxprocs"], "Unexpected GOMAXPROCS.")
+		assert.Equal(t, runtime.NumCPU(), rt["cpus"], "Unexpected CPU count.")
+		assert.True(t, rt["goroutines"].(int) > 0, "Expected a positive goroutine count.")
+	})
+}
+
+func TestLoggerAddSessionID(t *testing.T) {
+	sessions := make(map[string]struct{})
+	for i := 0; i < 2; i++ {
+		withLogger(t, DebugLevel, opts(AddSessionID()), func(logger *Logger, logs *observer.ObservedLogs) {
+			logger.Info("parent")
+			logger.With(String("foo", "bar")).Info("child")
+
+			entries := logs.AllUntimed()
+			require.Equal(t, 2, len(entries), "Unexpected number of logs written out.")
+			session, ok := entries[0].ContextMap()["session"].(string)
+			require.True(t, ok, "Expected a string session ID.")
+			assert.Regexp(t, "^[0-9a-f]{16}$", session, "Unexpected session ID format.")
+			assert.Equal(t, session, entries[1].ContextMap()["session"], "Expected child loggers to share the session ID.")
+			sessions[session] = struct{}{}
+		})
+	}
+	assert.Equal(t, 2, len(sessions), "Expected each logger to get a distinct session ID.")
+}
+
+// notifyingSyncer signals each time it's synced.
+type notifyingSyncer struct {
+	*ztest.Discarder
+	synced chan struct{}
+}
+
+func (s notifyingSyncer) Sync() error {
+	select {
+	case s.synced <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func TestLoggerSyncEvery(t *testing.T) {
+	sink := notifyingSyncer{Discarder: &ztest.Discarder{}, synced: make(chan struct{}, 1)}
+	logger := New(zapcore.NewCore(zapcore.NewJSONEncoder(NewProductionEncoderConfig()), sink, DebugLevel), SyncEvery(time.Millisecond))
+	logger.Info("hello")
+	select {
+	case <-sink.synced:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the sink to be synced in the background.")
+	}
+}
+
+func TestLoggerSyncEveryClock(t *testing.T) {
+	clock := &stubClock{}
+	sink := &ztest.Discarder{}
+	logger := New(zapcore.NewCore(zapcore.NewJSONEncoder(NewProductionEncoderConfig()), sink, DebugLevel), WithClock(clock), SyncEvery(time.Minute))
+	logger.Info("hello")
+	assert.False(t, sink.Called(), "Expected the sync to wait for the clock.")
+	clock.fire()
+	assert.True(t, sink.Called(), "Expected the Logger's clock to schedule the sync.")
+}
+
+func TestLoggerWithLevel(t *testing.T) {
+	atom := NewAtomicLevelAt(WarnLevel)
+	withLogger(t, DebugLevel, opts(WithLevel(atom)), func(logger *Logger, logs *observer.ObservedLogs) {
+		child := logger.With(String("child", "yes"))
+		assert.False(t, logger.Core().Enabled(InfoLevel), "Expected the Core to honor the level.")
+		assert.Nil(t, child.Check(InfoLevel, ""), "Expected Check to honor the level.")
+
+		logger.Info("dropped")
+		child.Warn("kept")
+		atom.SetLevel(DebugLevel)
+		logger.Debug("debug")
+		child.Debug("child debug")
+
+		assert.Equal(
+			t,
+			[]string{"kept", "debug", "child debug"},
+			messages(logs.AllUntimed()),
+			"Expected the Logger and its children to observe level changes.",
+		)
+	})
+}
+
+func TestLoggerWithNameLevels(t *testing.T) {
+	pool := NewAtomicLevelAt(DebugLevel)
+	levels := map[string]zapcore.LevelEnabler{
+		"":        ErrorLevel,
+		"db":      WarnLevel,
+		"db.pool": pool,
+	}
+	withLogger(t, DebugLevel, opts(WithNameLevels(levels)), func(logger *Logger, logs *observer.ObservedLogs) {
+		// Changing the map afterwards mustn't affect the Logger.
+		levels["db"] = DebugLevel
+
+		logger.Warn("root warn")
+		logger.Error("root error")
+		logger.Named("db").Info("db info")
+		logger.Named("db").Warn("db warn")
+		logger.Named("db").Named("conn").Info("db.conn info")
+		logger.Named("db").Named("pool").Debug("db.pool debug")
+		logger.Named("db").Named("pool").With(String("k", "v")).Named("conn").Debug("db.pool.conn debug")
+		logger.Named("dbx").Debug("dbx debug")
+		pool.SetLevel(InfoLevel)
+		logger.Named("db.pool").Debug("db.pool debug again")
+
+		assert.Equal(
+			t,
+			[]string{"root error", "db warn", "db.pool debug", "db.pool.conn debug", "dbx debug"},
+			messages(logs.AllUntimed()),
+			"Expected entries to be filtered by the level of their longest matching name.",
+		)
+	})
+}
+
+func TestLoggerTee(t *testing.T) {
+	errCore, errLogs := observer.New(ErrorLevel)
+	withLogger(t, InfoLevel, opts(Fields(String("ctx", "primary"))), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger = logger.WithOptions(Tee(errCore))
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.With(Int("n", 1)).Error("error")
+
+		assert.Equal(t, []string{"info", "error"}, messages(logs.AllUntimed()), "Unexpected entries in the original Core.")
+		assert.Equal(t, []observer.LoggedEntry{{
+			Entry:   zapcore.Entry{Level: ErrorLevel, Message: "error"},
+			Context: []Field{Int("n", 1)},
+		}}, errLogs.AllUntimed(), "Expected the teed Core to apply its own level and omit earlier fields.")
+	})
+}
+
+func TestLoggerWithOnlyLevels(t *testing.T) {
+	withLogger(t, DebugLevel, opts(WithLevel(OnlyLevels(DebugLevel, ErrorLevel))), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Warn("warn")
+		logger.Error("error")
+		assert.Equal(t, []string{"debug", "error"}, messages(logs.AllUntimed()), "Expected only the listed levels to be logged.")
+	})
+}
+
+func TestLoggerDedup(t *testing.T) {
+	withLogger(t, DebugLevel, opts(Dedup()), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.With(String("k", "ctx"), Int("n", 1)).Info("", String("k", "site"))
+		assert.Equal(t, []zapcore.Field{Int("n", 1), String("k", "site")}, logs.AllUntimed()[0].Context, "Expected the log site to override the context.")
+
+		lazy := func() Field { return String("", "lazy") }
+		logger.With(Lazy("k", lazy)).Info("", String("k", "site"))
+		logger.With(String("k", "ctx")).Info("", Lazy("k", lazy))
+		assert.Equal(t, map[string]interface{}{"k": "site"}, logs.AllUntimed()[1].ContextMap(), "Expected the log site to override lazy context.")
+		assert.Equal(t, map[string]interface{}{"k": "lazy"}, logs.AllUntimed()[2].ContextMap(), "Expected lazy fields to override the context.")
+		for _, entry := range logs.AllUntimed()[1:] {
+			assert.Equal(t, 1, len(entry.Context), "Expected a single field.")
+		}
+	})
+}
+
+func TestLoggerSample(t *testing.T) {
+	withLogger(t, DebugLevel, opts(Sample(time.Minute, 2, 3)), func(logger *Logger, logs *observer.ObservedLogs) {
+		for i := 0; i < 9; i++ {
+			logger.Info("hot", Int("i", i))
+			logger.Warn("hot", Int("i", i))
+		}
+		assert.Nil(t, logger.Check(InfoLevel, "hot"), "Expected Check to drop sampled-out entries.")
+		assert.NotNil(t, logger.Check(InfoLevel, "cold"), "Expected other messages to be sampled separately.")
+
+		var seen []int64
+		for _, entry := range logs.FilterMessage("hot").All() {
+			if entry.Level == InfoLevel {
+				seen = append(seen, entry.Context[0].Integer)
+			}
+		}
+		assert.Equal(t, []int64{0, 1, 4, 7}, seen, "Expected the first two entries and every third after.")
+		assert.Equal(t, 8, logs.FilterMessage("hot").Len(), "Expected levels to be sampled separately.")
+	})
+}
+
+func TestLoggerSampleHook(t *testing.T) {
+	var dropped, sampled atomic.Int64
+	hook := zapcore.SamplerHook(func(_ zapcore.Entry, dec zapcore.SamplingDecision) {
+		if dec&zapcore.LogDropped > 0 {
+			dropped.Inc()
+		}
+		if dec&zapcore.LogSampled > 0 {
+			sampled.Inc()
+		}
+	})
+	withLogger(t, InfoLevel, opts(Sample(time.Minute, 2, 3, hook)), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Debug("disabled")
+		for i := 0; i < 9; i++ {
+			logger.Info("hot")
+		}
+		assert.Equal(t, 4, logs.Len(), "Unexpected number of sampled entries.")
+		assert.Equal(t, int64(5), dropped.Load(), "Expected the hook to count dropped entries.")
+		assert.Equal(t, int64(4), sampled.Load(), "Expected the hook to count sampled entries.")
+	})
+}
+
+func TestLoggerPrioritySampler(t *testing.T) {
+	rate := func(_ zapcore.Entry, fields []Field) zapcore.SampleRate {
+		for _, f := range fields {
+			if f.Key == "priority" && f.String == "low" {
+				return 0.1
+			}
+		}
+		return 1
+	}
+	withLogger(t, DebugLevel, opts(PrioritySampler(rate)), func(logger *Logger, logs *observer.ObservedLogs) {
+		for i := 0; i < 100; i++ {
+			logger.Info("high", String("priority", "high"))
+			logger.Info("low", String("priority", "low"))
+		}
+		assert.Equal(t, 100, logs.FilterMessage("high").Len(), "Expected all high-priority entries to be kept.")
+		assert.Equal(t, 10, logs.FilterMessage("low").Len(), "Expected 10% of low-priority entries to be kept.")
+	})
+}
+
+func TestLoggerOnReservedKeyCollision(t *testing.T) {
+	tests := []struct {
+		opt      Option
+		expected map[string]interface{}
+	}{
+		{OnReservedKeyCollision(zapcore.RenameReservedKeys), map[string]interface{}{"level_": "ctx", "ts_": "site", "ok": true}},
+		{OnReservedKeyCollision(zapcore.DropReservedKeys), map[string]interface{}{"ok": true}},
+		{OnReservedKeyCollision(zapcore.RenameReservedKeys, "ok"), map[string]interface{}{"level": "ctx", "ts": "site", "ok_": true}},
+	}
+
+	for _, tt := range tests {
+		withLogger(t, DebugLevel, opts(tt.opt), func(logger *Logger, logs *observer.ObservedLogs) {
+			logger.With(String("level", "ctx")).Info("", String("ts", "site"), Bool("ok", true))
+			assert.Equal(t, tt.expected, logs.AllUntimed()[0].ContextMap(), "Unexpected context.")
+		})
+	}
+
+	errSink := &ztest.Buffer{}
+	withLogger(t, DebugLevel, opts(OnReservedKeyCollision(zapcore.ErrorOnReservedKeys), ErrorOutput(errSink)), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("", String("msg", "site"), Bool("ok", true))
+		assert.Equal(t, map[string]interface{}{"ok": true}, logs.AllUntimed()[0].ContextMap(), "Expected colliding fields to be dropped.")
+		assert.Contains(t, errSink.String(), "dropped fields with reserved keys: msg", "Expected collisions to be sent to ErrorOutput.")
+	})
+}
+
 func TestLoggerConcurrent(t *testing.T) {
 	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
 		child := logger.With(String("foo", "bar"))
@@ -430,3 +952,217 @@ func TestLoggerConcurrent(t *testing.T) {
 		}
 	})
 }
+
+func TestLoggerRateLimit(t *testing.T) {
+	withLogger(t, DebugLevel, opts(RateLimit(0.001, 1, RateLimitByField("user"))), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("login failed", String("user", "alice"))
+		logger.Info("login failed", String("user", "alice"))
+		logger.Info("login failed", String("user", "bob"))
+		logger.Warn("no user")
+		logger.Warn("no user")
+		assert.Equal(t, []string{"login failed", "login failed", "no user"}, messages(logs.AllUntimed()), "Unexpected entries.")
+
+		require.NoError(t, logger.Sync(), "Unexpected error syncing.")
+		entries := logs.AllUntimed()
+		require.Equal(t, 5, len(entries), "Expected Sync to report suppressed entries.")
+		assert.Equal(t, []zapcore.Field{String("user", "alice"), Int64("suppressed", 1)}, entries[3].Context, "Unexpected suppressed count.")
+		assert.Equal(t, "no user", entries[4].Message, "Unexpected suppressed entry.")
+	})
+}
+
+func TestLoggerRateLimitClock(t *testing.T) {
+	clock := &stubClock{now: time.Unix(0, 0)}
+	withLogger(t, DebugLevel, opts(WithClock(clock), RateLimit(1, 1, RateLimitMaxKeys(-1))), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("loop")
+		logger.Info("loop")
+		clock.add(time.Second)
+		logger.Info("loop")
+		assert.Equal(t, []string{"loop", "loop"}, messages(logs.AllUntimed()), "Expected the Logger's clock to refill tokens.")
+	})
+}
+
+func TestCheckAll(t *testing.T) {
+	assert.Nil(t, CheckAll(InfoLevel, "none"), "Expected checking no loggers to return nil.")
+
+	primaryCore, primaryLogs := observer.New(InfoLevel)
+	auditCore, auditLogs := observer.New(DebugLevel)
+	primary := New(primaryCore, AddCaller()).Named("primary")
+	audit := New(auditCore, AddCaller()).Named("audit")
+
+	var fieldsBuilt int
+	build := func() []Field {
+		fieldsBuilt++
+		return []Field{Int("n", fieldsBuilt)}
+	}
+	for _, lvl := range []zapcore.Level{InfoLevel, DebugLevel, DebugLevel - 1} {
+		if ce := CheckAll(lvl, lvl.String(), primary, nil, audit); ce != nil {
+			ce.Write(build()...)
+		}
+	}
+
+	assert.Equal(t, 2, fieldsBuilt, "Expected fields to be built once per enabled entry.")
+	assert.Equal(t, []string{"info"}, messages(primaryLogs.AllUntimed()), "Unexpected primary entries.")
+	assert.Equal(t, []string{"info", "debug"}, messages(auditLogs.AllUntimed()), "Unexpected audit entries.")
+	for _, entries := range [][]observer.LoggedEntry{primaryLogs.AllUntimed(), auditLogs.AllUntimed()} {
+		assert.Equal(t, []zapcore.Field{Int("n", 1)}, entries[0].Context, "Expected shared fields.")
+		assert.Regexp(t, `.+/zap/logger_test.go:[\d]+$`, entries[0].Entry.Caller, "Expected CheckAll's caller to be the call site.")
+	}
+}
+
+func TestLoggerLazy(t *testing.T) {
+	withLogger(t, InfoLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		var calls int
+		expensive := func() Field {
+			calls++
+			return String("", "computed")
+		}
+		logger.Debug("disabled", Lazy("value", expensive))
+		assert.Equal(t, 0, calls, "Expected lazy fields on disabled entries not to be computed.")
+
+		child := logger.WithLazy(Lazy("ctx", expensive))
+		child.Debug("disabled")
+		assert.Equal(t, 0, calls, "Expected lazy context not to be computed for disabled entries.")
+		child.Info("enabled", Lazy("value", expensive))
+		assert.Equal(t, logger, logger.WithLazy(), "Expected WithLazy without fields to return the same logger.")
+
+		// The observer keeps fields as they are, so they're only computed once
+		// encoded here.
+		entries := logs.AllUntimed()
+		require.Equal(t, 1, len(entries), "Unexpected number of entries.")
+		assert.Equal(t, map[string]interface{}{"ctx": "computed", "value": "computed"}, entries[0].ContextMap(), "Unexpected context.")
+		assert.Equal(t, 2, calls, "Expected lazy fields to be computed once encoded.")
+	})
+}
+
+func TestLoggerClose(t *testing.T) {
+	var closed []string
+	closeFn := func(name string, err error) func() error {
+		return func() error {
+			closed = append(closed, name)
+			return err
+		}
+	}
+
+	withLogger(t, DebugLevel, opts(OnClose(closeFn("first", nil))), func(logger *Logger, logs *observer.ObservedLogs) {
+		child := logger.WithOptions(OnClose(closeFn("second", errors.New("fail"))))
+		logger.Info("parent")
+
+		err := child.Close()
+		assert.EqualError(t, err, "fail", "Expected errors from closing.")
+		assert.Equal(t, []string{"second", "first"}, closed, "Expected closers to run in reverse order.")
+
+		assert.NoError(t, logger.Close(), "Expected no errors closing an already-closed Logger.")
+		assert.NoError(t, child.Close(), "Expected no errors closing an already-closed Logger.")
+		assert.Equal(t, []string{"second", "first"}, closed, "Expected each closer to run once.")
+	})
+
+	assert.NoError(t, NewNop().Close(), "Expected closing a no-op Logger to succeed.")
+}
+
+// writeRecorder records each call to Write separately.
+type writeRecorder struct {
+	sync.Mutex
+	writes []string
+}
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *writeRecorder) Sync() error { return nil }
+
+func TestLoggerColorWhen(t *testing.T) {
+	color := func(ent zapcore.Entry, fields []zapcore.Field) (string, bool) {
+		for _, f := range fields {
+			if f.Key == "user" && f.String == "alice" {
+				return "31", true
+			}
+		}
+		return "", false
+	}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+
+	tty := &ztest.Terminal{}
+	logger := New(zapcore.NewCore(enc.Clone(), zapcore.Lock(tty), DebugLevel), ColorWhen(color))
+	logger.Info("hello", String("user", "alice"))
+	logger.Info("hello", String("user", "bob"))
+	assert.Equal(t, []string{
+		"\x1b[31m" + `{"msg":"hello","user":"alice"}` + "\x1b[0m",
+		`{"msg":"hello","user":"bob"}`,
+	}, tty.Lines(), "Expected matching entries written to a terminal to be colored.")
+
+	buf := &ztest.Buffer{}
+	logger = New(zapcore.NewTee(
+		zapcore.NewCore(enc.Clone(), buf, DebugLevel),
+		zapcore.NewCore(enc.Clone(), tty, DebugLevel),
+	), ColorWhen(color))
+	tty.Reset()
+	logger.Info("hello", String("user", "alice"))
+	assert.Equal(t, `{"msg":"hello","user":"alice"}`, buf.Stripped(), "Expected entries written elsewhere to be left alone.")
+	assert.Equal(t, "\x1b[31m"+`{"msg":"hello","user":"alice"}`+"\x1b[0m", tty.Stripped(), "Expected entries written to a terminal to be colored.")
+}
+
+func TestLoggerMessagePrefix(t *testing.T) {
+	prefixes := map[zapcore.Level]string{WarnLevel: "⚠ ", ErrorLevel: "✖ "}
+	cfg := zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: zapcore.CapitalLevelEncoder}
+	console := &ztest.Buffer{}
+	text := &ztest.Buffer{}
+	json := &ztest.Buffer{}
+	logger := New(zapcore.NewTee(
+		zapcore.NewCore(zapcore.NewConsoleEncoder(cfg), console, DebugLevel),
+		zapcore.NewCore(zapcore.NewTextEncoder(cfg), text, DebugLevel),
+		zapcore.NewCore(zapcore.NewJSONEncoder(cfg), json, DebugLevel),
+	), MessagePrefix(prefixes))
+
+	logger.Info("fine")
+	logger.Warn("careful")
+	logger.Error("broken")
+	assert.Equal(t, []string{"INFO\tfine", "WARN\t⚠ careful", "ERROR\t✖ broken"}, console.Lines(), "Unexpected console output.")
+	assert.Equal(t, []string{"INFO\tfine", "WARN\t⚠ careful", "ERROR\t✖ broken"}, text.Lines(), "Unexpected text output.")
+	assert.Equal(t, []string{
+		`{"level":"INFO","msg":"fine"}`,
+		`{"level":"WARN","msg":"careful"}`,
+		`{"level":"ERROR","msg":"broken"}`,
+	}, json.Lines(), "Expected JSON messages to be left alone.")
+}
+
+func TestLoggerBulkHeader(t *testing.T) {
+	header := func(ent zapcore.Entry) []byte {
+		if ent.Level == DebugLevel {
+			return nil
+		}
+		return []byte(`{"index":{}}`)
+	}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+
+	tests := []struct {
+		desc string
+		opts []Option
+	}{
+		{"plain core", nil},
+		{"sampled core", []Option{WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewSampler(c, time.Second, 10, 10)
+		})}},
+		{"tee", []Option{WrapCore(func(c zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(c)
+		})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			w := &writeRecorder{}
+			opts := append(tt.opts, BulkHeader(header))
+			logger := New(zapcore.NewCore(enc.Clone(), w, DebugLevel), opts...).With(String("k", "v"))
+
+			logger.Info("hello")
+			logger.Debug("no header")
+			assert.Equal(t, []string{
+				`{"index":{}}` + "\n" + `{"msg":"hello","k":"v"}` + "\n",
+				`{"msg":"no header","k":"v"}` + "\n",
+			}, w.writes, "Expected each header and entry to be written together.")
+		})
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// StringMapN constructs a field that carries at most max entries of a map of
+// strings as a nested object, keeping the log entry bounded even if the map
+// is large. The entries with the lexically smallest keys are kept, so the
+// output is deterministic. Like StringsN, if any entries are omitted, their
+// number is added under key+"_truncated".
+func StringMapN(key string, m map[string]string, max int) Field {
+	n := truncatedLen(len(m), max)
+	sm := stringMap{m: m, max: n}
+	if n == len(m) {
+		return Object(key, sm)
+	}
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: truncatedMap{
+		key:     key,
+		m:       sm,
+		omitted: len(m) - n,
+	}}
+}
+
+// stringMap adds the max entries of m with the smallest keys to an object.
+type stringMap struct {
+	m   map[string]string
+	max int
+}
+
+func (sm stringMap) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(sm.m))
+	for k := range sm.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	// The map may have shrunk since the field was constructed.
+	n := sm.max
+	if n > len(keys) {
+		n = len(keys)
+	}
+	for _, k := range keys[:n] {
+		enc.AddString(k, sm.m[k])
+	}
+	return nil
+}
+
+// truncatedMap adds a truncated map and the number of entries omitted from
+// it to the enclosing object.
+type truncatedMap struct {
+	key     string
+	m       zapcore.ObjectMarshaler
+	omitted int
+}
+
+func (t truncatedMap) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	err := enc.AddObject(t.key, t.m)
+	enc.AddInt(t.key+"_truncated", t.omitted)
+	return err
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringMapN(t *testing.T) {
+	m := map[string]string{"c": "3", "a": "1", "d": "4", "b": "2"}
+	tests := []struct {
+		desc     string
+		max      int
+		expected map[string]interface{}
+	}{
+		{
+			desc:     "under the limit",
+			max:      5,
+			expected: map[string]interface{}{"m": map[string]interface{}{"a": "1", "b": "2", "c": "3", "d": "4"}},
+		},
+		{
+			desc:     "at the limit",
+			max:      4,
+			expected: map[string]interface{}{"m": map[string]interface{}{"a": "1", "b": "2", "c": "3", "d": "4"}},
+		},
+		{
+			desc:     "over the limit",
+			max:      2,
+			expected: map[string]interface{}{"m": map[string]interface{}{"a": "1", "b": "2"}, "m_truncated": 2},
+		},
+		{
+			desc:     "negative limit",
+			max:      -1,
+			expected: map[string]interface{}{"m": map[string]interface{}{}, "m_truncated": 4},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		f := StringMapN("m", m, tt.max)
+		f.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields, "Unexpected output %s.", tt.desc)
+		assertCanBeReused(t, f)
+	}
+}
+
+func TestStringMapNOrdering(t *testing.T) {
+	m := map[string]string{"zeta": "z", "alpha": "a", "mu": "m", "beta": "b", "omega": "o"}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	for i := 0; i < 10; i++ {
+		buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{StringMapN("m", m, 3)})
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		assert.Equal(t, `{"m":{"alpha":"a","beta":"b","mu":"m"},"m_truncated":2}`+"\n", buf.String(), "Expected keys in sorted order.")
+		buf.Free()
+	}
+}
+
+func TestStringMapNShrunk(t *testing.T) {
+	m := map[string]string{"a": "1", "b": "2", "c": "3"}
+	f := StringMapN("m", m, 5)
+	delete(m, "b")
+	delete(m, "c")
+
+	enc := zapcore.NewMapObjectEncoder()
+	assert.NotPanics(t, func() { f.AddTo(enc) }, "Expected encoding a shrunken map not to panic.")
+	assert.Equal(t, map[string]interface{}{"m": map[string]interface{}{"a": "1"}}, enc.Fields, "Unexpected output.")
+}
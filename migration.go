@@ -0,0 +1,53 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Migration constructs a field that logs a step of a versioned schema
+// migration under the key "migration", giving migration logs a uniform,
+// queryable shape:
+//   {"from":3,"to":4,"direction":"up"}
+// The direction must be "up" or "down". Any other direction is omitted, and
+// the problem is reported under "migrationError", like any other marshaling
+// error.
+func Migration(from, to int, direction string) Field {
+	return Object("migration", migration{from: from, to: to, direction: direction})
+}
+
+type migration struct {
+	from, to  int
+	direction string
+}
+
+func (m migration) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("from", m.from)
+	enc.AddInt("to", m.to)
+	if m.direction != "up" && m.direction != "down" {
+		return fmt.Errorf("invalid migration direction %q: must be \"up\" or \"down\"", m.direction)
+	}
+	enc.AddString("direction", m.direction)
+	return nil
+}
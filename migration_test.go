@@ -0,0 +1,67 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigration(t *testing.T) {
+	tests := []struct {
+		desc     string
+		field    Field
+		expected map[string]interface{}
+	}{
+		{
+			desc:  "up",
+			field: Migration(3, 4, "up"),
+			expected: map[string]interface{}{
+				"migration": map[string]interface{}{"from": 3, "to": 4, "direction": "up"},
+			},
+		},
+		{
+			desc:  "down",
+			field: Migration(4, 3, "down"),
+			expected: map[string]interface{}{
+				"migration": map[string]interface{}{"from": 4, "to": 3, "direction": "down"},
+			},
+		},
+		{
+			desc:  "invalid direction",
+			field: Migration(4, 3, "sideways"),
+			expected: map[string]interface{}{
+				"migration":      map[string]interface{}{"from": 4, "to": 3},
+				"migrationError": `invalid migration direction "sideways": must be "up" or "down"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		tt.field.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields, "Unexpected output for %s.", tt.desc)
+		assertCanBeReused(t, tt.field)
+	}
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+const (
+	_defaultNetworkTimeout    = 5 * time.Second
+	_defaultNetworkSpoolBytes = 1024 * 1024
+	_defaultNetworkBackoff    = 100 * time.Millisecond
+	_defaultNetworkMaxBackoff = 30 * time.Second
+)
+
+// A NetworkOption configures a Sink returned by NetworkSink.
+type NetworkOption interface {
+	apply(*networkSink)
+}
+
+type networkOptionFunc func(*networkSink)
+
+func (f networkOptionFunc) apply(s *networkSink) {
+	f(s)
+}
+
+// NetworkDialTimeout limits how long each attempt to connect may take. It
+// defaults to 5 seconds; a timeout of zero or less disables it.
+func NetworkDialTimeout(timeout time.Duration) NetworkOption {
+	return networkOptionFunc(func(s *networkSink) {
+		s.dialTimeout = timeout
+	})
+}
+
+// NetworkWriteTimeout limits how long each write may take before the
+// connection is considered broken. It defaults to 5 seconds; a timeout of
+// zero or less disables it.
+func NetworkWriteTimeout(timeout time.Duration) NetworkOption {
+	return networkOptionFunc(func(s *networkSink) {
+		s.writeTimeout = timeout
+	})
+}
+
+// NetworkSpoolSize sets how many bytes of writes are kept in memory while
+// disconnected. It defaults to 1 MB.
+func NetworkSpoolSize(bytes int) NetworkOption {
+	return networkOptionFunc(func(s *networkSink) {
+		s.maxSpool = bytes
+	})
+}
+
+// NetworkRetryBackoff sets how long to wait between attempts to reconnect.
+// After n consecutive failures, the next attempt is made no sooner than
+// BackoffDelay(n-1, base, max). It defaults to a base of 100 milliseconds and
+// a max of 30 seconds.
+func NetworkRetryBackoff(base, max time.Duration) NetworkOption {
+	return networkOptionFunc(func(s *networkSink) {
+		s.backoffBase = base
+		s.backoffMax = max
+	})
+}
+
+// NetworkSink returns a Sink that writes to the given network address, as
+// accepted by net.Dial, reconnecting whenever the connection breaks. Each
+// write is sent over the connection as is, so it's suited to collectors that
+// accept newline-delimited entries over TCP, UDP, or Unix sockets, like
+// Fluentd, Logstash, and Vector.
+//
+// While disconnected, writes are kept in a bounded in-memory spool and sent,
+// in order, once a connection is re-established. Reconnection happens on
+// later writes and syncs, backing off after each failed attempt. When the
+// spool is full, the oldest writes are dropped and Write reports an error;
+// otherwise, spooled writes succeed. Sync reports an error if the spool
+// can't be emptied.
+//
+// Since the first connection is established the same way, NetworkSink
+// doesn't fail if the address is unreachable. It's safe for concurrent use.
+func NetworkSink(network, addr string, opts ...NetworkOption) (Sink, error) {
+	s := &networkSink{
+		network:      network,
+		addr:         addr,
+		dialTimeout:  _defaultNetworkTimeout,
+		writeTimeout: _defaultNetworkTimeout,
+		maxSpool:     _defaultNetworkSpoolBytes,
+		backoffBase:  _defaultNetworkBackoff,
+		backoffMax:   _defaultNetworkMaxBackoff,
+		dial:         net.DialTimeout,
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+	s.connect()
+	return s, nil
+}
+
+type networkSink struct {
+	mu           sync.Mutex
+	network      string
+	addr         string
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	maxSpool     int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	dial         func(network, addr string, timeout time.Duration) (net.Conn, error)
+	now          func() time.Time
+
+	conn     net.Conn // nil while disconnected
+	failures int      // consecutive failed attempts to connect
+	nextDial time.Time
+	dialErr  error
+	spool    [][]byte
+	spooled  int // bytes in spool
+	closed   bool
+}
+
+func (s *networkSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, os.ErrClosed
+	}
+	n := len(p)
+	if s.flush() == nil {
+		sent, err := s.send(p)
+		if err == nil {
+			return n, nil
+		}
+		// Spool only what the connection didn't accept, so that nothing
+		// reaches the other end twice.
+		p = p[sent:]
+	}
+	return n, s.enqueue(p)
+}
+
+func (s *networkSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.spool) == 0 {
+		return nil
+	}
+	if err := s.flush(); err != nil {
+		return fmt.Errorf("couldn't send %d spooled writes to %s: %v", len(s.spool), s.addr, err)
+	}
+	return nil
+}
+
+// Close makes a last attempt to send any spooled writes, then closes the
+// connection.
+func (s *networkSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	var err error
+	if len(s.spool) > 0 {
+		if ferr := s.flush(); ferr != nil {
+			err = fmt.Errorf("dropped %d spooled writes to %s: %v", len(s.spool), s.addr, ferr)
+		}
+		s.spool, s.spooled = nil, 0
+	}
+	if s.conn != nil {
+		err = multierr.Append(err, s.conn.Close())
+		s.conn = nil
+	}
+	return err
+}
+
+// connect establishes a connection if there isn't one, unless the last
+// attempt failed too recently. It must be called with the lock held.
+func (s *networkSink) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+	now := s.now()
+	if now.Before(s.nextDial) {
+		return s.dialErr
+	}
+	conn, err := s.dial(s.network, s.addr, s.dialTimeout)
+	if err != nil {
+		s.failures++
+		s.nextDial = now.Add(BackoffDelay(s.failures-1, s.backoffBase, s.backoffMax))
+		s.dialErr = err
+		return err
+	}
+	s.conn = conn
+	s.failures = 0
+	s.dialErr = nil
+	return nil
+}
+
+// flush connects if necessary and sends the spooled writes, oldest first.
+// It must be called with the lock held.
+func (s *networkSink) flush() error {
+	if err := s.connect(); err != nil {
+		return err
+	}
+	for len(s.spool) > 0 {
+		if n, err := s.send(s.spool[0]); err != nil {
+			s.spool[0] = s.spool[0][n:]
+			s.spooled -= n
+			return err
+		}
+		s.spooled -= len(s.spool[0])
+		s.spool[0] = nil
+		s.spool = s.spool[1:]
+	}
+	return nil
+}
+
+// send writes p to the current connection, dropping the connection if the
+// write fails, and reports how many bytes were written. It must be called
+// with the lock held.
+func (s *networkSink) send(p []byte) (int, error) {
+	if s.writeTimeout > 0 {
+		s.conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+	}
+	n, err := s.conn.Write(p)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return n, err
+}
+
+// enqueue adds a copy of p to the spool, dropping the oldest writes to make
+// room. It must be called with the lock held.
+func (s *networkSink) enqueue(p []byte) error {
+	if len(p) > s.maxSpool {
+		return fmt.Errorf("dropped a %d-byte write to %s, which is larger than the spool", len(p), s.addr)
+	}
+	var dropped int
+	for s.spooled+len(p) > s.maxSpool {
+		s.spooled -= len(s.spool[0])
+		s.spool[0] = nil
+		s.spool = s.spool[1:]
+		dropped++
+	}
+	s.spool = append(s.spool, append([]byte(nil), p...))
+	s.spooled += len(p)
+	if dropped > 0 {
+		return fmt.Errorf("spool of writes to %s is full, dropped %d writes", s.addr, dropped)
+	}
+	return nil
+}
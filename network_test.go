@@ -0,0 +1,223 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn records writes until it's broken. If limit is positive, a write
+// longer than limit is cut short and breaks the connection.
+type fakeConn struct {
+	net.Conn
+	writes []string
+	limit  int
+	broken bool
+	closed bool
+}
+
+func (c *fakeConn) Write(p []byte) (int, error) {
+	if c.broken {
+		return 0, errors.New("broken pipe")
+	}
+	if c.limit > 0 && len(p) > c.limit {
+		c.writes = append(c.writes, string(p[:c.limit]))
+		c.broken = true
+		return c.limit, errors.New("connection reset")
+	}
+	c.writes = append(c.writes, string(p))
+	return len(p), nil
+}
+
+func (c *fakeConn) SetWriteDeadline(time.Time) error { return nil }
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+// fakeNetwork hands out fakeConns, or fails to connect while it's down.
+type fakeNetwork struct {
+	down  bool
+	limit int // passed on to new conns
+	dials int
+	conns []*fakeConn
+	now   time.Time
+}
+
+func (n *fakeNetwork) dial(network, addr string, timeout time.Duration) (net.Conn, error) {
+	n.dials++
+	if n.down {
+		return nil, errors.New("connection refused")
+	}
+	c := &fakeConn{limit: n.limit}
+	n.conns = append(n.conns, c)
+	return c, nil
+}
+
+func (n *fakeNetwork) writes() []string {
+	var ws []string
+	for _, c := range n.conns {
+		ws = append(ws, c.writes...)
+	}
+	return ws
+}
+
+func newFakeNetworkSink(t testing.TB, n *fakeNetwork, opts ...NetworkOption) *networkSink {
+	opts = append([]NetworkOption{networkOptionFunc(func(s *networkSink) {
+		s.dial = n.dial
+		s.now = func() time.Time { return n.now }
+	})}, opts...)
+	sink, err := NetworkSink("tcp", "collector:5170", opts...)
+	require.NoError(t, err, "Unexpected error creating network sink.")
+	return sink.(*networkSink)
+}
+
+func writeNetwork(t testing.TB, s Sink, msg string) error {
+	n, err := s.Write([]byte(msg))
+	assert.Equal(t, len(msg), n, "Expected writes to be accepted.")
+	return err
+}
+
+func TestNetworkSinkReconnects(t *testing.T) {
+	network := &fakeNetwork{now: time.Unix(0, 0)}
+	sink := newFakeNetworkSink(t, network)
+
+	assert.NoError(t, writeNetwork(t, sink, "a"), "Unexpected error writing.")
+	network.conns[0].broken = true
+	assert.NoError(t, writeNetwork(t, sink, "b"), "Expected writes to be spooled rather than fail.")
+	assert.True(t, network.conns[0].closed, "Expected the broken connection to be closed.")
+
+	assert.NoError(t, writeNetwork(t, sink, "c"), "Unexpected error writing.")
+	assert.Equal(t, 2, len(network.conns), "Expected to reconnect.")
+	assert.Equal(t, []string{"a", "b", "c"}, network.writes(), "Expected spooled writes to be sent in order.")
+	assert.NoError(t, sink.Sync(), "Unexpected error syncing.")
+	require.NoError(t, sink.Close(), "Unexpected error closing.")
+	assert.True(t, network.conns[1].closed, "Expected Close to close the connection.")
+	_, err := sink.Write([]byte("d"))
+	assert.Equal(t, os.ErrClosed, err, "Expected writes after Close to fail.")
+}
+
+func TestNetworkSinkBacksOff(t *testing.T) {
+	network := &fakeNetwork{down: true, now: time.Unix(0, 0)}
+	sink := newFakeNetworkSink(t, network, NetworkRetryBackoff(time.Second, 4*time.Second))
+	assert.Equal(t, 1, network.dials, "Expected to connect eagerly.")
+
+	assert.NoError(t, writeNetwork(t, sink, "a"), "Expected writes to be spooled.")
+	assert.Equal(t, 1, network.dials, "Expected no attempts to reconnect during the backoff.")
+	assert.Error(t, sink.Sync(), "Expected Sync to fail while writes are spooled.")
+
+	network.now = network.now.Add(time.Second)
+	assert.NoError(t, writeNetwork(t, sink, "b"), "Expected writes to be spooled.")
+	assert.Equal(t, 2, network.dials, "Expected to reconnect after the backoff.")
+
+	network.now = network.now.Add(time.Second)
+	writeNetwork(t, sink, "c")
+	assert.Equal(t, 2, network.dials, "Expected the backoff to grow.")
+
+	network.down = false
+	network.now = network.now.Add(time.Second)
+	assert.NoError(t, sink.Sync(), "Expected Sync to send the spooled writes.")
+	assert.Equal(t, []string{"a", "b", "c"}, network.writes(), "Unexpected writes.")
+}
+
+func TestNetworkSinkShortWrites(t *testing.T) {
+	network := &fakeNetwork{limit: 2, now: time.Unix(0, 0)}
+	sink := newFakeNetworkSink(t, network)
+
+	assert.NoError(t, writeNetwork(t, sink, "abcd"), "Expected the unwritten tail to be spooled.")
+	assert.Equal(t, []string{"ab"}, network.writes(), "Unexpected writes.")
+	network.limit = 0
+	assert.NoError(t, writeNetwork(t, sink, "e"), "Unexpected error writing.")
+	assert.Equal(t, []string{"ab", "cd", "e"}, network.writes(), "Expected only the unwritten tail to be resent.")
+
+	network = &fakeNetwork{down: true, now: time.Unix(0, 0)}
+	sink = newFakeNetworkSink(t, network)
+	assert.NoError(t, writeNetwork(t, sink, "abcd"), "Expected writes to be spooled.")
+	network.down, network.limit = false, 2
+	network.now = network.now.Add(time.Minute)
+	assert.Error(t, sink.Sync(), "Expected Sync to fail after a short write.")
+	network.limit = 0
+	assert.NoError(t, sink.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, []string{"ab", "cd"}, network.writes(), "Expected only the unwritten tail of spooled writes to be resent.")
+}
+
+func TestNetworkSinkSpoolOverflow(t *testing.T) {
+	network := &fakeNetwork{down: true, now: time.Unix(0, 0)}
+	sink := newFakeNetworkSink(t, network, NetworkSpoolSize(4))
+
+	assert.NoError(t, writeNetwork(t, sink, "aa"), "Unexpected error spooling.")
+	assert.NoError(t, writeNetwork(t, sink, "bb"), "Unexpected error spooling.")
+	err := writeNetwork(t, sink, "cc")
+	require.Error(t, err, "Expected an error when the spool overflows.")
+	assert.Contains(t, err.Error(), "dropped 1 writes", "Unexpected error.")
+	err = writeNetwork(t, sink, strings.Repeat("x", 5))
+	require.Error(t, err, "Expected an error for writes larger than the spool.")
+	assert.Contains(t, err.Error(), "larger than the spool", "Unexpected error.")
+
+	network.down = false
+	network.now = network.now.Add(time.Minute)
+	assert.NoError(t, sink.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, []string{"bb", "cc"}, network.writes(), "Expected the oldest writes to be dropped.")
+}
+
+func TestNetworkSinkCloseDropsSpool(t *testing.T) {
+	network := &fakeNetwork{down: true, now: time.Unix(0, 0)}
+	sink := newFakeNetworkSink(t, network)
+	writeNetwork(t, sink, "a")
+	err := sink.Close()
+	require.Error(t, err, "Expected Close to report undelivered writes.")
+	assert.Contains(t, err.Error(), "dropped 1 spooled writes", "Unexpected error.")
+	assert.NoError(t, sink.Close(), "Expected closing twice to be a no-op.")
+}
+
+func TestNetworkSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen.")
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 3)
+		n, _ := conn.Read(buf)
+		received <- string(buf[:n])
+	}()
+
+	sink, err := NetworkSink("tcp", ln.Addr().String(), NetworkDialTimeout(time.Second), NetworkWriteTimeout(time.Second))
+	require.NoError(t, err, "Unexpected error creating network sink.")
+	defer sink.Close()
+	assert.NoError(t, writeNetwork(t, sink, "foo"), "Unexpected error writing.")
+	assert.Equal(t, "foo", <-received, "Unexpected output sent over TCP.")
+}
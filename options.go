@@ -20,7 +20,16 @@
 
 package zap
 
-import "go.uber.org/zap/zapcore"
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
 
 // An Option configures a Logger.
 type Option interface {
@@ -42,7 +51,9 @@ func WrapCore(f func(zapcore.Core) zapcore.Core) Option {
 }
 
 // Hooks registers functions which will be called each time the Logger writes
-// out an Entry. Repeated use of Hooks is additive.
+// out an Entry. Repeated use of Hooks is additive. The hooks run in order,
+// after the Entry is written; errors they return don't prevent the write, but
+// are reported to the Logger's ErrorOutput.
 //
 // Hooks are useful for simple side effects, like capturing metrics for the
 // number of emitted logs. More complex side effects, including anything that
@@ -54,6 +65,32 @@ func Hooks(hooks ...func(zapcore.Entry) error) Option {
 	})
 }
 
+// Tee configures the Logger to duplicate its entries into the supplied Cores,
+// in addition to its existing Core. Each Core applies its own level, so it's
+// easy to add outputs with different thresholds; for example,
+//   errorsCore := zapcore.NewCore(jsonEncoder, errorFile, zap.ErrorLevel)
+//   logger = logger.WithOptions(zap.Tee(errorsCore))
+// sends error-level entries to a separate file, while the Logger's original
+// output is unchanged. Fields already added to the Logger aren't added to the
+// new Cores. See zapcore.NewTee for details.
+func Tee(cores ...zapcore.Core) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(append([]zapcore.Core{core}, cores...)...)
+	})
+}
+
+// HooksWithFields is like Hooks, but the hooks also receive the entry's
+// fields, including those added via With. It's useful for side effects that
+// depend on context, like forwarding fatal entries along with their fields to
+// an alerting system. Fields added before HooksWithFields is applied (by
+// Fields options or a Config's InitialFields) aren't passed to the hooks. See
+// zapcore.RegisterHooksWithFields for details.
+func HooksWithFields(hooks ...func(zapcore.Entry, []Field) error) Option {
+	return optionFunc(func(log *Logger) {
+		log.core = zapcore.RegisterHooksWithFields(log.core, hooks...)
+	})
+}
+
 // Fields adds fields to the Logger.
 func Fields(fs ...Field) Option {
 	return optionFunc(func(log *Logger) {
@@ -66,6 +103,12 @@ func Fields(fs ...Field) Option {
 // error-level logs to a different location from info- and debug-level logs,
 // see the package-level AdvancedConfiguration example.
 //
+// Entries that fail to write, whether because a sink returned an error or a
+// short write or because encoding them panicked, are reported to the error
+// output along with the number of failed writes so far. That count is
+// process-wide, covering all Loggers (see zapcore.WriteErrors). The Logger
+// keeps writing the entry to any other Cores that agreed to log it.
+//
 // The supplied WriteSyncer must be safe for concurrent use. The Open and
 // zapcore.Lock functions are the simplest ways to protect files with a mutex.
 func ErrorOutput(w zapcore.WriteSyncer) Option {
@@ -75,15 +118,92 @@ func ErrorOutput(w zapcore.WriteSyncer) Option {
 }
 
 // Development puts the logger in development mode, which makes DPanic-level
-// logs panic instead of simply logging an error.
+// logs panic instead of simply logging an error. NewDevelopment and
+// NewDevelopmentConfig enable it, as does setting Config.Development.
 func Development() Option {
 	return optionFunc(func(log *Logger) {
 		log.development = true
 	})
 }
 
+// OnFatal replaces the os.Exit(1) that normally follows a Fatal-level entry
+// with f, which runs after the entry is written and the Logger's Core is
+// synced. It's useful in tests, which can't assert on Fatal-level logging if
+// the test binary exits, and in supervisors that prefer to handle shutdown
+// themselves. Since f runs in place of the exit, the statement after the
+// Fatal call runs as usual unless f panics or exits.
+//
+// To intercept the exit itself, as a supervisor that exits with its own
+// status code might, pass a function that exits. OnFatal replaces any action
+// set with OnFatalAction, and passing a nil f restores the default behavior.
+func OnFatal(f func()) Option {
+	return optionFunc(func(log *Logger) {
+		log.onFatal = f
+		log.onFatalAction = zapcore.WriteThenNoop
+	})
+}
+
+// OnFatalAction replaces the os.Exit(1) that normally follows a Fatal-level
+// entry with another CheckWriteAction: zapcore.WriteThenPanic panics with the
+// entry's message, and zapcore.WriteThenGoexit stops the logging goroutine
+// with runtime.Goexit, much as testing.T.FailNow does. Unlike a function
+// passed to OnFatal, actions run without syncing the Logger's Core first.
+//
+// OnFatalAction replaces any function set with OnFatal. Since a Fatal call
+// should never return, zapcore.WriteThenNoop restores the default behavior
+// rather than letting execution continue.
+func OnFatalAction(action zapcore.CheckWriteAction) Option {
+	return optionFunc(func(log *Logger) {
+		log.onFatal = nil
+		log.onFatalAction = action
+	})
+}
+
+// ReplayOnError configures the Logger to hold back its most recent size
+// entries below ErrorLevel, writing them only when an entry at ErrorLevel or
+// above is logged. Applied to each request's Logger, it records the full
+// debug context of failed requests without writing debug logs for the rest:
+//
+//   reqLogger := logger.WithOptions(zap.ReplayOnError(100))
+//
+// Each use of the option creates a new ring of held-back entries, shared by
+// the Logger and its children. See zapcore.NewReplayCore for details.
+func ReplayOnError(size int) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewReplayCore(core, size, zapcore.ErrorLevel)
+	})
+}
+
+// FilterFunc configures the Logger to drop the entries for which keep returns
+// false. It runs after the level check, so it's only called for entries that
+// would otherwise be logged, and it sees all of the entry's fields. For
+// example, to silence a known-noisy warning from a dependency without
+// raising the Logger's level:
+//
+//   zap.FilterFunc(func(ent zapcore.Entry, _ []zapcore.Field) bool {
+//     return !(ent.LoggerName == "grpc" && strings.HasPrefix(ent.Message, "transport: "))
+//   })
+//
+// Entries at PanicLevel and above are still followed by a panic or exit,
+// even if they're dropped. See zapcore.NewFilterCore for details.
+func FilterFunc(keep func(zapcore.Entry, []zapcore.Field) bool) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewFilterCore(core, keep)
+	})
+}
+
+// WithClock configures the Logger to timestamp entries using the supplied
+// Clock rather than the system time. It's mostly useful in tests, which can
+// substitute a clock that returns predictable times; see zaptest.NewClock.
+func WithClock(clock zapcore.Clock) Option {
+	return optionFunc(func(log *Logger) {
+		log.clock = clock
+	})
+}
+
 // AddCaller configures the Logger to annotate each message with the filename
-// and line number of zap's caller.
+// and line number of zap's caller. Encoders also record the name of the
+// caller's function if their EncoderConfig sets a FunctionKey.
 func AddCaller() Option {
 	return optionFunc(func(log *Logger) {
 		log.addCaller = true
@@ -107,3 +227,314 @@ func AddStacktrace(lvl zapcore.LevelEnabler) Option {
 		log.addStack = lvl
 	})
 }
+
+// WithLevel restricts the Logger to entries enabled by lvl, in addition to
+// those enabled by its Core. The level is consulted on every call, so
+// passing an AtomicLevel lets you change a running Logger's level, along
+// with that of every child created from it. For example,
+//   atom := zap.NewAtomicLevel()
+//   logger := zap.New(core, zap.WithLevel(atom))
+//   atom.SetLevel(zap.DebugLevel)
+// enables debug logging on logger and its children, provided that core
+// itself is enabled at DebugLevel.
+func WithLevel(lvl zapcore.LevelEnabler) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelFilterCore{core, lvl}
+	})
+}
+
+type levelFilterCore struct {
+	zapcore.Core
+	level zapcore.LevelEnabler
+}
+
+func (c *levelFilterCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl) && c.Core.Enabled(lvl)
+}
+
+func (c *levelFilterCore) With(fields []Field) zapcore.Core {
+	return &levelFilterCore{c.Core.With(fields), c.level}
+}
+
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// WithNameLevels restricts entries from named Loggers (see Logger.Named) to
+// the levels configured for their names, in addition to the levels enabled
+// by the Core. An entry uses the level of the longest configured prefix of
+// its logger's name: a Logger named "db.pool.conn" uses the level for
+// "db.pool.conn" if there is one, then "db.pool", then "db". Entries whose
+// names match no prefix use the level for "*", if any, and are otherwise only
+// restricted by the Core. Configure the empty name to restrict only unnamed
+// Loggers. For example,
+//   logger := zap.New(core, zap.WithNameLevels(map[string]zapcore.LevelEnabler{
+//     "db":      zap.WarnLevel,
+//     "db.pool": zap.DebugLevel,
+//   }))
+// keeps debug logs from the connection pool, but only warnings and errors
+// from the rest of the database layer. As with WithLevel, passing
+// AtomicLevels lets you change the levels of a running Logger.
+func WithNameLevels(levels map[string]zapcore.LevelEnabler) Option {
+	copied := make(map[string]zapcore.LevelEnabler, len(levels))
+	for name, lvl := range levels {
+		copied[name] = lvl
+	}
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &nameLevelCore{core, copied}
+	})
+}
+
+type nameLevelCore struct {
+	zapcore.Core
+	levels map[string]zapcore.LevelEnabler
+}
+
+func (c *nameLevelCore) With(fields []Field) zapcore.Core {
+	return &nameLevelCore{c.Core.With(fields), c.levels}
+}
+
+func (c *nameLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if lvl, ok := c.levelFor(ent.LoggerName); ok && !lvl.Enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+func (c *nameLevelCore) levelFor(name string) (zapcore.LevelEnabler, bool) {
+	for {
+		if lvl, ok := c.levels[name]; ok {
+			return lvl, true
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	lvl, ok := c.levels["*"]
+	return lvl, ok
+}
+
+// Dedup configures the Logger to write at most one field with any given key
+// per entry, with later fields replacing earlier ones, even if the earlier
+// ones were added via With. Since fields added before Dedup is applied are
+// already serialized, pass Dedup before any options that add fields. Loggers
+// built from a Config add the Config's InitialFields first, so they should
+// set Config.DeduplicateKeys instead. See zapcore.NewDedupCore for the costs
+// of deduplicating.
+func Dedup() Option {
+	return WrapCore(zapcore.NewDedupCore)
+}
+
+// SyncEvery configures the Logger to sync its Core in the background after
+// writing, but at most once per interval: entries are synced within an
+// interval of being written, and an idle Logger isn't synced at all. Syncs
+// are scheduled with the Logger's clock, so pass WithClock first to change
+// it. See zapcore.NewSyncEveryCore for details.
+func SyncEvery(interval time.Duration) Option {
+	return optionFunc(func(log *Logger) {
+		log.core = zapcore.NewSyncEveryCore(log.core, interval, log.clock)
+	})
+}
+
+// Sample caps the volume of repetitive entries: for each level and message,
+// the Logger writes the first entries in each tick and then only every
+// thereafter-th entry until the tick ends. Sampled-out entries are dropped
+// by Check, before their fields are serialized. Loggers built from a Config
+// with a SamplingConfig already sample, once per second. To count dropped
+// entries, pass a zapcore.SamplerHook. See zapcore.NewSampler for details.
+func Sample(tick time.Duration, first, thereafter int, opts ...zapcore.SamplerOption) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewSamplerWithOptions(core, tick, first, thereafter, opts...)
+	})
+}
+
+// PrioritySampler samples the Logger's entries at rates computed from their
+// contents, so that important entries survive sampling while noisy ones are
+// thinned out. For example,
+//   zap.PrioritySampler(func(ent zapcore.Entry, fields []zapcore.Field) zapcore.SampleRate {
+//     for _, f := range fields {
+//       if f.Key == "priority" && f.String == "low" {
+//         return 0.01
+//       }
+//     }
+//     return 1
+//   })
+// keeps every entry except those with a low priority field, of which it keeps
+// 1%. See zapcore.NewPrioritySampler for details.
+func PrioritySampler(rate func(zapcore.Entry, []Field) zapcore.SampleRate) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewPrioritySampler(core, rate)
+	})
+}
+
+// OnReservedKeyCollision configures how the Logger handles fields whose keys
+// collide with the keys reserved for the entry's metadata, like "level" and
+// "ts", which would otherwise produce objects with duplicate keys. See
+// zapcore.ReservedKeyPolicy for the available policies.
+//
+// The reserved keys default to those of NewProductionEncoderConfig; Loggers
+// whose encoders use other keys should supply them explicitly. Loggers built
+// from a Config already rename colliding fields, using the Config's keys.
+func OnReservedKeyCollision(policy zapcore.ReservedKeyPolicy, keys ...string) Option {
+	if len(keys) == 0 {
+		keys = NewProductionEncoderConfig().ReservedKeys()
+	}
+	return optionFunc(func(log *Logger) {
+		log.core = zapcore.NewReservedKeyCore(log.core, policy, keys...)
+	})
+}
+
+// WithBreadcrumbs attaches a ring of breadcrumbs to the Logger, so that
+// Logger.Crumb records into it. Loggers derived from this one share the ring.
+// Use the Crumbs field to flush the breadcrumbs into a log entry.
+func WithBreadcrumbs(b *Breadcrumbs) Option {
+	return optionFunc(func(log *Logger) {
+		log.crumbs = b
+	})
+}
+
+// AddRuntimeContext configures the Logger to annotate each entry with a
+// snapshot of the Go scheduler's state, captured when the entry is written:
+// the current GOMAXPROCS setting, the number of goroutines, and the number of
+// logical CPUs. The snapshot is added as a nested object under the "runtime"
+// key.
+//
+// Entries below the Logger's level never take a snapshot. Entries that are
+// written pay for a few runtime calls and an extra allocation or two, which
+// is negligible next to encoding but not free; this option is intended for
+// debugging scheduling and contention problems rather than for permanent use.
+func AddRuntimeContext() Option {
+	return optionFunc(func(log *Logger) {
+		log.core = zapcore.RegisterFieldHooks(log.core, func(zapcore.Entry) []Field {
+			return []Field{Object("runtime", runtimeContext{})}
+		})
+	})
+}
+
+// runtimeContext reads the scheduler's state only when it's marshaled.
+type runtimeContext struct{}
+
+func (runtimeContext) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt("gomaxprocs", runtime.GOMAXPROCS(0))
+	enc.AddInt("goroutines", runtime.NumGoroutine())
+	enc.AddInt("cpus", runtime.NumCPU())
+	return nil
+}
+
+// AddSessionID configures the Logger to add a randomly-generated session ID
+// to every entry under the "session" key. The ID is generated once, when the
+// option is applied, and is shared by all loggers derived from the result.
+//
+// Session IDs make it easy to tell apart the logs of concurrent runs of the
+// same program, like parallel test processes writing to a shared output.
+func AddSessionID() Option {
+	return optionFunc(func(log *Logger) {
+		log.core = log.core.With([]Field{String("session", newSessionID())})
+	})
+}
+
+func newSessionID() string {
+	return randomID(8)
+}
+
+// randomID returns n random bytes (n must be at least 8), hex-encoded.
+func randomID(n int) string {
+	id := make([]byte, n)
+	if _, err := rand.Read(id); err != nil {
+		// The system's source of randomness is broken, which is exceedingly
+		// rare; the current time is the next best thing.
+		binary.BigEndian.PutUint64(id, uint64(time.Now().UnixNano()))
+	}
+	return hex.EncodeToString(id)
+}
+
+// OnClose registers functions for the Logger's Close method to run after
+// syncing, typically to release the resources its Core writes to. Repeated use
+// of OnClose is additive, and functions registered later run first. Each runs
+// at most once, no matter how many Loggers derived from the result are
+// closed.
+func OnClose(fs ...func() error) Option {
+	return optionFunc(func(log *Logger) {
+		closers := make([]*closer, 0, len(log.closers)+len(fs))
+		closers = append(closers, log.closers...)
+		for _, f := range fs {
+			closers = append(closers, &closer{f: f})
+		}
+		log.closers = closers
+	})
+}
+
+// BulkHeader precedes each entry with a header line computed by the supplied
+// function, as ingestion APIs like Elasticsearch's bulk endpoint expect:
+//
+//   {"index":{}}
+//   {"level":"info","msg":"hello"}
+//
+// If the function returns an empty slice, no header is written for that
+// entry. The header and the entry are encoded together and written with a
+// single call to the Core's WriteSyncer, so concurrent entries never land
+// between a header and its entry.
+//
+// BulkHeader wraps the Encoder of the Logger's Core with
+// zapcore.NewHeaderEncoder, so it works with the Cores that
+// zapcore.WrapEncoder understands, including those built by Config. Other
+// Cores are left unchanged; wrap their Encoders with zapcore.NewHeaderEncoder
+// directly.
+func BulkHeader(header func(zapcore.Entry) []byte) Option {
+	return wrapEncoders(func(core zapcore.Core) zapcore.Core {
+		return zapcore.WrapEncoder(core, func(enc zapcore.Encoder) zapcore.Encoder {
+			return zapcore.NewHeaderEncoder(enc, header)
+		})
+	})
+}
+
+// ColorWhen highlights interesting entries in a terminal: for each entry, the
+// supplied function decides whether to color the whole entry and returns the
+// ANSI SGR parameters to use (e.g., "31" for red or "1;33" for bold yellow).
+// It's meant for eyeballing a firehose of development logs, and sees only the
+// fields passed at the log site.
+//
+// Only Cores that write to a terminal are affected (see
+// zapcore.IsTerminal), so escape codes never reach files or log aggregators.
+// Like BulkHeader, ColorWhen works with the Cores that zapcore.WrapEncoder
+// understands; see zapcore.NewColorEncoder for other Cores.
+func ColorWhen(color func(zapcore.Entry, []zapcore.Field) (string, bool)) Option {
+	return wrapEncoders(func(core zapcore.Core) zapcore.Core {
+		return zapcore.WrapTerminalEncoder(core, func(enc zapcore.Encoder) zapcore.Encoder {
+			return zapcore.NewColorEncoder(enc, color)
+		})
+	})
+}
+
+// MessagePrefix prefixes the messages of entries at some levels with a fixed
+// string, like "⚠ " for warnings, to make severe entries stand out in a
+// stream of development logs. Levels without a prefix are left alone.
+//
+// Only the console and text encoders are affected, so the message fields of
+// machine-readable output, like JSON, stay as they were logged. To prefix
+// them too, wrap their Encoders with zapcore.NewMessagePrefixEncoder
+// explicitly. Like BulkHeader, MessagePrefix works with the Cores that
+// zapcore.WrapEncoder understands.
+func MessagePrefix(prefixes map[zapcore.Level]string) Option {
+	return wrapEncoders(func(core zapcore.Core) zapcore.Core {
+		return zapcore.WrapHumanEncoder(core, func(enc zapcore.Encoder) zapcore.Encoder {
+			return zapcore.NewMessagePrefixEncoder(enc, prefixes)
+		})
+	})
+}
+
+// wrapEncoders wraps the Logger's Core with a function that replaces its
+// Encoders, looking through the Core that WithNameLevels adds.
+func wrapEncoders(wrap func(zapcore.Core) zapcore.Core) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		if c, ok := core.(*nameLevelCore); ok {
+			return &nameLevelCore{wrap(c.Core), c.levels}
+		}
+		return wrap(core)
+	})
+}
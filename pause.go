@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "go.uber.org/atomic"
+
+// A pauseSwitch silences a logger and the loggers derived from it.
+type pauseSwitch struct {
+	paused atomic.Bool
+	parent *pauseSwitch
+}
+
+func newPauseSwitch(parent *pauseSwitch) *pauseSwitch {
+	return &pauseSwitch{parent: parent}
+}
+
+func (s *pauseSwitch) isPaused() bool {
+	for ; s != nil; s = s.parent {
+		if s.paused.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// Pause silences the logger until Resume is called. While paused, Check
+// returns nil and the logging methods are no-ops at every level, which makes
+// pausing useful for quieting a noisy subsystem (say, during a bulk import)
+// without touching the level shared with the rest of the application. Panic,
+// Fatal, and (in development) DPanic still panic or exit, but they don't log.
+//
+// The pause applies to the logger and to every logger derived from it, via
+// Named, With, WithOptions, and the like. It doesn't affect the logger it was
+// derived from, or its siblings. For example, pausing logger.Named("importer")
+// silences "importer" and "importer.db", but not the root logger, and pausing
+// logger.With(zap.String("job", "import")) leaves logger itself logging.
+//
+// Pause and Resume are safe for concurrent use, and checking whether a logger
+// is paused costs only an atomic load per logger it was derived through.
+func (log *Logger) Pause() {
+	log.pause.paused.Store(true)
+}
+
+// Resume re-enables a logger silenced by Pause. It has no effect if the
+// logger wasn't paused, or if it's silenced by a paused ancestor.
+func (log *Logger) Resume() {
+	log.pause.paused.Store(false)
+}
+
+// Paused reports whether the logger, or any logger it was derived from, is
+// paused.
+func (log *Logger) Paused() bool {
+	return log.pause.isPaused()
+}
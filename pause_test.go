@@ -0,0 +1,138 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/internal/exit"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerPause(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Pause()
+		assert.True(t, logger.Paused(), "Expected logger to be paused.")
+		for _, lvl := range []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel} {
+			assert.Nil(t, logger.Check(lvl, ""), "Expected Check to return nil at %v while paused.", lvl)
+		}
+		logger.Error("silenced")
+		logger.With(String("k", "v")).Warn("silenced")
+		logger.Sugar().Infow("silenced")
+		assert.Equal(t, 0, logs.Len(), "Expected no output while paused.")
+
+		logger.Resume()
+		assert.False(t, logger.Paused(), "Expected logger to be resumed.")
+		logger.Info("resumed")
+		assert.Equal(t, 1, logs.Len(), "Expected output after resuming.")
+	})
+}
+
+func TestLoggerPauseNamed(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		importer := logger.Named("importer")
+		db := importer.Named("db")
+
+		importer.Pause()
+		logger.Info("root")
+		importer.Info("importer")
+		db.Info("db")
+		assert.Equal(t, []string{"root"}, messages(logs.TakeAll()), "Expected pausing a child to silence only its subtree.")
+
+		// Resuming a descendant doesn't override a paused ancestor.
+		db.Resume()
+		assert.True(t, db.Paused(), "Expected descendant of a paused logger to be paused.")
+
+		importer.Resume()
+		logger.Pause()
+		db.Info("db")
+		importer.Info("importer")
+		assert.Equal(t, 0, logs.Len(), "Expected pausing the root to silence all descendants.")
+	})
+}
+
+func TestLoggerPauseWith(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		child := logger.With(String("job", "import"))
+		sibling := logger.WithOptions(Fields(String("job", "export")))
+		grandchild := child.With(String("step", "load"))
+
+		child.Pause()
+		logger.Info("root")
+		sibling.Info("sibling")
+		child.Info("child")
+		grandchild.Info("grandchild")
+		assert.Equal(t, []string{"root", "sibling"}, messages(logs.TakeAll()), "Expected pausing a With child to silence only it and its descendants.")
+		assert.False(t, logger.Paused(), "Expected the parent not to be paused.")
+		assert.True(t, grandchild.Paused(), "Expected the child's descendants to be paused.")
+
+		child.Resume()
+		logger.Pause()
+		child.Info("child")
+		sibling.Info("sibling")
+		assert.Equal(t, 0, logs.Len(), "Expected pausing the parent to silence its With children.")
+	})
+}
+
+func TestLoggerPauseTerminalBehavior(t *testing.T) {
+	withLogger(t, DebugLevel, opts(Development()), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Pause()
+		assert.Panics(t, func() { logger.Panic("") }, "Expected paused logger to panic.")
+		assert.Panics(t, func() { logger.DPanic("") }, "Expected paused development logger to panic.")
+		stub := exit.WithStub(func() { logger.Fatal("") })
+		assert.True(t, stub.Exited, "Expected paused logger to exit.")
+		assert.Equal(t, 0, logs.Len(), "Expected no output while paused.")
+	})
+}
+
+func TestLoggerPauseConcurrent(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					logger.Info("")
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				logger.Pause()
+				logger.Resume()
+			}()
+		}
+		wg.Wait()
+		assert.False(t, logger.Paused(), "Expected logger to be resumed.")
+	})
+}
+
+func messages(entries []observer.LoggedEntry) []string {
+	msgs := make([]string, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.Message
+	}
+	return msgs
+}
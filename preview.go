@@ -0,0 +1,70 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"bytes"
+	"io"
+)
+
+// PreviewReader reads up to n bytes from r and returns a field that carries
+// them as UTF-8 encoded text, along with a reader that replays those bytes
+// before the remainder of r. The classic use is logging the start of an HTTP
+// request body without consuming it:
+//
+//   body, preview := zap.PreviewReader("body", req.Body, 256)
+//   req.Body = ioutil.NopCloser(body)
+//   logger.Info("Received request.", preview)
+//
+// The preview is buffered in memory, so PreviewReader allocates n bytes up
+// front, even if r turns out to be shorter, and they can't be reclaimed until
+// both the field and the returned reader are no longer referenced. Keep n
+// small.
+//
+// If reading from r fails with an error other than io.EOF, the preview
+// contains whatever was read before the failure, and the returned reader
+// reports the error once the preview is exhausted.
+func PreviewReader(key string, r io.Reader, n int) (io.Reader, Field) {
+	preview, rest := readPreview(r, n)
+	return rest, ByteString(key, preview)
+}
+
+func readPreview(r io.Reader, n int) ([]byte, io.Reader) {
+	if n <= 0 {
+		return []byte{}, r
+	}
+	buf := make([]byte, n)
+	read, err := io.ReadFull(r, buf)
+	buf = buf[:read]
+	switch err {
+	case nil:
+		return buf, io.MultiReader(bytes.NewReader(buf), r)
+	case io.EOF, io.ErrUnexpectedEOF:
+		// We've already drained r.
+		return buf, bytes.NewReader(buf)
+	default:
+		return buf, io.MultiReader(bytes.NewReader(buf), errReader{err})
+	}
+}
+
+type errReader struct{ err error }
+
+func (r errReader) Read([]byte) (int, error) { return 0, r.err }
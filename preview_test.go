@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewReader(t *testing.T) {
+	tests := []struct {
+		desc    string
+		input   string
+		n       int
+		preview string
+	}{
+		{"empty input", "", 4, ""},
+		{"short input", "foo", 4, "foo"},
+		{"exact length", "barz", 4, "barz"},
+		{"long input", "foo bar baz", 4, "foo "},
+		{"zero limit", "foo", 0, ""},
+		{"negative limit", "foo", -1, ""},
+	}
+
+	for _, tt := range tests {
+		r, f := PreviewReader("body", strings.NewReader(tt.input), tt.n)
+		assert.Equal(t, ByteString("body", []byte(tt.preview)), f, "%s: unexpected field.", tt.desc)
+
+		rest, err := ioutil.ReadAll(r)
+		require.NoError(t, err, "%s: unexpected error reading replayed input.", tt.desc)
+		assert.Equal(t, tt.input, string(rest), "%s: expected to replay the full input.", tt.desc)
+		assertCanBeReused(t, f)
+	}
+}
+
+func TestPreviewReaderError(t *testing.T) {
+	fail := errors.New("fail")
+	input := io.MultiReader(strings.NewReader("foo"), errReader{fail})
+	r, f := PreviewReader("body", input, 10)
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	assert.Equal(t, "foo", enc.Fields["body"], "Expected preview of bytes read before the failure.")
+
+	rest, err := ioutil.ReadAll(r)
+	assert.Equal(t, fail, err, "Expected the read error to be replayed.")
+	assert.Equal(t, "foo", string(rest), "Expected bytes read before the failure to be replayed.")
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const _defaultRateLimitMaxKeys = 1000
+
+// A RateLimitOption configures the RateLimit option.
+type RateLimitOption interface {
+	applyRateLimit(*rateLimitOptions)
+}
+
+type rateLimitOptions struct {
+	field   string
+	maxKeys int
+}
+
+type rateLimitOptionFunc func(*rateLimitOptions)
+
+func (f rateLimitOptionFunc) applyRateLimit(opts *rateLimitOptions) {
+	f(opts)
+}
+
+// RateLimitByField keys the rate limit by the value of the named field passed
+// at the log site, rather than by the entry's message. Entries without the
+// field are keyed by their message.
+func RateLimitByField(key string) RateLimitOption {
+	return rateLimitOptionFunc(func(opts *rateLimitOptions) {
+		opts.field = key
+	})
+}
+
+// RateLimitMaxKeys sets the number of distinct keys the rate limit tracks at
+// once. It defaults to 1000; values below 1 are treated as 1.
+func RateLimitMaxKeys(n int) RateLimitOption {
+	return rateLimitOptionFunc(func(opts *rateLimitOptions) {
+		opts.maxKeys = n
+	})
+}
+
+// RateLimit configures the Logger to write at most perSecond entries with the
+// same message per second, allowing bursts of up to burst entries. Further
+// entries are dropped, and the next one written reports how many were under
+// the "suppressed" key. For example, to write a failing dependency's errors
+// at most ten times a second:
+//
+//   zap.RateLimit(10, 10)
+//
+// Each use of the option creates new limits, shared by the Logger and its
+// children. Tokens are refilled according to the Logger's clock, so pass
+// WithClock first to change it. See zapcore.NewRateLimitCore for details.
+func RateLimit(perSecond float64, burst int, opts ...RateLimitOption) Option {
+	ro := rateLimitOptions{maxKeys: _defaultRateLimitMaxKeys}
+	for _, opt := range opts {
+		opt.applyRateLimit(&ro)
+	}
+	key := messageKey
+	if ro.field != "" {
+		key = fieldKey(ro.field)
+	}
+	return optionFunc(func(log *Logger) {
+		log.core = zapcore.NewRateLimitCore(log.core, key, perSecond, burst, ro.maxKeys, log.clock)
+	})
+}
+
+func messageKey(ent zapcore.Entry, _ []zapcore.Field) string {
+	return ent.Message
+}
+
+func fieldKey(name string) func(zapcore.Entry, []zapcore.Field) string {
+	return func(ent zapcore.Entry, fields []zapcore.Field) string {
+		for _, f := range fields {
+			if f.Key != name {
+				continue
+			}
+			switch f.Type {
+			case zapcore.StringType, zapcore.TagType:
+				// Prefix keys so that field values never collide with
+				// messages.
+				return "field:" + f.String
+			default:
+				enc := zapcore.NewMapObjectEncoder()
+				f.AddTo(enc)
+				return "field:" + fmt.Sprint(enc.Fields[name])
+			}
+		}
+		return "msg:" + ent.Message
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import "runtime"
+
+// A RecoverOption configures Logger.Recover.
+type RecoverOption interface {
+	applyRecover(*recoverOptions)
+}
+
+type recoverOptions struct {
+	allGoroutines bool
+}
+
+type recoverOptionFunc func(*recoverOptions)
+
+func (f recoverOptionFunc) applyRecover(opts *recoverOptions) {
+	f(opts)
+}
+
+// DumpGoroutines configures Logger.Recover to also log the stacks of all
+// goroutines, not just the panicking one, under the "goroutines" key. That's
+// invaluable for diagnosing the deadlocks and leaks that often accompany a
+// panic, but stopping the world to take the dump is expensive, and the dump
+// can be very large.
+func DumpGoroutines() RecoverOption {
+	return recoverOptionFunc(func(opts *recoverOptions) {
+		opts.allGoroutines = true
+	})
+}
+
+// Recover recovers from a panic, if any, and logs it at ErrorLevel along with
+// the panicking goroutine's stack trace. It must be deferred directly, since
+// Go only lets deferred functions recover:
+//   defer logger.Recover()
+// The panic's value is logged under "panic" and the stack trace under
+// "stacktrace".
+func (log *Logger) Recover(opts ...RecoverOption) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	var ro recoverOptions
+	for _, opt := range opts {
+		opt.applyRecover(&ro)
+	}
+
+	fields := []Field{Any("panic", r), Stack("stacktrace")}
+	if ro.allGoroutines {
+		fields = append(fields, String("goroutines", allGoroutineStacks()))
+	}
+	log.Error("Recovered from panic.", fields...)
+}
+
+// allGoroutineStacks returns the stacks of all goroutines, in the same format
+// as an unrecovered panic.
+func allGoroutineStacks() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
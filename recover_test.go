@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggerRecover(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		assert.NotPanics(t, func() {
+			defer logger.Recover()
+			panic("oh no")
+		}, "Expected Recover to recover from the panic.")
+
+		entries := logs.AllUntimed()
+		require.Equal(t, 1, len(entries), "Unexpected number of entries.")
+		ent := entries[0]
+		assert.Equal(t, zapcore.ErrorLevel, ent.Level, "Unexpected level.")
+		assert.Equal(t, "Recovered from panic.", ent.Message, "Unexpected message.")
+		ctx := ent.ContextMap()
+		assert.Equal(t, "oh no", ctx["panic"], "Unexpected panic value.")
+		assert.Contains(t, ctx["stacktrace"], "TestLoggerRecover", "Expected the panicking goroutine's stack.")
+		assert.NotContains(t, ctx, "goroutines", "Expected no goroutine dump without the option.")
+	})
+}
+
+func TestLoggerRecoverNoPanic(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		func() {
+			defer logger.Recover(DumpGoroutines())
+		}()
+		assert.Equal(t, 0, logs.Len(), "Expected nothing to be logged without a panic.")
+	})
+}
+
+func TestLoggerRecoverDumpGoroutines(t *testing.T) {
+	release := make(chan struct{})
+	var started, stopped sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		started.Add(1)
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			started.Done()
+			<-release
+		}()
+	}
+	started.Wait()
+	defer stopped.Wait()
+	defer close(release)
+
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		func() {
+			defer logger.Recover(DumpGoroutines())
+			panic("oh no")
+		}()
+
+		require.Equal(t, 1, logs.Len(), "Unexpected number of entries.")
+		dump, ok := logs.AllUntimed()[0].ContextMap()["goroutines"].(string)
+		require.True(t, ok, "Expected a goroutine dump.")
+		assert.True(t, strings.Count(dump, "goroutine ") >= 3, "Expected the dump to include every goroutine, got:\n%s", dump)
+		assert.Contains(t, dump, "TestLoggerRecoverDumpGoroutines.func", "Expected the dump to include the background goroutines.")
+	})
+}
+
+func TestAllGoroutineStacksGrowsBuffer(t *testing.T) {
+	// Make sure the dump is larger than the initial buffer.
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 500; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-release
+		}()
+	}
+	dump := allGoroutineStacks()
+	close(release)
+	wg.Wait()
+	assert.True(t, len(dump) > 64*1024, "Expected a dump larger than the initial buffer.")
+	assert.True(t, strings.HasPrefix(dump, "goroutine "), "Unexpected dump format.")
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// A RedactOption configures RedactPattern.
+type RedactOption interface {
+	applyRedact(*redactOptions)
+}
+
+type redactOptions struct {
+	messages bool
+}
+
+type redactOptionFunc func(*redactOptions)
+
+func (f redactOptionFunc) applyRedact(opts *redactOptions) {
+	f(opts)
+}
+
+// RedactMessages configures RedactPattern to redact log messages as well as
+// fields.
+func RedactMessages() RedactOption {
+	return redactOptionFunc(func(opts *redactOptions) {
+		opts.messages = true
+	})
+}
+
+// RedactPattern configures the Logger to replace matches of re in the string
+// values of its fields with replacement, as with re.ReplaceAllString. For
+// example,
+//   zap.RedactPattern(regexp.MustCompile(`\b\d{13,16}\b`), "[CARD]")
+// masks anything that looks like a credit card number, whatever the key of
+// the field it's logged under.
+//
+// Every logged string is scanned, which costs time in proportion to its
+// length; see zapcore.NewRedactingCore for details.
+func RedactPattern(re *regexp.Regexp, replacement string, opts ...RedactOption) Option {
+	var o redactOptions
+	for _, opt := range opts {
+		opt.applyRedact(&o)
+	}
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewRedactingCore(core, re, replacement, o.messages)
+	})
+}
+
+// RedactKeys configures the Logger to mask, hash, or drop the values of
+// fields logged under any of the given keys, ignoring case, wherever they're
+// nested. For example,
+//   zap.RedactKeys(zapcore.RedactMask, "password", "token", "ssn")
+// replaces the values of those fields with "[REDACTED]", so that call sites
+// can't leak them by accident. See zapcore.NewKeyRedactingCore for details.
+func RedactKeys(action zapcore.RedactAction, keys ...string) Option {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[strings.ToLower(k)] = struct{}{}
+	}
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewKeyRedactingCore(core, func(key string) bool {
+			_, ok := set[strings.ToLower(key)]
+			return ok
+		}, action)
+	})
+}
+
+// RedactKeyPattern is like RedactKeys, but it redacts the fields whose keys
+// match re, like `(?i)secret|token$`.
+func RedactKeyPattern(action zapcore.RedactAction, re *regexp.Regexp) Option {
+	return WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewKeyRedactingCore(core, re.MatchString, action)
+	})
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactPattern(t *testing.T) {
+	re := regexp.MustCompile(`\b\d{4}-\d{4}\b`)
+	withLogger(t, DebugLevel, opts(RedactPattern(re, "[CARD]")), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("paid with 1234-5678", String("note", "card 1234-5678"), Error(errors.New("declined 1234-5678")))
+		entry := logs.AllUntimed()[0]
+		assert.Equal(t, "paid with 1234-5678", entry.Message, "Expected messages to be left alone by default.")
+		assert.Equal(
+			t,
+			map[string]interface{}{"note": "card [CARD]", "error": "declined [CARD]"},
+			entry.ContextMap(),
+			"Expected field values to be redacted.",
+		)
+	})
+
+	withLogger(t, DebugLevel, opts(RedactPattern(re, "[CARD]", RedactMessages())), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("paid with 1234-5678")
+		assert.Equal(t, "paid with [CARD]", logs.AllUntimed()[0].Message, "Expected the message to be redacted.")
+	})
+}
+
+func TestRedactKeys(t *testing.T) {
+	withLogger(t, DebugLevel, opts(RedactKeys(zapcore.RedactMask, "password", "SSN")), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.With(String("Password", "hunter2")).Info(
+			"login",
+			Int("ssn", 123456789),
+			String("user", "jane"),
+			Dict("nested", String("password", "hunter2"), Int("attempt", 3)),
+		)
+		assert.Equal(t, map[string]interface{}{
+			"Password": "[REDACTED]",
+			"ssn":      "[REDACTED]",
+			"user":     "jane",
+			"nested":   map[string]interface{}{"password": "[REDACTED]", "attempt": int64(3)},
+		}, logs.AllUntimed()[0].ContextMap(), "Expected sensitive keys to be masked.")
+	})
+
+	re := regexp.MustCompile(`(?i)token$`)
+	withLogger(t, DebugLevel, opts(RedactKeyPattern(zapcore.RedactDrop, re)), func(logger *Logger, logs *observer.ObservedLogs) {
+		logger.Info("call", String("authToken", "abc"), String("method", "GET"))
+		assert.Equal(t, map[string]interface{}{"method": "GET"}, logs.AllUntimed()[0].ContextMap(), "Expected matching keys to be dropped.")
+	})
+}
@@ -0,0 +1,350 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/zapcore"
+)
+
+// A RotateOption configures a Sink returned by RotatingFile.
+type RotateOption interface {
+	apply(*rotatingFile)
+}
+
+type rotateOptionFunc func(*rotatingFile)
+
+func (f rotateOptionFunc) apply(r *rotatingFile) {
+	f(r)
+}
+
+// RotateMaxAge deletes backups whose last write is more than maxAge ago
+// whenever the file is rolled over, even if there are fewer than maxBackups
+// of them. A maxAge of zero or less keeps backups regardless of age.
+func RotateMaxAge(maxAge time.Duration) RotateOption {
+	return rotateOptionFunc(func(r *rotatingFile) {
+		r.maxAge = maxAge
+	})
+}
+
+// RotateClock sets the clock RotateMaxAge uses to decide which backups have
+// expired. By default, it uses zapcore.DefaultClock.
+func RotateClock(clock zapcore.Clock) RotateOption {
+	return rotateOptionFunc(func(r *rotatingFile) {
+		r.clock = clock
+	})
+}
+
+// RotateCompress gzips backups, naming them path.1.gz, path.2.gz, and so on.
+// Files are compressed as they're rolled over, so writes wait for the
+// compression to finish. If compressing a file fails, it's left uncompressed
+// at path.1 until the next roll replaces it.
+func RotateCompress() RotateOption {
+	return rotateOptionFunc(func(r *rotatingFile) {
+		r.compress = true
+	})
+}
+
+// RotateReopenOn reopens the file at path whenever the process receives one
+// of the given signals, typically syscall.SIGHUP. This lets external tools
+// like logrotate move the file aside without copying and truncating it. The
+// Sink stops listening for the signals when it's closed.
+func RotateReopenOn(sigs ...os.Signal) RotateOption {
+	return rotateOptionFunc(func(r *rotatingFile) {
+		r.reopenOn = append(r.reopenOn, sigs...)
+	})
+}
+
+// RotatingFile opens the file at path for appending, creating it if
+// necessary, and returns a Sink that rolls the file over once it's full.
+// Before a write that would take the file past maxBytes, the file is renamed
+// to path.1, existing backups are shifted up (path.1 to path.2, and so on),
+// backups beyond maxBackups are deleted, and a fresh file is created at path.
+// Writes are never split across files, so a single write larger than
+// maxBytes gets a file to itself. With a maxBytes of zero or less, the file
+// is never rolled; with a maxBackups of zero or less, full files are simply
+// replaced. RotateOptions can also limit the age of backups, compress them,
+// and reopen the file on a signal.
+//
+// Writing, rolling, syncing, and closing are protected by a mutex, so the
+// Sink is safe for concurrent use and entries never straddle a roll. Sync
+// flushes the current file. After Close, writes fail with os.ErrClosed.
+func RotatingFile(path string, maxBytes int64, maxBackups int, opts ...RotateOption) (Sink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if maxBackups < 0 {
+		maxBackups = 0
+	}
+	r := &rotatingFile{
+		path:       path,
+		maxBytes:   maxBytes,
+		maxBackups: maxBackups,
+		file:       f,
+		size:       fi.Size(),
+		clock:      zapcore.DefaultClock,
+	}
+	for _, opt := range opts {
+		opt.apply(r)
+	}
+	if len(r.reopenOn) > 0 {
+		r.signals = make(chan os.Signal, 1)
+		r.stop = make(chan struct{})
+		signal.Notify(r.signals, r.reopenOn...)
+		go r.reopenOnSignals()
+	}
+	return r, nil
+}
+
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	maxAge     time.Duration
+	clock      zapcore.Clock
+	compress   bool
+	reopenOn   []os.Signal
+	signals    chan os.Signal
+	stop       chan struct{}
+	file       *os.File // nil if a roll failed to create a new file
+	size       int64
+	closed     bool
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	var err error
+	if r.file == nil || (r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes) {
+		err = r.roll()
+		if r.file == nil {
+			return 0, err
+		}
+	}
+	n, werr := r.file.Write(p)
+	r.size += int64(n)
+	return n, multierr.Append(err, werr)
+}
+
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed && r.signals != nil {
+		signal.Stop(r.signals)
+		close(r.stop)
+	}
+	r.closed = true
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *rotatingFile) reopenOnSignals() {
+	for {
+		select {
+		case <-r.signals:
+			r.reopen()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// reopen closes the current file and opens the file at path, which may have
+// been moved or removed since it was opened.
+func (r *rotatingFile) reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return os.ErrClosed
+	}
+
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	f, oerr := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if oerr != nil {
+		return multierr.Append(err, oerr)
+	}
+	r.file = f
+	r.size = 0
+	if fi, serr := f.Stat(); serr == nil {
+		r.size = fi.Size()
+	}
+	return err
+}
+
+// roll moves the current file aside and opens a new one. If moving the file
+// fails, it keeps appending to the current file rather than losing logs.
+func (r *rotatingFile) roll() error {
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+
+	if rerr := r.shiftBackups(); rerr != nil {
+		err = multierr.Append(err, rerr)
+		f, oerr := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if oerr != nil {
+			return multierr.Append(err, oerr)
+		}
+		r.file = f
+		if fi, serr := f.Stat(); serr == nil {
+			r.size = fi.Size()
+		}
+		return err
+	}
+
+	f, oerr := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_TRUNC, 0644)
+	if oerr != nil {
+		return multierr.Append(err, oerr)
+	}
+	r.file = f
+	r.size = 0
+
+	// The new file is in place, so failing to tidy up the backups doesn't
+	// lose any logs.
+	if r.compress && r.maxBackups > 0 {
+		err = multierr.Append(err, compressFile(r.path+".1", r.backup(1)))
+	}
+	return multierr.Append(err, r.removeExpired())
+}
+
+func (r *rotatingFile) shiftBackups() error {
+	if r.maxBackups == 0 {
+		// The new file truncates the old one.
+		return nil
+	}
+	if err := os.Remove(r.backup(r.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := r.maxBackups - 1; i > 0; i-- {
+		if err := os.Rename(r.backup(i), r.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	// When compressing, the file is compressed to backup(1) once the new
+	// file is open.
+	return os.Rename(r.path, r.path+".1")
+}
+
+// removeExpired deletes the backups older than maxAge. Since backups are
+// ordered by age, it stops at the first one that's too new.
+func (r *rotatingFile) removeExpired() error {
+	if r.maxAge <= 0 {
+		return nil
+	}
+	cutoff := r.clock.Now().Add(-r.maxAge)
+	var err error
+	for i := r.maxBackups; i > 0; i-- {
+		fi, serr := os.Stat(r.backup(i))
+		if os.IsNotExist(serr) {
+			continue
+		}
+		if serr != nil {
+			return multierr.Append(err, serr)
+		}
+		if !fi.ModTime().Before(cutoff) {
+			break
+		}
+		err = multierr.Append(err, os.Remove(r.backup(i)))
+	}
+	return err
+}
+
+func (r *rotatingFile) backup(n int) string {
+	if r.compress {
+		return fmt.Sprintf("%s.%d.gz", r.path, n)
+	}
+	return fmt.Sprintf("%s.%d", r.path, n)
+}
+
+// compressFile gzips src to dst, then removes src. If anything fails, src
+// is left in place and dst is removed.
+func compressFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	gz := gzip.NewWriter(out)
+	_, err = io.Copy(gz, in)
+	err = multierr.Combine(err, gz.Close(), out.Close())
+	if err != nil {
+		return err
+	}
+	// Keep the backup's age, so that RotateMaxAge treats it the same way
+	// whether or not it's compressed.
+	if err = os.Chtimes(dst, fi.ModTime(), fi.ModTime()); err != nil {
+		return err
+	}
+	in.Close()
+	return os.Remove(src)
+}
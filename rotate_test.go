@@ -0,0 +1,238 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withTempDir(t testing.TB, f func(dir string)) {
+	dir, err := ioutil.TempDir("", "zap-rotate-test")
+	require.NoError(t, err, "Failed to create temp dir.")
+	defer os.RemoveAll(dir)
+	f(dir)
+}
+
+func readFile(t testing.TB, path string) string {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	require.NoError(t, err, "Failed to read %v.", path)
+	return string(contents)
+}
+
+func TestRotatingFile(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		sink, err := RotatingFile(path, 8, 2)
+		require.NoError(t, err, "Unexpected error opening rotating file.")
+		defer sink.Close()
+
+		for _, s := range []string{"aaaa\n", "bb\n", "cccc\n", "dddd\n", "eeeeeeeeee\n", "ff\n"} {
+			n, err := sink.Write([]byte(s))
+			require.NoError(t, err, "Unexpected error writing %q.", s)
+			assert.Equal(t, len(s), n, "Unexpected number of bytes written.")
+		}
+		require.NoError(t, sink.Sync(), "Unexpected error syncing.")
+
+		assert.Equal(t, "ff\n", readFile(t, path), "Unexpected contents in current file.")
+		assert.Equal(t, "eeeeeeeeee\n", readFile(t, path+".1"), "Expected an oversized write to get a file to itself.")
+		assert.Equal(t, "dddd\n", readFile(t, path+".2"), "Unexpected contents in oldest backup.")
+		assert.Equal(t, "<missing>", readFile(t, path+".3"), "Expected backups beyond the limit to be deleted.")
+	})
+}
+
+func TestRotatingFileAppendsToExisting(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		require.NoError(t, ioutil.WriteFile(path, []byte("old\n"), 0644), "Failed to write existing file.")
+
+		sink, err := RotatingFile(path, 6, 1)
+		require.NoError(t, err, "Unexpected error opening rotating file.")
+		_, err = sink.Write([]byte("new\n"))
+		require.NoError(t, err, "Unexpected error writing.")
+		require.NoError(t, sink.Close(), "Unexpected error closing.")
+
+		assert.Equal(t, "new\n", readFile(t, path), "Unexpected contents in current file.")
+		assert.Equal(t, "old\n", readFile(t, path+".1"), "Expected the existing file's size to count toward the limit.")
+
+		_, err = sink.Write([]byte("closed\n"))
+		assert.Equal(t, os.ErrClosed, err, "Expected writes after Close to fail.")
+	})
+}
+
+func TestRotatingFileNoBackups(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		sink, err := RotatingFile(path, 4, 0)
+		require.NoError(t, err, "Unexpected error opening rotating file.")
+		defer sink.Close()
+
+		sink.Write([]byte("abc\n"))
+		sink.Write([]byte("def\n"))
+		assert.Equal(t, "def\n", readFile(t, path), "Expected full files to be replaced.")
+		assert.Equal(t, "<missing>", readFile(t, path+".1"), "Expected no backups.")
+	})
+}
+
+func TestRotatingFileConcurrent(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		sink, err := RotatingFile(path, 100, 100)
+		require.NoError(t, err, "Unexpected error opening rotating file.")
+
+		const goroutines, writes = 10, 20
+		line := strings.Repeat("x", 9) + "\n"
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < writes; j++ {
+					sink.Write([]byte(line))
+				}
+			}()
+		}
+		wg.Wait()
+		require.NoError(t, sink.Close(), "Unexpected error closing.")
+
+		files, err := filepath.Glob(path + "*")
+		require.NoError(t, err, "Unexpected error listing files.")
+		var total int
+		for _, f := range files {
+			contents := readFile(t, f)
+			assert.True(t, len(contents) <= 100, "Expected %v to respect the size limit.", f)
+			assert.Equal(t, strings.Repeat(line, len(contents)/len(line)), contents, "Expected only whole lines in %v.", f)
+			total += len(contents)
+		}
+		assert.Equal(t, goroutines*writes*len(line), total, "Expected no lost writes.")
+	})
+}
+
+func TestRotatingFileOpenError(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		_, err := RotatingFile(filepath.Join(dir, "missing", "app.log"), 10, 1)
+		assert.Error(t, err, "Expected an error opening a file in a missing directory.")
+	})
+}
+
+func readGzipFile(t testing.TB, path string) string {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return "<missing>"
+	}
+	require.NoError(t, err, "Failed to open %v.", path)
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err, "Failed to read gzip header from %v.", path)
+	contents, err := ioutil.ReadAll(gz)
+	require.NoError(t, err, "Failed to decompress %v.", path)
+	return string(contents)
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		sink, err := RotatingFile(path, 4, 2, RotateCompress())
+		require.NoError(t, err, "Unexpected error opening rotating file.")
+		defer sink.Close()
+
+		for _, s := range []string{"aaa\n", "bbb\n", "ccc\n", "ddd\n"} {
+			_, err := sink.Write([]byte(s))
+			require.NoError(t, err, "Unexpected error writing %q.", s)
+		}
+
+		assert.Equal(t, "ddd\n", readFile(t, path), "Unexpected contents in current file.")
+		assert.Equal(t, "ccc\n", readGzipFile(t, path+".1.gz"), "Unexpected contents in newest backup.")
+		assert.Equal(t, "bbb\n", readGzipFile(t, path+".2.gz"), "Unexpected contents in oldest backup.")
+		assert.Equal(t, "<missing>", readGzipFile(t, path+".3.gz"), "Expected backups beyond the limit to be deleted.")
+		assert.Equal(t, "<missing>", readFile(t, path+".1"), "Expected no uncompressed backups.")
+	})
+}
+
+func TestRotatingFileMaxAge(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		clock := &stubClock{now: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)}
+		sink, err := RotatingFile(path, 4, 3, RotateMaxAge(time.Hour), RotateClock(clock))
+		require.NoError(t, err, "Unexpected error opening rotating file.")
+		defer sink.Close()
+
+		sink.Write([]byte("aaa\n"))
+		sink.Write([]byte("bbb\n"))
+		sink.Write([]byte("ccc\n"))
+		old, recent := clock.now.Add(-2*time.Hour), clock.now.Add(-30*time.Minute)
+		require.NoError(t, os.Chtimes(path+".2", old, old), "Failed to age backup.")
+		require.NoError(t, os.Chtimes(path+".1", recent, recent), "Failed to age backup.")
+
+		sink.Write([]byte("ddd\n"))
+		assert.Equal(t, "ccc\n", readFile(t, path+".1"), "Expected recent backups to be kept.")
+		assert.Equal(t, "bbb\n", readFile(t, path+".2"), "Expected recent backups to be kept.")
+		assert.Equal(t, "<missing>", readFile(t, path+".3"), "Expected backups older than the max age to be deleted.")
+	})
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	withTempDir(t, func(dir string) {
+		path := filepath.Join(dir, "app.log")
+		sink, err := RotatingFile(path, 0, 0, RotateReopenOn(os.Interrupt))
+		require.NoError(t, err, "Unexpected error opening rotating file.")
+		r := sink.(*rotatingFile)
+
+		sink.Write([]byte("before\n"))
+		require.NoError(t, os.Rename(path, path+".moved"), "Failed to move file aside.")
+		sink.Write([]byte("moved\n"))
+		require.NoError(t, r.reopen(), "Unexpected error reopening file.")
+		sink.Write([]byte("after\n"))
+
+		assert.Equal(t, "before\nmoved\n", readFile(t, path+".moved"), "Expected writes before reopening to go to the moved file.")
+		assert.Equal(t, "after\n", readFile(t, path), "Expected writes after reopening to go to a new file.")
+
+		// Simulate a signal, since sending real ones isn't portable.
+		require.NoError(t, os.Remove(path), "Failed to remove file.")
+		r.signals <- os.Interrupt
+		assert.True(t, waitForFile(path), "Expected a signal to reopen the file.")
+
+		require.NoError(t, sink.Close(), "Unexpected error closing.")
+		assert.Equal(t, os.ErrClosed, r.reopen(), "Expected reopening a closed file to fail.")
+	})
+}
+
+func waitForFile(path string) bool {
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRusage(t *testing.T) {
+	f := Rusage("rusage")
+	if f.Type == zapcore.SkipType {
+		t.Skip("getrusage isn't supported on this platform")
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	f.AddTo(enc)
+	usage, ok := enc.Fields["rusage"].(map[string]interface{})
+	require.True(t, ok, "Expected resource usage to be logged as a nested object.")
+	assert.NotContains(t, enc.Fields, "rusageError", "Unexpected error reading resource usage.")
+	for _, k := range []string{"user", "system", "max_rss_bytes", "minor_faults", "major_faults"} {
+		assert.Contains(t, usage, k, "Expected resource usage to include %q.", k)
+	}
+	assert.True(t, usage["max_rss_bytes"].(int64) > 0, "Expected a positive maximum RSS.")
+	assertCanBeReused(t, f)
+}
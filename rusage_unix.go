@@ -0,0 +1,69 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package zap
+
+import (
+	"runtime"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Rusage constructs a field that logs the resource usage of the current
+// process, as reported by getrusage(2), as a nested object: the user and
+// system CPU time consumed so far, the maximum resident set size in bytes,
+// and the number of minor and major page faults. For example,
+//   {"user":0.012,"system":0.004,"max_rss_bytes":7340032,"minor_faults":1124,"major_faults":0}
+//
+// The system call is made when the field is serialized, so it costs nothing
+// if the entry is disabled. On platforms without getrusage, Rusage returns a
+// no-op field.
+func Rusage(key string) Field {
+	return Object(key, rusage{})
+}
+
+type rusage struct{}
+
+func (rusage) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return err
+	}
+	enc.AddDuration("user", time.Duration(ru.Utime.Nano()))
+	enc.AddDuration("system", time.Duration(ru.Stime.Nano()))
+	enc.AddInt64("max_rss_bytes", int64(ru.Maxrss)*maxRSSUnit())
+	enc.AddInt64("minor_faults", int64(ru.Minflt))
+	enc.AddInt64("major_faults", int64(ru.Majflt))
+	return nil
+}
+
+// maxRSSUnit returns the size, in bytes, of the units of Rusage.Maxrss, which
+// macOS reports in bytes and other systems in kilobytes.
+func maxRSSUnit() int64 {
+	if runtime.GOOS == "darwin" {
+		return 1
+	}
+	return 1024
+}
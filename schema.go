@@ -0,0 +1,209 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ECSVersion is the version of the Elastic Common Schema that ECSSchema
+// follows. It's recorded on every entry under "ecs.version".
+const ECSVersion = "1.6.0"
+
+var (
+	errNoSchemaNameSpecified = errors.New("no schema name specified")
+
+	_schemaNameToConstructor = map[string]func() Schema{
+		"ecs":          ECSSchema,
+		"cloudLogging": CloudLoggingSchema,
+	}
+	_schemaMutex sync.RWMutex
+)
+
+// A Schema adapts a Logger's output to the conventions of a log pipeline: the
+// keys and formats of each entry's metadata, plus any static fields the
+// pipeline expects, like the version of the schema itself. Config.Schema
+// applies one in a single setting.
+type Schema struct {
+	// EncoderConfig names and formats the level, time, message, caller, and
+	// stacktrace of each entry.
+	EncoderConfig zapcore.EncoderConfig
+	// Fields are added to every entry.
+	Fields map[string]interface{}
+	// CallerFields, if set, records the caller of each entry that has one as
+	// the returned fields, for schemas that split the caller into several
+	// keys or nest it in an object. EncoderConfig.CallerKey should then be
+	// empty.
+	CallerFields func(zapcore.EntryCaller) []Field
+}
+
+// ECSSchema returns a Schema for the Elastic Common Schema (see
+// https://www.elastic.co/guide/en/ecs/current/index.html). Times are written
+// under "@timestamp" in ISO8601 format, levels under "log.level" in lowercase,
+// messages under "message", logger names under "log.logger", and
+// stacktraces under "error.stack_trace". Callers are split into the file's
+// name under "log.origin.file.name", the line number under
+// "log.origin.file.line", and the function under "log.origin.function".
+// Durations are written as integer nanoseconds, as ECS's event.duration
+// expects, and every entry records ECSVersion under "ecs.version".
+func ECSSchema() Schema {
+	return Schema{
+		EncoderConfig: zapcore.EncoderConfig{
+			TimeKey:        "@timestamp",
+			LevelKey:       "log.level",
+			NameKey:        "log.logger",
+			MessageKey:     "message",
+			StacktraceKey:  "error.stack_trace",
+			LineEnding:     zapcore.DefaultLineEnding,
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeTime:     zapcore.ISO8601TimeEncoder,
+			EncodeDuration: zapcore.NanosDurationEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
+		},
+		Fields:       map[string]interface{}{"ecs.version": ECSVersion},
+		CallerFields: ecsCallerFields,
+	}
+}
+
+func ecsCallerFields(caller zapcore.EntryCaller) []Field {
+	fields := []Field{
+		String("log.origin.file.name", filepath.Base(caller.File)),
+		Int("log.origin.file.line", caller.Line),
+	}
+	if fn := callerFunction(caller); fn != "" {
+		fields = append(fields, String("log.origin.function", fn))
+	}
+	return fields
+}
+
+// CloudLoggingSchema returns a Schema for the structured logs that Google
+// Cloud Logging (formerly Stackdriver) ingests from standard output and
+// standard error. Times are written under "timestamp" in RFC3339 format with
+// nanoseconds, levels under "severity" using CloudLoggingLevelEncoder's names,
+// messages under "message", and callers as a LogEntrySourceLocation object
+// under "logging.googleapis.com/sourceLocation", which are the keys Cloud
+// Logging lifts into each log entry's metadata. Stacktraces are written under
+// "stack_trace", where Error Reporting looks for them.
+func CloudLoggingSchema() Schema {
+	return Schema{
+		EncoderConfig: zapcore.EncoderConfig{
+			TimeKey:        "timestamp",
+			LevelKey:       "severity",
+			NameKey:        "logger",
+			MessageKey:     "message",
+			StacktraceKey:  "stack_trace",
+			LineEnding:     zapcore.DefaultLineEnding,
+			EncodeLevel:    zapcore.CloudLoggingLevelEncoder,
+			EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+			EncodeDuration: zapcore.SecondsDurationEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
+		},
+		CallerFields: cloudLoggingCallerFields,
+	}
+}
+
+func cloudLoggingCallerFields(caller zapcore.EntryCaller) []Field {
+	return []Field{Object("logging.googleapis.com/sourceLocation", zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+		enc.AddString("file", caller.File)
+		// The line is an int64, which the JSON mapping of the API encodes as
+		// a string.
+		enc.AddString("line", strconv.Itoa(caller.Line))
+		if fn := callerFunction(caller); fn != "" {
+			enc.AddString("function", fn)
+		}
+		return nil
+	}))}
+}
+
+// callerFunction returns the fully-qualified name of the caller's function,
+// or an empty string if it's unknown.
+func callerFunction(caller zapcore.EntryCaller) string {
+	if fn := runtime.FuncForPC(caller.PC); fn != nil {
+		return fn.Name()
+	}
+	return ""
+}
+
+// RegisterSchema registers a Schema constructor, which the Config struct can
+// then reference. By default, the "ecs" (ECSSchema) and "cloudLogging"
+// (CloudLoggingSchema) schemas are registered.
+//
+// Attempting to register a schema whose name is already taken returns an
+// error.
+func RegisterSchema(name string, constructor func() Schema) error {
+	_schemaMutex.Lock()
+	defer _schemaMutex.Unlock()
+	if name == "" {
+		return errNoSchemaNameSpecified
+	}
+	if _, ok := _schemaNameToConstructor[name]; ok {
+		return fmt.Errorf("schema already registered for name %q", name)
+	}
+	_schemaNameToConstructor[name] = constructor
+	return nil
+}
+
+func newSchema(name string) (Schema, error) {
+	_schemaMutex.RLock()
+	defer _schemaMutex.RUnlock()
+	constructor, ok := _schemaNameToConstructor[name]
+	if !ok {
+		return Schema{}, fmt.Errorf("no schema registered for name %q", name)
+	}
+	return constructor(), nil
+}
+
+// applySchema returns a copy of the Config with its schema, if any, applied,
+// along with the schema itself: the schema's EncoderConfig replaces the
+// Config's, apart from its BufferPool, and its fields are added to
+// InitialFields, unless InitialFields already has a field with the same key.
+func (cfg Config) applySchema() (Config, Schema, error) {
+	if cfg.Schema == "" {
+		return cfg, Schema{}, nil
+	}
+	s, err := newSchema(cfg.Schema)
+	if err != nil {
+		return cfg, s, err
+	}
+	pool := cfg.EncoderConfig.BufferPool
+	cfg.EncoderConfig = s.EncoderConfig
+	if cfg.EncoderConfig.BufferPool == nil {
+		cfg.EncoderConfig.BufferPool = pool
+	}
+	if len(s.Fields) > 0 {
+		fields := make(map[string]interface{}, len(s.Fields)+len(cfg.InitialFields))
+		for k, v := range s.Fields {
+			fields[k] = v
+		}
+		for k, v := range cfg.InitialFields {
+			fields[k] = v
+		}
+		cfg.InitialFields = fields
+	}
+	return cfg, s, nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSchema(t *testing.T) {
+	tests := []struct {
+		schema   string
+		initial  map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			schema: "ecs",
+			expected: map[string]interface{}{
+				"log.level":   "warn",
+				"log.logger":  "child",
+				"message":     "hello",
+				"ecs.version": ECSVersion,
+			},
+		},
+		{
+			schema:  "ecs",
+			initial: map[string]interface{}{"ecs.version": "1.0.0", "service": "api"},
+			expected: map[string]interface{}{
+				"log.level":   "warn",
+				"log.logger":  "child",
+				"message":     "hello",
+				"ecs.version": "1.0.0",
+				"service":     "api",
+			},
+		},
+		{
+			schema: "cloudLogging",
+			expected: map[string]interface{}{
+				"severity": "WARNING",
+				"logger":   "child",
+				"message":  "hello",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			temp, err := ioutil.TempFile("", "zap-schema-config-test")
+			require.NoError(t, err, "Failed to create temp file.")
+			defer os.Remove(temp.Name())
+
+			cfg := NewProductionConfig()
+			cfg.Schema = tt.schema
+			cfg.InitialFields = tt.initial
+			cfg.OutputPaths = []string{temp.Name()}
+			cfg.DisableCaller = true
+			cfg.DisableStacktrace = true
+			logger, err := cfg.Build()
+			require.NoError(t, err, "Unexpected error constructing logger.")
+
+			logger.Named("child").Warn("hello")
+			require.NoError(t, logger.Sync(), "Unexpected error syncing.")
+
+			contents, err := ioutil.ReadFile(temp.Name())
+			require.NoError(t, err, "Couldn't read log contents from temp file.")
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(contents, &entry), "Expected a JSON entry.")
+
+			timeKey := cfg.EncoderConfig.TimeKey
+			if s, err := newSchema(tt.schema); assert.NoError(t, err) {
+				timeKey = s.EncoderConfig.TimeKey
+			}
+			assert.IsType(t, "", entry[timeKey], "Expected a formatted timestamp under %q.", timeKey)
+			delete(entry, timeKey)
+			assert.Equal(t, tt.expected, entry, "Unexpected output.")
+		})
+	}
+
+	cfg := NewProductionConfig()
+	cfg.Schema = "foo"
+	_, err := cfg.Build()
+	assert.Error(t, err, "Expected an error building with an unknown schema.")
+}
+
+func TestConfigSchemaCallers(t *testing.T) {
+	tests := []struct {
+		schema string
+		check  func(t testing.TB, entry map[string]interface{})
+	}{
+		{
+			schema: "ecs",
+			check: func(t testing.TB, entry map[string]interface{}) {
+				assert.Equal(t, "schema_test.go", entry["log.origin.file.name"], "Expected only the file name.")
+				assert.IsType(t, float64(0), entry["log.origin.file.line"], "Expected a numeric line.")
+				assert.Contains(t, entry["log.origin.function"], "TestConfigSchemaCallers", "Unexpected function.")
+			},
+		},
+		{
+			schema: "cloudLogging",
+			check: func(t testing.TB, entry map[string]interface{}) {
+				loc, ok := entry["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+				require.True(t, ok, "Expected a source location object.")
+				assert.Contains(t, loc["file"], "schema_test.go", "Unexpected file.")
+				assert.Regexp(t, "^[0-9]+$", loc["line"], "Expected the line as a string.")
+				assert.Contains(t, loc["function"], "TestConfigSchemaCallers", "Unexpected function.")
+				assert.NotContains(t, entry, "caller", "Expected no plain caller key.")
+				assert.Contains(t, entry, "stack_trace", "Expected the stacktrace under Error Reporting's key.")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			temp, err := ioutil.TempFile("", "zap-schema-caller-test")
+			require.NoError(t, err, "Failed to create temp file.")
+			defer os.Remove(temp.Name())
+
+			pool := buffer.NewPool()
+			cfg := NewProductionConfig()
+			cfg.Schema = tt.schema
+			cfg.OutputPaths = []string{temp.Name()}
+			cfg.EncoderConfig.BufferPool = &pool
+			logger, err := cfg.Build()
+			require.NoError(t, err, "Unexpected error constructing logger.")
+
+			logger.Error("hello")
+			require.NoError(t, logger.Sync(), "Unexpected error syncing.")
+
+			contents, err := ioutil.ReadFile(temp.Name())
+			require.NoError(t, err, "Couldn't read log contents from temp file.")
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(contents, &entry), "Expected a JSON entry.")
+			tt.check(t, entry)
+
+			applied, _, err := cfg.applySchema()
+			require.NoError(t, err, "Unexpected error applying schema.")
+			assert.Equal(t, &pool, applied.EncoderConfig.BufferPool, "Expected the buffer pool to be kept.")
+		})
+	}
+}
+
+func TestRegisterSchema(t *testing.T) {
+	defer func() {
+		_schemaMutex.Lock()
+		delete(_schemaNameToConstructor, "test-schema")
+		_schemaMutex.Unlock()
+	}()
+
+	custom := func() Schema {
+		return Schema{EncoderConfig: zapcore.EncoderConfig{MessageKey: "text"}}
+	}
+	assert.Equal(t, errNoSchemaNameSpecified, RegisterSchema("", custom), "Expected an error registering an unnamed schema.")
+	assert.Error(t, RegisterSchema("ecs", custom), "Expected an error overwriting a schema.")
+	require.NoError(t, RegisterSchema("test-schema", custom), "Unexpected error registering a schema.")
+
+	s, err := newSchema("test-schema")
+	require.NoError(t, err, "Unexpected error looking up a registered schema.")
+	assert.Equal(t, "text", s.EncoderConfig.MessageKey, "Unexpected schema.")
+}
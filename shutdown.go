@@ -0,0 +1,110 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap/internal/exit"
+)
+
+var _shutdown = struct {
+	sync.Mutex
+	loggers []*Logger
+	seen    map[*Logger]struct{}
+}{seen: make(map[*Logger]struct{})}
+
+// RegisterForShutdown adds a Logger to the set synced by SyncAll, so that
+// programs don't lose buffered entries from Loggers that main doesn't hold
+// directly. Registering the same Logger more than once has no effect.
+// Registered Loggers are never released, so register long-lived Loggers
+// rather than per-request ones.
+func RegisterForShutdown(logger *Logger) {
+	_shutdown.Lock()
+	defer _shutdown.Unlock()
+	if _, ok := _shutdown.seen[logger]; ok {
+		return
+	}
+	_shutdown.seen[logger] = struct{}{}
+	_shutdown.loggers = append(_shutdown.loggers, logger)
+}
+
+// SyncAll syncs every Logger registered with RegisterForShutdown, in the
+// order they were registered, and returns any errors. It's typically
+// deferred in main:
+//   defer zap.SyncAll()
+func SyncAll() error {
+	_shutdown.Lock()
+	loggers := append([]*Logger(nil), _shutdown.loggers...)
+	_shutdown.Unlock()
+
+	var err error
+	for _, logger := range loggers {
+		err = multierr.Append(err, logger.Sync())
+	}
+	return err
+}
+
+// FlushAllOnExit arranges for SyncAll to run when the process is about to
+// exit because of a Fatal-level entry or an interrupt or termination signal
+// (SIGINT or SIGTERM). After syncing, the signal is re-raised with its default
+// handling. It returns a function that undoes these arrangements.
+//
+// Go doesn't run any handlers when main returns or when os.Exit is called
+// directly, so programs should still defer SyncAll in main.
+func FlushAllOnExit() (undo func()) {
+	restore := exit.Before(func() { SyncAll() })
+
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-sigs:
+			SyncAll()
+			signal.Stop(sigs)
+			reraise(sig)
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+			restore()
+		})
+	}
+}
+
+// reraise delivers sig to the current process again, now that zap no longer
+// handles it. If that fails, it exits instead.
+func reraise(sig os.Signal) {
+	if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(sig) == nil {
+		return
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap/internal/exit"
+	"go.uber.org/zap/internal/ztest"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withShutdownRegistry runs f with an empty shutdown registry.
+func withShutdownRegistry(f func()) {
+	_shutdown.Lock()
+	loggers, seen := _shutdown.loggers, _shutdown.seen
+	_shutdown.loggers, _shutdown.seen = nil, make(map[*Logger]struct{})
+	_shutdown.Unlock()
+	defer func() {
+		_shutdown.Lock()
+		_shutdown.loggers, _shutdown.seen = loggers, seen
+		_shutdown.Unlock()
+	}()
+	f()
+}
+
+type countingSink struct {
+	ztest.Discarder
+	syncs int
+	err   error
+}
+
+func (s *countingSink) Sync() error {
+	s.syncs++
+	return s.err
+}
+
+func newSyncCountingLogger(err error) (*Logger, *countingSink) {
+	sink := &countingSink{err: err}
+	return New(zapcore.NewCore(zapcore.NewJSONEncoder(NewProductionEncoderConfig()), sink, DebugLevel)), sink
+}
+
+func TestSyncAll(t *testing.T) {
+	withShutdownRegistry(func() {
+		assert.NoError(t, SyncAll(), "Expected no error syncing with nothing registered.")
+
+		first, firstSink := newSyncCountingLogger(nil)
+		second, secondSink := newSyncCountingLogger(nil)
+		RegisterForShutdown(first)
+		RegisterForShutdown(second)
+		RegisterForShutdown(first)
+
+		assert.NoError(t, SyncAll(), "Unexpected error syncing registered loggers.")
+		assert.Equal(t, 1, firstSink.syncs, "Expected duplicate registrations to be synced once.")
+		assert.Equal(t, 1, secondSink.syncs, "Expected every registered logger to be synced.")
+	})
+}
+
+func TestSyncAllErrors(t *testing.T) {
+	withShutdownRegistry(func() {
+		failing, _ := newSyncCountingLogger(errors.New("fail"))
+		ok, okSink := newSyncCountingLogger(nil)
+		RegisterForShutdown(failing)
+		RegisterForShutdown(ok)
+
+		assert.EqualError(t, SyncAll(), "fail", "Expected sync errors to be returned.")
+		assert.Equal(t, 1, okSink.syncs, "Expected a failure not to stop other loggers from syncing.")
+	})
+}
+
+func TestSyncAllConcurrent(t *testing.T) {
+	withShutdownRegistry(func() {
+		logger, sink := newSyncCountingLogger(nil)
+		var wg sync.WaitGroup
+		runConcurrently(10, 10, &wg, func() { RegisterForShutdown(logger) })
+		wg.Wait()
+		SyncAll()
+		assert.Equal(t, 1, sink.syncs, "Expected concurrent registrations to be deduplicated.")
+	})
+}
+
+func TestFlushAllOnExit(t *testing.T) {
+	withShutdownRegistry(func() {
+		fatal, _ := newSyncCountingLogger(nil)
+		other, otherSink := newSyncCountingLogger(nil)
+		RegisterForShutdown(other)
+
+		undo := FlushAllOnExit()
+		stub := exit.WithStub(func() { fatal.Fatal("fatal") })
+		assert.True(t, stub.Exited, "Expected a Fatal entry to exit.")
+		assert.Equal(t, 1, otherSink.syncs, "Expected registered loggers to be synced before exiting.")
+
+		undo()
+		undo() // should be a no-op
+		exit.WithStub(func() { fatal.Fatal("fatal") })
+		assert.Equal(t, 1, otherSink.syncs, "Expected undo to stop syncing on exit.")
+	})
+}
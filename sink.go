@@ -32,7 +32,11 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-const schemeFile = "file"
+const (
+	schemeFile = "file"
+	schemeTCP  = "tcp"
+	schemeUDP  = "udp"
+)
 
 var (
 	_sinkMutex     sync.RWMutex
@@ -49,6 +53,8 @@ func resetSinkRegistry() {
 
 	_sinkFactories = map[string]func(*url.URL) (Sink, error){
 		schemeFile: newFileSink,
+		schemeTCP:  newNetSink,
+		schemeUDP:  newNetSink,
 	}
 }
 
@@ -62,6 +68,13 @@ type nopCloserSink struct{ zapcore.WriteSyncer }
 
 func (nopCloserSink) Close() error { return nil }
 
+// stdSink is a standard stream, which mustn't be closed. Unlike a
+// nopCloserSink, it keeps the *os.File's Stat method, so that
+// zapcore.IsTerminal can tell whether it's a terminal.
+type stdSink struct{ *os.File }
+
+func (stdSink) Close() error { return nil }
+
 type errSinkNotFound struct {
 	scheme string
 }
@@ -75,8 +88,8 @@ func (e *errSinkNotFound) Error() string {
 //
 // All schemes must be ASCII, valid under section 3.1 of RFC 3986
 // (https://tools.ietf.org/html/rfc3986#section-3.1), and must not already
-// have a factory registered. Zap automatically registers a factory for the
-// "file" scheme.
+// have a factory registered. Zap automatically registers factories for the
+// "file", "tcp", and "udp" schemes.
 func RegisterSink(scheme string, factory func(*url.URL) (Sink, error)) error {
 	_sinkMutex.Lock()
 	defer _sinkMutex.Unlock()
@@ -132,13 +145,32 @@ func newFileSink(u *url.URL) (Sink, error) {
 	}
 	switch u.Path {
 	case "stdout":
-		return nopCloserSink{os.Stdout}, nil
+		return stdSink{os.Stdout}, nil
 	case "stderr":
-		return nopCloserSink{os.Stderr}, nil
+		return stdSink{os.Stderr}, nil
 	}
 	return os.OpenFile(u.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
 }
 
+func newNetSink(u *url.URL) (Sink, error) {
+	if u.User != nil {
+		return nil, fmt.Errorf("user and password not allowed with %s URLs: got %v", u.Scheme, u)
+	}
+	if u.Fragment != "" {
+		return nil, fmt.Errorf("fragments not allowed with %s URLs: got %v", u.Scheme, u)
+	}
+	if u.RawQuery != "" {
+		return nil, fmt.Errorf("query parameters not allowed with %s URLs: got %v", u.Scheme, u)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, fmt.Errorf("paths not allowed with %s URLs: got %v", u.Scheme, u)
+	}
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("%s URLs must specify a host and port: got %v", u.Scheme, u)
+	}
+	return NetworkSink(u.Scheme, u.Host)
+}
+
 func normalizeScheme(s string) (string, error) {
 	// https://tools.ietf.org/html/rfc3986#section-3.1
 	s = strings.ToLower(s)
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"runtime"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// A Span times an operation, logging an entry when the operation starts and
+// another when it finishes. Both entries carry the same randomly-generated
+// "span_id", so they're easy to correlate.
+type Span struct {
+	logger   *Logger
+	name     string
+	start    time.Time
+	finished atomic.Bool
+}
+
+// StartSpan logs the start of an operation at InfoLevel, with the operation's
+// name as the message, a "span" field set to "start", and the supplied
+// fields, and returns a Span for logging its end. For example,
+//   span := zap.StartSpan(logger, "backfill", zap.String("table", "users"))
+//   defer span.Finish()
+//
+// If a Span is garbage collected without being finished, a warning is logged
+// on a best-effort basis; since finalizers aren't guaranteed to run, don't
+// rely on it to catch every leak. Durations are measured with the Logger's
+// clock (see WithClock).
+func StartSpan(logger *Logger, name string, fields ...Field) *Span {
+	context := make([]Field, 0, len(fields)+1)
+	context = append(context, String("span_id", randomID(8)))
+	logger = logger.WithOptions(AddCallerSkip(1)).With(append(context, fields...)...)
+	s := &Span{
+		logger: logger,
+		name:   name,
+		start:  logger.clock.Now(),
+	}
+	s.logger.Info(name, String("span", "start"))
+	runtime.SetFinalizer(s, (*Span).leaked)
+	return s
+}
+
+// Finish logs the end of the operation at InfoLevel, with the start entry's
+// message and fields, a "span" field set to "finish", the time elapsed since
+// the start under "duration", and the supplied fields. Only the first call
+// to Finish logs anything.
+func (s *Span) Finish(fields ...Field) {
+	if !s.finished.CAS(false, true) {
+		return
+	}
+	runtime.SetFinalizer(s, nil)
+	all := make([]Field, 0, len(fields)+2)
+	all = append(all, String("span", "finish"), Duration("duration", s.logger.clock.Now().Sub(s.start)))
+	s.logger.Info(s.name, append(all, fields...)...)
+}
+
+func (s *Span) leaked() {
+	s.logger.Warn("Span garbage collected without being finished.", String("span_name", s.name))
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpan(t *testing.T) {
+	clock := &stubClock{now: time.Unix(0, 0)}
+	withLogger(t, DebugLevel, opts(AddCaller(), WithClock(clock)), func(logger *Logger, logs *observer.ObservedLogs) {
+		span := StartSpan(logger, "backfill", String("table", "users"))
+		clock.add(3 * time.Second)
+		span.Finish(Int("rows", 10))
+		span.Finish() // should be a no-op
+
+		entries := logs.AllUntimed()
+		require.Equal(t, 2, len(entries), "Unexpected number of entries.")
+		start, finish := entries[0], entries[1]
+
+		id, ok := start.ContextMap()["span_id"].(string)
+		require.True(t, ok, "Expected a string span ID.")
+		assert.Regexp(t, "^[0-9a-f]{16}$", id, "Unexpected span ID format.")
+		assert.Equal(t, map[string]interface{}{
+			"span_id": id,
+			"table":   "users",
+			"span":    "start",
+		}, start.ContextMap(), "Unexpected start entry.")
+		assert.Equal(t, map[string]interface{}{
+			"span_id":  id,
+			"table":    "users",
+			"span":     "finish",
+			"duration": 3 * time.Second,
+			"rows":     int64(10),
+		}, finish.ContextMap(), "Unexpected finish entry.")
+
+		for _, ent := range entries {
+			assert.Equal(t, "backfill", ent.Message, "Unexpected message.")
+			assert.Equal(t, zapcore.InfoLevel, ent.Level, "Unexpected level.")
+			assert.Contains(t, ent.Entry.Caller.File, "span_test.go", "Expected the caller to be the span's user.")
+		}
+	})
+}
+
+func TestSpanDistinctIDs(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		StartSpan(logger, "one").Finish()
+		StartSpan(logger, "two").Finish()
+		entries := logs.AllUntimed()
+		require.Equal(t, 4, len(entries), "Unexpected number of entries.")
+		assert.NotEqual(t, entries[0].ContextMap()["span_id"], entries[2].ContextMap()["span_id"], "Expected spans to have distinct IDs.")
+	})
+}
+
+func TestSpanLeaked(t *testing.T) {
+	withLogger(t, DebugLevel, nil, func(logger *Logger, logs *observer.ObservedLogs) {
+		StartSpan(logger, "leaky")
+		for i := 0; i < 100 && logs.FilterMessage("Span garbage collected without being finished.").Len() == 0; i++ {
+			runtime.GC()
+			time.Sleep(time.Millisecond)
+		}
+		leaks := logs.FilterMessage("Span garbage collected without being finished.").AllUntimed()
+		require.Equal(t, 1, len(leaks), "Expected a warning about the leaked span.")
+		assert.Equal(t, zapcore.WarnLevel, leaks[0].Level, "Unexpected level.")
+		assert.Equal(t, "leaky", leaks[0].ContextMap()["span_name"], "Unexpected span name.")
+	})
+}
@@ -26,6 +26,7 @@ import (
 	"sync"
 
 	"go.uber.org/zap/internal/bufferpool"
+	"go.uber.org/zap/zapcore"
 )
 
 const _zapPackage = "go.uber.org/zap"
@@ -44,6 +45,15 @@ var (
 )
 
 func takeStacktrace() string {
+	trace, _ := takeStacktraceN(0, 0)
+	return trace
+}
+
+// takeStacktraceN captures the current goroutine's stacktrace, omitting zap's
+// own frames and then skip more. If max is positive, it stops at the last
+// whole frame that fits in max bytes, and also returns the number of frames
+// it omitted.
+func takeStacktraceN(skip, max int) (string, int) {
 	buffer := bufferpool.Get()
 	defer buffer.Free()
 	programCounters := _stacktracePool.Get().(*programCounters)
@@ -51,8 +61,8 @@ func takeStacktrace() string {
 
 	var numFrames int
 	for {
-		// Skip the call to runtime.Counters and takeStacktrace so that the
-		// program counters start at the caller of takeStacktrace.
+		// Skip the call to runtime.Counters and takeStacktraceN so that the
+		// program counters start at the caller of takeStacktraceN.
 		numFrames = runtime.Callers(2, programCounters.pcs)
 		if numFrames < len(programCounters.pcs) {
 			break
@@ -63,6 +73,8 @@ func takeStacktrace() string {
 	}
 
 	i := 0
+	end := 0 // length of the whole frames in the buffer
+	omitted := 0
 	skipZapFrames := true // skip all consecutive zap frames at the beginning.
 	frames := runtime.CallersFrames(programCounters.pcs[:numFrames])
 
@@ -75,22 +87,191 @@ func takeStacktrace() string {
 		} else {
 			skipZapFrames = false
 		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if omitted > 0 {
+			omitted++
+			continue
+		}
 
 		if i != 0 {
 			buffer.AppendByte('\n')
 		}
-		i++
 		buffer.AppendString(frame.Function)
 		buffer.AppendByte('\n')
 		buffer.AppendByte('\t')
 		buffer.AppendString(frame.File)
 		buffer.AppendByte(':')
 		buffer.AppendInt(int64(frame.Line))
+		if max > 0 && buffer.Len() > max {
+			// Leave out the partial frame.
+			omitted++
+			continue
+		}
+		i++
+		end = buffer.Len()
+	}
+
+	return string(buffer.Bytes()[:end]), omitted
+}
+
+// A StackOption configures the stacktrace captured by Stack.
+type StackOption interface {
+	applyStack(*stackOptions)
+}
+
+type stackOptions struct {
+	skip       int
+	maxFrames  int
+	filters    []string
+	structured bool
+}
+
+type stackOptionFunc func(*stackOptions)
+
+func (f stackOptionFunc) applyStack(opts *stackOptions) {
+	f(opts)
+}
+
+// SkipFrames skips the given number of frames from the top of the
+// stacktrace, after zap's own frames, as StackSkip does.
+func SkipFrames(n int) StackOption {
+	return stackOptionFunc(func(opts *stackOptions) {
+		opts.skip = n
+	})
+}
+
+// MaxFrames keeps at most n frames, dropping the ones furthest from the top
+// of the stacktrace.
+func MaxFrames(n int) StackOption {
+	return stackOptionFunc(func(opts *stackOptions) {
+		opts.maxFrames = n
+	})
+}
+
+// FilterFrames leaves out the frames whose function names start with any of
+// the given prefixes, wherever they appear in the stacktrace. For example,
+// FilterFrames("runtime.", "net/http.") trims the runtime's and the HTTP
+// server's frames. Filtered frames don't count towards MaxFrames or
+// SkipFrames.
+func FilterFrames(prefixes ...string) StackOption {
+	return stackOptionFunc(func(opts *stackOptions) {
+		opts.filters = append(opts.filters, prefixes...)
+	})
+}
+
+// StructuredFrames encodes the stacktrace as an array of frames, each an
+// object with "function", "file", and "line" keys, rather than as a single
+// string. It's easier for log processors to index, and cheaper to build,
+// since the frames' strings needn't be copied into one.
+func StructuredFrames() StackOption {
+	return stackOptionFunc(func(opts *stackOptions) {
+		opts.structured = true
+	})
+}
+
+// A stackFrame is the part of a runtime.Frame that's logged.
+type stackFrame struct {
+	function string
+	file     string
+	line     int
+}
+
+func (f stackFrame) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("function", f.function)
+	enc.AddString("file", f.file)
+	enc.AddInt("line", f.line)
+	return nil
+}
+
+type stackFrames []stackFrame
+
+func (fs stackFrames) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for i := range fs {
+		if err := enc.AppendObject(fs[i]); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// String formats the frames like takeStacktrace.
+func (fs stackFrames) String() string {
+	buffer := bufferpool.Get()
+	defer buffer.Free()
+	for i, f := range fs {
+		if i != 0 {
+			buffer.AppendByte('\n')
+		}
+		buffer.AppendString(f.function)
+		buffer.AppendByte('\n')
+		buffer.AppendByte('\t')
+		buffer.AppendString(f.file)
+		buffer.AppendByte(':')
+		buffer.AppendInt(int64(f.line))
+	}
 	return buffer.String()
 }
 
+// takeFrames captures the current goroutine's stacktrace, omitting zap's own
+// frames, and returns the frames selected by the options along with the
+// number of frames MaxFrames dropped.
+func takeFrames(so stackOptions) (stackFrames, int) {
+	programCounters := _stacktracePool.Get().(*programCounters)
+	defer _stacktracePool.Put(programCounters)
+
+	var numFrames int
+	for {
+		numFrames = runtime.Callers(2, programCounters.pcs)
+		if numFrames < len(programCounters.pcs) {
+			break
+		}
+		programCounters = newProgramCounters(len(programCounters.pcs) * 2)
+	}
+	return collectFrames(runtime.CallersFrames(programCounters.pcs[:numFrames]).Next, so)
+}
+
+// collectFrames selects frames from an iterator like runtime.Frames.Next.
+// As in takeStacktraceN, the last frame is left out.
+func collectFrames(next func() (runtime.Frame, bool), so stackOptions) (stackFrames, int) {
+	var (
+		frames  stackFrames
+		omitted int
+	)
+	skipZapFrames := true
+	skip := so.skip
+	for frame, more := next(); more; frame, more = next() {
+		if skipZapFrames && isZapFrame(frame.Function) {
+			continue
+		}
+		skipZapFrames = false
+		if hasAnyPrefix(frame.Function, so.filters) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if so.maxFrames > 0 && len(frames) >= so.maxFrames {
+			omitted++
+			continue
+		}
+		frames = append(frames, stackFrame{function: frame.Function, file: frame.File, line: frame.Line})
+	}
+	return frames, omitted
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func isZapFrame(function string) bool {
 	for _, prefix := range _zapStacktracePrefixes {
 		if strings.HasPrefix(function, prefix) {
@@ -21,6 +21,7 @@
 package zap
 
 import (
+	"runtime"
 	"strings"
 	"testing"
 
@@ -68,6 +69,68 @@ func TestIsZapFrame(t *testing.T) {
 	})
 }
 
+func TestCollectFrames(t *testing.T) {
+	frame := func(fn string) runtime.Frame {
+		return runtime.Frame{Function: fn, File: fn + ".go", Line: 1}
+	}
+	stack := []runtime.Frame{
+		frame("go.uber.org/zap.Stack"),
+		frame("main.handler"),
+		frame("runtime.call32"),
+		frame("main.serve"),
+		frame("net/http.(*conn).serve"),
+		frame("main.main"),
+		frame("runtime.main"),
+	}
+	names := func(frames stackFrames) []string {
+		var fns []string
+		for _, f := range frames {
+			fns = append(fns, f.function)
+		}
+		return fns
+	}
+
+	tests := []struct {
+		desc    string
+		opts    stackOptions
+		want    []string
+		omitted int
+	}{
+		{
+			desc: "defaults",
+			want: []string{"main.handler", "runtime.call32", "main.serve", "net/http.(*conn).serve", "main.main"},
+		},
+		{
+			desc: "filtered",
+			opts: stackOptions{filters: []string{"runtime.", "net/http."}},
+			want: []string{"main.handler", "main.serve", "main.main"},
+		},
+		{
+			desc:    "skipped and limited",
+			opts:    stackOptions{skip: 1, maxFrames: 2, filters: []string{"runtime."}},
+			want:    []string{"main.serve", "net/http.(*conn).serve"},
+			omitted: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			i := 0
+			next := func() (runtime.Frame, bool) {
+				i++
+				return stack[i-1], i < len(stack)
+			}
+			frames, omitted := collectFrames(next, tt.opts)
+			assert.Equal(t, tt.want, names(frames), "Unexpected frames.")
+			assert.Equal(t, tt.omitted, omitted, "Unexpected number of omitted frames.")
+		})
+	}
+}
+
+func TestStackFramesString(t *testing.T) {
+	frames := stackFrames{{"main.a", "a.go", 1}, {"main.b", "b.go", 2}}
+	assert.Equal(t, "main.a\n\ta.go:1\nmain.b\n\tb.go:2", frames.String(), "Unexpected string form.")
+}
+
 func BenchmarkTakeStacktrace(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		takeStacktrace()
@@ -229,6 +229,42 @@ func TestSugarConcatenatingLogging(t *testing.T) {
 	}
 }
 
+func TestSugarLnLogging(t *testing.T) {
+	tests := []struct {
+		args   []interface{}
+		expect string
+	}{
+		{nil, ""},
+		{[]interface{}{nil}, "<nil>"},
+		// Unlike fmt.Sprint, fmt.Sprintln always separates operands.
+		{[]interface{}{"foo", "bar", 42}, "foo bar 42"},
+		{[]interface{}{"multi\nline\n"}, "multi\nline\n"},
+	}
+
+	// Common to all test cases.
+	context := []interface{}{"foo", "bar"}
+	expectedFields := []Field{String("foo", "bar")}
+
+	for _, tt := range tests {
+		withSugar(t, DebugLevel, nil, func(logger *SugaredLogger, logs *observer.ObservedLogs) {
+			logger.With(context...).Debugln(tt.args...)
+			logger.With(context...).Infoln(tt.args...)
+			logger.With(context...).Warnln(tt.args...)
+			logger.With(context...).Errorln(tt.args...)
+			logger.With(context...).DPanicln(tt.args...)
+
+			expected := make([]observer.LoggedEntry, 5)
+			for i, lvl := range []zapcore.Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel} {
+				expected[i] = observer.LoggedEntry{
+					Entry:   zapcore.Entry{Message: tt.expect, Level: lvl},
+					Context: expectedFields,
+				}
+			}
+			assert.Equal(t, expected, logs.AllUntimed(), "Unexpected log output.")
+		})
+	}
+}
+
 func TestSugarTemplatedLogging(t *testing.T) {
 	tests := []struct {
 		format string
@@ -280,6 +316,9 @@ func TestSugarPanicLogging(t *testing.T) {
 		{FatalLevel, func(s *SugaredLogger) { s.Panicw("foo") }, ""},
 		{PanicLevel, func(s *SugaredLogger) { s.Panicw("foo") }, "foo"},
 		{DebugLevel, func(s *SugaredLogger) { s.Panicw("foo") }, "foo"},
+		{FatalLevel, func(s *SugaredLogger) { s.Panicln("foo") }, ""},
+		{PanicLevel, func(s *SugaredLogger) { s.Panicln("foo") }, "foo"},
+		{DebugLevel, func(s *SugaredLogger) { s.Panicln("foo") }, "foo"},
 	}
 
 	for _, tt := range tests {
@@ -312,6 +351,9 @@ func TestSugarFatalLogging(t *testing.T) {
 		{FatalLevel + 1, func(s *SugaredLogger) { s.Fatalw("foo") }, ""},
 		{FatalLevel, func(s *SugaredLogger) { s.Fatalw("foo") }, "foo"},
 		{DebugLevel, func(s *SugaredLogger) { s.Fatalw("foo") }, "foo"},
+		{FatalLevel + 1, func(s *SugaredLogger) { s.Fatalln("foo") }, ""},
+		{FatalLevel, func(s *SugaredLogger) { s.Fatalln("foo") }, "foo"},
+		{DebugLevel, func(s *SugaredLogger) { s.Fatalln("foo") }, "foo"},
 	}
 
 	for _, tt := range tests {
@@ -343,14 +385,17 @@ func TestSugarAddCaller(t *testing.T) {
 	for _, tt := range tests {
 		withSugar(t, DebugLevel, tt.options, func(logger *SugaredLogger, logs *observer.ObservedLogs) {
 			logger.Info("")
+			logger.Infoln("")
 			output := logs.AllUntimed()
-			assert.Equal(t, 1, len(output), "Unexpected number of logs written out.")
-			assert.Regexp(
-				t,
-				tt.pat,
-				output[0].Entry.Caller,
-				"Expected to find package name and file name in output.",
-			)
+			assert.Equal(t, 2, len(output), "Unexpected number of logs written out.")
+			for _, entry := range output {
+				assert.Regexp(
+					t,
+					tt.pat,
+					entry.Entry.Caller,
+					"Expected to find package name and file name in output.",
+				)
+			}
 		})
 	}
 }
@@ -0,0 +1,53 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// TimeRange constructs a field that logs a window of time, like a query
+// window or a reporting period, as a nested object with its start, end, and
+// duration. The times and duration are added with AddTime and AddDuration,
+// so they're serialized by the encoder's EncodeTime and EncodeDuration.
+//
+// A zero end denotes an ongoing range: both the end and the duration are
+// omitted.
+func TimeRange(key string, start, end time.Time) Field {
+	return Object(key, timeRange{start: start, end: end})
+}
+
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+func (r timeRange) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddTime("start", r.start)
+	if r.end.IsZero() {
+		return nil
+	}
+	enc.AddTime("end", r.end)
+	enc.AddDuration("duration", r.end.Sub(r.start))
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeRange(t *testing.T) {
+	start := time.Unix(100, 0)
+	tests := []struct {
+		desc     string
+		end      time.Time
+		expected map[string]interface{}
+	}{
+		{
+			desc: "closed range",
+			end:  start.Add(90 * time.Second),
+			expected: map[string]interface{}{
+				"start":    start,
+				"end":      start.Add(90 * time.Second),
+				"duration": 90 * time.Second,
+			},
+		},
+		{
+			desc:     "open-ended range",
+			expected: map[string]interface{}{"start": start},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		f := TimeRange("window", start, tt.end)
+		f.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields["window"], "Unexpected output for %s.", tt.desc)
+		assertCanBeReused(t, f)
+	}
+}
+
+func TestTimeRangeEncoders(t *testing.T) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		EncodeTime:     zapcore.EpochTimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})
+	start := time.Unix(100, 0)
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{TimeRange("window", start, start.Add(time.Minute))})
+	if assert.NoError(t, err, "Unexpected error encoding entry.") {
+		assert.Equal(t, `{"window":{"start":100,"end":160,"duration":"1m0s"}}`+"\n", buf.String(), "Expected the encoder's time and duration formatters to be used.")
+		buf.Free()
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"encoding/hex"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// A SpanContext identifies the span in a distributed trace that's active
+// while an entry is logged. It's deliberately independent of any tracing
+// library: adapters for OpenTelemetry or OpenTracing only need to copy the
+// span's IDs, hex-encoded, and its trace flags.
+type SpanContext struct {
+	TraceID    string
+	SpanID     string
+	TraceFlags byte
+}
+
+// IsValid reports whether the SpanContext has both a trace ID and a span ID.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.SpanID != ""
+}
+
+// Sampled reports whether the trace flags' sampled bit is set.
+func (sc SpanContext) Sampled() bool {
+	return sc.TraceFlags&_traceFlagSampled != 0
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler, using the field names
+// of the OpenTelemetry log data model: trace_id, span_id, and trace_flags,
+// the last as two hex digits.
+func (sc SpanContext) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("trace_id", sc.TraceID)
+	enc.AddString("span_id", sc.SpanID)
+	enc.AddString("trace_flags", hex.EncodeToString([]byte{sc.TraceFlags}))
+	return nil
+}
+
+// TraceContext constructs a field that adds the span context's trace_id,
+// span_id, and trace_flags directly to the entry, so that tracing backends
+// like Jaeger and Tempo can link the entry to its trace. If the span context
+// isn't valid, the field is a no-op.
+func TraceContext(sc SpanContext) Field {
+	if !sc.IsValid() {
+		return Skip()
+	}
+	return Inline(sc)
+}
+
+// ParseTraceparent parses a W3C Trace Context traceparent header, like
+//   00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+// into a SpanContext whose span ID is the header's parent ID. It reports
+// false if the header is malformed.
+func ParseTraceparent(traceparent string) (SpanContext, bool) {
+	return parseTraceparent(traceparent)
+}
+
+// WithTraceContext registers a context extractor (see WithContextExtractor)
+// that adds the fields of TraceContext to the Logger returned by FromContext,
+// so entries logged while handling a traced request link to the trace. The
+// extract function typically wraps a tracing library; with OpenTelemetry, for
+// example:
+//
+//   zap.WithTraceContext(func(ctx context.Context) (zap.SpanContext, bool) {
+//     sc := trace.SpanContextFromContext(ctx)
+//     return zap.SpanContext{
+//       TraceID:    sc.TraceID().String(),
+//       SpanID:     sc.SpanID().String(),
+//       TraceFlags: byte(sc.TraceFlags()),
+//     }, sc.IsValid()
+//   })
+func WithTraceContext(extract func(context.Context) (SpanContext, bool)) Option {
+	return WithContextExtractor(func(ctx context.Context) []Field {
+		sc, ok := extract(ctx)
+		if !ok || !sc.IsValid() {
+			return nil
+		}
+		return []Field{TraceContext(sc)}
+	})
+}
+
+// TraceSampled is shorthand for the common idiom
+// NamedTraceSampled("trace_sampled", traceparent).
+func TraceSampled(traceparent string) Field {
+	return NamedTraceSampled("trace_sampled", traceparent)
+}
+
+// NamedTraceSampled constructs a boolean field recording whether a
+// distributed trace was sampled, taken from the sampled bit of the trace
+// flags in a W3C Trace Context traceparent header, like
+//   00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+// Logging the sampling decision lets downstream systems keep only the logs
+// that belong to recorded traces. If the header is malformed, the field is a
+// no-op.
+//
+// For the common case in which the key is simply "trace_sampled", the
+// TraceSampled function is shorter and less repetitive.
+func NamedTraceSampled(key, traceparent string) Field {
+	sc, ok := parseTraceparent(traceparent)
+	if !ok {
+		return Skip()
+	}
+	return Bool(key, sc.Sampled())
+}
+
+const _traceFlagSampled = 0x01
+
+// parseTraceparent validates a traceparent header and returns its span
+// context. See https://www.w3.org/TR/trace-context/#traceparent-header.
+func parseTraceparent(traceparent string) (SpanContext, bool) {
+	// version "-" trace-id "-" parent-id "-" trace-flags
+	const size = 2 + 1 + 32 + 1 + 16 + 1 + 2
+	if len(traceparent) < size {
+		return SpanContext{}, false
+	}
+	version := traceparent[0:2]
+	if !isLowerHex(version) || version == "ff" {
+		return SpanContext{}, false
+	}
+	// Version 00 has exactly four parts, but later versions may append more.
+	if version == "00" && len(traceparent) != size {
+		return SpanContext{}, false
+	}
+	if len(traceparent) > size && traceparent[size] != '-' {
+		return SpanContext{}, false
+	}
+	if traceparent[2] != '-' || traceparent[35] != '-' || traceparent[52] != '-' {
+		return SpanContext{}, false
+	}
+	traceID, parentID, flags := traceparent[3:35], traceparent[36:52], traceparent[53:55]
+	if !isLowerHex(traceID) || isAllZeros(traceID) ||
+		!isLowerHex(parentID) || isAllZeros(parentID) ||
+		!isLowerHex(flags) {
+		return SpanContext{}, false
+	}
+	b, err := hex.DecodeString(flags)
+	if err != nil {
+		return SpanContext{}, false
+	}
+	return SpanContext{TraceID: traceID, SpanID: parentID, TraceFlags: b[0]}, true
+}
+
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAllZeros(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '0' {
+			return false
+		}
+	}
+	return true
+}
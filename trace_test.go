@@ -0,0 +1,116 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceSampled(t *testing.T) {
+	const (
+		traceID  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID = "00f067aa0ba902b7"
+	)
+
+	tests := []struct {
+		desc        string
+		traceparent string
+		expected    Field
+	}{
+		{"sampled", "00-" + traceID + "-" + parentID + "-01", Bool("trace_sampled", true)},
+		{"not sampled", "00-" + traceID + "-" + parentID + "-00", Bool("trace_sampled", false)},
+		{"other flags set", "00-" + traceID + "-" + parentID + "-fe", Bool("trace_sampled", false)},
+		{"future version", "cc-" + traceID + "-" + parentID + "-09-extra", Bool("trace_sampled", true)},
+		{"empty", "", Skip()},
+		{"invalid version", "ff-" + traceID + "-" + parentID + "-01", Skip()},
+		{"trailing data on version 00", "00-" + traceID + "-" + parentID + "-01-extra", Skip()},
+		{"future version without separator", "cc-" + traceID + "-" + parentID + "-01extra", Skip()},
+		{"uppercase", "00-" + "4BF92F3577B34DA6A3CE929D0E0E4736" + "-" + parentID + "-01", Skip()},
+		{"zero trace ID", "00-00000000000000000000000000000000-" + parentID + "-01", Skip()},
+		{"zero parent ID", "00-" + traceID + "-0000000000000000-01", Skip()},
+		{"bad separator", "00_" + traceID + "-" + parentID + "-01", Skip()},
+		{"bad flags", "00-" + traceID + "-" + parentID + "-0g", Skip()},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, TraceSampled(tt.traceparent), "%s: unexpected field.", tt.desc)
+	}
+	assert.Equal(
+		t,
+		Bool("sampled", true),
+		NamedTraceSampled("sampled", "00-"+traceID+"-"+parentID+"-01"),
+		"Unexpected field with custom key.",
+	)
+}
+
+func TestTraceContext(t *testing.T) {
+	sc, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok, "Expected a valid traceparent.")
+	assert.Equal(t, SpanContext{
+		TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:     "00f067aa0ba902b7",
+		TraceFlags: 1,
+	}, sc, "Unexpected span context.")
+	assert.True(t, sc.Sampled(), "Expected the span to be sampled.")
+
+	_, ok = ParseTraceparent("00-garbage")
+	assert.False(t, ok, "Expected a malformed traceparent to be rejected.")
+
+	enc := zapcore.NewMapObjectEncoder()
+	TraceContext(sc).AddTo(enc)
+	assert.Equal(t, map[string]interface{}{
+		"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+		"span_id":     "00f067aa0ba902b7",
+		"trace_flags": "01",
+	}, enc.Fields, "Expected the span context to be inlined.")
+	assert.Equal(t, Skip(), TraceContext(SpanContext{TraceID: "abc"}), "Expected a no-op field for invalid span contexts.")
+}
+
+func TestWithTraceContext(t *testing.T) {
+	sc, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	type spanKey struct{}
+	extract := WithTraceContext(func(ctx context.Context) (SpanContext, bool) {
+		sc, ok := ctx.Value(spanKey{}).(SpanContext)
+		return sc, ok
+	})
+
+	withLogger(t, DebugLevel, opts(extract), func(logger *Logger, logs *observer.ObservedLogs) {
+		ctx := NewContext(context.Background(), logger)
+		FromContext(ctx).Info("untraced")
+		FromContext(context.WithValue(ctx, spanKey{}, sc)).Info("traced")
+
+		entries := logs.AllUntimed()
+		require.Equal(t, 2, len(entries), "Unexpected number of entries.")
+		assert.Empty(t, entries[0].Context, "Expected no trace fields without a span.")
+		assert.Equal(t, map[string]interface{}{
+			"trace_id":    "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span_id":     "00f067aa0ba902b7",
+			"trace_flags": "00",
+		}, entries[1].ContextMap(), "Unexpected trace fields.")
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	errNoTypeSpecified = errors.New("no type specified")
+
+	// _typeEncoders holds a map[reflect.Type]TypeEncoder. It's replaced
+	// wholesale on registration, so that lookups don't need a lock.
+	_typeEncoders     atomic.Value
+	_typeEncoderMutex sync.Mutex
+)
+
+func init() {
+	_typeEncoders.Store(map[reflect.Type]TypeEncoder{})
+}
+
+// A TypeEncoder adds a value of a particular type to an ObjectEncoder under
+// the supplied key.
+type TypeEncoder func(enc zapcore.ObjectEncoder, key string, val interface{}) error
+
+// RegisterTypeEncoder teaches Any and Reflect to encode values of a
+// particular type with a custom function rather than with reflection. This
+// lets libraries define how their common types (decimals, UUIDs, and the
+// like) are logged once, for every application that uses them. (It isn't
+// named RegisterEncoder, since that name registers whole encoders.)
+//
+// When Any or Reflect is given a value of a registered type, the registered
+// encoder takes precedence over everything else, including the value's
+// MarshalLogObject, Error, or String methods. The strongly-typed field
+// constructors, like Object and Stringer, ignore the registry. Types match
+// exactly, so registering T doesn't affect *T.
+// If the encoder returns an error, the error message is logged under the key
+// with an "Error" suffix, as with marshalers.
+//
+// Encoders should be registered during initialization, before any logging.
+// Registration is safe for concurrent use, but fields constructed before a
+// type is registered keep using reflection. Attempting to register an encoder
+// for a type that already has one returns an error.
+func RegisterTypeEncoder(t reflect.Type, encoder TypeEncoder) error {
+	if t == nil {
+		return errNoTypeSpecified
+	}
+	_typeEncoderMutex.Lock()
+	defer _typeEncoderMutex.Unlock()
+	old := _typeEncoders.Load().(map[reflect.Type]TypeEncoder)
+	if _, ok := old[t]; ok {
+		return fmt.Errorf("encoder already registered for type %v", t)
+	}
+	encoders := make(map[reflect.Type]TypeEncoder, len(old)+1)
+	for k, v := range old {
+		encoders[k] = v
+	}
+	encoders[t] = encoder
+	_typeEncoders.Store(encoders)
+	return nil
+}
+
+// typeEncoded returns a field that encodes val with its registered
+// TypeEncoder, if any.
+func typeEncoded(key string, val interface{}) (Field, bool) {
+	encoders := _typeEncoders.Load().(map[reflect.Type]TypeEncoder)
+	if len(encoders) == 0 || val == nil {
+		return Field{}, false
+	}
+	encoder, ok := encoders[reflect.TypeOf(val)]
+	if !ok {
+		return Field{}, false
+	}
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: typeEncodedValue{
+		key:     key,
+		val:     val,
+		encoder: encoder,
+	}}, true
+}
+
+type typeEncodedValue struct {
+	key     string
+	val     interface{}
+	encoder TypeEncoder
+}
+
+func (v typeEncodedValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return v.encoder(enc, v.key, v.val)
+}
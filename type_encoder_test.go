@@ -0,0 +1,96 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// typeEncoderMoney and typeEncoderFailing are only used by these tests, since
+// the type encoder registry is global.
+type typeEncoderMoney struct {
+	cents    int64
+	currency string
+}
+
+func (m typeEncoderMoney) String() string { return "not used" }
+
+type typeEncoderFailing struct{}
+
+func init() {
+	err := RegisterTypeEncoder(reflect.TypeOf(typeEncoderMoney{}), func(enc zapcore.ObjectEncoder, key string, val interface{}) error {
+		m := val.(typeEncoderMoney)
+		enc.AddString(key, fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency))
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	err = RegisterTypeEncoder(reflect.TypeOf(typeEncoderFailing{}), func(zapcore.ObjectEncoder, string, interface{}) error {
+		return errors.New("fail")
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterTypeEncoder(t *testing.T) {
+	m := typeEncoderMoney{cents: 1234, currency: "USD"}
+	tests := []struct {
+		desc     string
+		field    Field
+		expected map[string]interface{}
+	}{
+		{"Any", Any("price", m), map[string]interface{}{"price": "12.34 USD"}},
+		{"Reflect", Reflect("price", m), map[string]interface{}{"price": "12.34 USD"}},
+		{"pointer", Any("price", &m), map[string]interface{}{"price": "not used"}},
+		{"Stringer", Stringer("price", m), map[string]interface{}{"price": "not used"}},
+		{"error", Any("failing", typeEncoderFailing{}), map[string]interface{}{"failingError": "fail"}},
+		{"unregistered", Any("other", struct{ A int }{1}), map[string]interface{}{"other": struct{ A int }{1}}},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		tt.field.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields, "Unexpected output with %s.", tt.desc)
+		assertCanBeReused(t, tt.field)
+	}
+}
+
+func TestRegisterTypeEncoderErrors(t *testing.T) {
+	noop := func(zapcore.ObjectEncoder, string, interface{}) error { return nil }
+	assert.Equal(t, errNoTypeSpecified, RegisterTypeEncoder(nil, noop), "Expected an error registering a nil type.")
+	err := RegisterTypeEncoder(reflect.TypeOf(typeEncoderMoney{}), noop)
+	require.Error(t, err, "Expected an error registering a type twice.")
+	assert.Contains(t, err.Error(), "already registered", "Unexpected error message.")
+}
+
+func TestTypeEncoderNil(t *testing.T) {
+	assert.Equal(t, Reflect("nil", nil), Any("nil", nil), "Expected nil values to be reflected.")
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"sort"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Validation constructs a field that records why a validation failed. The
+// errors are keyed by the name of the offending input and encoded as a nested
+// object, with the keys in sorted order so that the output is deterministic:
+//   {"age":"must be positive","email":"must not be empty"}
+// An empty or nil map is encoded as an empty object; use ValidationOmitEmpty
+// to drop the field entirely instead.
+func Validation(key string, errs map[string]string) Field {
+	return Object(key, validationErrors(errs))
+}
+
+// ValidationOmitEmpty is like Validation, but it returns a no-op field if
+// there are no validation errors.
+func ValidationOmitEmpty(key string, errs map[string]string) Field {
+	if len(errs) == 0 {
+		return Skip()
+	}
+	return Validation(key, errs)
+}
+
+type validationErrors map[string]string
+
+func (errs validationErrors) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		enc.AddString(name, errs[name])
+	}
+	return nil
+}
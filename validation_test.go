@@ -0,0 +1,97 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zap
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidation(t *testing.T) {
+	errs := map[string]string{
+		"name":  "too long",
+		"age":   "must be positive",
+		"email": "must not be empty",
+	}
+
+	tests := []struct {
+		desc     string
+		field    Field
+		expected map[string]interface{}
+	}{
+		{
+			desc:  "multiple errors",
+			field: Validation("invalid", errs),
+			expected: map[string]interface{}{"invalid": map[string]interface{}{
+				"name":  "too long",
+				"age":   "must be positive",
+				"email": "must not be empty",
+			}},
+		},
+		{
+			desc:     "empty",
+			field:    Validation("invalid", nil),
+			expected: map[string]interface{}{"invalid": map[string]interface{}{}},
+		},
+		{
+			desc:     "omit empty",
+			field:    ValidationOmitEmpty("invalid", map[string]string{}),
+			expected: map[string]interface{}{},
+		},
+		{
+			desc:     "omit empty with errors",
+			field:    ValidationOmitEmpty("invalid", map[string]string{"id": "unknown"}),
+			expected: map[string]interface{}{"invalid": map[string]interface{}{"id": "unknown"}},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		tt.field.AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields, "%s: unexpected encoded fields.", tt.desc)
+		assertCanBeReused(t, tt.field)
+	}
+}
+
+func TestValidationOrdering(t *testing.T) {
+	errs := map[string]string{
+		"zip":   "invalid",
+		"age":   "must be positive",
+		"name":  "too long",
+		"email": "must not be empty",
+	}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	for i := 0; i < 10; i++ {
+		buf, err := enc.EncodeEntry(zapcore.Entry{}, []Field{Validation("invalid", errs)})
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		assert.Equal(
+			t,
+			`{"invalid":{"age":"must be positive","email":"must not be empty","name":"too long","zip":"invalid"}}`+"\n",
+			buf.String(),
+			"Expected validation errors to be sorted by key.",
+		)
+		buf.Free()
+	}
+}
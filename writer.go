@@ -36,13 +36,18 @@ import (
 // any opened files.
 //
 // Passing no URLs returns a no-op WriteSyncer. Zap handles URLs without a
-// scheme and URLs with the "file" scheme. Third-party code may register
-// factories for other schemes using RegisterSink.
+// scheme and URLs with the "file", "tcp", and "udp" schemes. Third-party code
+// may register factories for other schemes using RegisterSink.
 //
 // URLs with the "file" scheme must use absolute paths on the local
 // filesystem. No user, password, port, fragments, or query parameters are
 // allowed, and the hostname must be empty or "localhost".
 //
+// URLs with the "tcp" and "udp" schemes (e.g., "tcp://collector:5170") write
+// to the given host and port using NetworkSink's defaults, so broken
+// connections are re-established and writes are spooled in the meantime. No
+// user, password, path, fragments, or query parameters are allowed.
+//
 // Since it's common to write logs to the local filesystem, URLs without a
 // scheme (e.g., "/var/log/foo.log") are treated as local file paths. Without
 // a scheme, the special paths "stdout" and "stderr" are interpreted as
@@ -55,16 +60,20 @@ func Open(paths ...string) (zapcore.WriteSyncer, func(), error) {
 	}
 
 	writer := CombineWriteSyncers(writers...)
-	return writer, close, nil
+	return writer, func() { close() }, nil
 }
 
-func open(paths []string) ([]zapcore.WriteSyncer, func(), error) {
+// open is like Open, but returns the individual sinks, and its close function
+// reports any errors from closing them.
+func open(paths []string) ([]zapcore.WriteSyncer, func() error, error) {
 	writers := make([]zapcore.WriteSyncer, 0, len(paths))
 	closers := make([]io.Closer, 0, len(paths))
-	close := func() {
+	close := func() error {
+		var err error
 		for _, c := range closers {
-			c.Close()
+			err = multierr.Append(err, c.Close())
 		}
+		return err
 	}
 
 	var openErr error
@@ -0,0 +1,209 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+	"go.uber.org/multierr"
+)
+
+const _defaultAsyncQueueSize = 1024
+
+// An OverflowPolicy tells an AsyncWriteSyncer what to do with a write when its
+// queue is full.
+type OverflowPolicy int
+
+const (
+	// BlockOnOverflow makes writes wait for room in the queue, so no output
+	// is lost.
+	BlockOnOverflow OverflowPolicy = iota
+	// DropOldestOnOverflow discards the oldest queued write to make room for
+	// the new one.
+	DropOldestOnOverflow
+	// DropNewestOnOverflow discards the new write, leaving the queue as is.
+	DropNewestOnOverflow
+)
+
+// An AsyncWriteSyncer is a WriteSyncer that hands writes off to a background
+// goroutine through a bounded queue, so that callers don't wait on slow disks
+// or network sinks. What happens when the queue is full is up to its
+// OverflowPolicy; the number of writes discarded is reported by Dropped.
+//
+// Since writes happen in the background, their errors are returned by the
+// next call to Sync or Stop. Sync waits until every queued write is done, and
+// since Loggers sync their Cores before exiting on Fatal and Panic entries,
+// those entries aren't lost.
+//
+// It's safe for concurrent use.
+type AsyncWriteSyncer struct {
+	mu sync.Mutex
+	// notEmpty is signaled when a write is queued or the AsyncWriteSyncer is
+	// stopped, and notFull whenever a queued write is done.
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	ws       WriteSyncer
+	policy   OverflowPolicy
+	// queue is a ring buffer of n writes, starting at head.
+	queue   [][]byte
+	head, n int
+	writing bool
+	err     error
+	stopped bool
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// NewAsyncWriteSyncer wraps a WriteSyncer so that writes happen in a
+// background goroutine, queueing at most size writes. A non-positive size
+// uses a default of 1024 writes.
+//
+// Call Stop to write out the queue and stop the background goroutine when the
+// AsyncWriteSyncer is no longer needed.
+func NewAsyncWriteSyncer(ws WriteSyncer, size int, policy OverflowPolicy) *AsyncWriteSyncer {
+	if size <= 0 {
+		size = _defaultAsyncQueueSize
+	}
+	s := &AsyncWriteSyncer{
+		ws:     ws,
+		policy: policy,
+		queue:  make([][]byte, size),
+		done:   make(chan struct{}),
+	}
+	s.notEmpty = sync.NewCond(&s.mu)
+	s.notFull = sync.NewCond(&s.mu)
+	go s.run()
+	return s
+}
+
+// Write queues a copy of p, so callers may reuse p as soon as Write returns.
+// It always reports writing all of p, even if the write is dropped. After
+// Stop, writes go straight to the underlying WriteSyncer.
+func (s *AsyncWriteSyncer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.n == len(s.queue) && !s.stopped {
+		switch s.policy {
+		case DropOldestOnOverflow:
+			s.queue[s.head] = nil
+			s.head = (s.head + 1) % len(s.queue)
+			s.n--
+			s.dropped.Inc()
+		case DropNewestOnOverflow:
+			s.dropped.Inc()
+			return len(p), nil
+		default:
+			s.notFull.Wait()
+		}
+	}
+
+	if s.stopped {
+		// Wait for the queue to drain, so that writes stay in order and the
+		// underlying WriteSyncer is never written to concurrently.
+		s.mu.Unlock()
+		<-s.done
+		s.mu.Lock()
+		return s.ws.Write(p)
+	}
+
+	s.queue[(s.head+s.n)%len(s.queue)] = append([]byte(nil), p...)
+	s.n++
+	s.notEmpty.Signal()
+	return len(p), nil
+}
+
+// Sync waits for the queued writes to finish, then syncs the underlying
+// WriteSyncer. It returns any errors from writes since the last Sync.
+func (s *AsyncWriteSyncer) Sync() error {
+	s.mu.Lock()
+	for (s.n > 0 || s.writing) && !s.stopped {
+		s.notFull.Wait()
+	}
+	s.mu.Unlock()
+	if s.isStopped() {
+		<-s.done
+	}
+	return multierr.Append(s.takeErr(), s.ws.Sync())
+}
+
+// Stop writes out the queued writes and stops the background goroutine.
+// Subsequent writes are synchronous. It returns any errors from writes since
+// the last Sync, but doesn't sync or close the underlying WriteSyncer.
+func (s *AsyncWriteSyncer) Stop() error {
+	s.mu.Lock()
+	s.stopped = true
+	s.notEmpty.Broadcast()
+	s.notFull.Broadcast()
+	s.mu.Unlock()
+	<-s.done
+	return s.takeErr()
+}
+
+// Dropped returns the number of writes discarded because the queue was full.
+func (s *AsyncWriteSyncer) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *AsyncWriteSyncer) run() {
+	defer close(s.done)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		for s.n == 0 && !s.stopped {
+			s.notEmpty.Wait()
+		}
+		if s.n == 0 {
+			// Stopped, and the queue is drained.
+			return
+		}
+
+		p := s.queue[s.head]
+		s.queue[s.head] = nil
+		s.head = (s.head + 1) % len(s.queue)
+		s.n--
+		s.writing = true
+		s.mu.Unlock()
+
+		_, err := s.ws.Write(p)
+
+		s.mu.Lock()
+		s.writing = false
+		s.err = multierr.Append(s.err, err)
+		s.notFull.Broadcast()
+	}
+}
+
+func (s *AsyncWriteSyncer) isStopped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopped
+}
+
+func (s *AsyncWriteSyncer) takeErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
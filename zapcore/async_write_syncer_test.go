@@ -0,0 +1,153 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatedSyncer records writes, but blocks them until it's opened. It reports
+// when the first write starts.
+type gatedSyncer struct {
+	recordingSyncer
+	once    sync.Once
+	started chan struct{}
+	open    chan struct{}
+}
+
+func newGatedSyncer() *gatedSyncer {
+	return &gatedSyncer{started: make(chan struct{}), open: make(chan struct{})}
+}
+
+func (s *gatedSyncer) Write(p []byte) (int, error) {
+	s.once.Do(func() { close(s.started) })
+	<-s.open
+	return s.recordingSyncer.Write(p)
+}
+
+func TestAsyncWriteSyncer(t *testing.T) {
+	ws := &recordingSyncer{}
+	async := NewAsyncWriteSyncer(ws, 0, BlockOnOverflow)
+	defer async.Stop()
+
+	p := []byte("foo")
+	writeString(t, async, string(p))
+	n, err := async.Write(p)
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, 3, n, "Unexpected number of bytes written.")
+	p[0] = 'b'
+	writeString(t, async, "baz")
+
+	require.NoError(t, async.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, []string{"foo", "foo", "baz"}, ws.Writes(), "Expected Sync to wait for queued writes.")
+	assert.True(t, ws.Called(), "Expected Sync to sync the underlying WriteSyncer.")
+	assert.Equal(t, uint64(0), async.Dropped(), "Expected no dropped writes.")
+}
+
+func TestAsyncWriteSyncerOverflow(t *testing.T) {
+	tests := []struct {
+		policy   OverflowPolicy
+		expected []string
+		dropped  uint64
+	}{
+		{BlockOnOverflow, []string{"a", "b", "c", "d", "e"}, 0},
+		{DropOldestOnOverflow, []string{"a", "d", "e"}, 2},
+		{DropNewestOnOverflow, []string{"a", "b", "c"}, 2},
+	}
+
+	for _, tt := range tests {
+		ws := newGatedSyncer()
+		async := NewAsyncWriteSyncer(ws, 2, tt.policy)
+
+		// Hold the first write in the background, then fill the queue.
+		writeString(t, async, "a")
+		<-ws.started
+		writeString(t, async, "b")
+		writeString(t, async, "c")
+
+		overflowed := make(chan struct{})
+		go func() {
+			defer close(overflowed)
+			writeString(t, async, "d")
+			writeString(t, async, "e")
+		}()
+		if tt.policy == BlockOnOverflow {
+			select {
+			case <-overflowed:
+				t.Errorf("Policy %v: expected writes to a full queue to block.", tt.policy)
+			case <-time.After(10 * time.Millisecond):
+			}
+		} else {
+			<-overflowed
+		}
+
+		close(ws.open)
+		<-overflowed
+		require.NoError(t, async.Sync(), "Policy %v: unexpected error syncing.", tt.policy)
+		assert.Equal(t, tt.expected, ws.Writes(), "Policy %v: unexpected writes.", tt.policy)
+		assert.Equal(t, tt.dropped, async.Dropped(), "Policy %v: unexpected number of dropped writes.", tt.policy)
+		require.NoError(t, async.Stop(), "Policy %v: unexpected error stopping.", tt.policy)
+	}
+}
+
+func TestAsyncWriteSyncerErrors(t *testing.T) {
+	ws := &ztest.FailWriter{}
+	async := NewAsyncWriteSyncer(ws, 0, BlockOnOverflow)
+
+	writeString(t, async, "foo")
+	assert.Error(t, async.Sync(), "Expected Sync to return background write errors.")
+	assert.NoError(t, async.Sync(), "Expected Sync to return each error once.")
+
+	writeString(t, async, "foo")
+	assert.Error(t, async.Stop(), "Expected Stop to return background write errors.")
+
+	ws.SetError(errors.New("failed"))
+	assert.Error(t, async.Sync(), "Expected Sync to return sync errors.")
+}
+
+func TestAsyncWriteSyncerStop(t *testing.T) {
+	ws := newGatedSyncer()
+	async := NewAsyncWriteSyncer(ws, 2, BlockOnOverflow)
+
+	writeString(t, async, "a")
+	<-ws.started
+	writeString(t, async, "b")
+
+	stopped := make(chan error)
+	go func() { stopped <- async.Stop() }()
+	close(ws.open)
+	require.NoError(t, <-stopped, "Unexpected error stopping.")
+	assert.Equal(t, []string{"a", "b"}, ws.Writes(), "Expected Stop to write out the queue.")
+
+	writeString(t, async, "c")
+	assert.Equal(t, []string{"a", "b", "c"}, ws.Writes(), "Expected writes after Stop to be synchronous.")
+	require.NoError(t, async.Sync(), "Unexpected error syncing after Stop.")
+	require.NoError(t, async.Stop(), "Expected stopping twice to be safe.")
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+)
+
+const (
+	_defaultBufferSize    = 256 * 1024
+	_defaultFlushInterval = 30 * time.Second
+)
+
+// A BufferedWriteSyncer is a WriteSyncer that buffers writes in memory,
+// trading a little latency for far fewer writes to the underlying
+// WriteSyncer. Buffered data is written when the buffer fills, when the
+// flush interval has elapsed since the first buffered write, and whenever
+// the BufferedWriteSyncer is synced. Since Loggers sync their Cores before
+// exiting on Fatal and Panic entries, those entries aren't lost.
+//
+// It's safe for concurrent use.
+type BufferedWriteSyncer struct {
+	mu       sync.Mutex
+	ws       WriteSyncer
+	size     int
+	interval time.Duration
+	clock    Clock
+	buf      []byte
+	// cancel cancels the pending flush, if any. Each scheduled flush
+	// remembers the generation it was scheduled in, so a flush that fires
+	// just as it's canceled doesn't clobber its successor.
+	cancel  func() bool
+	gen     uint64
+	stopped bool
+}
+
+// NewBufferedWriteSyncer wraps a WriteSyncer so that writes are buffered, up
+// to size bytes, for at most interval. Non-positive sizes and intervals use
+// defaults of 256 kB and 30 seconds. Writes at least as large as the buffer
+// bypass it.
+//
+// Flushes triggered by the interval happen in the background, so their
+// errors are dropped; errors from flushes triggered by Write or Sync are
+// returned as usual. Call Stop to flush the buffer and cancel any pending
+// flush when the BufferedWriteSyncer is no longer needed.
+func NewBufferedWriteSyncer(ws WriteSyncer, size int, interval time.Duration, clock Clock) *BufferedWriteSyncer {
+	if size <= 0 {
+		size = _defaultBufferSize
+	}
+	if interval <= 0 {
+		interval = _defaultFlushInterval
+	}
+	return &BufferedWriteSyncer{
+		ws:       ws,
+		size:     size,
+		interval: interval,
+		clock:    clock,
+		buf:      make([]byte, 0, size),
+	}
+}
+
+// Write buffers p, first writing out any buffered data that p wouldn't fit
+// alongside. After Stop, writes go straight to the underlying WriteSyncer.
+func (s *BufferedWriteSyncer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return s.ws.Write(p)
+	}
+
+	var err error
+	if len(s.buf)+len(p) > s.size {
+		err = s.flush()
+	}
+	if len(p) >= s.size {
+		n, werr := s.ws.Write(p)
+		return n, multierr.Append(err, werr)
+	}
+
+	s.buf = append(s.buf, p...)
+	if s.cancel == nil {
+		s.gen++
+		gen := s.gen
+		s.cancel = s.clock.AfterFunc(s.interval, func() { s.flushScheduled(gen) })
+	}
+	return len(p), err
+}
+
+// Sync writes out any buffered data and syncs the underlying WriteSyncer.
+func (s *BufferedWriteSyncer) Sync() error {
+	s.mu.Lock()
+	err := s.flush()
+	s.mu.Unlock()
+	return multierr.Append(err, s.ws.Sync())
+}
+
+// Stop writes out any buffered data and cancels the pending flush, if any.
+// Subsequent writes are unbuffered. It doesn't sync or close the underlying
+// WriteSyncer.
+func (s *BufferedWriteSyncer) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	return s.flush()
+}
+
+func (s *BufferedWriteSyncer) flushScheduled(gen uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen != gen || s.cancel == nil {
+		// The buffer was flushed since this flush was scheduled.
+		return
+	}
+	s.flush()
+}
+
+// flush writes out the buffer and cancels the pending flush. It must be
+// called with the lock held.
+func (s *BufferedWriteSyncer) flush() error {
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	if len(s.buf) == 0 {
+		return nil
+	}
+	_, err := s.ws.Write(s.buf)
+	s.buf = s.buf[:0]
+	return err
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSyncer records each write separately.
+type recordingSyncer struct {
+	sync.Mutex
+	ztest.Syncer
+	writes []string
+}
+
+func (s *recordingSyncer) Write(p []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.writes = append(s.writes, string(p))
+	return len(p), nil
+}
+
+func (s *recordingSyncer) Writes() []string {
+	s.Lock()
+	defer s.Unlock()
+	return append([]string(nil), s.writes...)
+}
+
+func writeString(t testing.TB, ws WriteSyncer, s string) {
+	n, err := ws.Write([]byte(s))
+	require.NoError(t, err, "Unexpected error writing %q.", s)
+	require.Equal(t, len(s), n, "Unexpected number of bytes written.")
+}
+
+func TestBufferedWriteSyncerFlushesWhenFull(t *testing.T) {
+	ws := &recordingSyncer{}
+	buf := NewBufferedWriteSyncer(ws, 8, time.Minute, &fakeClock{})
+
+	writeString(t, buf, "abc")
+	writeString(t, buf, "def")
+	assert.Empty(t, ws.Writes(), "Expected writes to be buffered.")
+
+	writeString(t, buf, "ghi")
+	assert.Equal(t, []string{"abcdef"}, ws.Writes(), "Expected the buffer to be flushed before it overflows.")
+
+	writeString(t, buf, "0123456789")
+	assert.Equal(t, []string{"abcdef", "ghi", "0123456789"}, ws.Writes(), "Expected large writes to bypass the buffer.")
+}
+
+func TestBufferedWriteSyncerFlushesOnInterval(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ws := &recordingSyncer{}
+	buf := NewBufferedWriteSyncer(ws, 1024, time.Second, clock)
+
+	writeString(t, buf, "a")
+	clock.Advance(500 * time.Millisecond)
+	writeString(t, buf, "b")
+	assert.Empty(t, ws.Writes(), "Expected writes to be buffered until the interval elapses.")
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, []string{"ab"}, ws.Writes(), "Expected a flush an interval after the first buffered write.")
+	assert.False(t, ws.Called(), "Expected a background flush not to sync.")
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, []string{"ab"}, ws.Writes(), "Expected no flushes while the buffer is empty.")
+
+	writeString(t, buf, "c")
+	clock.Advance(time.Second)
+	assert.Equal(t, []string{"ab", "c"}, ws.Writes(), "Expected each new write to schedule a flush.")
+}
+
+func TestBufferedWriteSyncerSync(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ws := &recordingSyncer{}
+	buf := NewBufferedWriteSyncer(ws, 1024, time.Second, clock)
+
+	writeString(t, buf, "a")
+	require.NoError(t, buf.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, []string{"a"}, ws.Writes(), "Expected Sync to flush the buffer.")
+	assert.True(t, ws.Called(), "Expected Sync to sync the underlying WriteSyncer.")
+
+	writeString(t, buf, "b")
+	clock.Advance(time.Second)
+	assert.Equal(t, []string{"a", "b"}, ws.Writes(), "Expected Sync not to disturb later flushes.")
+
+	ws.SetError(errors.New("fail"))
+	assert.Error(t, buf.Sync(), "Expected errors from the underlying Sync.")
+}
+
+func TestBufferedWriteSyncerStop(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ws := &recordingSyncer{}
+	buf := NewBufferedWriteSyncer(ws, 1024, time.Second, clock)
+
+	writeString(t, buf, "a")
+	require.NoError(t, buf.Stop(), "Unexpected error stopping.")
+	assert.Equal(t, []string{"a"}, ws.Writes(), "Expected Stop to flush the buffer.")
+
+	writeString(t, buf, "b")
+	assert.Equal(t, []string{"a", "b"}, ws.Writes(), "Expected writes after Stop to be unbuffered.")
+	clock.Advance(time.Minute)
+	assert.Equal(t, []string{"a", "b"}, ws.Writes(), "Expected Stop to cancel pending flushes.")
+}
+
+func TestBufferedWriteSyncerDefaults(t *testing.T) {
+	ws := &recordingSyncer{}
+	buf := NewBufferedWriteSyncer(ws, 0, 0, DefaultClock)
+	writeString(t, buf, "a")
+	assert.Empty(t, ws.Writes(), "Expected writes to be buffered by default.")
+	require.NoError(t, buf.Stop(), "Unexpected error stopping.")
+	assert.Equal(t, []string{"a"}, ws.Writes(), "Expected Stop to flush the buffer.")
+}
+
+func TestBufferedWriteSyncerConcurrent(t *testing.T) {
+	ws := &recordingSyncer{}
+	buf := NewBufferedWriteSyncer(ws, 64, time.Millisecond, DefaultClock)
+	core := NewCore(NewJSONEncoder(testEncoderConfig()), buf, DebugLevel)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				assert.NoError(t, core.Write(Entry{Message: "hello"}, nil), "Unexpected error writing.")
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	require.NoError(t, buf.Stop(), "Unexpected error stopping.")
+
+	line, err := NewJSONEncoder(testEncoderConfig()).EncodeEntry(Entry{Message: "hello"}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	var total int
+	for _, w := range ws.Writes() {
+		total += len(w)
+	}
+	assert.Equal(t, 200*line.Len(), total, "Expected every byte to be written exactly once.")
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"io"
+	"os"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/internal/bufferpool"
+)
+
+type colorEncoder struct {
+	Encoder
+	color func(Entry, []Field) (string, bool)
+}
+
+// NewColorEncoder wraps an Encoder so that entries can be highlighted in a
+// terminal. For each entry, the supplied function decides whether to color
+// the entry and returns the ANSI SGR parameters to use (e.g., "31" for red or
+// "1;33" for bold yellow). The function sees the fields passed at the log
+// site, but not any fields added via With.
+//
+// The whole encoded entry, excluding its trailing line ending, is wrapped in
+// the escape sequence; this includes any stacktrace. That's handy for
+// picking interesting entries out of a firehose of development logs, but
+// makes the output unsuitable for machine consumption. Since escape codes
+// are noise in files and log aggregators, only use this encoder when writing
+// to a terminal; IsTerminal offers a simple check.
+func NewColorEncoder(enc Encoder, color func(Entry, []Field) (string, bool)) Encoder {
+	return colorEncoder{
+		Encoder: enc,
+		color:   color,
+	}
+}
+
+func (c colorEncoder) Clone() Encoder {
+	return colorEncoder{
+		Encoder: c.Encoder.Clone(),
+		color:   c.color,
+	}
+}
+
+func (c colorEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	body, err := c.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	code, ok := c.color(ent, fields)
+	if !ok || code == "" {
+		return body, nil
+	}
+
+	bs := body.Bytes()
+	end := len(bs)
+	for end > 0 && (bs[end-1] == '\n' || bs[end-1] == '\r') {
+		end--
+	}
+
+	line := bufferpool.Get()
+	line.AppendString("\x1b[")
+	line.AppendString(code)
+	line.AppendByte('m')
+	line.Write(bs[:end])
+	line.AppendString("\x1b[0m")
+	line.Write(bs[end:])
+	body.Free()
+	return line, nil
+}
+
+// IsTerminal reports whether w is an *os.File connected to a terminal (or,
+// more precisely, to a character device). It's a best-effort check, meant
+// for deciding whether to emit color. It sees through Lock and
+// NewMultiWriteSyncer, reporting whether every wrapped writer is a terminal,
+// and accepts any writer with an *os.File's Stat method.
+func IsTerminal(w io.Writer) bool {
+	switch w := w.(type) {
+	case *lockedWriteSyncer:
+		return IsTerminal(w.ws)
+	case multiWriteSyncer:
+		for _, ws := range w {
+			if !IsTerminal(ws) {
+				return false
+			}
+		}
+		return len(w) > 0
+	case interface {
+		Stat() (os.FileInfo, error)
+	}:
+		info, err := w.Stat()
+		if err != nil {
+			return false
+		}
+		return info.Mode()&os.ModeCharDevice != 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestColorEncoder(t *testing.T) {
+	enc := NewColorEncoder(
+		NewConsoleEncoder(EncoderConfig{MessageKey: "M", LevelKey: "L", EncodeLevel: CapitalLevelEncoder}),
+		func(ent Entry, fields []Field) (string, bool) {
+			for _, f := range fields {
+				if f.Key == "user" && f.String == "alice" {
+					return "1;31", true
+				}
+			}
+			if ent.Level == WarnLevel {
+				// An empty code disables coloring.
+				return "", true
+			}
+			return "", false
+		},
+	)
+
+	tests := []struct {
+		desc     string
+		ent      Entry
+		fields   []Field
+		expected string
+	}{
+		{
+			desc:     "match",
+			ent:      Entry{Level: InfoLevel, Message: "hello"},
+			fields:   []Field{{Key: "user", Type: StringType, String: "alice"}},
+			expected: "\x1b[1;31mINFO\thello\t{\"user\": \"alice\"}\x1b[0m\n",
+		},
+		{
+			desc:     "no match",
+			ent:      Entry{Level: InfoLevel, Message: "hello"},
+			fields:   []Field{{Key: "user", Type: StringType, String: "bob"}},
+			expected: "INFO\thello\t{\"user\": \"bob\"}\n",
+		},
+		{
+			desc:     "empty code",
+			ent:      Entry{Level: WarnLevel, Message: "hello"},
+			expected: "WARN\thello\n",
+		},
+	}
+
+	for _, tt := range tests {
+		buf, err := enc.Clone().EncodeEntry(tt.ent, tt.fields)
+		require.NoError(t, err, "%s: unexpected error encoding entry.", tt.desc)
+		assert.Equal(t, tt.expected, buf.String(), "%s: unexpected output.", tt.desc)
+		buf.Free()
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := ioutil.TempFile("", "is-terminal")
+	require.NoError(t, err, "Failed to create temporary file.")
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	assert.False(t, IsTerminal(f), "Regular files aren't terminals.")
+	assert.False(t, IsTerminal(&bytes.Buffer{}), "Buffers aren't terminals.")
+
+	tty := &ztest.Terminal{}
+	assert.True(t, IsTerminal(tty), "Expected writers with a character device's Stat method to be terminals.")
+	assert.True(t, IsTerminal(Lock(tty)), "Expected IsTerminal to see through Lock.")
+	assert.True(t, IsTerminal(NewMultiWriteSyncer(tty, &ztest.Terminal{})), "Expected several terminals to be terminals.")
+	assert.False(t, IsTerminal(NewMultiWriteSyncer(tty, f)), "Expected a terminal and a file not to be a terminal.")
+	assert.False(t, IsTerminal(NewMultiWriteSyncer()), "Expected no writers not to be a terminal.")
+}
@@ -25,7 +25,6 @@ import (
 	"sync"
 
 	"go.uber.org/zap/buffer"
-	"go.uber.org/zap/internal/bufferpool"
 )
 
 var _sliceEncoderPool = sync.Pool{
@@ -64,8 +63,31 @@ func (c consoleEncoder) Clone() Encoder {
 }
 
 func (c consoleEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
-	line := bufferpool.Get()
+	line := c.getBuffer()
 
+	appendConsoleMetadata(c.EncoderConfig, line, ent)
+
+	// Add any structured context.
+	c.writeContext(line, fields)
+
+	// If there's no stacktrace key, honor that; this allows users to force
+	// single-line output.
+	if ent.Stack != "" && c.StacktraceKey != "" {
+		line.AppendByte('\n')
+		line.AppendString(ent.Stack)
+	}
+
+	if c.LineEnding != "" {
+		line.AppendString(c.LineEnding)
+	} else {
+		line.AppendString(DefaultLineEnding)
+	}
+	return line, nil
+}
+
+// appendConsoleMetadata writes the entry's metadata and message to line in
+// the console encoder's plain-text format, separated by tabs.
+func appendConsoleMetadata(cfg *EncoderConfig, line *buffer.Buffer, ent Entry) {
 	// We don't want the entry's metadata to be quoted and escaped (if it's
 	// encoded as strings), which means that we can't use the JSON encoder. The
 	// simplest option is to use the memory encoder and fmt.Fprint.
@@ -73,14 +95,14 @@ func (c consoleEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer,
 	// If this ever becomes a performance bottleneck, we can implement
 	// ArrayEncoder for our plain-text format.
 	arr := getSliceEncoder()
-	if c.TimeKey != "" && c.EncodeTime != nil {
-		c.EncodeTime(ent.Time, arr)
+	if cfg.TimeKey != "" && cfg.EncodeTime != nil {
+		cfg.EncodeTime(ent.Time, arr)
 	}
-	if c.LevelKey != "" && c.EncodeLevel != nil {
-		c.EncodeLevel(ent.Level, arr)
+	if cfg.LevelKey != "" && cfg.EncodeLevel != nil {
+		cfg.EncodeLevel(ent.Level, arr)
 	}
-	if ent.LoggerName != "" && c.NameKey != "" {
-		nameEncoder := c.EncodeName
+	if ent.LoggerName != "" && cfg.NameKey != "" {
+		nameEncoder := cfg.EncodeName
 
 		if nameEncoder == nil {
 			// Fall back to FullNameEncoder for backward compatibility.
@@ -89,8 +111,13 @@ func (c consoleEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer,
 
 		nameEncoder(ent.LoggerName, arr)
 	}
-	if ent.Caller.Defined && c.CallerKey != "" && c.EncodeCaller != nil {
-		c.EncodeCaller(ent.Caller, arr)
+	if ent.Caller.Defined && cfg.CallerKey != "" && cfg.EncodeCaller != nil {
+		cfg.EncodeCaller(ent.Caller, arr)
+	}
+	if ent.Caller.Defined && cfg.FunctionKey != "" {
+		if fn := ent.Caller.Function(); fn != "" {
+			arr.AppendString(fn)
+		}
 	}
 	for i := range arr.elems {
 		if i > 0 {
@@ -101,27 +128,12 @@ func (c consoleEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer,
 	putSliceEncoder(arr)
 
 	// Add the message itself.
-	if c.MessageKey != "" {
-		c.addTabIfNecessary(line)
+	if cfg.MessageKey != "" {
+		if line.Len() > 0 {
+			line.AppendByte('\t')
+		}
 		line.AppendString(ent.Message)
 	}
-
-	// Add any structured context.
-	c.writeContext(line, fields)
-
-	// If there's no stacktrace key, honor that; this allows users to force
-	// single-line output.
-	if ent.Stack != "" && c.StacktraceKey != "" {
-		line.AppendByte('\n')
-		line.AppendString(ent.Stack)
-	}
-
-	if c.LineEnding != "" {
-		line.AppendString(c.LineEnding)
-	} else {
-		line.AppendString(DefaultLineEnding)
-	}
-	return line, nil
 }
 
 func (c consoleEncoder) writeContext(line *buffer.Buffer, extra []Field) {
@@ -20,6 +20,8 @@
 
 package zapcore
 
+import "io"
+
 // Core is a minimal, fast logger interface. It's designed for library authors
 // to wrap in a more user-friendly API.
 type Core interface {
@@ -87,7 +89,11 @@ func (c *ioCore) Write(ent Entry, fields []Field) error {
 	if err != nil {
 		return err
 	}
-	_, err = c.out.Write(buf.Bytes())
+	n, err := c.out.Write(buf.Bytes())
+	if err == nil && n < buf.Len() {
+		// A short write without an error is still a failure.
+		err = io.ErrShortWrite
+	}
 	buf.Free()
 	if err != nil {
 		return err
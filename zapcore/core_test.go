@@ -21,9 +21,13 @@
 package zapcore_test
 
 import (
+	"encoding/json"
 	"errors"
+	"io"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -105,6 +109,43 @@ func TestIOCore(t *testing.T) {
 	)
 }
 
+func TestIOCoreConcurrentChildren(t *testing.T) {
+	// Children created via With clone the parent's encoder, so concurrent
+	// children must never share a buffer. Run with -race to check.
+	const children, writes = 10, 50
+
+	buf := &ztest.Buffer{}
+	parent := NewCore(NewJSONEncoder(EncoderConfig{MessageKey: "msg"}), Lock(buf), DebugLevel).
+		With([]Field{makeStringField("parent", "p")})
+
+	var wg sync.WaitGroup
+	for i := 0; i < children; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			child := parent.With([]Field{makeInt64Field("child", i)})
+			for j := 0; j < writes; j++ {
+				grandchild := child.With([]Field{makeInt64Field("write", j)})
+				require.NoError(t, grandchild.Write(Entry{Message: strconv.Itoa(i)}, nil), "Unexpected error writing.")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	lines := buf.Lines()
+	require.Equal(t, children*writes, len(lines), "Unexpected number of lines.")
+	for _, line := range lines {
+		var entry struct {
+			Msg    string `json:"msg"`
+			Parent string `json:"parent"`
+			Child  int    `json:"child"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), "Malformed line %q.", line)
+		assert.Equal(t, "p", entry.Parent, "Expected the parent's context in %q.", line)
+		assert.Equal(t, entry.Msg, strconv.Itoa(entry.Child), "Expected only the writing child's context in %q.", line)
+	}
+}
+
 func TestIOCoreSyncFail(t *testing.T) {
 	sink := &ztest.Discarder{}
 	err := errors.New("failed")
@@ -161,3 +202,21 @@ func TestIOCoreWriteFailure(t *testing.T) {
 	// Should log the error.
 	assert.Error(t, err, "Expected writing Entry to fail.")
 }
+
+func TestIOCoreShortWrite(t *testing.T) {
+	errSink := &ztest.Buffer{}
+	core := NewCore(
+		NewJSONEncoder(testEncoderConfig()),
+		&ztest.ShortWriter{},
+		DebugLevel,
+	)
+	assert.Equal(t, io.ErrShortWrite, core.Write(Entry{}, nil), "Expected a short write to fail.")
+
+	before := WriteErrors()
+	ce := core.Check(Entry{Level: InfoLevel, Message: "short"}, nil)
+	require.NotNil(t, ce, "Expected the entry to be checked.")
+	ce.ErrorOutput = errSink
+	ce.Write()
+	assert.Equal(t, before+1, WriteErrors(), "Expected short writes to be counted.")
+	assert.Contains(t, errSink.String(), io.ErrShortWrite.Error(), "Expected short writes to be reported.")
+}
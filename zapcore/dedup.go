@@ -0,0 +1,130 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+type dedupCore struct {
+	Core
+	// context holds the fields added via With. They can't be serialized
+	// eagerly, since a later field may replace them.
+	context []Field
+}
+
+// NewDedupCore wraps a Core so that each entry has at most one field with any
+// given key: when several fields share a key, only the last one is written, in
+// its original position. Fields added via With and fields passed at the log
+// site are considered together, so a log site can override the context.
+//
+// Keys are compared within a namespace; fields in different namespaces never
+// replace each other. Namespaces, skipped fields, and inline marshalers
+// without a key have no key of their own and are always kept. Inline
+// marshalers with a key, like the fields built by zap.Lazy, are compared by
+// that key, and replacing one drops everything it would have written.
+//
+// Deduplicating isn't free. Since context fields may be replaced, they're
+// kept unserialized and encoded anew with every entry, which the wrapped Core
+// would otherwise do only once; checking for duplicate keys adds a scan of
+// the fields. Only when there's no context and no duplicate does an entry cost
+// no additional allocations. Context added to the wrapped Core before it's
+// wrapped is already serialized, so it can't be deduplicated.
+func NewDedupCore(core Core) Core {
+	return &dedupCore{Core: core}
+}
+
+func (c *dedupCore) With(fields []Field) Core {
+	context := make([]Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+	return &dedupCore{
+		Core:    c.Core,
+		context: dedupFields(context),
+	}
+}
+
+func (c *dedupCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkRewritingFields(c.Core, ent, ce, c.rewrite)
+}
+
+func (c *dedupCore) Write(ent Entry, fields []Field) error {
+	return c.Core.Write(ent, c.rewrite(ent, fields))
+}
+
+func (c *dedupCore) rewrite(_ Entry, fields []Field) []Field {
+	if len(c.context) == 0 {
+		return dedupFields(fields)
+	}
+	// Copy the fields so that we never write into the caller's backing array.
+	all := make([]Field, 0, len(c.context)+len(fields))
+	all = append(all, c.context...)
+	all = append(all, fields...)
+	return dedupFields(all)
+}
+
+// dedupFields returns the fields, less any that are shadowed by a later field
+// with the same key. If no fields are shadowed, it returns its input.
+func dedupFields(fields []Field) []Field {
+	first := -1
+	for i := range fields {
+		if shadowed(fields, i) {
+			first = i
+			break
+		}
+	}
+	if first < 0 {
+		return fields
+	}
+
+	out := make([]Field, 0, len(fields)-1)
+	out = append(out, fields[:first]...)
+	for i := first + 1; i < len(fields); i++ {
+		if !shadowed(fields, i) {
+			out = append(out, fields[i])
+		}
+	}
+	return out
+}
+
+// shadowed reports whether a later field in the same namespace shares the key
+// of fields[i].
+func shadowed(fields []Field, i int) bool {
+	if !hasKey(fields[i]) {
+		return false
+	}
+	for j := i + 1; j < len(fields); j++ {
+		if fields[j].Type == NamespaceType {
+			return false
+		}
+		if hasKey(fields[j]) && fields[j].Key == fields[i].Key {
+			return true
+		}
+	}
+	return false
+}
+
+func hasKey(f Field) bool {
+	switch f.Type {
+	case NamespaceType, SkipType:
+		return false
+	case InlineMarshalerType:
+		return f.Key != ""
+	default:
+		return true
+	}
+}
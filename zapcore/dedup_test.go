@@ -0,0 +1,116 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupCore(t *testing.T) {
+	ns := Field{Key: "ns", Type: NamespaceType}
+	skip := Field{Type: SkipType}
+	inline := Field{Type: InlineMarshalerType, Interface: users(1)}
+	keyedInline := Field{Key: "a", Type: InlineMarshalerType, Interface: users(2)}
+
+	tests := []struct {
+		desc     string
+		context  []Field
+		fields   []Field
+		expected []Field
+	}{
+		{
+			desc:     "no duplicates",
+			context:  []Field{makeInt64Field("a", 1)},
+			fields:   []Field{makeInt64Field("b", 2)},
+			expected: []Field{makeInt64Field("a", 1), makeInt64Field("b", 2)},
+		},
+		{
+			desc:     "duplicates at the log site",
+			fields:   []Field{makeInt64Field("a", 1), makeInt64Field("b", 2), makeStringField("a", "last")},
+			expected: []Field{makeInt64Field("b", 2), makeStringField("a", "last")},
+		},
+		{
+			desc:     "log site overrides context",
+			context:  []Field{makeInt64Field("a", 1), makeInt64Field("b", 2)},
+			fields:   []Field{makeInt64Field("a", 3)},
+			expected: []Field{makeInt64Field("b", 2), makeInt64Field("a", 3)},
+		},
+		{
+			desc:     "namespaces separate keys",
+			context:  []Field{makeInt64Field("a", 1), ns},
+			fields:   []Field{makeInt64Field("a", 2), makeInt64Field("a", 3)},
+			expected: []Field{makeInt64Field("a", 1), ns, makeInt64Field("a", 3)},
+		},
+		{
+			desc:     "keyless fields are kept",
+			fields:   []Field{skip, skip, inline, inline, makeInt64Field("a", 1)},
+			expected: []Field{skip, skip, inline, inline, makeInt64Field("a", 1)},
+		},
+		{
+			desc:     "keyed inline marshalers",
+			context:  []Field{makeInt64Field("a", 1), keyedInline},
+			fields:   []Field{makeInt64Field("b", 2)},
+			expected: []Field{keyedInline, makeInt64Field("b", 2)},
+		},
+		{
+			desc:     "keyed inline marshalers are replaced",
+			context:  []Field{keyedInline},
+			fields:   []Field{makeInt64Field("a", 3)},
+			expected: []Field{makeInt64Field("a", 3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fac, logs := observer.New(DebugLevel)
+			core := NewDedupCore(fac).With(tt.context)
+			ce := core.Check(Entry{Level: InfoLevel}, nil)
+			require.NotNil(t, ce, "Expected the entry to be logged.")
+			ce.Write(tt.fields...)
+
+			require.Equal(t, 1, logs.Len(), "Expected exactly one entry.")
+			assert.Equal(t, tt.expected, logs.AllUntimed()[0].Context, "Unexpected fields.")
+		})
+	}
+}
+
+func TestDedupCoreWithDuplicateContext(t *testing.T) {
+	buf := &ztest.Buffer{}
+	core := NewDedupCore(NewCore(NewJSONEncoder(EncoderConfig{MessageKey: "msg"}), buf, DebugLevel))
+	core = core.With([]Field{makeInt64Field("a", 1)}).With([]Field{makeInt64Field("a", 2)})
+	require.NoError(t, core.Write(Entry{Message: "foo"}, []Field{makeInt64Field("b", 3)}), "Unexpected error writing.")
+	assert.Equal(t, `{"msg":"foo","a":2,"b":3}`, buf.Stripped(), "Unexpected output.")
+}
+
+func TestDedupCoreDoesNotModifyFields(t *testing.T) {
+	fac, _ := observer.New(DebugLevel)
+	core := NewDedupCore(fac)
+	fields := []Field{makeInt64Field("a", 1), makeInt64Field("a", 2)}
+	require.NoError(t, core.Write(Entry{}, fields), "Unexpected error writing.")
+	assert.Equal(t, []Field{makeInt64Field("a", 1), makeInt64Field("a", 2)}, fields, "Expected the caller's fields to be unchanged.")
+}
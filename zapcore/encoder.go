@@ -21,9 +21,12 @@
 package zapcore
 
 import (
+	"math"
+	"strings"
 	"time"
 
 	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/internal/color"
 )
 
 // DefaultLineEnding defines the default line ending when writing logs.
@@ -66,10 +69,99 @@ func CapitalColorLevelEncoder(l Level, enc PrimitiveArrayEncoder) {
 	enc.AppendString(s)
 }
 
+// ColorLevels wraps a LevelEncoder so that the level strings it produces are
+// colored for display in a terminal, using the same colors as
+// CapitalColorLevelEncoder. For example, wrapping CapitalLevelEncoder
+// produces the same output as CapitalColorLevelEncoder. Levels encoded as
+// anything other than strings aren't colored, and wrapping a nil LevelEncoder
+// returns nil.
+//
+// Since the escape codes are noise anywhere but a terminal, this is best
+// paired with a check like IsTerminal.
+func ColorLevels(enc LevelEncoder) LevelEncoder {
+	if enc == nil {
+		return nil
+	}
+	return func(l Level, arr PrimitiveArrayEncoder) {
+		c, ok := _levelToColor[l]
+		if !ok {
+			c = _unknownLevelColor
+		}
+		enc(l, coloringArrayEncoder{arr, c})
+	}
+}
+
+type coloringArrayEncoder struct {
+	PrimitiveArrayEncoder
+	color color.Color
+}
+
+func (enc coloringArrayEncoder) AppendString(s string) {
+	enc.PrimitiveArrayEncoder.AppendString(enc.color.Add(s))
+}
+
+func (enc coloringArrayEncoder) AppendByteString(s []byte) {
+	enc.PrimitiveArrayEncoder.AppendString(enc.color.Add(string(s)))
+}
+
+// SeverityLevelEncoder serializes a Level to an integer syslog severity, as
+// defined by RFC 5424. For example, InfoLevel is serialized to 6
+// (informational) and ErrorLevel to 3 (error). DPanicLevel and the levels
+// above it are all serialized to 2 (critical).
+func SeverityLevelEncoder(l Level, enc PrimitiveArrayEncoder) {
+	enc.AppendInt(SyslogSeverity(l))
+}
+
+// SyslogSeverity returns the syslog severity that SeverityLevelEncoder
+// serializes a Level to.
+func SyslogSeverity(l Level) int {
+	switch l {
+	case DebugLevel:
+		return 7
+	case InfoLevel:
+		return 6
+	case WarnLevel:
+		return 4
+	case ErrorLevel:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// CloudLoggingLevelEncoder serializes a Level to one of the severity names
+// understood by Google Cloud Logging. DebugLevel, InfoLevel, and ErrorLevel
+// keep their names, WarnLevel is serialized to "WARNING", and DPanicLevel,
+// PanicLevel, and FatalLevel are serialized to "CRITICAL", "ALERT", and
+// "EMERGENCY", respectively. Unknown levels are serialized to "DEFAULT".
+func CloudLoggingLevelEncoder(l Level, enc PrimitiveArrayEncoder) {
+	switch l {
+	case DebugLevel:
+		enc.AppendString("DEBUG")
+	case InfoLevel:
+		enc.AppendString("INFO")
+	case WarnLevel:
+		enc.AppendString("WARNING")
+	case ErrorLevel:
+		enc.AppendString("ERROR")
+	case DPanicLevel:
+		enc.AppendString("CRITICAL")
+	case PanicLevel:
+		enc.AppendString("ALERT")
+	case FatalLevel:
+		enc.AppendString("EMERGENCY")
+	default:
+		enc.AppendString("DEFAULT")
+	}
+}
+
 // UnmarshalText unmarshals text to a LevelEncoder. "capital" is unmarshaled to
-// CapitalLevelEncoder, "coloredCapital" is unmarshaled to CapitalColorLevelEncoder,
-// "colored" is unmarshaled to LowercaseColorLevelEncoder, and anything else
-// is unmarshaled to LowercaseLevelEncoder.
+// CapitalLevelEncoder, "capitalColor" is unmarshaled to
+// CapitalColorLevelEncoder, "color" is unmarshaled to
+// LowercaseColorLevelEncoder, "severity" is unmarshaled to
+// SeverityLevelEncoder, "cloudLogging" is unmarshaled to
+// CloudLoggingLevelEncoder, and anything else is unmarshaled to
+// LowercaseLevelEncoder.
 func (e *LevelEncoder) UnmarshalText(text []byte) error {
 	switch string(text) {
 	case "capital":
@@ -78,6 +170,10 @@ func (e *LevelEncoder) UnmarshalText(text []byte) error {
 		*e = CapitalColorLevelEncoder
 	case "color":
 		*e = LowercaseColorLevelEncoder
+	case "severity":
+		*e = SeverityLevelEncoder
+	case "cloudLogging":
+		*e = CloudLoggingLevelEncoder
 	default:
 		*e = LowercaseLevelEncoder
 	}
@@ -87,26 +183,38 @@ func (e *LevelEncoder) UnmarshalText(text []byte) error {
 // A TimeEncoder serializes a time.Time to a primitive type.
 type TimeEncoder func(time.Time, PrimitiveArrayEncoder)
 
+var (
+	_minTimeNanos = time.Unix(0, math.MinInt64)
+	_maxTimeNanos = time.Unix(0, math.MaxInt64)
+)
+
 // EpochTimeEncoder serializes a time.Time to a floating-point number of seconds
 // since the Unix epoch.
 func EpochTimeEncoder(t time.Time, enc PrimitiveArrayEncoder) {
-	nanos := t.UnixNano()
-	sec := float64(nanos) / float64(time.Second)
+	sec := float64(t.Unix()) + float64(t.Nanosecond())/float64(time.Second)
 	enc.AppendFloat64(sec)
 }
 
 // EpochMillisTimeEncoder serializes a time.Time to a floating-point number of
 // milliseconds since the Unix epoch.
 func EpochMillisTimeEncoder(t time.Time, enc PrimitiveArrayEncoder) {
-	nanos := t.UnixNano()
-	millis := float64(nanos) / float64(time.Millisecond)
+	millis := float64(t.Unix())*1e3 + float64(t.Nanosecond())/float64(time.Millisecond)
 	enc.AppendFloat64(millis)
 }
 
 // EpochNanosTimeEncoder serializes a time.Time to an integer number of
-// nanoseconds since the Unix epoch.
+// nanoseconds since the Unix epoch. Since an int64 of nanoseconds only spans
+// the years 1678 to 2262, times outside that range (including the zero
+// time.Time) saturate to the smallest or largest int64.
 func EpochNanosTimeEncoder(t time.Time, enc PrimitiveArrayEncoder) {
-	enc.AppendInt64(t.UnixNano())
+	switch {
+	case t.Before(_minTimeNanos):
+		enc.AppendInt64(math.MinInt64)
+	case t.After(_maxTimeNanos):
+		enc.AppendInt64(math.MaxInt64)
+	default:
+		enc.AppendInt64(t.UnixNano())
+	}
 }
 
 // ISO8601TimeEncoder serializes a time.Time to an ISO8601-formatted string
@@ -115,13 +223,40 @@ func ISO8601TimeEncoder(t time.Time, enc PrimitiveArrayEncoder) {
 	enc.AppendString(t.Format("2006-01-02T15:04:05.000Z0700"))
 }
 
+// RFC3339TimeEncoder serializes a time.Time to an RFC3339-formatted string.
+func RFC3339TimeEncoder(t time.Time, enc PrimitiveArrayEncoder) {
+	enc.AppendString(t.Format(time.RFC3339))
+}
+
+// RFC3339NanoTimeEncoder serializes a time.Time to an RFC3339-formatted string
+// with nanosecond precision. Trailing zeros are dropped from the fractional
+// seconds.
+func RFC3339NanoTimeEncoder(t time.Time, enc PrimitiveArrayEncoder) {
+	enc.AppendString(t.Format(time.RFC3339Nano))
+}
+
+// TimeEncoderOfLayout returns a TimeEncoder which serializes a time.Time
+// using the given layout, in the format accepted by time.Time.Format.
+func TimeEncoderOfLayout(layout string) TimeEncoder {
+	return func(t time.Time, enc PrimitiveArrayEncoder) {
+		enc.AppendString(t.Format(layout))
+	}
+}
+
 // UnmarshalText unmarshals text to a TimeEncoder. "iso8601" and "ISO8601" are
-// unmarshaled to ISO8601TimeEncoder, "millis" is unmarshaled to
-// EpochMillisTimeEncoder, and anything else is unmarshaled to EpochTimeEncoder.
+// unmarshaled to ISO8601TimeEncoder, "rfc3339" and "RFC3339" are unmarshaled
+// to RFC3339TimeEncoder, "rfc3339nano" and "RFC3339Nano" are unmarshaled to
+// RFC3339NanoTimeEncoder, "millis" is unmarshaled to EpochMillisTimeEncoder,
+// "nanos" is unmarshaled to EpochNanosTimeEncoder, and anything else is
+// unmarshaled to EpochTimeEncoder.
 func (e *TimeEncoder) UnmarshalText(text []byte) error {
 	switch string(text) {
 	case "iso8601", "ISO8601":
 		*e = ISO8601TimeEncoder
+	case "rfc3339", "RFC3339":
+		*e = RFC3339TimeEncoder
+	case "rfc3339nano", "RFC3339Nano":
+		*e = RFC3339NanoTimeEncoder
 	case "millis":
 		*e = EpochMillisTimeEncoder
 	case "nanos":
@@ -146,6 +281,12 @@ func NanosDurationEncoder(d time.Duration, enc PrimitiveArrayEncoder) {
 	enc.AppendInt64(int64(d))
 }
 
+// MillisDurationEncoder serializes a time.Duration to an integer number of
+// milliseconds elapsed, truncating any remainder.
+func MillisDurationEncoder(d time.Duration, enc PrimitiveArrayEncoder) {
+	enc.AppendInt64(int64(d / time.Millisecond))
+}
+
 // StringDurationEncoder serializes a time.Duration using its built-in String
 // method.
 func StringDurationEncoder(d time.Duration, enc PrimitiveArrayEncoder) {
@@ -153,14 +294,17 @@ func StringDurationEncoder(d time.Duration, enc PrimitiveArrayEncoder) {
 }
 
 // UnmarshalText unmarshals text to a DurationEncoder. "string" is unmarshaled
-// to StringDurationEncoder, and anything else is unmarshaled to
-// NanosDurationEncoder.
+// to StringDurationEncoder, "nanos" is unmarshaled to NanosDurationEncoder,
+// "ms" and "millis" are unmarshaled to MillisDurationEncoder, and anything
+// else (including "seconds") is unmarshaled to SecondsDurationEncoder.
 func (e *DurationEncoder) UnmarshalText(text []byte) error {
 	switch string(text) {
 	case "string":
 		*e = StringDurationEncoder
 	case "nanos":
 		*e = NanosDurationEncoder
+	case "ms", "millis":
+		*e = MillisDurationEncoder
 	default:
 		*e = SecondsDurationEncoder
 	}
@@ -205,6 +349,15 @@ func FullNameEncoder(loggerName string, enc PrimitiveArrayEncoder) {
 	enc.AppendString(loggerName)
 }
 
+// SeparatedNameEncoder returns a NameEncoder that serializes the logger name
+// with each period replaced by sep, so that a Logger named "http" and then
+// "router" is encoded as "http/router" with a sep of "/".
+func SeparatedNameEncoder(sep string) NameEncoder {
+	return func(loggerName string, enc PrimitiveArrayEncoder) {
+		enc.AppendString(strings.Replace(loggerName, ".", sep, -1))
+	}
+}
+
 // UnmarshalText unmarshals text to a NameEncoder. Currently, everything is
 // unmarshaled to FullNameEncoder.
 func (e *NameEncoder) UnmarshalText(text []byte) error {
@@ -227,6 +380,7 @@ type EncoderConfig struct {
 	TimeKey       string `json:"timeKey" yaml:"timeKey"`
 	NameKey       string `json:"nameKey" yaml:"nameKey"`
 	CallerKey     string `json:"callerKey" yaml:"callerKey"`
+	FunctionKey   string `json:"functionKey" yaml:"functionKey"`
 	StacktraceKey string `json:"stacktraceKey" yaml:"stacktraceKey"`
 	LineEnding    string `json:"lineEnding" yaml:"lineEnding"`
 	// Configure the primitive representations of common complex types. For
@@ -239,6 +393,41 @@ type EncoderConfig struct {
 	// Unlike the other primitive type encoders, EncodeName is optional. The
 	// zero value falls back to FullNameEncoder.
 	EncodeName NameEncoder `json:"nameEncoder" yaml:"nameEncoder"`
+	// BufferPool, if set, supplies the buffers that the JSON and console
+	// encoders encode entries into, rather than zap's shared pool. A pool
+	// sized for the application's entries, created with buffer.NewSizedPool,
+	// avoids repeatedly growing buffers for large entries. Other encoders
+	// ignore it.
+	BufferPool *buffer.Pool `json:"-" yaml:"-"`
+	// FlagEvalKeys names the keys of the objects logged by zap.FlagEval and
+	// zap.NamedFlagEval.
+	FlagEvalKeys FlagEvalKeys `json:"flagEvalKeys" yaml:"flagEvalKeys"`
+}
+
+// FlagEvalKeys names the keys of a logged feature flag evaluation. Empty keys
+// fall back to "flag", "variant", and "reason".
+type FlagEvalKeys struct {
+	FlagKey    string `json:"flagKey" yaml:"flagKey"`
+	VariantKey string `json:"variantKey" yaml:"variantKey"`
+	ReasonKey  string `json:"reasonKey" yaml:"reasonKey"`
+}
+
+// EncoderConfigOf returns the configuration of the encoder that's marshaling
+// an object, which lets ObjectMarshalers honor encoder options. It reports
+// false if enc isn't one of zapcore's encoders (for example, when marshaling
+// into a MapObjectEncoder), in which case marshalers should use their
+// defaults.
+func EncoderConfigOf(enc ObjectEncoder) (*EncoderConfig, bool) {
+	var cfg *EncoderConfig
+	switch e := enc.(type) {
+	case *jsonEncoder:
+		cfg = e.EncoderConfig
+	case *msgpackEncoder:
+		cfg = e.EncoderConfig
+	case *textEncoder:
+		cfg = e.EncoderConfig
+	}
+	return cfg, cfg != nil
 }
 
 // ObjectEncoder is a strongly-typed, encoding-agnostic interface for adding a
@@ -339,7 +528,10 @@ type Encoder interface {
 	ObjectEncoder
 
 	// Clone copies the encoder, ensuring that adding fields to the copy doesn't
-	// affect the original.
+	// affect the original. The copy carries the original's configuration and
+	// accumulated context, but must not share any buffers with it: Cores
+	// clone their encoders in With, and parents and children may then be used
+	// concurrently.
 	Clone() Encoder
 
 	// EncodeEntry encodes an entry and fields, along with any accumulated
@@ -21,6 +21,8 @@
 package zapcore_test
 
 import (
+	"math"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -512,6 +514,8 @@ func TestLevelEncoders(t *testing.T) {
 	}{
 		{"capital", "INFO"},
 		{"lower", "info"},
+		{"severity", 6},
+		{"cloudLogging", "INFO"},
 		{"", "info"},
 		{"something-random", "info"},
 	}
@@ -528,6 +532,85 @@ func TestLevelEncoders(t *testing.T) {
 	}
 }
 
+func TestSeverityLevelEncoder(t *testing.T) {
+	expected := map[Level]int{
+		DebugLevel:  7,
+		InfoLevel:   6,
+		WarnLevel:   4,
+		ErrorLevel:  3,
+		DPanicLevel: 2,
+		PanicLevel:  2,
+		FatalLevel:  2,
+	}
+	for lvl, severity := range expected {
+		assertAppended(
+			t,
+			severity,
+			func(arr ArrayEncoder) { SeverityLevelEncoder(lvl, arr) },
+			"Unexpected severity for %v.", lvl,
+		)
+		assert.Equal(t, severity, SyslogSeverity(lvl), "Unexpected syslog severity for %v.", lvl)
+	}
+}
+
+func TestCloudLoggingLevelEncoder(t *testing.T) {
+	expected := map[Level]string{
+		DebugLevel:  "DEBUG",
+		InfoLevel:   "INFO",
+		WarnLevel:   "WARNING",
+		ErrorLevel:  "ERROR",
+		DPanicLevel: "CRITICAL",
+		PanicLevel:  "ALERT",
+		FatalLevel:  "EMERGENCY",
+		Level(42):   "DEFAULT",
+	}
+	for lvl, severity := range expected {
+		assertAppended(
+			t,
+			severity,
+			func(arr ArrayEncoder) { CloudLoggingLevelEncoder(lvl, arr) },
+			"Unexpected severity for %v.", lvl,
+		)
+	}
+}
+
+func TestColorLevels(t *testing.T) {
+	levels := []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, DPanicLevel, PanicLevel, FatalLevel, Level(42)}
+	for _, l := range levels {
+		mem := NewMapObjectEncoder()
+		mem.AddArray("k", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+			CapitalColorLevelEncoder(l, arr)
+			return nil
+		}))
+		want := mem.Fields["k"].([]interface{})[0]
+		assertAppended(
+			t,
+			want,
+			func(arr ArrayEncoder) { ColorLevels(CapitalLevelEncoder)(l, arr) },
+			"Expected wrapping CapitalLevelEncoder to match CapitalColorLevelEncoder for %v.", l,
+		)
+	}
+
+	assertAppended(
+		t,
+		"\x1b[34mInfo\x1b[0m",
+		func(arr ArrayEncoder) {
+			ColorLevels(func(Level, PrimitiveArrayEncoder) {})(InfoLevel, arr)
+			ColorLevels(func(_ Level, enc PrimitiveArrayEncoder) { enc.AppendByteString([]byte("Info")) })(InfoLevel, arr)
+		},
+		"Expected byte strings to be colored.",
+	)
+	assertAppended(
+		t,
+		int8(0),
+		func(arr ArrayEncoder) {
+			ColorLevels(func(l Level, enc PrimitiveArrayEncoder) { enc.AppendInt8(int8(l)) })(InfoLevel, arr)
+		},
+		"Expected non-string levels to be left alone.",
+	)
+	assert.Nil(t, ColorLevels(nil), "Expected wrapping a nil LevelEncoder to return nil.")
+}
+
 func TestTimeEncoders(t *testing.T) {
 	moment := time.Unix(100, 50005000).UTC()
 	tests := []struct {
@@ -536,6 +619,10 @@ func TestTimeEncoders(t *testing.T) {
 	}{
 		{"iso8601", "1970-01-01T00:01:40.050Z"},
 		{"ISO8601", "1970-01-01T00:01:40.050Z"},
+		{"rfc3339", "1970-01-01T00:01:40Z"},
+		{"RFC3339", "1970-01-01T00:01:40Z"},
+		{"rfc3339nano", "1970-01-01T00:01:40.050005Z"},
+		{"RFC3339Nano", "1970-01-01T00:01:40.050005Z"},
 		{"millis", 100050.005},
 		{"nanos", int64(100050005000)},
 		{"", 100.050005},
@@ -554,6 +641,46 @@ func TestTimeEncoders(t *testing.T) {
 	}
 }
 
+func TestEpochTimeEncodersOutOfRange(t *testing.T) {
+	year3000 := time.Date(3000, time.January, 1, 0, 0, 0, 500000000, time.UTC)
+	tests := []struct {
+		desc   string
+		t      time.Time
+		enc    TimeEncoder
+		expect interface{}
+	}{
+		{"zero seconds", time.Time{}, EpochTimeEncoder, float64(-62135596800)},
+		{"zero millis", time.Time{}, EpochMillisTimeEncoder, float64(-62135596800000)},
+		{"zero nanos", time.Time{}, EpochNanosTimeEncoder, int64(math.MinInt64)},
+		{"year 3000 seconds", year3000, EpochTimeEncoder, 32503680000.5},
+		{"year 3000 millis", year3000, EpochMillisTimeEncoder, float64(32503680000500)},
+		{"year 3000 nanos", year3000, EpochNanosTimeEncoder, int64(math.MaxInt64)},
+		{"min nanos", time.Unix(0, math.MinInt64), EpochNanosTimeEncoder, int64(math.MinInt64)},
+		{"max nanos", time.Unix(0, math.MaxInt64), EpochNanosTimeEncoder, int64(math.MaxInt64)},
+		{"before epoch nanos", time.Unix(-1, 5), EpochNanosTimeEncoder, int64(-999999995)},
+		{"before epoch seconds", time.Unix(-2, 500000000), EpochTimeEncoder, -1.5},
+	}
+
+	for _, tt := range tests {
+		assertAppended(
+			t,
+			tt.expect,
+			func(arr ArrayEncoder) { tt.enc(tt.t, arr) },
+			"%s: unexpected output serializing %v.", tt.desc, tt.t,
+		)
+	}
+}
+
+func TestTimeEncoderOfLayout(t *testing.T) {
+	moment := time.Date(2000, time.January, 2, 3, 4, 5, 6000000, time.FixedZone("", -7*60*60))
+	assertAppended(
+		t,
+		"2000-01-02 03:04:05.006 -0700",
+		func(arr ArrayEncoder) { TimeEncoderOfLayout("2006-01-02 15:04:05.000 -0700")(moment, arr) },
+		"Unexpected output serializing %v with a custom layout.", moment,
+	)
+}
+
 func TestDurationEncoders(t *testing.T) {
 	elapsed := time.Second + 500*time.Nanosecond
 	tests := []struct {
@@ -562,6 +689,9 @@ func TestDurationEncoders(t *testing.T) {
 	}{
 		{"string", "1.0000005s"},
 		{"nanos", int64(1000000500)},
+		{"ms", int64(1000)},
+		{"millis", int64(1000)},
+		{"seconds", 1.0000005},
 		{"", 1.0000005},
 		{"something-random", 1.0000005},
 	}
@@ -578,6 +708,30 @@ func TestDurationEncoders(t *testing.T) {
 	}
 }
 
+func TestDurationEncodersEdgeCases(t *testing.T) {
+	tests := []struct {
+		d       time.Duration
+		string  string
+		nanos   int64
+		seconds float64
+	}{
+		{0, "0s", 0, 0},
+		{-1500 * time.Millisecond, "-1.5s", -1500000000, -1.5},
+		{time.Nanosecond, "1ns", 1, 1e-9},
+		{-time.Nanosecond, "-1ns", -1, -1e-9},
+	}
+
+	for _, tt := range tests {
+		assertAppended(t, tt.string, func(arr ArrayEncoder) { StringDurationEncoder(tt.d, arr) }, "Unexpected string for %v.", tt.d)
+		assertAppended(t, tt.nanos, func(arr ArrayEncoder) { NanosDurationEncoder(tt.d, arr) }, "Unexpected nanos for %v.", tt.d)
+		assertAppended(t, tt.seconds, func(arr ArrayEncoder) { SecondsDurationEncoder(tt.d, arr) }, "Unexpected seconds for %v.", tt.d)
+
+		parsed, err := time.ParseDuration(tt.string)
+		require.NoError(t, err, "Unexpected error parsing %q.", tt.string)
+		assert.Equal(t, tt.d, parsed, "Expected the string form of %v to round-trip.", tt.d)
+	}
+}
+
 func TestCallerEncoders(t *testing.T) {
 	caller := EntryCaller{Defined: true, File: "/home/jack/src/github.com/foo/foo.go", Line: 42}
 	tests := []struct {
@@ -624,6 +778,26 @@ func TestNameEncoders(t *testing.T) {
 	}
 }
 
+func TestSeparatedNameEncoder(t *testing.T) {
+	tests := []struct {
+		name, sep, expected string
+	}{
+		{"main", "/", "main"},
+		{"http.router", "/", "http/router"},
+		{"a.b.c", "::", "a::b::c"},
+		{"a.b", "", "ab"},
+	}
+
+	for _, tt := range tests {
+		assertAppended(
+			t,
+			tt.expected,
+			func(arr ArrayEncoder) { SeparatedNameEncoder(tt.sep)(tt.name, arr) },
+			"Unexpected output serializing logger name %q with separator %q.", tt.name, tt.sep,
+		)
+	}
+}
+
 func assertAppended(t testing.TB, expected interface{}, f func(ArrayEncoder), msgAndArgs ...interface{}) {
 	mem := NewMapObjectEncoder()
 	mem.AddArray("k", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
@@ -634,3 +808,48 @@ func assertAppended(t testing.TB, expected interface{}, f func(ArrayEncoder), ms
 	require.Equal(t, 1, len(arr), "Expected to append exactly one element to array.")
 	assert.Equal(t, expected, arr[0], msgAndArgs...)
 }
+
+func TestEncoderFunctionKey(t *testing.T) {
+	cfg := testEncoderConfig()
+	cfg.TimeKey = ""
+	cfg.FunctionKey = "func"
+	ent := Entry{Message: "hello", Caller: NewEntryCaller(runtime.Caller(0))}
+	const fn = "go.uber.org/zap/zapcore_test.TestEncoderFunctionKey"
+
+	tests := []struct {
+		enc      Encoder
+		expected string
+	}{
+		{NewJSONEncoder(cfg), `{"level":"info","caller":"zapcore/encoder_test.go:\d+","func":"` + fn + `","msg":"hello"}`},
+		{NewConsoleEncoder(cfg), `info\tzapcore/encoder_test.go:\d+\t` + fn + `\thello`},
+		{NewLogfmtEncoder(cfg), `level=info caller=zapcore/encoder_test.go:\d+ func=` + fn + ` msg=hello`},
+	}
+	for _, tt := range tests {
+		buf, err := tt.enc.EncodeEntry(ent, nil)
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		assert.Regexp(t, "^"+tt.expected+"\n$", buf.String(), "Unexpected encoded entry.")
+		buf.Free()
+	}
+}
+
+func TestEncoderConfigOf(t *testing.T) {
+	cfg := EncoderConfig{MessageKey: "msg"}
+	for _, enc := range []Encoder{
+		NewJSONEncoder(cfg),
+		NewConsoleEncoder(cfg),
+		NewMsgpackEncoder(cfg),
+		NewTextEncoder(cfg),
+		NewLogfmtEncoder(cfg),
+	} {
+		enc.AddObject("k", ObjectMarshalerFunc(func(inner ObjectEncoder) error {
+			got, ok := EncoderConfigOf(inner)
+			if assert.True(t, ok, "Expected %T to expose its configuration.", enc) {
+				assert.Equal(t, "msg", got.MessageKey, "Unexpected configuration from %T.", enc)
+			}
+			return nil
+		}))
+	}
+
+	_, ok := EncoderConfigOf(NewMapObjectEncoder())
+	assert.False(t, ok, "Expected MapObjectEncoder not to expose a configuration.")
+}
@@ -22,6 +22,7 @@ package zapcore
 
 import (
 	"fmt"
+	"runtime"
 	"strings"
 	"sync"
 	"time"
@@ -29,10 +30,13 @@ import (
 	"go.uber.org/zap/internal/bufferpool"
 	"go.uber.org/zap/internal/exit"
 
+	"go.uber.org/atomic"
 	"go.uber.org/multierr"
 )
 
 var (
+	_writeErrors = atomic.NewUint64(0)
+
 	_cePool = sync.Pool{New: func() interface{} {
 		// Pre-allocate some space for cores.
 		return &CheckedEntry{
@@ -96,6 +100,21 @@ func (ec EntryCaller) FullPath() string {
 	return caller
 }
 
+// Function returns the fully-qualified name of the caller's function, like
+// "go.uber.org/zap.TestLogger", or an empty string if the caller is undefined
+// or its function is unknown. The name is looked up from the caller's PC on
+// each call.
+func (ec EntryCaller) Function() string {
+	if !ec.Defined {
+		return ""
+	}
+	fn := runtime.FuncForPC(ec.PC)
+	if fn == nil {
+		return ""
+	}
+	return fn.Name()
+}
+
 // TrimmedPath returns a package/file:line description of the caller,
 // preserving only the leaf directory name and file name.
 func (ec EntryCaller) TrimmedPath() string {
@@ -150,7 +169,8 @@ type Entry struct {
 }
 
 // CheckWriteAction indicates what action to take after a log entry is
-// processed. Actions are ordered in increasing severity.
+// processed. From least to most severe, the actions are WriteThenNoop,
+// WriteThenGoexit, WriteThenPanic, and WriteThenFatal.
 type CheckWriteAction uint8
 
 const (
@@ -161,8 +181,27 @@ const (
 	WriteThenPanic
 	// WriteThenFatal causes a fatal os.Exit after Write.
 	WriteThenFatal
+	// WriteThenGoexit runs runtime.Goexit after Write, stopping the current
+	// goroutine after running its deferred calls. It ranks below
+	// WriteThenPanic, since it never crashes the process.
+	WriteThenGoexit
 )
 
+// severity ranks the action, so that the most severe of a chain of entries'
+// actions wins. Unknown actions rank with WriteThenNoop.
+func (a CheckWriteAction) severity() int {
+	switch a {
+	case WriteThenGoexit:
+		return 1
+	case WriteThenPanic:
+		return 2
+	case WriteThenFatal:
+		return 3
+	default:
+		return 0
+	}
+}
+
 // CheckedEntry is an Entry together with a collection of Cores that have
 // already agreed to log it.
 //
@@ -174,6 +213,8 @@ type CheckedEntry struct {
 	ErrorOutput WriteSyncer
 	dirty       bool // best-effort detection of pool misuse
 	should      CheckWriteAction
+	after       func()
+	next        *CheckedEntry
 	cores       []Core
 }
 
@@ -182,6 +223,8 @@ func (ce *CheckedEntry) reset() {
 	ce.ErrorOutput = nil
 	ce.dirty = false
 	ce.should = WriteThenNoop
+	ce.after = nil
+	ce.next = nil
 	for i := range ce.cores {
 		// don't keep references to cores
 		ce.cores[i] = nil
@@ -191,12 +234,57 @@ func (ce *CheckedEntry) reset() {
 
 // Write writes the entry to the stored Cores, returns any errors, and returns
 // the CheckedEntry reference to a pool for immediate re-use. Finally, it
-// executes any required CheckWriteAction.
+// runs the function registered with After, if any, and executes any required
+// CheckWriteAction.
+//
+// If other CheckedEntries are chained to this one, Write writes them too, in
+// order, before running their After functions and executing the most severe
+// of their CheckWriteActions.
 func (ce *CheckedEntry) Write(fields ...Field) {
-	if ce == nil {
-		return
+	var (
+		should pendingAction
+		afters []func()
+	)
+	for ce != nil {
+		next := ce.next
+		s, after, ok := ce.write(fields)
+		if !ok {
+			// The entry was already written, so its chain can't be trusted.
+			break
+		}
+		if s.action.severity() > should.action.severity() {
+			should = s
+		}
+		if after != nil {
+			afters = append(afters, after)
+		}
+		ce = next
+	}
+
+	for _, after := range afters {
+		after()
+	}
+	switch should.action {
+	case WriteThenGoexit:
+		runtime.Goexit()
+	case WriteThenPanic:
+		panic(should.msg)
+	case WriteThenFatal:
+		exit.Exit()
 	}
+}
+
+// pendingAction is a CheckWriteAction, along with the message of the entry
+// that requested it.
+type pendingAction struct {
+	action CheckWriteAction
+	msg    string
+}
 
+// write writes a single CheckedEntry, without following its chain, and
+// returns it to the pool. It reports false if the entry had already been
+// written.
+func (ce *CheckedEntry) write(fields []Field) (pendingAction, func(), bool) {
 	if ce.dirty {
 		if ce.ErrorOutput != nil {
 			// Make a best effort to detect unsafe re-use of this CheckedEntry.
@@ -206,30 +294,52 @@ func (ce *CheckedEntry) Write(fields ...Field) {
 			fmt.Fprintf(ce.ErrorOutput, "%v Unsafe CheckedEntry re-use near Entry %+v.\n", time.Now(), ce.Entry)
 			ce.ErrorOutput.Sync()
 		}
-		return
+		return pendingAction{}, nil, false
 	}
 	ce.dirty = true
 
-	var err error
-	for i := range ce.cores {
-		err = multierr.Append(err, ce.cores[i].Write(ce.Entry, fields))
-	}
-	if ce.ErrorOutput != nil {
-		if err != nil {
-			fmt.Fprintf(ce.ErrorOutput, "%v write error: %v\n", time.Now(), err)
+	if err := ce.writeCores(fields); err != nil {
+		n := _writeErrors.Inc()
+		if ce.ErrorOutput != nil {
+			fmt.Fprintf(ce.ErrorOutput, "%v write error: %v (%d failed writes so far)\n", time.Now(), err, n)
 			ce.ErrorOutput.Sync()
 		}
 	}
 
-	should, msg := ce.should, ce.Message
+	should, after := pendingAction{ce.should, ce.Message}, ce.after
 	putCheckedEntry(ce)
+	return should, after, true
+}
 
-	switch should {
-	case WriteThenPanic:
-		panic(msg)
-	case WriteThenFatal:
-		exit.Exit()
+// writeCores writes the entry to each of its Cores. A Core that panics, for
+// instance because one of the fields' marshalers panicked, is reported as an
+// error, so a single bad field can't crash the program or keep the entry from
+// the remaining Cores.
+func (ce *CheckedEntry) writeCores(fields []Field) error {
+	var err error
+	for i := range ce.cores {
+		err = multierr.Append(err, writeCore(ce.cores[i], ce.Entry, fields))
 	}
+	return err
+}
+
+func writeCore(core Core, ent Entry, fields []Field) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic writing entry: %v", r)
+		}
+	}()
+	return core.Write(ent, fields)
+}
+
+// WriteErrors returns the number of entries that have failed to write, across
+// all Loggers, since the program started; there's no per-Logger or
+// per-ErrorOutput count. When a CheckedEntry's ErrorOutput is set, each
+// failure is also reported there, along with this count. It's
+// useful for health checks and metrics that shouldn't rely on anyone reading
+// the error output.
+func WriteErrors() uint64 {
+	return _writeErrors.Load()
 }
 
 // AddCore adds a Core that has agreed to log this CheckedEntry. It's intended to be
@@ -255,3 +365,48 @@ func (ce *CheckedEntry) Should(ent Entry, should CheckWriteAction) *CheckedEntry
 	ce.should = should
 	return ce
 }
+
+// After registers a function to run once this CheckedEntry is written, before
+// its CheckWriteAction is executed. It's intended for callers that need to
+// replace the usual panic or exit with their own behavior: they register a
+// function with After and leave the CheckWriteAction at WriteThenNoop. Like
+// Should, it's safe to call on nil CheckedEntry references.
+func (ce *CheckedEntry) After(ent Entry, f func()) *CheckedEntry {
+	if ce == nil {
+		ce = getCheckedEntry()
+		ce.Entry = ent
+	}
+	ce.after = f
+	return ce
+}
+
+// Chain links other to this CheckedEntry, so that writing the result writes
+// both with the same fields. It's useful for sending one event to several
+// independent Loggers, each of which keeps its own entry metadata, context,
+// and encoding:
+//   if ce := primary.Check(lvl, msg).Chain(audit.Check(lvl, msg)); ce != nil {
+//     ce.Write(fields...)
+//   }
+// Like AddCore, Chain is safe to call on nil CheckedEntry references, and
+// nil arguments are ignored, so the result is nil only if every link is nil.
+//
+// Once every link is written, their After functions run in order and the
+// most severe of their CheckWriteActions is executed.
+func (ce *CheckedEntry) Chain(other *CheckedEntry) *CheckedEntry {
+	if ce == nil {
+		return other
+	}
+	tail := ce
+	for {
+		if tail == other {
+			// Already chained; linking again would write it twice.
+			return ce
+		}
+		if tail.next == nil {
+			break
+		}
+		tail = tail.next
+	}
+	tail.next = other
+	return ce
+}
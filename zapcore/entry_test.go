@@ -21,8 +21,11 @@
 package zapcore
 
 import (
+	"bytes"
+	"runtime"
 	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/zap/internal/exit"
 
@@ -87,6 +90,14 @@ func TestEntryCaller(t *testing.T) {
 	}
 }
 
+func TestEntryCallerFunction(t *testing.T) {
+	caller := NewEntryCaller(runtime.Caller(0))
+	assert.Equal(t, "go.uber.org/zap/zapcore.TestEntryCallerFunction", caller.Function(), "Unexpected caller function.")
+
+	assert.Equal(t, "", NewEntryCaller(0, "foo.go", 42, false).Function(), "Expected no function for undefined callers.")
+	assert.Equal(t, "", NewEntryCaller(0, "foo.go", 42, true).Function(), "Expected no function for unknown PCs.")
+}
+
 func TestCheckedEntryWrite(t *testing.T) {
 	// Nil checked entries are safe.
 	var ce *CheckedEntry
@@ -104,4 +115,166 @@ func TestCheckedEntryWrite(t *testing.T) {
 	})
 	assert.True(t, stub.Exited, "Expected to exit when WriteThenFatal is set.")
 	ce.reset()
+
+	// WriteThenGoexit
+	ce = ce.Should(Entry{}, WriteThenGoexit)
+	var (
+		wg       sync.WaitGroup
+		returned bool
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ce.Write()
+		returned = true
+	}()
+	wg.Wait()
+	assert.False(t, returned, "Expected to stop the goroutine when WriteThenGoexit is set.")
+	ce.reset()
+
+	// After
+	var called bool
+	ce = ce.After(Entry{}, func() { called = true })
+	assert.NotPanics(t, func() { ce.Write() }, "Unexpected panic with only an After function set.")
+	assert.True(t, called, "Expected to run the After function.")
+	ce.reset()
+
+	// After runs before the CheckWriteAction.
+	called = false
+	ce = ce.After(Entry{}, func() { called = true }).Should(Entry{}, WriteThenPanic)
+	assert.Panics(t, func() { ce.Write() }, "Expected to panic when WriteThenPanic is set.")
+	assert.True(t, called, "Expected to run the After function before panicking.")
+	ce.reset()
+}
+
+// panicCore panics when writing entries, like a Core whose encoder hits a
+// panicking marshaler.
+type panicCore struct{ Core }
+
+func (c panicCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (panicCore) Write(Entry, []Field) error {
+	panic("boom")
+}
+
+func TestCheckedEntryWritePanic(t *testing.T) {
+	var out, errOut bytes.Buffer
+	enc := NewJSONEncoder(EncoderConfig{MessageKey: "msg"})
+	good := NewCore(enc, AddSync(&out), DebugLevel)
+	bad := panicCore{good}
+
+	before := WriteErrors()
+	ent := Entry{Message: "hello"}
+	ce := bad.Check(ent, nil)
+	ce = good.Check(ent, ce)
+	ce.ErrorOutput = AddSync(&errOut)
+	assert.NotPanics(t, func() { ce.Write() }, "Expected panicking Cores to be reported, not re-raised.")
+
+	assert.Equal(t, `{"msg":"hello"}`+"\n", out.String(), "Expected the entry to reach the remaining Cores.")
+	assert.Contains(t, errOut.String(), "write error: panic writing entry: boom (", "Expected the panic to be reported.")
+	assert.Equal(t, before+1, WriteErrors(), "Expected the failed write to be counted.")
+}
+
+// panicWriter panics on its first write.
+type panicWriter struct {
+	bytes.Buffer
+	panicked bool
+}
+
+func (w *panicWriter) Write(p []byte) (int, error) {
+	if !w.panicked {
+		w.panicked = true
+		panic("boom")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestCheckedEntryWritePanicLockedSink(t *testing.T) {
+	var errOut bytes.Buffer
+	w := &panicWriter{}
+	core := NewCore(NewJSONEncoder(EncoderConfig{MessageKey: "msg"}), Lock(AddSync(w)), DebugLevel)
+
+	write := func(msg string) {
+		ce := core.Check(Entry{Message: msg}, nil)
+		ce.ErrorOutput = AddSync(&errOut)
+		ce.Write()
+	}
+	write("panics")
+	assert.Contains(t, errOut.String(), "panic writing entry: boom", "Expected the panic to be reported.")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		write("first")
+		write("second")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected writes after a panicking write not to deadlock.")
+	}
+	assert.Equal(t, `{"msg":"first"}`+"\n"+`{"msg":"second"}`+"\n", w.String(), "Expected later writes to succeed.")
+}
+
+func TestCheckedEntryChain(t *testing.T) {
+	var nilCE *CheckedEntry
+	assert.Nil(t, nilCE.Chain(nil), "Expected chaining nils to return nil.")
+
+	// Avoid the pool, since other tests may leave shared entries in it.
+	newEntry := func(msg string) *CheckedEntry {
+		return (&CheckedEntry{Entry: Entry{Message: msg}}).AddCore(Entry{}, NewNopCore())
+	}
+
+	a := newEntry("a")
+	assert.Equal(t, a, nilCE.Chain(a), "Expected chaining onto nil to return the argument.")
+	assert.Equal(t, a, a.Chain(nil), "Expected chaining nil to return the receiver.")
+	assert.Equal(t, a, a.Chain(a), "Expected chaining an entry to itself to be a no-op.")
+	assert.Nil(t, a.next, "Expected chaining an entry to itself not to link it.")
+	a.reset()
+
+	// The most severe action wins, and its message is used.
+	var order []string
+	a = newEntry("a").After(Entry{}, func() { order = append(order, "a") })
+	b := newEntry("b").Should(Entry{}, WriteThenPanic)
+	c := newEntry("c").After(Entry{}, func() { order = append(order, "c") })
+	ce := a.Chain(b).Chain(c)
+	assert.PanicsWithValue(t, "b", func() { ce.Write() }, "Expected to panic with the panicking entry's message.")
+	assert.Equal(t, []string{"a", "c"}, order, "Expected After functions to run in order, before panicking.")
+
+	fatal := newEntry("fatal").Should(Entry{}, WriteThenFatal)
+	ce = newEntry("panic").Should(Entry{}, WriteThenPanic).Chain(fatal)
+	stub := exit.WithStub(func() {
+		assert.NotPanics(t, func() { ce.Write() }, "Expected exiting to take precedence over panicking.")
+	})
+	assert.True(t, stub.Exited, "Expected to exit when any chained entry is fatal.")
+
+	ce = newEntry("goexit").Should(Entry{}, WriteThenGoexit).Chain(newEntry("panic").Should(Entry{}, WriteThenPanic))
+	assert.PanicsWithValue(t, "panic", func() { ce.Write() }, "Expected panicking to take precedence over Goexit.")
+}
+
+func TestCheckWriteActionValues(t *testing.T) {
+	// These values are part of the public API, so they must never change.
+	assert.Equal(t, CheckWriteAction(0), WriteThenNoop, "Unexpected value for WriteThenNoop.")
+	assert.Equal(t, CheckWriteAction(1), WriteThenPanic, "Unexpected value for WriteThenPanic.")
+	assert.Equal(t, CheckWriteAction(2), WriteThenFatal, "Unexpected value for WriteThenFatal.")
+	assert.Equal(t, CheckWriteAction(3), WriteThenGoexit, "Unexpected value for WriteThenGoexit.")
+}
+
+func TestCheckedEntryChainCycle(t *testing.T) {
+	a := (&CheckedEntry{}).AddCore(Entry{Message: "a"}, NewNopCore())
+	b := (&CheckedEntry{}).AddCore(Entry{Message: "b"}, NewNopCore())
+	a.Chain(b)
+	b.Chain(a)
+	done := make(chan struct{})
+	go func() {
+		a.Write()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected writing a cyclic chain to terminate.")
+	}
 }
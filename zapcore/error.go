@@ -31,13 +31,26 @@ import (
 // If the error implements fmt.Formatter, a field with the name ${key}Verbose
 // is also added with the full verbose error message.
 //
-// Finally, if the error implements errorGroup (from go.uber.org/multierr) or
-// causer (from github.com/pkg/errors), a ${key}Causes field is added with an
-// array of objects containing the errors this error was comprised of.
+// Finally, if the error implements errorGroup (from go.uber.org/multierr), a
+// ${key}Causes field is added with an array of objects containing the errors
+// this error was comprised of. Errors that wrap another error, by implementing
+// wrapper (like those produced by fmt.Errorf's %w verb) or causer (from
+// github.com/pkg/errors), get a ${key}Causes field holding the wrapped error
+// instead, alongside any verbose message. Since each cause is encoded the same
+// way, the rest of the chain nests inside it.
+//
+// If the error carries its own structured context by implementing
+// fieldCarrier, a ${key}Fields field is added with an object containing those
+// fields. Nesting them keeps them from colliding with the fields supplied at
+// the log site.
 //
 //  {
 //    "error": err.Error(),
+//    "errorFields": {
+//      ...
+//    },
 //    "errorVerbose": fmt.Sprintf("%+v", err),
+//    // Either the errors in the group or the wrapped error.
 //    "errorCauses": [
 //      ...
 //    ],
@@ -46,6 +59,12 @@ func encodeError(key string, err error, enc ObjectEncoder) error {
 	basic := err.Error()
 	enc.AddString(key, basic)
 
+	if fc, ok := err.(fieldCarrier); ok {
+		if fields := fc.LogFields(); len(fields) > 0 {
+			enc.AddObject(key+"Fields", errFields(fields))
+		}
+	}
+
 	switch e := err.(type) {
 	case errorGroup:
 		return enc.AddArray(key+"Causes", errArray(e.Errors()))
@@ -57,6 +76,24 @@ func encodeError(key string, err error, enc ObjectEncoder) error {
 			enc.AddString(key+"Verbose", verbose)
 		}
 	}
+
+	if cause := unwrapError(err); cause != nil {
+		el := newErrArrayElem(cause)
+		err := enc.AddArray(key+"Causes", el)
+		el.Free()
+		return err
+	}
+	return nil
+}
+
+// unwrapError returns the error wrapped by err, if any.
+func unwrapError(err error) error {
+	switch e := err.(type) {
+	case wrapper:
+		return e.Unwrap()
+	case causer:
+		return e.Cause()
+	}
 	return nil
 }
 
@@ -66,11 +103,29 @@ type errorGroup interface {
 	Errors() []error
 }
 
+type fieldCarrier interface {
+	// Returns structured context describing the error.
+	LogFields() []Field
+}
+
+// Encodes the fields carried by an error as an object.
+type errFields []Field
+
+func (fs errFields) MarshalLogObject(enc ObjectEncoder) error {
+	addFields(enc, fs)
+	return nil
+}
+
 type causer interface {
 	// Provides access to the error that caused this error.
 	Cause() error
 }
 
+type wrapper interface {
+	// Provides access to the error wrapped by this error.
+	Unwrap() error
+}
+
 // Note that errArry and errArrayElem are very similar to the version
 // implemented in the top-level error.go file. We can't re-use this because
 // that would require exporting errArray as part of the zapcore API.
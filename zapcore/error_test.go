@@ -64,6 +64,34 @@ func (e customMultierr) Errors() []error {
 	}
 }
 
+type errNotFound struct{ user string }
+
+func (e errNotFound) Error() string {
+	return "user not found"
+}
+
+func (e errNotFound) LogFields() []Field {
+	return []Field{{Key: "user", Type: StringType, String: e.user}}
+}
+
+type errWrapped struct {
+	msg   string
+	cause error
+}
+
+func (e errWrapped) Error() string { return e.msg + ": " + e.cause.Error() }
+
+func (e errWrapped) Unwrap() error { return e.cause }
+
+type errCaused struct {
+	msg   string
+	cause error
+}
+
+func (e errCaused) Error() string { return e.msg + ": " + e.cause.Error() }
+
+func (e errCaused) Cause() error { return e.cause }
+
 func TestErrorEncoding(t *testing.T) {
 	tests := []struct {
 		k     string
@@ -111,12 +139,67 @@ func TestErrorEncoding(t *testing.T) {
 				},
 			},
 		},
+		{
+			k:     "err",
+			iface: errNotFound{user: "bob"},
+			want: map[string]interface{}{
+				"err":       "user not found",
+				"errFields": map[string]interface{}{"user": "bob"},
+			},
+		},
+		{
+			k:     "err",
+			iface: errWrapped{"connecting", errCaused{"dialing", errNotFound{user: "bob"}}},
+			want: map[string]interface{}{
+				"err": "connecting: dialing: user not found",
+				"errCauses": []interface{}{
+					map[string]interface{}{
+						"error": "dialing: user not found",
+						"errorCauses": []interface{}{
+							map[string]interface{}{
+								"error":       "user not found",
+								"errorFields": map[string]interface{}{"user": "bob"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			k:     "err",
+			iface: errWrapped{"counting", errTooManyUsers(3)},
+			want: map[string]interface{}{
+				"err": "counting: 3 too many users",
+				"errCauses": []interface{}{
+					map[string]interface{}{"error": "3 too many users"},
+				},
+			},
+		},
+		{
+			k:     "err",
+			iface: errWrapped{"counting", richErrors.WithMessage(errors.New("egad"), "failed")},
+			want: map[string]interface{}{
+				"err": "counting: failed: egad",
+				"errCauses": []interface{}{
+					map[string]interface{}{
+						"error":        "failed: egad",
+						"errorVerbose": "egad\nfailed",
+						"errorCauses": []interface{}{
+							map[string]interface{}{"error": "egad"},
+						},
+					},
+				},
+			},
+		},
 		{
 			k:     "k",
 			iface: richErrors.WithMessage(errors.New("egad"), "failed"),
 			want: map[string]interface{}{
 				"k":        "failed: egad",
 				"kVerbose": "egad\nfailed",
+				"kCauses": []interface{}{
+					map[string]interface{}{"error": "egad"},
+				},
 			},
 		},
 		{
@@ -138,9 +221,24 @@ func TestErrorEncoding(t *testing.T) {
 							" -  foo\n" +
 							" -  bar\n" +
 							"hello",
+						"errorCauses": []interface{}{
+							map[string]interface{}{
+								"error": "foo; bar",
+								"errorCauses": []interface{}{
+									map[string]interface{}{"error": "foo"},
+									map[string]interface{}{"error": "bar"},
+								},
+							},
+						},
 					},
 					map[string]interface{}{"error": "baz"},
-					map[string]interface{}{"error": "world: qux", "errorVerbose": "qux\nworld"},
+					map[string]interface{}{
+						"error":        "world: qux",
+						"errorVerbose": "qux\nworld",
+						"errorCauses": []interface{}{
+							map[string]interface{}{"error": "qux"},
+						},
+					},
 				},
 			},
 		},
@@ -89,6 +89,22 @@ const (
 	ErrorType
 	// SkipType indicates that the field is a no-op.
 	SkipType
+	// TagType indicates that the field carries a low-cardinality string
+	// meant for indexing. Unless a Core treats tags specially (see
+	// NewTagCore), it's serialized like any other string.
+	TagType
+	// TemplateType indicates that the field's value is a template referring
+	// to other fields on the same entry. Unless a Core resolves templates
+	// (see NewTemplateCore), it's serialized as the raw template string.
+	TemplateType
+	// InlineMarshalerType indicates that the field carries an ObjectMarshaler
+	// whose fields should be added directly to the enclosing object, rather
+	// than nested under the field's key.
+	InlineMarshalerType
+	// TimeFullType indicates that the field carries a time.Time stored in
+	// full, for times that can't be represented as nanoseconds since the
+	// Unix epoch.
+	TimeFullType
 )
 
 // A Field is a marshaling operation used to add a key-value pair to a logger's
@@ -112,6 +128,8 @@ func (f Field) AddTo(enc ObjectEncoder) {
 		err = enc.AddArray(f.Key, f.Interface.(ArrayMarshaler))
 	case ObjectMarshalerType:
 		err = enc.AddObject(f.Key, f.Interface.(ObjectMarshaler))
+	case InlineMarshalerType:
+		err = f.Interface.(ObjectMarshaler).MarshalLogObject(enc)
 	case BinaryType:
 		enc.AddBinary(f.Key, f.Interface.([]byte))
 	case BoolType:
@@ -145,6 +163,8 @@ func (f Field) AddTo(enc ObjectEncoder) {
 			// Fall back to UTC if location is nil.
 			enc.AddTime(f.Key, time.Unix(0, f.Integer))
 		}
+	case TimeFullType:
+		enc.AddTime(f.Key, f.Interface.(time.Time))
 	case Uint64Type:
 		enc.AddUint64(f.Key, uint64(f.Integer))
 	case Uint32Type:
@@ -165,6 +185,8 @@ func (f Field) AddTo(enc ObjectEncoder) {
 		encodeError(f.Key, f.Interface.(error), enc)
 	case SkipType:
 		break
+	case TagType, TemplateType:
+		enc.AddString(f.Key, f.String)
 	default:
 		panic(fmt.Sprintf("unknown field type: %v", f))
 	}
@@ -187,8 +209,10 @@ func (f Field) Equals(other Field) bool {
 	switch f.Type {
 	case BinaryType, ByteStringType:
 		return bytes.Equal(f.Interface.([]byte), other.Interface.([]byte))
-	case ArrayMarshalerType, ObjectMarshalerType, ErrorType, ReflectType:
+	case ArrayMarshalerType, ObjectMarshalerType, InlineMarshalerType, ErrorType, ReflectType:
 		return reflect.DeepEqual(f.Interface, other.Interface)
+	case TimeFullType:
+		return f.Interface.(time.Time).Equal(other.Interface.(time.Time))
 	default:
 		return f == other
 	}
@@ -83,6 +83,18 @@ func TestFieldAddingError(t *testing.T) {
 	}
 }
 
+func TestInlineMarshalerField(t *testing.T) {
+	enc := NewMapObjectEncoder()
+	f := Field{Key: "k", Type: InlineMarshalerType, Interface: users(2)}
+	f.AddTo(enc)
+	assert.Equal(t, map[string]interface{}{"users": 2}, enc.Fields, "Expected inlined fields in the enclosing object.")
+	assert.True(t, f.Equals(f), "Field does not equal itself")
+
+	enc = NewMapObjectEncoder()
+	Field{Key: "k", Type: InlineMarshalerType, Interface: users(-1)}.AddTo(enc)
+	assert.Equal(t, map[string]interface{}{"kError": "too few users"}, enc.Fields, "Expected error message in log context.")
+}
+
 func TestFields(t *testing.T) {
 	tests := []struct {
 		t     FieldType
@@ -108,6 +120,7 @@ func TestFields(t *testing.T) {
 		{t: StringType, s: "foo", want: "foo"},
 		{t: TimeType, i: 1000, iface: time.UTC, want: time.Unix(0, 1000).In(time.UTC)},
 		{t: TimeType, i: 1000, want: time.Unix(0, 1000)},
+		{t: TimeFullType, iface: time.Time{}, want: time.Time{}},
 		{t: Uint64Type, i: 42, want: uint64(42)},
 		{t: Uint32Type, i: 42, want: uint32(42)},
 		{t: Uint16Type, i: 42, want: uint16(42)},
@@ -117,6 +130,8 @@ func TestFields(t *testing.T) {
 		{t: NamespaceType, want: map[string]interface{}{}},
 		{t: StringerType, iface: users(2), want: "2 users"},
 		{t: SkipType, want: interface{}(nil)},
+		{t: TagType, s: "foo", want: "foo"},
+		{t: TemplateType, s: "{foo}", want: "{foo}"},
 	}
 
 	for _, tt := range tests {
@@ -162,6 +177,16 @@ func TestEquals(t *testing.T) {
 			b:    zap.Time("k", time.Unix(1000, 1000)),
 			want: true,
 		},
+		{
+			a:    zap.Time("k", time.Time{}),
+			b:    zap.Time("k", time.Time{}),
+			want: true,
+		},
+		{
+			a:    zap.Time("k", time.Time{}),
+			b:    zap.Time("k", time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)),
+			want: false,
+		},
 		{
 			a:    zap.Time("k", time.Unix(1000, 1000).In(time.UTC)),
 			b:    zap.Time("k", time.Unix(1000, 1000).In(time.FixedZone("TEST", -8))),
@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+type filterCore struct {
+	Core
+	keep    func(Entry, []Field) bool
+	context []Field
+}
+
+// NewFilterCore wraps a Core so that only the entries for which keep returns
+// true are written. It's useful for dropping entries by message, logger name,
+// or field value, like a noisy warning from a dependency, without raising the
+// level of everything else.
+//
+// The wrapped Core checks each entry first, so keep is only called for
+// entries that would otherwise be written, and it sees all of the entry's
+// fields: first those added via With, then those passed at the log site. It
+// mustn't modify them.
+func NewFilterCore(core Core, keep func(Entry, []Field) bool) Core {
+	return &filterCore{Core: core, keep: keep}
+}
+
+func (c *filterCore) With(fields []Field) Core {
+	context := make([]Field, 0, len(c.context)+len(fields))
+	context = append(context, c.context...)
+	context = append(context, fields...)
+	return &filterCore{
+		Core:    c.Core.With(fields),
+		keep:    c.keep,
+		context: context,
+	}
+}
+
+func (c *filterCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkFilteringFields(c.Core, ent, ce, c.kept)
+}
+
+func (c *filterCore) Write(ent Entry, fields []Field) error {
+	if !c.kept(ent, fields) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c *filterCore) kept(ent Entry, fields []Field) bool {
+	if len(c.context) == 0 {
+		return c.keep(ent, fields)
+	}
+	all := make([]Field, 0, len(c.context)+len(fields))
+	all = append(all, c.context...)
+	all = append(all, fields...)
+	return c.keep(ent, all)
+}
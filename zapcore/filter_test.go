@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterCore(t *testing.T) {
+	var seen [][]Field
+	obs, logs := observer.New(InfoLevel)
+	core := NewFilterCore(obs, func(ent Entry, fields []Field) bool {
+		seen = append(seen, fields)
+		for _, f := range fields {
+			if f.Key == "noisy" {
+				return false
+			}
+		}
+		return ent.Message != "drop me"
+	}).With([]Field{makeInt64Field("ctx", 1)})
+
+	write := func(ent Entry, fields ...Field) {
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(fields...)
+		}
+	}
+	write(Entry{Level: DebugLevel, Message: "disabled"})
+	write(Entry{Level: InfoLevel, Message: "keep me"}, makeInt64Field("n", 1))
+	write(Entry{Level: InfoLevel, Message: "drop me"})
+	write(Entry{Level: WarnLevel, Message: "noisy"}, makeStringField("noisy", "yes"))
+
+	require.Equal(t, 3, len(seen), "Expected the filter to run only for enabled entries.")
+	assert.Equal(t, []Field{makeInt64Field("ctx", 1), makeInt64Field("n", 1)}, seen[0], "Expected the filter to see context and log-site fields.")
+	entries := logs.AllUntimed()
+	require.Equal(t, 1, len(entries), "Unexpected number of entries.")
+	assert.Equal(t, "keep me", entries[0].Message, "Unexpected entry.")
+
+	require.NoError(t, core.Write(Entry{Message: "drop me"}, nil), "Unexpected error writing directly.")
+	assert.Equal(t, 1, logs.Len(), "Expected direct writes to be filtered too.")
+}
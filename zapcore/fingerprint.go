@@ -0,0 +1,175 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// OccurrencesKey is the key under which the Core returned by
+// NewFingerprintCore reports how many times a fingerprint occurred.
+const OccurrencesKey = "occurrences"
+
+type fingerprintCore struct {
+	Core
+	state *fingerprintState
+}
+
+// fingerprintState is shared by a fingerprintCore and the Cores derived
+// from it with With.
+type fingerprintState struct {
+	sync.Mutex
+	fingerprint func(Entry, []Field) string
+	window      time.Duration
+	max         int
+	clock       Clock
+	windows     map[string]*fingerprintWindow
+}
+
+// A fingerprintWindow tracks the duplicates of one fingerprint.
+type fingerprintWindow struct {
+	// count is the number of occurrences, including the first.
+	count int64
+	// The latest duplicate, and the Core that checked it.
+	core   Core
+	ent    Entry
+	fields []Field
+	stop   func() bool
+}
+
+// NewFingerprintCore wraps a Core so that it collapses duplicate entries.
+// It computes a fingerprint for each entry that the wrapped Core would log;
+// the first entry with a given fingerprint is written immediately, and any
+// duplicates within the next window are suppressed. When the window closes,
+// the latest duplicate is written once more, with an additional field
+// reporting the total number of occurrences under OccurrencesKey. A
+// fingerprint without duplicates ends its window silently.
+//
+// To bound memory use, at most max fingerprints are tracked at once; while
+// the limit is reached, entries with new fingerprints are written as usual.
+// Calling Sync closes all open windows, so that pending counts aren't lost
+// when the program exits. Cores derived from the returned Core with With
+// share its fingerprints, and coalesced entries carry the context of the
+// Core that logged the latest duplicate.
+func NewFingerprintCore(core Core, fingerprint func(Entry, []Field) string, window time.Duration, max int, clock Clock) Core {
+	return &fingerprintCore{
+		Core: core,
+		state: &fingerprintState{
+			fingerprint: fingerprint,
+			window:      window,
+			max:         max,
+			clock:       clock,
+			windows:     make(map[string]*fingerprintWindow),
+		},
+	}
+}
+
+func (c *fingerprintCore) With(fields []Field) Core {
+	return &fingerprintCore{
+		Core:  c.Core.With(fields),
+		state: c.state,
+	}
+}
+
+func (c *fingerprintCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkFilteringFields(c.Core, ent, ce, c.keep)
+}
+
+func (c *fingerprintCore) Write(ent Entry, fields []Field) error {
+	if !c.keep(ent, fields) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c *fingerprintCore) Sync() error {
+	s := c.state
+	s.Lock()
+	fps := make([]string, 0, len(s.windows))
+	for fp := range s.windows {
+		fps = append(fps, fp)
+	}
+	sort.Strings(fps)
+	windows := make([]*fingerprintWindow, len(fps))
+	for i, fp := range fps {
+		windows[i] = s.windows[fp]
+		delete(s.windows, fp)
+	}
+	s.Unlock()
+
+	for _, w := range windows {
+		w.stop()
+		w.flush()
+	}
+	return c.Core.Sync()
+}
+
+// keep reports whether an entry is the first with its fingerprint, recording
+// it as a duplicate otherwise.
+func (c *fingerprintCore) keep(ent Entry, fields []Field) bool {
+	s := c.state
+	fp := s.fingerprint(ent, fields)
+
+	s.Lock()
+	defer s.Unlock()
+	if w, ok := s.windows[fp]; ok {
+		w.count++
+		w.core = c.Core
+		w.ent = ent
+		// Copy the fields, since the caller may reuse their backing array.
+		w.fields = append(w.fields[:0], fields...)
+		return false
+	}
+	if len(s.windows) >= s.max {
+		return true
+	}
+	w := &fingerprintWindow{count: 1}
+	s.windows[fp] = w
+	w.stop = s.clock.AfterFunc(s.window, func() { s.close(fp, w) })
+	return true
+}
+
+func (s *fingerprintState) close(fp string, w *fingerprintWindow) {
+	s.Lock()
+	if s.windows[fp] != w {
+		// The window was already closed by an explicit Sync.
+		s.Unlock()
+		return
+	}
+	delete(s.windows, fp)
+	s.Unlock()
+	w.flush()
+}
+
+// flush writes the coalesced entry, if there were any duplicates. Since
+// windows usually close in the background, the error is dropped. The window
+// must no longer be reachable from the fingerprintState.
+func (w *fingerprintWindow) flush() {
+	if w.count < 2 {
+		return
+	}
+	fields := append(w.fields, Field{Key: OccurrencesKey, Type: Int64Type, Integer: w.count})
+	if ce := w.core.Check(w.ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+}
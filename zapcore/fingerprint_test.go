@@ -0,0 +1,120 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fingerprintByMessage(ent Entry, _ []Field) string {
+	return ent.Message
+}
+
+func TestFingerprintCoreCoalesces(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewFingerprintCore(obs, fingerprintByMessage, time.Minute, 10, clock)
+
+	write := func(msg string, n int) {
+		ent := Entry{Level: InfoLevel, Message: msg}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(makeInt64Field("n", n))
+		}
+	}
+
+	write("disk full", 1)
+	write("disk full", 2)
+	write("disk full", 3)
+	write("other", 1)
+	if ce := core.Check(Entry{Level: DebugLevel, Message: "disk full"}, nil); ce != nil {
+		t.Fatal("Expected the wrapped Core's level to be respected.")
+	}
+
+	entries := logs.TakeAll()
+	require.Len(t, entries, 2, "Expected only the first occurrence of each fingerprint.")
+	assert.Equal(t, []Field{makeInt64Field("n", 1)}, entries[0].Context, "Unexpected first occurrence.")
+	assert.Equal(t, "other", entries[1].Message, "Unexpected second entry.")
+
+	clock.Advance(time.Minute)
+	entries = logs.TakeAll()
+	require.Len(t, entries, 1, "Expected a coalesced entry when the window closes.")
+	assert.Equal(t, "disk full", entries[0].Message, "Unexpected coalesced message.")
+	assert.Equal(
+		t,
+		[]Field{makeInt64Field("n", 3), makeInt64Field(OccurrencesKey, 3)},
+		entries[0].Context,
+		"Expected the latest duplicate's fields and an occurrence count.",
+	)
+
+	// Once the window closes, the fingerprint starts over.
+	write("disk full", 4)
+	assert.Equal(t, 1, logs.Len(), "Expected a closed window to forget its fingerprint.")
+}
+
+func TestFingerprintCoreMaxFingerprints(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewFingerprintCore(obs, fingerprintByMessage, time.Minute, 1, clock)
+
+	for _, msg := range []string{"a", "b", "b", "a"} {
+		assert.NoError(t, core.Write(Entry{Message: msg}, nil), "Unexpected error writing entry.")
+	}
+	assert.Equal(t, 3, logs.Len(), "Expected untracked fingerprints to be written as usual.")
+
+	clock.Advance(time.Minute)
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 4, "Expected a coalesced entry for the tracked fingerprint.")
+	assert.Equal(t, "a", entries[3].Message, "Unexpected coalesced entry.")
+	assert.Equal(t, []Field{makeInt64Field(OccurrencesKey, 2)}, entries[3].Context, "Unexpected occurrence count.")
+}
+
+func TestFingerprintCoreSync(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewFingerprintCore(obs, fingerprintByMessage, time.Minute, 10, clock)
+	child := core.With([]Field{makeStringField("child", "yes")})
+
+	for _, c := range []Core{core, child, core, child} {
+		require.NoError(t, c.Write(Entry{Message: "dup"}, nil), "Unexpected error writing entry.")
+	}
+	require.NoError(t, core.Write(Entry{Message: "single"}, nil), "Unexpected error writing entry.")
+	require.Equal(t, 2, logs.Len(), "Expected duplicates from derived Cores to be suppressed.")
+
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 3, "Expected Sync to flush pending coalesced entries.")
+	assert.Equal(
+		t,
+		[]Field{makeStringField("child", "yes"), makeInt64Field(OccurrencesKey, 4)},
+		entries[2].Context,
+		"Expected the coalesced entry to carry the latest duplicate's context.",
+	)
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, 3, logs.Len(), "Expected Sync to close the windows.")
+}
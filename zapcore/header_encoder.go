@@ -0,0 +1,99 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/internal/bufferpool"
+)
+
+type headerEncoder struct {
+	Encoder
+	header     func(Entry) []byte
+	lineEnding string
+}
+
+// NewHeaderEncoder wraps an Encoder so that each encoded entry is preceded by
+// a line computed by the supplied function. Some ingestion APIs need this
+// framing; for example, Elasticsearch's bulk API expects an action line
+// before each document:
+//
+//   {"index":{}}
+//   {"level":"info","msg":"hello"}
+//
+// The header is followed by the LineEnding of the wrapped Encoder's
+// EncoderConfig, or by DefaultLineEnding if it's empty or if the Encoder
+// isn't one of zapcore's own. If the function returns an empty slice, no
+// header line is written for that entry.
+//
+// Because the header and the entry are encoded into the same buffer, a Core
+// writes them with a single call to its WriteSyncer, so concurrent entries
+// can't be interleaved between a header and its entry (provided that the
+// WriteSyncer is itself safe for concurrent use).
+func NewHeaderEncoder(enc Encoder, header func(Entry) []byte) Encoder {
+	ending := DefaultLineEnding
+	if le, ok := enc.(lineEnder); ok {
+		ending = le.lineEnding()
+	}
+	return headerEncoder{
+		Encoder:    enc,
+		header:     header,
+		lineEnding: ending,
+	}
+}
+
+// lineEnder is implemented by the Encoders that embed their EncoderConfig.
+type lineEnder interface {
+	lineEnding() string
+}
+
+func (cfg EncoderConfig) lineEnding() string {
+	if cfg.LineEnding != "" {
+		return cfg.LineEnding
+	}
+	return DefaultLineEnding
+}
+
+func (h headerEncoder) Clone() Encoder {
+	return headerEncoder{
+		Encoder:    h.Encoder.Clone(),
+		header:     h.header,
+		lineEnding: h.lineEnding,
+	}
+}
+
+func (h headerEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	body, err := h.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	header := h.header(ent)
+	if len(header) == 0 {
+		return body, nil
+	}
+
+	line := bufferpool.Get()
+	line.Write(header)
+	line.AppendString(h.lineEnding)
+	line.Write(body.Bytes())
+	body.Free()
+	return line, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"strings"
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeaderEncoder(t *testing.T) {
+	enc := NewHeaderEncoder(
+		NewJSONEncoder(EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: LowercaseLevelEncoder}),
+		func(ent Entry) []byte {
+			if ent.Level == DebugLevel {
+				return nil
+			}
+			return []byte(`{"index":{}}`)
+		},
+	)
+	enc.AddString("foo", "bar")
+	clone := enc.Clone()
+	clone.AddInt("baz", 1)
+
+	tests := []struct {
+		desc     string
+		enc      Encoder
+		ent      Entry
+		expected string
+	}{
+		{
+			desc:     "with header",
+			enc:      enc,
+			ent:      Entry{Level: InfoLevel, Message: "hello"},
+			expected: `{"index":{}}` + "\n" + `{"level":"info","msg":"hello","foo":"bar","k":"v"}` + "\n",
+		},
+		{
+			desc:     "empty header",
+			enc:      enc,
+			ent:      Entry{Level: DebugLevel, Message: "hello"},
+			expected: `{"level":"debug","msg":"hello","foo":"bar","k":"v"}` + "\n",
+		},
+		{
+			desc:     "clone",
+			enc:      clone,
+			ent:      Entry{Level: InfoLevel, Message: "hello"},
+			expected: `{"index":{}}` + "\n" + `{"level":"info","msg":"hello","foo":"bar","baz":1,"k":"v"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		buf, err := tt.enc.EncodeEntry(tt.ent, []Field{{Key: "k", Type: StringType, String: "v"}})
+		require.NoError(t, err, "%s: unexpected error encoding entry.", tt.desc)
+		assert.Equal(t, tt.expected, buf.String(), "%s: unexpected output.", tt.desc)
+		buf.Free()
+	}
+}
+
+func TestHeaderEncoderLineEnding(t *testing.T) {
+	header := func(Entry) []byte { return []byte(`{"index":{}}`) }
+	tests := []struct {
+		desc string
+		enc  Encoder
+	}{
+		{"json", NewJSONEncoder(EncoderConfig{MessageKey: "msg", LineEnding: "\r\n"})},
+		{"console", NewConsoleEncoder(EncoderConfig{MessageKey: "msg", LineEnding: "\r\n"})},
+		{"logfmt", NewLogfmtEncoder(EncoderConfig{MessageKey: "msg", LineEnding: "\r\n"})},
+	}
+
+	for _, tt := range tests {
+		buf, err := NewHeaderEncoder(tt.enc, header).EncodeEntry(Entry{Message: "hello"}, nil)
+		require.NoError(t, err, "%s: unexpected error encoding entry.", tt.desc)
+		lines := strings.Split(buf.String(), "\r\n")
+		assert.Equal(t, []string{`{"index":{}}`}, lines[:1], "%s: expected the header to end with the configured line ending.", tt.desc)
+		assert.Equal(t, 3, len(lines), "%s: expected the header and entry to use the same line ending.", tt.desc)
+		buf.Free()
+	}
+}
@@ -20,7 +20,11 @@
 
 package zapcore
 
-import "go.uber.org/multierr"
+import (
+	"sync"
+
+	"go.uber.org/multierr"
+)
 
 type hooked struct {
 	Core
@@ -66,3 +70,207 @@ func (h *hooked) Write(ent Entry, _ []Field) error {
 	}
 	return err
 }
+
+type hookedWithFields struct {
+	Core
+	funcs   []func(Entry, []Field) error
+	context []Field
+}
+
+// RegisterHooksWithFields is like RegisterHooks, but the hooks also receive
+// the entry's fields: first those added via With, then those passed at the log
+// site. The hooks run after the wrapped Core writes the entry, so they mustn't
+// modify the fields.
+//
+// Since the hooks need the fields added via With, the returned Core keeps them
+// in addition to passing them to the wrapped Core.
+func RegisterHooksWithFields(core Core, hooks ...func(Entry, []Field) error) Core {
+	funcs := append([]func(Entry, []Field) error{}, hooks...)
+	return &hookedWithFields{
+		Core:  core,
+		funcs: funcs,
+	}
+}
+
+func (h *hookedWithFields) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	if downstream := h.Core.Check(ent, ce); downstream != nil {
+		return downstream.AddCore(ent, h)
+	}
+	return ce
+}
+
+func (h *hookedWithFields) With(fields []Field) Core {
+	context := make([]Field, 0, len(h.context)+len(fields))
+	context = append(context, h.context...)
+	context = append(context, fields...)
+	return &hookedWithFields{
+		Core:    h.Core.With(fields),
+		funcs:   h.funcs,
+		context: context,
+	}
+}
+
+func (h *hookedWithFields) Write(ent Entry, fields []Field) error {
+	// As in hooked, the downstream Core registered itself with the
+	// CheckedEntry, so only the hooks run here.
+	all := fields
+	if len(h.context) > 0 {
+		all = make([]Field, 0, len(h.context)+len(fields))
+		all = append(all, h.context...)
+		all = append(all, fields...)
+	}
+	var err error
+	for i := range h.funcs {
+		err = multierr.Append(err, h.funcs[i](ent, all))
+	}
+	return err
+}
+
+type fieldHooked struct {
+	Core
+	funcs []func(Entry) []Field
+}
+
+// RegisterFieldHooks wraps a Core and runs a collection of user-defined
+// callback hooks each time a message is logged, appending the fields they
+// return to the fields supplied at the log site. Execution of the callbacks is
+// blocking.
+//
+// Unlike fields added via With, which are serialized once, the hooks run
+// separately for each entry, and only if the wrapped Core decides to log it.
+// This makes them suitable for context that changes over time and is too
+// expensive to compute for disabled log levels.
+func RegisterFieldHooks(core Core, hooks ...func(Entry) []Field) Core {
+	funcs := append([]func(Entry) []Field{}, hooks...)
+	return &fieldHooked{
+		Core:  core,
+		funcs: funcs,
+	}
+}
+
+func (h *fieldHooked) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkRewritingFields(h.Core, ent, ce, h.appendFields)
+}
+
+func (h *fieldHooked) With(fields []Field) Core {
+	return &fieldHooked{
+		Core:  h.Core.With(fields),
+		funcs: h.funcs,
+	}
+}
+
+func (h *fieldHooked) Write(ent Entry, fields []Field) error {
+	return h.Core.Write(ent, h.appendFields(ent, fields))
+}
+
+func (h *fieldHooked) appendFields(ent Entry, fields []Field) []Field {
+	// Copy the fields so that we never write into the caller's backing array.
+	all := make([]Field, len(fields), len(fields)+len(h.funcs))
+	copy(all, fields)
+	for i := range h.funcs {
+		all = append(all, h.funcs[i](ent)...)
+	}
+	return all
+}
+
+// checkRewritingFields lets core decide whether to log an entry, so that
+// sampling, tees, and the like behave as usual. Any Cores it registers with
+// the CheckedEntry are then swapped out for a single Core that passes the
+// entry's fields through rewrite before writing.
+//
+// It's useful for Cores that need to inspect or alter the fields passed at
+// the log site; simply registering themselves with the CheckedEntry and
+// calling the wrapped Core's Write method would bypass the wrapped Core's
+// Check logic.
+func checkRewritingFields(core Core, ent Entry, ce *CheckedEntry, rewrite func(Entry, []Field) []Field) *CheckedEntry {
+	ce, downstream := checkWrappingCores(core, ent, ce)
+	if downstream != nil {
+		downstream.rewrite = rewrite
+	}
+	return ce
+}
+
+// checkFilteringFields is like checkRewritingFields, but rather than
+// rewriting the entry's fields, it uses them to decide whether to write the
+// entry at all. It's useful for Cores whose decision depends on the fields
+// passed at the log site, which aren't available until the entry is written.
+func checkFilteringFields(core Core, ent Entry, ce *CheckedEntry, keep func(Entry, []Field) bool) *CheckedEntry {
+	ce, downstream := checkWrappingCores(core, ent, ce)
+	if downstream != nil {
+		downstream.keep = keep
+	}
+	return ce
+}
+
+// checkWrappingCores lets core check the entry, then swaps any Cores it
+// registers with the CheckedEntry for a single rewritingWriter. It returns
+// a nil rewritingWriter if core didn't register any Cores.
+func checkWrappingCores(core Core, ent Entry, ce *CheckedEntry) (*CheckedEntry, *rewritingWriter) {
+	var start int
+	if ce != nil {
+		start = len(ce.cores)
+	}
+	ce = core.Check(ent, ce)
+	if ce == nil || len(ce.cores) == start {
+		return ce, nil
+	}
+	downstream := getRewritingWriter()
+	downstream.multiCore = append(downstream.multiCore, ce.cores[start:]...)
+	for i := start; i < len(ce.cores); i++ {
+		// don't keep references to cores
+		ce.cores[i] = nil
+	}
+	ce.cores = append(ce.cores[:start], downstream)
+	return ce, downstream
+}
+
+var _rewritingWriterPool = sync.Pool{New: func() interface{} {
+	// Pre-allocate some space for cores.
+	return &rewritingWriter{multiCore: make(multiCore, 0, 4)}
+}}
+
+// rewritingWriter writes to the Cores that agreed to log a single entry,
+// filtering the entry or rewriting its fields first.
+//
+// Since a CheckedEntry is written at most once, its rewritingWriter is
+// returned to a pool as soon as it's done writing. (If the CheckedEntry is
+// never written, the rewritingWriter is simply garbage collected.) The
+// rewritten fields, on the other hand, are never pooled: Cores that write
+// asynchronously may hold on to them after Write returns.
+type rewritingWriter struct {
+	multiCore
+	entry   func(Entry) Entry
+	rewrite func(Entry, []Field) []Field
+	keep    func(Entry, []Field) bool
+}
+
+func getRewritingWriter() *rewritingWriter {
+	return _rewritingWriterPool.Get().(*rewritingWriter)
+}
+
+func putRewritingWriter(w *rewritingWriter) {
+	for i := range w.multiCore {
+		// don't keep references to cores
+		w.multiCore[i] = nil
+	}
+	w.multiCore = w.multiCore[:0]
+	w.entry = nil
+	w.rewrite = nil
+	w.keep = nil
+	_rewritingWriterPool.Put(w)
+}
+
+func (w *rewritingWriter) Write(ent Entry, fields []Field) error {
+	var err error
+	if w.entry != nil {
+		ent = w.entry(ent)
+	}
+	if w.keep == nil || w.keep(ent, fields) {
+		if w.rewrite != nil {
+			fields = w.rewrite(ent, fields)
+		}
+		err = w.multiCore.Write(ent, fields)
+	}
+	putRewritingWriter(w)
+	return err
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+)
+
+// benchmarkRewritingCore measures the cost of logging through Cores that
+// rewrite fields. CheckedEntry is pooled regardless, so the allocations
+// reported here are the per-entry writers' and the rewritten fields'.
+func benchmarkRewritingCore(b *testing.B, wrap func(Core) Core) {
+	core := wrap(NewCore(NewJSONEncoder(testEncoderConfig()), &ztest.Discarder{}, DebugLevel))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if ce := core.Check(Entry{Level: InfoLevel, Message: "fake"}, nil); ce != nil {
+				ce.Write(makeInt64Field("i", 1))
+			}
+		}
+	})
+}
+
+func BenchmarkFieldHooks(b *testing.B) {
+	benchmarkRewritingCore(b, func(core Core) Core {
+		return RegisterFieldHooks(core, func(Entry) []Field { return nil })
+	})
+}
+
+func BenchmarkTagCoreWithoutTags(b *testing.B) {
+	benchmarkRewritingCore(b, func(core Core) Core {
+		return NewTagCore(core, "tags")
+	})
+}
+
+func BenchmarkNestedRewritingCores(b *testing.B) {
+	benchmarkRewritingCore(b, func(core Core) Core {
+		return NewTemplateCore(NewTagCore(NewStatsCore(core, time.Minute, "stats"), "tags"))
+	})
+}
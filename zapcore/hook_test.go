@@ -71,3 +71,103 @@ func TestHooks(t *testing.T) {
 		}
 	}
 }
+
+func TestHooksWithFields(t *testing.T) {
+	core, logs := observer.New(InfoLevel)
+	ctxField := makeInt64Field("ctx", 1)
+	siteField := makeStringField("site", "x")
+
+	var seen [][]Field
+	h := RegisterHooksWithFields(core, func(ent Entry, fields []Field) error {
+		assert.Equal(t, "msg", ent.Message, "Hook called with unexpected Entry.")
+		seen = append(seen, fields)
+		return nil
+	})
+
+	for _, c := range []Core{h, h.With([]Field{ctxField})} {
+		if ce := c.Check(Entry{Level: InfoLevel, Message: "msg"}, nil); ce != nil {
+			ce.Write(siteField)
+		}
+		assert.Nil(t, c.Check(Entry{Level: DebugLevel, Message: "msg"}, nil), "Expected disabled entries to be dropped.")
+	}
+
+	assert.Equal(t, [][]Field{{siteField}, {ctxField, siteField}}, seen, "Expected hooks to see context and log-site fields.")
+	assert.Equal(t, 2, logs.Len(), "Expected the wrapped Core to write each entry.")
+}
+
+func TestFieldHooks(t *testing.T) {
+	debugCore, debugLogs := observer.New(DebugLevel)
+	warnCore, warnLogs := observer.New(WarnLevel)
+	ctxField := makeInt64Field("foo", 42)
+
+	var called int
+	hook := func(e Entry) []Field {
+		called++
+		return []Field{makeInt64Field("calls", called)}
+	}
+	h := RegisterFieldHooks(NewTee(debugCore, warnCore), hook).With([]Field{ctxField})
+
+	siteFields := make([]Field, 1, 2)
+	siteFields[0] = makeInt64Field("site", 1)
+	for _, lvl := range []Level{InfoLevel, ErrorLevel} {
+		ent := Entry{Message: "bar", Level: lvl}
+		if ce := h.Check(ent, nil); ce != nil {
+			ce.Write(siteFields...)
+		}
+	}
+	assert.Equal(t, 2, called, "Expected to call hook once per written entry.")
+	assert.Equal(t, Field{}, siteFields[:2][1], "Hook fields shouldn't be appended to the caller's slice.")
+
+	assert.Equal(t, []observer.LoggedEntry{
+		{
+			Entry:   Entry{Message: "bar", Level: InfoLevel},
+			Context: []Field{ctxField, makeInt64Field("site", 1), makeInt64Field("calls", 1)},
+		},
+		{
+			Entry:   Entry{Message: "bar", Level: ErrorLevel},
+			Context: []Field{ctxField, makeInt64Field("site", 1), makeInt64Field("calls", 2)},
+		},
+	}, debugLogs.AllUntimed(), "Unexpected logs written to debug-level core.")
+	assert.Equal(t, []observer.LoggedEntry{
+		{
+			Entry:   Entry{Message: "bar", Level: ErrorLevel},
+			Context: []Field{ctxField, makeInt64Field("site", 1), makeInt64Field("calls", 2)},
+		},
+	}, warnLogs.AllUntimed(), "Tee'd core should only see entries at its level.")
+}
+
+func TestFieldHooksDisabled(t *testing.T) {
+	core, logs := observer.New(InfoLevel)
+	var called bool
+	h := RegisterFieldHooks(core, func(Entry) []Field {
+		called = true
+		return nil
+	})
+
+	assert.Nil(t, h.Check(Entry{Level: DebugLevel}, nil), "Expected disabled entry to be dropped.")
+	assert.False(t, called, "Hooks shouldn't run for disabled levels.")
+	assert.Equal(t, 0, logs.Len(), "Unexpected logs written out.")
+}
+
+func TestFieldHooksInterleavedEntries(t *testing.T) {
+	// Each checked entry gets its own pooled writer, so entries that are
+	// checked before any of them are written mustn't interfere.
+	core, logs := observer.New(DebugLevel)
+	field := func(key string) func(Entry) []Field {
+		return func(Entry) []Field { return []Field{makeInt64Field(key, 1)} }
+	}
+	a := RegisterFieldHooks(core, field("a"))
+	b := RegisterFieldHooks(core, field("b"))
+
+	for i := 0; i < 3; i++ {
+		ceA := a.Check(Entry{Message: "a"}, nil)
+		ceB := b.Check(Entry{Message: "b"}, nil)
+		ceB.Write()
+		ceA.Write()
+	}
+
+	for _, ent := range logs.AllUntimed() {
+		assert.Equal(t, []Field{makeInt64Field(ent.Message, 1)}, ent.Context, "Unexpected fields on entry %q.", ent.Message)
+	}
+	assert.Equal(t, 6, logs.Len(), "Expected every entry to be written.")
+}
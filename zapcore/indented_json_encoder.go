@@ -0,0 +1,159 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"go.uber.org/zap/buffer"
+)
+
+type indentedJSONEncoder struct {
+	*jsonEncoder
+	prefix, indent string
+}
+
+// NewIndentedJSONEncoder creates an encoder like the one returned by
+// NewJSONEncoder, but which spreads each entry over several lines, with
+// nested objects and arrays indented. Every line starts with prefix and each
+// level of nesting adds one copy of indent, as in json.Indent. The output is
+// still valid JSON, one document per entry, terminated by the line ending.
+//
+// Indenting re-scans and copies each encoded entry, so it's best reserved for
+// development, where entries are read by people rather than machines. The
+// encoders returned by NewJSONEncoder are unaffected. To indent the output of an
+// existing JSON encoder instead, see NewIndentingWriteSyncer.
+func NewIndentedJSONEncoder(cfg EncoderConfig, prefix, indent string) Encoder {
+	return &indentedJSONEncoder{
+		jsonEncoder: newJSONEncoder(cfg, false),
+		prefix:      prefix,
+		indent:      indent,
+	}
+}
+
+func (enc *indentedJSONEncoder) Clone() Encoder {
+	return &indentedJSONEncoder{
+		jsonEncoder: enc.clone(),
+		prefix:      enc.prefix,
+		indent:      enc.indent,
+	}
+}
+
+func (enc *indentedJSONEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	line, err := enc.jsonEncoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	ending := enc.LineEnding
+	if ending == "" {
+		ending = DefaultLineEnding
+	}
+	compact := bytes.TrimSuffix(line.Bytes(), []byte(ending))
+	var indented bytes.Buffer
+	indented.WriteString(enc.prefix)
+	if err := json.Indent(&indented, compact, enc.prefix, enc.indent); err != nil {
+		// The JSON encoder's output is always valid, so this shouldn't
+		// happen; if it does, the compact entry is better than nothing.
+		return line, nil
+	}
+
+	line.Reset()
+	line.Write(indented.Bytes())
+	line.AppendString(ending)
+	return line, nil
+}
+
+type indentingWriteSyncer struct {
+	sync.Mutex
+	ws             WriteSyncer
+	prefix, indent string
+	partial        []byte
+}
+
+// NewIndentingWriteSyncer wraps a WriteSyncer so that each line of JSON
+// written to it is indented, as in json.Indent, before it's passed on. Lines
+// that aren't valid JSON are passed on unchanged. It's the post-processing
+// counterpart of NewIndentedJSONEncoder: wrapped around a development
+// logger's output, it makes the compact entries of any JSON encoder, or of
+// another process's logs, readable without reconfiguring their encoders.
+//
+// A line is passed on once its newline is written, so a partial line is held
+// back until the rest of it arrives or the WriteSyncer is synced. The
+// returned WriteSyncer is safe for concurrent use.
+func NewIndentingWriteSyncer(ws WriteSyncer, prefix, indent string) WriteSyncer {
+	return &indentingWriteSyncer{ws: ws, prefix: prefix, indent: indent}
+}
+
+func (s *indentingWriteSyncer) Write(p []byte) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	var out bytes.Buffer
+	rest := p
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		line := rest[:i]
+		if len(s.partial) > 0 {
+			line = append(s.partial, line...)
+			s.partial = s.partial[:0]
+		}
+		s.indentLine(&out, line)
+		rest = rest[i+1:]
+	}
+	s.partial = append(s.partial, rest...)
+
+	if out.Len() > 0 {
+		if _, err := s.ws.Write(out.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (s *indentingWriteSyncer) indentLine(out *bytes.Buffer, line []byte) {
+	compact := bytes.TrimSuffix(line, []byte("\r"))
+	start := out.Len()
+	out.WriteString(s.prefix)
+	if len(bytes.TrimSpace(compact)) == 0 || json.Indent(out, compact, s.prefix, s.indent) != nil {
+		out.Truncate(start)
+		out.Write(line)
+	}
+	out.WriteByte('\n')
+}
+
+func (s *indentingWriteSyncer) Sync() error {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.partial) > 0 {
+		_, err := s.ws.Write(s.partial)
+		s.partial = s.partial[:0]
+		if err != nil {
+			return err
+		}
+	}
+	return s.ws.Sync()
+}
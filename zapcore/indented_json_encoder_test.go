@@ -0,0 +1,113 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndentedJSONEncoder(t *testing.T) {
+	enc := NewIndentedJSONEncoder(EncoderConfig{MessageKey: "msg", LevelKey: "level", EncodeLevel: LowercaseLevelEncoder}, "", "  ")
+	enc.AddString("service", "api")
+	enc.OpenNamespace("req")
+
+	obj := ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		enc.AddString("name", "x")
+		return enc.AddArray("tags", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+			arr.AppendString("a")
+			arr.AppendString("b")
+			return nil
+		}))
+	})
+	buf, err := enc.EncodeEntry(
+		Entry{Level: InfoLevel, Message: "hello"},
+		[]Field{makeInt64Field("status", 200), {Key: "obj", Type: ObjectMarshalerType, Interface: obj}},
+	)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	expected := `{
+  "level": "info",
+  "msg": "hello",
+  "service": "api",
+  "req": {
+    "status": 200,
+    "obj": {
+      "name": "x",
+      "tags": [
+        "a",
+        "b"
+      ]
+    }
+  }
+}
+`
+	assert.Equal(t, expected, buf.String(), "Unexpected indented output.")
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded), "Expected valid JSON.")
+	buf.Free()
+}
+
+func TestIndentedJSONEncoderPrefixAndLineEnding(t *testing.T) {
+	enc := NewIndentedJSONEncoder(EncoderConfig{MessageKey: "msg", LineEnding: "\r\n"}, "> ", "\t")
+	clone := enc.Clone()
+	clone.AddString("k", "v")
+
+	buf, err := clone.EncodeEntry(Entry{Message: "hello"}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "> {\n> \t\"msg\": \"hello\",\n> \t\"k\": \"v\"\n> }\r\n", buf.String(), "Unexpected indented output.")
+	buf.Free()
+
+	buf, err = enc.EncodeEntry(Entry{Message: "hello"}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "> {\n> \t\"msg\": \"hello\"\n> }\r\n", buf.String(), "Expected the original encoder to be unaffected by its clone.")
+	buf.Free()
+}
+
+func TestIndentingWriteSyncer(t *testing.T) {
+	buf := &ztest.Buffer{}
+	ws := NewIndentingWriteSyncer(buf, "", "  ")
+
+	input := []byte(`{"msg":"a","n":[1]}` + "\nnot json\n\n" + `{"msg":`)
+	n, err := ws.Write(input)
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, len(input), n, "Expected to report the whole input as written.")
+	assert.Equal(t, "{\n  \"msg\": \"a\",\n  \"n\": [\n    1\n  ]\n}\nnot json\n\n", buf.String(), "Expected complete lines to be passed on.")
+
+	buf.Reset()
+	_, err = ws.Write([]byte(`"b"}` + "\r\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, "{\n  \"msg\": \"b\"\n}\n", buf.String(), "Expected partial lines to be joined.")
+
+	buf.Reset()
+	_, err = ws.Write([]byte("partial"))
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, "", buf.String(), "Expected a partial line to be held back.")
+	require.NoError(t, ws.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, "partial", buf.String(), "Expected Sync to pass on the partial line.")
+	assert.True(t, buf.Called(), "Expected Sync to sync the wrapped WriteSyncer.")
+}
@@ -82,11 +82,21 @@ func NewJSONEncoder(cfg EncoderConfig) Encoder {
 }
 
 func newJSONEncoder(cfg EncoderConfig, spaced bool) *jsonEncoder {
-	return &jsonEncoder{
+	enc := &jsonEncoder{
 		EncoderConfig: &cfg,
-		buf:           bufferpool.Get(),
 		spaced:        spaced,
 	}
+	enc.buf = enc.getBuffer()
+	return enc
+}
+
+// getBuffer gets a buffer from the configured BufferPool, if any, or from
+// the shared pool.
+func (enc *jsonEncoder) getBuffer() *buffer.Buffer {
+	if enc.EncoderConfig != nil && enc.BufferPool != nil {
+		return enc.BufferPool.Get()
+	}
+	return bufferpool.Get()
 }
 
 func (enc *jsonEncoder) AddArray(key string, arr ArrayMarshaler) error {
@@ -100,7 +110,10 @@ func (enc *jsonEncoder) AddObject(key string, obj ObjectMarshaler) error {
 }
 
 func (enc *jsonEncoder) AddBinary(key string, val []byte) {
-	enc.AddString(key, base64.StdEncoding.EncodeToString(val))
+	enc.addKey(key)
+	enc.buf.AppendByte('"')
+	enc.appendBase64(val)
+	enc.buf.AppendByte('"')
 }
 
 func (enc *jsonEncoder) AddByteString(key string, val []byte) {
@@ -135,7 +148,7 @@ func (enc *jsonEncoder) AddInt64(key string, val int64) {
 
 func (enc *jsonEncoder) resetReflectBuf() {
 	if enc.reflectBuf == nil {
-		enc.reflectBuf = bufferpool.Get()
+		enc.reflectBuf = enc.getBuffer()
 		enc.reflectEnc = json.NewEncoder(enc.reflectBuf)
 	} else {
 		enc.reflectBuf.Reset()
@@ -301,7 +314,7 @@ func (enc *jsonEncoder) clone() *jsonEncoder {
 	clone.EncoderConfig = enc.EncoderConfig
 	clone.spaced = enc.spaced
 	clone.openNamespaces = enc.openNamespaces
-	clone.buf = bufferpool.Get()
+	clone.buf = clone.getBuffer()
 	return clone
 }
 
@@ -350,6 +363,11 @@ func (enc *jsonEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer,
 			final.AppendString(ent.Caller.String())
 		}
 	}
+	if ent.Caller.Defined && final.FunctionKey != "" {
+		if fn := ent.Caller.Function(); fn != "" {
+			final.AddString(final.FunctionKey, fn)
+		}
+	}
 	if final.MessageKey != "" {
 		final.addKey(enc.MessageKey)
 		final.AppendString(ent.Message)
@@ -500,3 +518,23 @@ func (enc *jsonEncoder) tryAddRuneError(r rune, size int) bool {
 	}
 	return false
 }
+
+// _base64Chunk is the number of bytes appendBase64 encodes at a time. It's a
+// multiple of three, so that only the final chunk is padded.
+const _base64Chunk = 3 * 64
+
+// appendBase64 base64-encodes val directly into the buffer, a chunk at a time,
+// rather than allocating the encoded string. The base64 alphabet never needs
+// escaping in JSON.
+func (enc *jsonEncoder) appendBase64(val []byte) {
+	var dst [_base64Chunk / 3 * 4]byte
+	for len(val) > 0 {
+		n := len(val)
+		if n > _base64Chunk {
+			n = _base64Chunk
+		}
+		base64.StdEncoding.Encode(dst[:], val[:n])
+		enc.buf.Write(dst[:base64.StdEncoding.EncodedLen(n)])
+		val = val[n:]
+	}
+}
@@ -21,6 +21,7 @@
 package zapcore
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"math"
@@ -108,6 +109,8 @@ func TestJSONEncoderObjectFields(t *testing.T) {
 		f        func(Encoder)
 	}{
 		{"binary", `"k":"YWIxMg=="`, func(e Encoder) { e.AddBinary("k", []byte("ab12")) }},
+		{"binary", `"k":""`, func(e Encoder) { e.AddBinary("k", nil) }},
+		{"binary", `"k":""`, func(e Encoder) { e.AddBinary("k", []byte{}) }},
 		{"bool", `"k\\":true`, func(e Encoder) { e.AddBool(`k\`, true) }}, // test key escaping once
 		{"bool", `"k":true`, func(e Encoder) { e.AddBool("k", true) }},
 		{"bool", `"k":false`, func(e Encoder) { e.AddBool("k", false) }},
@@ -229,6 +232,40 @@ func TestJSONEncoderObjectFields(t *testing.T) {
 	}
 }
 
+func TestJSONEncoderLongBinary(t *testing.T) {
+	// Cover lengths around the boundaries between encoded chunks.
+	for _, n := range []int{_base64Chunk - 1, _base64Chunk, _base64Chunk + 1, 3*_base64Chunk + 2} {
+		val := make([]byte, n)
+		for i := range val {
+			val[i] = byte(i)
+		}
+		assertOutput(t, `"k":"`+base64.StdEncoding.EncodeToString(val)+`"`, func(e Encoder) { e.AddBinary("k", val) })
+	}
+}
+
+func TestJSONEncoderBinaryAllocs(t *testing.T) {
+	enc := &jsonEncoder{buf: bufferpool.Get(), EncoderConfig: &EncoderConfig{}}
+	val := make([]byte, 1024)
+	enc.AddBinary("warmup", val)
+	allocs := testing.AllocsPerRun(10, func() {
+		enc.truncate()
+		enc.AddBinary("k", val)
+	})
+	assert.Equal(t, 0.0, allocs, "Expected encoding binary data not to allocate.")
+}
+
+func TestJSONEncoderByteStringAllocs(t *testing.T) {
+	enc := &jsonEncoder{buf: bufferpool.Get(), EncoderConfig: &EncoderConfig{}}
+	val := []byte("some UTF-8 text, with \"quotes\" and ünicode")
+	enc.AddByteString("warmup", val)
+	allocs := testing.AllocsPerRun(10, func() {
+		enc.truncate()
+		enc.AddByteString("k", val)
+		enc.AppendByteString(val)
+	})
+	assert.Equal(t, 0.0, allocs, "Expected encoding byte strings not to allocate.")
+}
+
 func TestJSONEncoderArrays(t *testing.T) {
 	tests := []struct {
 		desc     string
@@ -21,12 +21,15 @@
 package zapcore_test
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -120,3 +123,71 @@ func TestJSONEncodeEntry(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONEncodeEntryOmittedKeys(t *testing.T) {
+	// Every combination of omitted metadata keys, with and without fields,
+	// should produce valid JSON containing exactly the remaining keys.
+	keys := []string{"level", "ts", "name", "caller", "msg", "stacktrace"}
+	ent := zapcore.Entry{
+		Level:      zapcore.InfoLevel,
+		Time:       time.Unix(0, 0),
+		LoggerName: "main",
+		Message:    "hello",
+		Caller:     zapcore.EntryCaller{Defined: true, File: "foo.go", Line: 42},
+		Stack:      "fake-stack",
+	}
+
+	for mask := 0; mask < 1<<uint(len(keys)); mask++ {
+		enabled := make([]string, len(keys))
+		expected := []string{}
+		for i, k := range keys {
+			if mask&(1<<uint(i)) != 0 {
+				enabled[i] = k
+				expected = append(expected, k)
+			}
+		}
+		cfg := zapcore.EncoderConfig{
+			LevelKey:       enabled[0],
+			TimeKey:        enabled[1],
+			NameKey:        enabled[2],
+			CallerKey:      enabled[3],
+			MessageKey:     enabled[4],
+			StacktraceKey:  enabled[5],
+			EncodeLevel:    zapcore.LowercaseLevelEncoder,
+			EncodeTime:     zapcore.EpochTimeEncoder,
+			EncodeDuration: zapcore.SecondsDurationEncoder,
+			EncodeCaller:   zapcore.ShortCallerEncoder,
+		}
+
+		for _, fields := range [][]zapcore.Field{nil, {zap.Int("n", 1)}} {
+			buf, err := zapcore.NewJSONEncoder(cfg).EncodeEntry(ent, fields)
+			require.NoError(t, err, "Unexpected error encoding entry with keys %v.", expected)
+
+			var decoded map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded), "Invalid JSON %q with keys %v.", buf.String(), expected)
+			got := make([]string, 0, len(decoded))
+			for _, k := range keys {
+				if _, ok := decoded[k]; ok {
+					got = append(got, k)
+				}
+			}
+			assert.Equal(t, len(expected)+len(fields), len(decoded), "Unexpected number of keys in %q.", buf.String())
+			assert.Equal(t, expected, got, "Unexpected metadata in %q.", buf.String())
+			buf.Free()
+		}
+	}
+}
+
+func TestJSONEncoderBufferPool(t *testing.T) {
+	pool := buffer.NewSizedPool(4096, 0)
+	cfg := zapcore.EncoderConfig{MessageKey: "msg", BufferPool: &pool}
+	for _, enc := range []zapcore.Encoder{zapcore.NewJSONEncoder(cfg), zapcore.NewConsoleEncoder(cfg)} {
+		enc = enc.Clone()
+		enc.AddString("k", "v")
+		buf, err := enc.EncodeEntry(zapcore.Entry{Message: "hello"}, []zapcore.Field{zap.Int("n", 1)})
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		assert.Contains(t, buf.String(), "hello", "Unexpected output.")
+		assert.True(t, buf.Cap() >= 4096, "Expected the buffer to come from the configured pool.")
+		buf.Free()
+	}
+}
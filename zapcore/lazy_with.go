@@ -0,0 +1,72 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import "sync"
+
+type lazyWithCore struct {
+	core   Core
+	fields []Field
+
+	once sync.Once
+	with Core
+}
+
+// NewLazyWith wraps a Core so that adding fields to it with With is deferred
+// until it's first asked to check an enabled entry, to write an entry, or to
+// derive another Core. Since many Cores serialize the fields passed to With right away,
+// that saves the cost of encoding context for child loggers that never log
+// anything, like per-request loggers for requests that succeed quietly.
+func NewLazyWith(core Core, fields []Field) Core {
+	return &lazyWithCore{core: core, fields: fields}
+}
+
+func (c *lazyWithCore) init() Core {
+	c.once.Do(func() {
+		c.with = c.core.With(c.fields)
+	})
+	return c.with
+}
+
+func (c *lazyWithCore) Enabled(lvl Level) bool {
+	// Adding fields doesn't change which levels are enabled.
+	return c.core.Enabled(lvl)
+}
+
+func (c *lazyWithCore) With(fields []Field) Core {
+	return c.init().With(fields)
+}
+
+func (c *lazyWithCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	// Don't add the fields for entries that are disabled anyway.
+	if !c.core.Enabled(ent.Level) {
+		return ce
+	}
+	return c.init().Check(ent, ce)
+}
+
+func (c *lazyWithCore) Write(ent Entry, fields []Field) error {
+	return c.init().Write(ent, fields)
+}
+
+func (c *lazyWithCore) Sync() error {
+	return c.core.Sync()
+}
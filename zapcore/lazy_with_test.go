@@ -0,0 +1,92 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"io/ioutil"
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withCounter counts the calls to its With method.
+type withCounter struct {
+	Core
+	calls *int
+}
+
+func (c withCounter) With(fields []Field) Core {
+	*c.calls++
+	return withCounter{Core: c.Core.With(fields), calls: c.calls}
+}
+
+func TestLazyWith(t *testing.T) {
+	var calls int
+	obs, logs := observer.New(InfoLevel)
+	core := NewLazyWith(withCounter{Core: obs, calls: &calls}, []Field{makeInt64Field("ctx", 1)})
+
+	assert.True(t, core.Enabled(InfoLevel), "Expected InfoLevel to be enabled.")
+	assert.False(t, core.Enabled(DebugLevel), "Expected DebugLevel to be disabled.")
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, 0, calls, "Expected fields not to be added before logging.")
+
+	for i := 0; i < 2; i++ {
+		if ce := core.Check(Entry{Level: InfoLevel, Message: "hello"}, nil); ce != nil {
+			ce.Write(makeInt64Field("n", i))
+		}
+	}
+	require.NoError(t, core.Write(Entry{Message: "direct"}, nil), "Unexpected error writing directly.")
+	assert.Equal(t, 1, calls, "Expected fields to be added exactly once.")
+
+	child := core.With([]Field{makeInt64Field("child", 1)})
+	assert.Equal(t, 2, calls, "Expected With to add both sets of fields.")
+	require.NoError(t, child.Write(Entry{Message: "child"}, nil), "Unexpected error writing to child.")
+
+	entries := logs.AllUntimed()
+	require.Equal(t, 4, len(entries), "Unexpected number of entries.")
+	assert.Equal(t, []Field{makeInt64Field("ctx", 1), makeInt64Field("n", 1)}, entries[1].Context, "Expected the deferred fields to be added.")
+	assert.Equal(t, []Field{makeInt64Field("ctx", 1), makeInt64Field("child", 1)}, entries[3].Context, "Expected the child to keep the deferred fields.")
+}
+
+func TestLazyWithDisabledLevel(t *testing.T) {
+	var calls int
+	ctx := ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		calls++
+		enc.AddString("k", "v")
+		return nil
+	})
+	enc := NewJSONEncoder(EncoderConfig{MessageKey: "msg"})
+	core := NewLazyWith(NewCore(enc, AddSync(ioutil.Discard), InfoLevel), []Field{
+		{Key: "ctx", Type: ObjectMarshalerType, Interface: ctx},
+	})
+
+	assert.Nil(t, core.Check(Entry{Level: DebugLevel}, nil), "Expected DebugLevel entries to be dropped.")
+	assert.Equal(t, 0, calls, "Expected the lazy context not to be marshaled for disabled entries.")
+
+	ce := core.Check(Entry{Level: InfoLevel}, nil)
+	require.NotNil(t, ce, "Expected InfoLevel entries to be logged.")
+	ce.Write()
+	assert.Equal(t, 1, calls, "Expected the lazy context to be marshaled once enabled.")
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"go.uber.org/zap/buffer"
+)
+
+type logfmtEncoder struct {
+	*textEncoder
+}
+
+// NewLogfmtEncoder creates an encoder whose output is logfmt: a single line
+// of space-separated key=value pairs, which many log aggregation services
+// (Heroku and Grafana Loki among them) parse natively. For example,
+//   ts=2016-10-01T12:00:00.000Z level=info msg="served request" path=/ status=200
+//
+// Unlike the text encoder, the logfmt encoder writes the entry's metadata as
+// key=value pairs too, using the keys in the encoder configuration, so every
+// entry fits on one line; stack traces are quoted with their newlines
+// escaped. Elements whose key is empty are omitted. Fields are serialized
+// exactly as they are by the text encoder: strings are quoted only when
+// necessary, nested objects and namespaces are flattened into dotted keys,
+// and arrays and reflected values are serialized as JSON.
+//
+// Encoding an entry doesn't allocate unless a field requires reflection or
+// an encoder in the configuration allocates.
+func NewLogfmtEncoder(cfg EncoderConfig) Encoder {
+	return logfmtEncoder{getTextEncoder(&cfg)}
+}
+
+func (enc logfmtEncoder) Clone() Encoder {
+	return logfmtEncoder{enc.textEncoder.Clone().(*textEncoder)}
+}
+
+func (enc logfmtEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	final := getTextEncoder(enc.EncoderConfig)
+
+	if final.TimeKey != "" {
+		final.AddTime(final.TimeKey, ent.Time)
+	}
+	if final.LevelKey != "" {
+		final.addKey(final.LevelKey)
+		cur := final.buf.Len()
+		if final.EncodeLevel != nil {
+			final.EncodeLevel(ent.Level, final)
+		}
+		if cur == final.buf.Len() {
+			final.AppendString(ent.Level.String())
+		}
+	}
+	if ent.LoggerName != "" && final.NameKey != "" {
+		final.addKey(final.NameKey)
+		cur := final.buf.Len()
+		nameEncoder := final.EncodeName
+
+		// if no name encoder provided, fall back to FullNameEncoder for backwards
+		// compatibility
+		if nameEncoder == nil {
+			nameEncoder = FullNameEncoder
+		}
+
+		nameEncoder(ent.LoggerName, final)
+		if cur == final.buf.Len() {
+			final.AppendString(ent.LoggerName)
+		}
+	}
+	if ent.Caller.Defined && final.CallerKey != "" {
+		final.addKey(final.CallerKey)
+		cur := final.buf.Len()
+		if final.EncodeCaller != nil {
+			final.EncodeCaller(ent.Caller, final)
+		}
+		if cur == final.buf.Len() {
+			final.AppendString(ent.Caller.String())
+		}
+	}
+	if ent.Caller.Defined && final.FunctionKey != "" {
+		if fn := ent.Caller.Function(); fn != "" {
+			final.AddString(final.FunctionKey, fn)
+		}
+	}
+	if final.MessageKey != "" {
+		final.AddString(final.MessageKey, ent.Message)
+	}
+	if enc.buf.Len() > 0 {
+		if final.buf.Len() > 0 {
+			final.buf.AppendByte(' ')
+		}
+		final.buf.Write(enc.buf.Bytes())
+	}
+
+	// Fields added at the log site belong to any namespace opened via With,
+	// but the stacktrace doesn't.
+	final.namespace = append(final.namespace, enc.namespace...)
+	addFields(final, fields)
+	final.namespace = final.namespace[:0]
+	if ent.Stack != "" && final.StacktraceKey != "" {
+		final.AddString(final.StacktraceKey, ent.Stack)
+	}
+
+	if final.LineEnding != "" {
+		final.buf.AppendString(final.LineEnding)
+	} else {
+		final.buf.AppendString(DefaultLineEnding)
+	}
+
+	ret := final.buf
+	final.buf = nil
+	putTextEncoder(final)
+	return ret, nil
+}
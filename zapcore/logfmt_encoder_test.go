@@ -0,0 +1,183 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogfmtEncoderEntry(t *testing.T) {
+	cfg := testEncoderConfig()
+	cfg.EncodeTime = ISO8601TimeEncoder
+	enc := NewLogfmtEncoder(cfg)
+	enc.AddString("service", "api")
+
+	ent := Entry{
+		Level:      InfoLevel,
+		Time:       time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC),
+		LoggerName: "main",
+		Message:    "served request",
+		Caller:     NewEntryCaller(0, "/src/app/server.go", 42, true),
+		Stack:      "goroutine 1\n\tmain.go:1",
+	}
+	buf, err := enc.EncodeEntry(ent, []Field{makeStringField("path", "/"), makeInt64Field("status", 200)})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		`ts=2016-10-01T12:00:00.000Z level=info name=main caller=app/server.go:42 msg="served request" `+
+			`service=api path=/ status=200 stacktrace="goroutine 1\n\tmain.go:1"`+"\n",
+		buf.String(),
+		"Unexpected encoded entry.",
+	)
+	buf.Free()
+
+	// Encoding an entry shouldn't change the encoder's context.
+	buf, err = enc.EncodeEntry(Entry{Message: "again"}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "ts=0001-01-01T00:00:00.000Z level=info msg=again service=api\n", buf.String(), "Unexpected encoded entry.")
+	buf.Free()
+}
+
+func TestLogfmtEncoderOmitsEmptyKeys(t *testing.T) {
+	enc := NewLogfmtEncoder(EncoderConfig{LineEnding: "\r\n"})
+	ent := Entry{
+		Message: "invisible",
+		Caller:  NewEntryCaller(0, "file.go", 1, true),
+		Stack:   "fake-stack",
+	}
+	buf, err := enc.EncodeEntry(ent, []Field{makeInt64Field("k", 1)})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "k=1\r\n", buf.String(), "Expected only fields without configured keys.")
+	buf.Free()
+}
+
+func TestLogfmtEncoderNoopEncoders(t *testing.T) {
+	cfg := testEncoderConfig()
+	cfg.EncodeTime = func(time.Time, PrimitiveArrayEncoder) {}
+	cfg.EncodeLevel = func(Level, PrimitiveArrayEncoder) {}
+	cfg.EncodeName = func(string, PrimitiveArrayEncoder) {}
+	cfg.EncodeCaller = func(EntryCaller, PrimitiveArrayEncoder) {}
+	enc := NewLogfmtEncoder(cfg)
+
+	ent := Entry{
+		Level:      WarnLevel,
+		Time:       time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC),
+		LoggerName: "main",
+		Message:    "m",
+		Caller:     NewEntryCaller(0, "file.go", 1, true),
+	}
+	buf, err := enc.EncodeEntry(ent, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		"ts=2016-10-01T12:00:00Z level=warn name=main caller=file.go:1 msg=m\n",
+		buf.String(),
+		"Expected no-op encoders to fall back to strings.",
+	)
+	buf.Free()
+}
+
+func TestLogfmtEncoderNamespaces(t *testing.T) {
+	enc := NewLogfmtEncoder(testEncoderConfig())
+	enc.AddString("a", "1")
+	enc.OpenNamespace("ns")
+	enc.AddString("b", "2")
+
+	clone := enc.Clone()
+	clone.AddString("c", "3")
+
+	ent := Entry{Time: time.Unix(0, 0), Message: "msg", Stack: "stack"}
+	buf, err := clone.EncodeEntry(ent, []Field{makeStringField("d", "4")})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		"ts=0 level=info msg=msg a=1 ns.b=2 ns.c=3 ns.d=4 stacktrace=stack\n",
+		buf.String(),
+		"Expected fields to stay in the open namespace, but not the stacktrace.",
+	)
+	buf.Free()
+
+	buf, err = enc.EncodeEntry(ent, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "ts=0 level=info msg=msg a=1 ns.b=2 stacktrace=stack\n", buf.String(), "Clone shouldn't affect the original encoder.")
+	buf.Free()
+}
+
+func TestLogfmtEncoderQuotesKeys(t *testing.T) {
+	enc := NewLogfmtEncoder(EncoderConfig{})
+	enc.OpenNamespace("my ns")
+	enc.AddString("ok", "1")
+	buf, err := enc.EncodeEntry(Entry{}, []Field{
+		makeStringField("bad key", "v"),
+		makeStringField("a=b", "c"),
+		makeStringField(`q"uote`, "d"),
+		makeStringField("ctl\x01", "e"),
+	})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		`"my ns.ok"=1 "my ns.bad key"=v "my ns.a=b"=c "my ns.q\"uote"=d "my ns.ctl\x01"=e`+"\n",
+		buf.String(),
+		"Expected ambiguous keys to be quoted and escaped.",
+	)
+	buf.Free()
+
+	enc = NewLogfmtEncoder(EncoderConfig{})
+	buf, err = enc.EncodeEntry(Entry{}, []Field{makeStringField("", "v"), makeStringField("plain", "w")})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, `""=v plain=w`+"\n", buf.String(), "Expected empty keys to be quoted.")
+	buf.Free()
+}
+
+func TestLogfmtEncoderAllocs(t *testing.T) {
+	if ztest.RaceEnabled {
+		t.Skip("The race detector makes pooled encoders allocate.")
+	}
+	enc := NewLogfmtEncoder(testEncoderConfig())
+	enc.AddString("service", "api")
+	ent := Entry{
+		Level:   ErrorLevel,
+		Message: "request failed",
+		Stack:   "fake-stack",
+	}
+	fields := []Field{
+		makeStringField("path", `/a "quoted" path`),
+		makeInt64Field("status", 500),
+		{Key: "took", Type: DurationType, Integer: int64(time.Millisecond)},
+		{Key: "req", Type: ObjectMarshalerType, Interface: ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+			enc.AddString("method", "GET")
+			enc.AddBool("tls", true)
+			return nil
+		})},
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		buf, _ := enc.EncodeEntry(ent, fields)
+		buf.Free()
+	})
+	assert.Equal(t, float64(0), allocs, "Expected encoding an entry not to allocate.")
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import "go.uber.org/zap/buffer"
+
+type messagePrefixEncoder struct {
+	Encoder
+	prefixes map[Level]string
+}
+
+// NewMessagePrefixEncoder wraps an Encoder so that the messages of entries at
+// some levels are prefixed with a fixed string, like "⚠ " for warnings. It's
+// a readability aid that makes severe entries stand out in a stream of
+// development logs; levels without a prefix are left alone.
+//
+// Since the prefix becomes part of the message, it's usually only worth
+// wrapping human-oriented encoders, like the console encoder; machine-readable
+// output is only affected if its encoder is wrapped explicitly.
+func NewMessagePrefixEncoder(enc Encoder, prefixes map[Level]string) Encoder {
+	ps := make(map[Level]string, len(prefixes))
+	for lvl, p := range prefixes {
+		ps[lvl] = p
+	}
+	return messagePrefixEncoder{
+		Encoder:  enc,
+		prefixes: ps,
+	}
+}
+
+func (m messagePrefixEncoder) Clone() Encoder {
+	return messagePrefixEncoder{
+		Encoder:  m.Encoder.Clone(),
+		prefixes: m.prefixes,
+	}
+}
+
+func (m messagePrefixEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	if p, ok := m.prefixes[ent.Level]; ok {
+		ent.Message = p + ent.Message
+	}
+	return m.Encoder.EncodeEntry(ent, fields)
+}
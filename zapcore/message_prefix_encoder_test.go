@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessagePrefixEncoder(t *testing.T) {
+	prefixes := map[Level]string{WarnLevel: "⚠ ", ErrorLevel: "!! "}
+	enc := NewMessagePrefixEncoder(NewConsoleEncoder(EncoderConfig{
+		MessageKey:  "msg",
+		LevelKey:    "level",
+		EncodeLevel: CapitalLevelEncoder,
+	}), prefixes)
+	// Changing the caller's map shouldn't affect the encoder.
+	prefixes[InfoLevel] = "ignored "
+	enc.AddString("foo", "bar")
+	clone := enc.Clone()
+
+	tests := []struct {
+		lvl      Level
+		expected string
+	}{
+		{DebugLevel, "DEBUG\thello\t{\"foo\": \"bar\"}\n"},
+		{InfoLevel, "INFO\thello\t{\"foo\": \"bar\"}\n"},
+		{WarnLevel, "WARN\t⚠ hello\t{\"foo\": \"bar\"}\n"},
+		{ErrorLevel, "ERROR\t!! hello\t{\"foo\": \"bar\"}\n"},
+	}
+
+	for _, tt := range tests {
+		for _, e := range []Encoder{enc, clone} {
+			buf, err := e.EncodeEntry(Entry{Level: tt.lvl, Message: "hello"}, nil)
+			require.NoError(t, err, "Unexpected error encoding entry.")
+			assert.Equal(t, tt.expected, buf.String(), "Unexpected output at level %v.", tt.lvl)
+			buf.Free()
+		}
+	}
+}
+
+func TestMessagePrefixEncoderJSON(t *testing.T) {
+	cfg := EncoderConfig{MessageKey: "msg"}
+	ent := Entry{Level: WarnLevel, Message: "hello"}
+
+	plain, err := NewJSONEncoder(cfg).EncodeEntry(ent, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, `{"msg":"hello"}`+"\n", plain.String(), "Expected unwrapped JSON to be unaffected.")
+
+	prefixed, err := NewMessagePrefixEncoder(NewJSONEncoder(cfg), map[Level]string{WarnLevel: "⚠ "}).EncodeEntry(ent, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, `{"msg":"⚠ hello"}`+"\n", prefixed.String(), "Expected explicitly wrapped JSON to be prefixed.")
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+// NewMirrorCore creates a Core that writes every entry to the primary Core
+// and additionally mirrors entries enabled by enab to a secondary
+// WriteSyncer, syncing it after each write. This pairs a fast, possibly
+// buffered primary sink with a durable record of the entries that matter
+// most, like
+//   NewMirrorCore(core, NewJSONEncoder(cfg), errorFile, ErrorLevel)
+//
+// The primary Core is always written first, and a failure to write to or
+// sync the secondary doesn't prevent the entry from reaching the primary;
+// any such error is returned from Write alongside the primary's.
+func NewMirrorCore(primary Core, enc Encoder, ws WriteSyncer, enab LevelEnabler) Core {
+	return NewTee(primary, &syncingCore{&ioCore{
+		LevelEnabler: enab,
+		enc:          enc,
+		out:          ws,
+	}})
+}
+
+// syncingCore is an ioCore that syncs its output after every write.
+type syncingCore struct {
+	*ioCore
+}
+
+func (c *syncingCore) With(fields []Field) Core {
+	clone := c.clone()
+	addFields(clone.enc, fields)
+	return &syncingCore{clone}
+}
+
+func (c *syncingCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syncingCore) Write(ent Entry, fields []Field) error {
+	if err := c.ioCore.Write(ent, fields); err != nil {
+		return err
+	}
+	return c.Sync()
+}
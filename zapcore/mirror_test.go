@@ -0,0 +1,86 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorCore(t *testing.T) {
+	primary, logs := observer.New(DebugLevel)
+	secondary := &ztest.Buffer{}
+	core := NewMirrorCore(
+		primary,
+		NewJSONEncoder(EncoderConfig{MessageKey: "msg"}),
+		secondary,
+		ErrorLevel,
+	).With([]Field{makeInt64Field("k", 1)})
+
+	for _, ent := range []Entry{
+		{Level: InfoLevel, Message: "info"},
+		{Level: ErrorLevel, Message: "error"},
+		{Level: DebugLevel, Message: "debug"},
+	} {
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(makeInt64Field("n", 2))
+		}
+	}
+
+	assert.Equal(t, 3, logs.Len(), "Expected all entries to reach the primary core.")
+	assert.Equal(t, []string{`{"msg":"error","k":1,"n":2}`}, secondary.Lines(), "Expected only errors to be mirrored.")
+	assert.True(t, secondary.Called(), "Expected the secondary sink to be synced.")
+}
+
+func TestMirrorCoreSecondaryFailure(t *testing.T) {
+	primary, logs := observer.New(DebugLevel)
+	errSync := errors.New("sync failed")
+
+	tests := []struct {
+		desc      string
+		secondary WriteSyncer
+	}{
+		{"write fails", &ztest.FailWriter{}},
+		{"sync fails", func() WriteSyncer {
+			ws := &ztest.Discarder{}
+			ws.SetError(errSync)
+			return ws
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			logs.TakeAll()
+			core := NewMirrorCore(primary, NewJSONEncoder(EncoderConfig{}), tt.secondary, ErrorLevel)
+			ce := core.Check(Entry{Level: ErrorLevel, Message: "fail"}, nil)
+			require.NotNil(t, ce, "Expected an error to be enabled.")
+			assert.Error(t, core.Write(Entry{Level: ErrorLevel, Message: "fail"}, nil), "Expected the secondary's error to be returned.")
+			assert.Equal(t, 1, logs.Len(), "Expected the entry to reach the primary core despite the secondary failing.")
+		})
+	}
+}
@@ -0,0 +1,604 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/internal/bufferpool"
+)
+
+var _msgpackPool = sync.Pool{New: func() interface{} {
+	return &msgpackEncoder{}
+}}
+
+func getMsgpackEncoder(cfg *EncoderConfig) *msgpackEncoder {
+	enc := _msgpackPool.Get().(*msgpackEncoder)
+	enc.EncoderConfig = cfg
+	enc.scopes = append(enc.scopes[:0], msgpackScope{buf: bufferpool.Get()})
+	return enc
+}
+
+func putMsgpackEncoder(enc *msgpackEncoder) {
+	for i := range enc.scopes {
+		if enc.scopes[i].buf != nil {
+			enc.scopes[i].buf.Free()
+		}
+		enc.scopes[i] = msgpackScope{}
+	}
+	enc.EncoderConfig = nil
+	enc.scopes = enc.scopes[:0]
+	_msgpackPool.Put(enc)
+}
+
+// A msgpackScope is a map (or array) under construction. Since MessagePack
+// prefixes maps and arrays with their length, elements are buffered until
+// the scope is closed.
+type msgpackScope struct {
+	key string // for namespaces
+	buf *buffer.Buffer
+	n   int // number of key-value pairs or array elements
+}
+
+type msgpackEncoder struct {
+	*EncoderConfig
+	// The first scope is the root object; each namespace opens another.
+	// Elements are always added to the last scope.
+	scopes []msgpackScope
+}
+
+// NewMsgpackEncoder creates an encoder that serializes each entry as a
+// MessagePack map (see https://msgpack.org), which is more compact than JSON
+// and is understood natively by collectors like Fluentd.
+//
+// MessagePack values are self-delimiting, so encoded entries are simply
+// concatenated: the encoder ignores the configured LineEnding. Integers use
+// the most compact representation that preserves their value, floats keep the
+// precision of their Go type, strings and ByteStrings are encoded as str, and
+// Binary fields as bin. Complex numbers have no MessagePack equivalent, so
+// they're encoded as strings (e.g., "1+2i"). Times and durations are handed
+// to the configured TimeEncoder and DurationEncoder, falling back to integer
+// nanoseconds.
+//
+// Values added via reflection are first marshaled with encoding/json, so they
+// follow that package's conventions for field names and omitted fields.
+func NewMsgpackEncoder(cfg EncoderConfig) Encoder {
+	return getMsgpackEncoder(&cfg)
+}
+
+func (enc *msgpackEncoder) cur() *msgpackScope {
+	return &enc.scopes[len(enc.scopes)-1]
+}
+
+func (enc *msgpackEncoder) root() *msgpackScope {
+	return &enc.scopes[0]
+}
+
+// addKey writes a key. Keys aren't counted; the value that follows is.
+func (enc *msgpackEncoder) addKey(key string) {
+	appendMsgpackString(enc.cur().buf, key)
+}
+
+func (enc *msgpackEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	enc.addKey(key)
+	return enc.AppendArray(arr)
+}
+
+func (enc *msgpackEncoder) AddObject(key string, obj ObjectMarshaler) error {
+	enc.addKey(key)
+	return enc.AppendObject(obj)
+}
+
+func (enc *msgpackEncoder) AddBinary(key string, val []byte) {
+	enc.addKey(key)
+	cur := enc.cur()
+	appendMsgpackBinary(cur.buf, val)
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AddByteString(key string, val []byte) {
+	enc.addKey(key)
+	enc.AppendByteString(val)
+}
+
+func (enc *msgpackEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.AppendBool(val)
+}
+
+func (enc *msgpackEncoder) AddComplex128(key string, val complex128) {
+	enc.addKey(key)
+	enc.AppendComplex128(val)
+}
+
+func (enc *msgpackEncoder) AddDuration(key string, val time.Duration) {
+	enc.addKey(key)
+	enc.AppendDuration(val)
+}
+
+func (enc *msgpackEncoder) AddFloat64(key string, val float64) {
+	enc.addKey(key)
+	enc.AppendFloat64(val)
+}
+
+func (enc *msgpackEncoder) AddFloat32(key string, val float32) {
+	enc.addKey(key)
+	enc.AppendFloat32(val)
+}
+
+func (enc *msgpackEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.AppendInt64(val)
+}
+
+func (enc *msgpackEncoder) AddReflected(key string, obj interface{}) error {
+	val, err := toMsgpackGeneric(obj)
+	if err != nil {
+		return err
+	}
+	enc.addKey(key)
+	cur := enc.cur()
+	appendMsgpackGeneric(cur.buf, val)
+	cur.n++
+	return nil
+}
+
+func (enc *msgpackEncoder) OpenNamespace(key string) {
+	enc.scopes = append(enc.scopes, msgpackScope{key: key, buf: bufferpool.Get()})
+}
+
+func (enc *msgpackEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.AppendString(val)
+}
+
+func (enc *msgpackEncoder) AddTime(key string, val time.Time) {
+	enc.addKey(key)
+	enc.AppendTime(val)
+}
+
+func (enc *msgpackEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.AppendUint64(val)
+}
+
+func (enc *msgpackEncoder) AppendArray(arr ArrayMarshaler) error {
+	child := getMsgpackEncoder(enc.EncoderConfig)
+	err := arr.MarshalLogArray(child)
+	child.closeNamespaces()
+
+	cur := enc.cur()
+	appendMsgpackArrayHeader(cur.buf, child.root().n)
+	cur.buf.Write(child.root().buf.Bytes())
+	cur.n++
+	putMsgpackEncoder(child)
+	return err
+}
+
+func (enc *msgpackEncoder) AppendObject(obj ObjectMarshaler) error {
+	child := getMsgpackEncoder(enc.EncoderConfig)
+	err := obj.MarshalLogObject(child)
+	child.closeNamespaces()
+
+	cur := enc.cur()
+	appendMsgpackMapHeader(cur.buf, child.root().n)
+	cur.buf.Write(child.root().buf.Bytes())
+	cur.n++
+	putMsgpackEncoder(child)
+	return err
+}
+
+func (enc *msgpackEncoder) AppendBool(val bool) {
+	cur := enc.cur()
+	if val {
+		cur.buf.AppendByte(0xc3)
+	} else {
+		cur.buf.AppendByte(0xc2)
+	}
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AppendByteString(val []byte) {
+	cur := enc.cur()
+	appendMsgpackStringHeader(cur.buf, len(val))
+	cur.buf.Write(val)
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AppendComplex128(val complex128) {
+	// MessagePack has no complex type, so use the same representation as the
+	// JSON encoder.
+	r, i := float64(real(val)), float64(imag(val))
+	s := bufferpool.Get()
+	s.AppendFloat(r, 64)
+	s.AppendByte('+')
+	s.AppendFloat(i, 64)
+	s.AppendByte('i')
+	enc.AppendByteString(s.Bytes())
+	s.Free()
+}
+
+func (enc *msgpackEncoder) AppendDuration(val time.Duration) {
+	cur := enc.cur().n
+	if enc.EncodeDuration != nil {
+		enc.EncodeDuration(val, enc)
+	}
+	if cur == enc.cur().n {
+		// User-supplied EncodeDuration is missing or a no-op. Fall back to
+		// nanoseconds.
+		enc.AppendInt64(int64(val))
+	}
+}
+
+func (enc *msgpackEncoder) AppendFloat64(val float64) {
+	cur := enc.cur()
+	cur.buf.AppendByte(0xcb)
+	appendBigEndian(cur.buf, math.Float64bits(val), 8)
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AppendFloat32(val float32) {
+	cur := enc.cur()
+	cur.buf.AppendByte(0xca)
+	appendBigEndian(cur.buf, uint64(math.Float32bits(val)), 4)
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AppendInt64(val int64) {
+	cur := enc.cur()
+	appendMsgpackInt(cur.buf, val)
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AppendReflected(val interface{}) error {
+	v, err := toMsgpackGeneric(val)
+	if err != nil {
+		return err
+	}
+	cur := enc.cur()
+	appendMsgpackGeneric(cur.buf, v)
+	cur.n++
+	return nil
+}
+
+func (enc *msgpackEncoder) AppendString(val string) {
+	cur := enc.cur()
+	appendMsgpackString(cur.buf, val)
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AppendTime(val time.Time) {
+	cur := enc.cur().n
+	if enc.EncodeTime != nil {
+		enc.EncodeTime(val, enc)
+	}
+	if cur == enc.cur().n {
+		// User-supplied EncodeTime is missing or a no-op. Fall back to nanos
+		// since epoch.
+		enc.AppendInt64(val.UnixNano())
+	}
+}
+
+func (enc *msgpackEncoder) AppendUint64(val uint64) {
+	cur := enc.cur()
+	appendMsgpackUint(cur.buf, val)
+	cur.n++
+}
+
+func (enc *msgpackEncoder) AddComplex64(k string, v complex64) { enc.AddComplex128(k, complex128(v)) }
+func (enc *msgpackEncoder) AddInt(k string, v int)             { enc.AddInt64(k, int64(v)) }
+func (enc *msgpackEncoder) AddInt32(k string, v int32)         { enc.AddInt64(k, int64(v)) }
+func (enc *msgpackEncoder) AddInt16(k string, v int16)         { enc.AddInt64(k, int64(v)) }
+func (enc *msgpackEncoder) AddInt8(k string, v int8)           { enc.AddInt64(k, int64(v)) }
+func (enc *msgpackEncoder) AddUint(k string, v uint)           { enc.AddUint64(k, uint64(v)) }
+func (enc *msgpackEncoder) AddUint32(k string, v uint32)       { enc.AddUint64(k, uint64(v)) }
+func (enc *msgpackEncoder) AddUint16(k string, v uint16)       { enc.AddUint64(k, uint64(v)) }
+func (enc *msgpackEncoder) AddUint8(k string, v uint8)         { enc.AddUint64(k, uint64(v)) }
+func (enc *msgpackEncoder) AddUintptr(k string, v uintptr)     { enc.AddUint64(k, uint64(v)) }
+func (enc *msgpackEncoder) AppendComplex64(v complex64)        { enc.AppendComplex128(complex128(v)) }
+func (enc *msgpackEncoder) AppendInt(v int)                    { enc.AppendInt64(int64(v)) }
+func (enc *msgpackEncoder) AppendInt32(v int32)                { enc.AppendInt64(int64(v)) }
+func (enc *msgpackEncoder) AppendInt16(v int16)                { enc.AppendInt64(int64(v)) }
+func (enc *msgpackEncoder) AppendInt8(v int8)                  { enc.AppendInt64(int64(v)) }
+func (enc *msgpackEncoder) AppendUint(v uint)                  { enc.AppendUint64(uint64(v)) }
+func (enc *msgpackEncoder) AppendUint32(v uint32)              { enc.AppendUint64(uint64(v)) }
+func (enc *msgpackEncoder) AppendUint16(v uint16)              { enc.AppendUint64(uint64(v)) }
+func (enc *msgpackEncoder) AppendUint8(v uint8)                { enc.AppendUint64(uint64(v)) }
+func (enc *msgpackEncoder) AppendUintptr(v uintptr)            { enc.AppendUint64(uint64(v)) }
+
+func (enc *msgpackEncoder) Clone() Encoder {
+	clone := _msgpackPool.Get().(*msgpackEncoder)
+	clone.EncoderConfig = enc.EncoderConfig
+	clone.scopes = clone.scopes[:0]
+	for _, s := range enc.scopes {
+		buf := bufferpool.Get()
+		buf.Write(s.buf.Bytes())
+		clone.scopes = append(clone.scopes, msgpackScope{key: s.key, buf: buf, n: s.n})
+	}
+	return clone
+}
+
+func (enc *msgpackEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	final := getMsgpackEncoder(enc.EncoderConfig)
+
+	if final.LevelKey != "" {
+		final.addKey(final.LevelKey)
+		cur := final.root().n
+		final.EncodeLevel(ent.Level, final)
+		if cur == final.root().n {
+			// User-supplied EncodeLevel was a no-op. Fall back to strings to
+			// keep the output valid.
+			final.AppendString(ent.Level.String())
+		}
+	}
+	if final.TimeKey != "" {
+		final.AddTime(final.TimeKey, ent.Time)
+	}
+	if ent.LoggerName != "" && final.NameKey != "" {
+		final.addKey(final.NameKey)
+		cur := final.root().n
+		nameEncoder := final.EncodeName
+
+		// if no name encoder provided, fall back to FullNameEncoder for backwards
+		// compatibility
+		if nameEncoder == nil {
+			nameEncoder = FullNameEncoder
+		}
+
+		nameEncoder(ent.LoggerName, final)
+		if cur == final.root().n {
+			// User-supplied EncodeName was a no-op. Fall back to strings to
+			// keep the output valid.
+			final.AppendString(ent.LoggerName)
+		}
+	}
+	if ent.Caller.Defined && final.CallerKey != "" {
+		final.addKey(final.CallerKey)
+		cur := final.root().n
+		final.EncodeCaller(ent.Caller, final)
+		if cur == final.root().n {
+			// User-supplied EncodeCaller was a no-op. Fall back to strings to
+			// keep the output valid.
+			final.AppendString(ent.Caller.String())
+		}
+	}
+	if ent.Caller.Defined && final.FunctionKey != "" {
+		if fn := ent.Caller.Function(); fn != "" {
+			final.AddString(final.FunctionKey, fn)
+		}
+	}
+	if final.MessageKey != "" {
+		final.AddString(final.MessageKey, ent.Message)
+	}
+
+	// Splice in the accumulated context, including any open namespaces.
+	root := final.root()
+	root.buf.Write(enc.root().buf.Bytes())
+	root.n += enc.root().n
+	for _, s := range enc.scopes[1:] {
+		buf := bufferpool.Get()
+		buf.Write(s.buf.Bytes())
+		final.scopes = append(final.scopes, msgpackScope{key: s.key, buf: buf, n: s.n})
+	}
+
+	addFields(final, fields)
+	final.closeNamespaces()
+	if ent.Stack != "" && final.StacktraceKey != "" {
+		final.AddString(final.StacktraceKey, ent.Stack)
+	}
+
+	ret := bufferpool.Get()
+	appendMsgpackMapHeader(ret, final.root().n)
+	ret.Write(final.root().buf.Bytes())
+	putMsgpackEncoder(final)
+	return ret, nil
+}
+
+func (enc *msgpackEncoder) closeNamespaces() {
+	for len(enc.scopes) > 1 {
+		ns := enc.scopes[len(enc.scopes)-1]
+		enc.scopes = enc.scopes[:len(enc.scopes)-1]
+
+		parent := enc.cur()
+		appendMsgpackString(parent.buf, ns.key)
+		appendMsgpackMapHeader(parent.buf, ns.n)
+		parent.buf.Write(ns.buf.Bytes())
+		parent.n++
+		ns.buf.Free()
+	}
+}
+
+func appendBigEndian(buf *buffer.Buffer, v uint64, size int) {
+	for i := size - 1; i >= 0; i-- {
+		buf.AppendByte(byte(v >> (8 * uint(i))))
+	}
+}
+
+func appendMsgpackUint(buf *buffer.Buffer, v uint64) {
+	switch {
+	case v <= 0x7f:
+		// positive fixint
+		buf.AppendByte(byte(v))
+	case v <= math.MaxUint8:
+		buf.AppendByte(0xcc)
+		appendBigEndian(buf, v, 1)
+	case v <= math.MaxUint16:
+		buf.AppendByte(0xcd)
+		appendBigEndian(buf, v, 2)
+	case v <= math.MaxUint32:
+		buf.AppendByte(0xce)
+		appendBigEndian(buf, v, 4)
+	default:
+		buf.AppendByte(0xcf)
+		appendBigEndian(buf, v, 8)
+	}
+}
+
+func appendMsgpackInt(buf *buffer.Buffer, v int64) {
+	switch {
+	case v >= 0:
+		appendMsgpackUint(buf, uint64(v))
+	case v >= -32:
+		// negative fixint
+		buf.AppendByte(byte(v))
+	case v >= math.MinInt8:
+		buf.AppendByte(0xd0)
+		appendBigEndian(buf, uint64(v), 1)
+	case v >= math.MinInt16:
+		buf.AppendByte(0xd1)
+		appendBigEndian(buf, uint64(v), 2)
+	case v >= math.MinInt32:
+		buf.AppendByte(0xd2)
+		appendBigEndian(buf, uint64(v), 4)
+	default:
+		buf.AppendByte(0xd3)
+		appendBigEndian(buf, uint64(v), 8)
+	}
+}
+
+func appendMsgpackStringHeader(buf *buffer.Buffer, n int) {
+	switch {
+	case n < 32:
+		// fixstr
+		buf.AppendByte(0xa0 | byte(n))
+	case n <= math.MaxUint8:
+		buf.AppendByte(0xd9)
+		appendBigEndian(buf, uint64(n), 1)
+	case n <= math.MaxUint16:
+		buf.AppendByte(0xda)
+		appendBigEndian(buf, uint64(n), 2)
+	default:
+		buf.AppendByte(0xdb)
+		appendBigEndian(buf, uint64(n), 4)
+	}
+}
+
+func appendMsgpackString(buf *buffer.Buffer, s string) {
+	appendMsgpackStringHeader(buf, len(s))
+	buf.AppendString(s)
+}
+
+func appendMsgpackBinary(buf *buffer.Buffer, bs []byte) {
+	switch n := len(bs); {
+	case n <= math.MaxUint8:
+		buf.AppendByte(0xc4)
+		appendBigEndian(buf, uint64(n), 1)
+	case n <= math.MaxUint16:
+		buf.AppendByte(0xc5)
+		appendBigEndian(buf, uint64(n), 2)
+	default:
+		buf.AppendByte(0xc6)
+		appendBigEndian(buf, uint64(n), 4)
+	}
+	buf.Write(bs)
+}
+
+func appendMsgpackArrayHeader(buf *buffer.Buffer, n int) {
+	switch {
+	case n < 16:
+		// fixarray
+		buf.AppendByte(0x90 | byte(n))
+	case n <= math.MaxUint16:
+		buf.AppendByte(0xdc)
+		appendBigEndian(buf, uint64(n), 2)
+	default:
+		buf.AppendByte(0xdd)
+		appendBigEndian(buf, uint64(n), 4)
+	}
+}
+
+func appendMsgpackMapHeader(buf *buffer.Buffer, n int) {
+	switch {
+	case n < 16:
+		// fixmap
+		buf.AppendByte(0x80 | byte(n))
+	case n <= math.MaxUint16:
+		buf.AppendByte(0xde)
+		appendBigEndian(buf, uint64(n), 2)
+	default:
+		buf.AppendByte(0xdf)
+		appendBigEndian(buf, uint64(n), 4)
+	}
+}
+
+// toMsgpackGeneric round-trips a value through encoding/json, producing
+// nils, bools, strings, json.Numbers, []interface{}s, and
+// map[string]interface{}s.
+func toMsgpackGeneric(obj interface{}) (interface{}, error) {
+	bs, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func appendMsgpackGeneric(buf *buffer.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		buf.AppendByte(0xc0)
+	case bool:
+		if v {
+			buf.AppendByte(0xc3)
+		} else {
+			buf.AppendByte(0xc2)
+		}
+	case string:
+		appendMsgpackString(buf, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			appendMsgpackInt(buf, i)
+		} else if f, err := v.Float64(); err == nil {
+			buf.AppendByte(0xcb)
+			appendBigEndian(buf, math.Float64bits(f), 8)
+		} else {
+			appendMsgpackString(buf, v.String())
+		}
+	case []interface{}:
+		appendMsgpackArrayHeader(buf, len(v))
+		for i := range v {
+			appendMsgpackGeneric(buf, v[i])
+		}
+	case map[string]interface{}:
+		// Sort the keys to make the output deterministic.
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		appendMsgpackMapHeader(buf, len(v))
+		for _, k := range keys {
+			appendMsgpackString(buf, k)
+			appendMsgpackGeneric(buf, v[k])
+		}
+	}
+}
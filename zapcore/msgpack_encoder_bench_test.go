@@ -0,0 +1,66 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+)
+
+func addBenchmarkContext(enc Encoder) {
+	enc.AddString("str", "foo")
+	enc.AddInt64("int64-1", 1)
+	enc.AddInt64("int64-2", 2)
+	enc.AddFloat64("float64", 1.0)
+	enc.AddString("string1", "\n")
+	enc.AddString("string2", "💩")
+	enc.AddString("string3", "🤔")
+	enc.AddString("string4", "🙊")
+	enc.AddBool("bool", true)
+	enc.AddDuration("duration", time.Second)
+}
+
+func benchmarkEncoder(b *testing.B, newEncoder func(EncoderConfig) Encoder) {
+	ent := Entry{Message: "fake", Level: DebugLevel, Time: time.Unix(0, 0)}
+
+	enc := newEncoder(testEncoderConfig())
+	addBenchmarkContext(enc)
+	buf, _ := enc.EncodeEntry(ent, nil)
+	b.Logf("encoded size: %d bytes", buf.Len())
+	buf.Free()
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			enc := newEncoder(testEncoderConfig())
+			addBenchmarkContext(enc)
+			buf, _ := enc.EncodeEntry(ent, nil)
+			buf.Free()
+		}
+	})
+}
+
+func BenchmarkZapMsgpackVersusJSON(b *testing.B) {
+	b.Run("msgpack", func(b *testing.B) { benchmarkEncoder(b, NewMsgpackEncoder) })
+	b.Run("json", func(b *testing.B) { benchmarkEncoder(b, NewJSONEncoder) })
+}
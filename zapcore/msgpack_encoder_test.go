@@ -0,0 +1,419 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeMsgpack is a minimal MessagePack decoder covering the subset of the
+// format emitted by the msgpack encoder. Integers decode to int64 (or uint64
+// if they overflow), maps to map[string]interface{}, and arrays to
+// []interface{}. It returns the decoded value and any remaining input.
+func decodeMsgpack(bs []byte) (interface{}, []byte, error) {
+	if len(bs) == 0 {
+		return nil, nil, errors.New("unexpected end of input")
+	}
+	b, bs := bs[0], bs[1:]
+	next := func(n int) ([]byte, error) {
+		if len(bs) < n {
+			return nil, errors.New("unexpected end of input")
+		}
+		out := bs[:n]
+		bs = bs[n:]
+		return out, nil
+	}
+	uintN := func(n int) (uint64, error) {
+		raw, err := next(n)
+		if err != nil {
+			return 0, err
+		}
+		var v uint64
+		for _, c := range raw {
+			v = v<<8 | uint64(c)
+		}
+		return v, nil
+	}
+	str := func(n int) (interface{}, []byte, error) {
+		raw, err := next(n)
+		return string(raw), bs, err
+	}
+	bin := func(n int) (interface{}, []byte, error) {
+		raw, err := next(n)
+		return append([]byte{}, raw...), bs, err
+	}
+	array := func(n int) (interface{}, []byte, error) {
+		arr := make([]interface{}, 0, n)
+		for i := 0; i < n; i++ {
+			var (
+				v   interface{}
+				err error
+			)
+			if v, bs, err = decodeMsgpack(bs); err != nil {
+				return nil, nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, bs, nil
+	}
+	object := func(n int) (interface{}, []byte, error) {
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			var (
+				k, v interface{}
+				err  error
+			)
+			if k, bs, err = decodeMsgpack(bs); err != nil {
+				return nil, nil, err
+			}
+			key, ok := k.(string)
+			if !ok {
+				return nil, nil, fmt.Errorf("non-string map key %v", k)
+			}
+			if v, bs, err = decodeMsgpack(bs); err != nil {
+				return nil, nil, err
+			}
+			m[key] = v
+		}
+		return m, bs, nil
+	}
+	length := func(size int, f func(int) (interface{}, []byte, error)) (interface{}, []byte, error) {
+		n, err := uintN(size)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f(int(n))
+	}
+	signed := func(size int) (interface{}, []byte, error) {
+		u, err := uintN(size)
+		shift := uint(64 - 8*size)
+		return int64(u<<shift) >> shift, bs, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), bs, nil
+	case b >= 0xe0:
+		return int64(int8(b)), bs, nil
+	case b&0xf0 == 0x80:
+		return object(int(b & 0x0f))
+	case b&0xf0 == 0x90:
+		return array(int(b & 0x0f))
+	case b&0xe0 == 0xa0:
+		return str(int(b & 0x1f))
+	}
+	switch b {
+	case 0xc0:
+		return nil, bs, nil
+	case 0xc2:
+		return false, bs, nil
+	case 0xc3:
+		return true, bs, nil
+	case 0xc4:
+		return length(1, bin)
+	case 0xc5:
+		return length(2, bin)
+	case 0xc6:
+		return length(4, bin)
+	case 0xca:
+		u, err := uintN(4)
+		return math.Float32frombits(uint32(u)), bs, err
+	case 0xcb:
+		u, err := uintN(8)
+		return math.Float64frombits(u), bs, err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := uintN(1 << (b - 0xcc))
+		if u > math.MaxInt64 {
+			return u, bs, err
+		}
+		return int64(u), bs, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		return signed(1 << (b - 0xd0))
+	case 0xd9:
+		return length(1, str)
+	case 0xda:
+		return length(2, str)
+	case 0xdb:
+		return length(4, str)
+	case 0xdc:
+		return length(2, array)
+	case 0xdd:
+		return length(4, array)
+	case 0xde:
+		return length(2, object)
+	case 0xdf:
+		return length(4, object)
+	}
+	return nil, nil, fmt.Errorf("unsupported MessagePack type byte %#x", b)
+}
+
+func decodeMsgpackEntry(t testing.TB, bs []byte) map[string]interface{} {
+	v, rest, err := decodeMsgpack(bs)
+	require.NoError(t, err, "Unexpected error decoding MessagePack.")
+	assert.Empty(t, rest, "Unexpected trailing bytes after entry.")
+	m, ok := v.(map[string]interface{})
+	require.True(t, ok, "Expected entry to be encoded as a map, got %T.", v)
+	return m
+}
+
+func TestMsgpackEncodeEntry(t *testing.T) {
+	type bar struct {
+		Key string  `json:"key"`
+		Val float64 `json:"val"`
+	}
+
+	enc := NewMsgpackEncoder(testEncoderConfig())
+	enc.AddString("context", "value")
+	enc.OpenNamespace("outer")
+	enc.AddInt("depth", 1)
+
+	ent := Entry{
+		Level:      WarnLevel,
+		Time:       time.Unix(0, 0),
+		LoggerName: "bob",
+		Message:    "lob law",
+		Caller:     EntryCaller{Defined: true, File: "foo.go", Line: 42},
+		Stack:      "fake-stack",
+	}
+	fields := []Field{
+		{Key: "so", Type: StringType, String: "passes"},
+		{Key: "answer", Type: Int64Type, Integer: 42},
+		{Key: "such", Type: ReflectType, Interface: []bar{{"pi", 3.5}}},
+	}
+
+	buf, err := enc.EncodeEntry(ent, fields)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	defer buf.Free()
+
+	assert.Equal(t, map[string]interface{}{
+		"level":      "warn",
+		"ts":         float64(0),
+		"name":       "bob",
+		"caller":     "foo.go:42",
+		"msg":        "lob law",
+		"context":    "value",
+		"stacktrace": "fake-stack",
+		"outer": map[string]interface{}{
+			"depth":  int64(1),
+			"so":     "passes",
+			"answer": int64(42),
+			"such": []interface{}{
+				map[string]interface{}{"key": "pi", "val": 3.5},
+			},
+		},
+	}, decodeMsgpackEntry(t, buf.Bytes()))
+
+	// Encoding an entry mustn't close the encoder's namespaces.
+	enc.AddInt("after", 2)
+	buf2, err := enc.EncodeEntry(Entry{Message: "again", Time: time.Unix(0, 0)}, nil)
+	require.NoError(t, err, "Unexpected error encoding second entry.")
+	defer buf2.Free()
+	assert.Equal(t, map[string]interface{}{
+		"level":   "info",
+		"ts":      float64(0),
+		"msg":     "again",
+		"context": "value",
+		"outer":   map[string]interface{}{"depth": int64(1), "after": int64(2)},
+	}, decodeMsgpackEntry(t, buf2.Bytes()))
+}
+
+func TestMsgpackEncoderValues(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	tests := []struct {
+		desc     string
+		f        func(Encoder)
+		expected interface{}
+	}{
+		{"fixint", func(e Encoder) { e.AddInt64("k", 7) }, int64(7)},
+		{"negative fixint", func(e Encoder) { e.AddInt64("k", -5) }, int64(-5)},
+		{"int8", func(e Encoder) { e.AddInt8("k", math.MinInt8) }, int64(math.MinInt8)},
+		{"int16", func(e Encoder) { e.AddInt16("k", math.MinInt16) }, int64(math.MinInt16)},
+		{"int32", func(e Encoder) { e.AddInt32("k", math.MinInt32) }, int64(math.MinInt32)},
+		{"int64", func(e Encoder) { e.AddInt64("k", math.MinInt64) }, int64(math.MinInt64)},
+		{"uint8", func(e Encoder) { e.AddUint8("k", math.MaxUint8) }, int64(math.MaxUint8)},
+		{"uint16", func(e Encoder) { e.AddUint16("k", math.MaxUint16) }, int64(math.MaxUint16)},
+		{"uint32", func(e Encoder) { e.AddUint32("k", math.MaxUint32) }, int64(math.MaxUint32)},
+		{"uint64", func(e Encoder) { e.AddUint64("k", math.MaxUint64) }, uint64(math.MaxUint64)},
+		{"float32", func(e Encoder) { e.AddFloat32("k", 1.5) }, float32(1.5)},
+		{"float64", func(e Encoder) { e.AddFloat64("k", math.Inf(-1)) }, math.Inf(-1)},
+		{"bool", func(e Encoder) { e.AddBool("k", true) }, true},
+		{"string", func(e Encoder) { e.AddString("k", "hello") }, "hello"},
+		{"long string", func(e Encoder) { e.AddString("k", long) }, long},
+		{"byte string", func(e Encoder) { e.AddByteString("k", []byte("bytes")) }, "bytes"},
+		{"binary", func(e Encoder) { e.AddBinary("k", []byte{0, 1, 2}) }, []byte{0, 1, 2}},
+		{"complex", func(e Encoder) { e.AddComplex128("k", 1+2i) }, "1+2i"},
+		{"duration", func(e Encoder) { e.AddDuration("k", time.Second) }, float64(1)},
+		{"time", func(e Encoder) { e.AddTime("k", time.Unix(1, 0)) }, float64(1)},
+		{
+			desc: "array",
+			f: func(e Encoder) {
+				e.AddArray("k", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+					for i := 0; i < 20; i++ {
+						arr.AppendInt(i)
+					}
+					return nil
+				}))
+			},
+			expected: func() []interface{} {
+				var out []interface{}
+				for i := 0; i < 20; i++ {
+					out = append(out, int64(i))
+				}
+				return out
+			}(),
+		},
+		{
+			desc: "object with namespace",
+			f: func(e Encoder) {
+				e.AddObject("k", ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+					enc.AddString("a", "b")
+					enc.OpenNamespace("ns")
+					enc.AddBool("c", false)
+					return nil
+				}))
+			},
+			expected: map[string]interface{}{
+				"a":  "b",
+				"ns": map[string]interface{}{"c": false},
+			},
+		},
+		{
+			desc:     "reflected",
+			f:        func(e Encoder) { e.AddReflected("k", map[string]interface{}{"n": nil, "f": 1.25}) },
+			expected: map[string]interface{}{"n": nil, "f": 1.25},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewMsgpackEncoder(EncoderConfig{
+				EncodeTime:     EpochTimeEncoder,
+				EncodeDuration: SecondsDurationEncoder,
+			})
+			tt.f(enc)
+			buf, err := enc.EncodeEntry(Entry{}, nil)
+			require.NoError(t, err, "Unexpected error encoding entry.")
+			defer buf.Free()
+			assert.Equal(t, map[string]interface{}{"k": tt.expected}, decodeMsgpackEntry(t, buf.Bytes()))
+		})
+	}
+}
+
+func TestMsgpackEncoderFallbacks(t *testing.T) {
+	noop := EncoderConfig{
+		MessageKey:     "msg",
+		LevelKey:       "level",
+		NameKey:        "name",
+		CallerKey:      "caller",
+		EncodeLevel:    func(Level, PrimitiveArrayEncoder) {},
+		EncodeName:     func(string, PrimitiveArrayEncoder) {},
+		EncodeCaller:   func(EntryCaller, PrimitiveArrayEncoder) {},
+		EncodeDuration: func(time.Duration, PrimitiveArrayEncoder) {},
+	}
+	enc := NewMsgpackEncoder(noop)
+	buf, err := enc.EncodeEntry(Entry{
+		Level:      ErrorLevel,
+		LoggerName: "named",
+		Caller:     EntryCaller{Defined: true, File: "foo.go", Line: 1},
+		Message:    "fallbacks",
+	}, []Field{
+		{Key: "dur", Type: DurationType, Integer: int64(time.Millisecond)},
+		{Key: "t", Type: TimeType, Integer: 1, Interface: time.UTC},
+	})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	defer buf.Free()
+	assert.Equal(t, map[string]interface{}{
+		"level":  "error",
+		"name":   "named",
+		"caller": "foo.go:1",
+		"msg":    "fallbacks",
+		"dur":    int64(time.Millisecond),
+		"t":      int64(1),
+	}, decodeMsgpackEntry(t, buf.Bytes()))
+}
+
+func TestMsgpackEncoderClone(t *testing.T) {
+	parent := NewMsgpackEncoder(EncoderConfig{})
+	parent.AddString("parent", "p")
+	parent.OpenNamespace("ns")
+
+	clone := parent.Clone()
+	clone.AddString("clone", "c")
+	parent.AddString("other", "o")
+
+	decode := func(enc Encoder) map[string]interface{} {
+		buf, err := enc.EncodeEntry(Entry{}, nil)
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		defer buf.Free()
+		return decodeMsgpackEntry(t, buf.Bytes())
+	}
+	assert.Equal(t, map[string]interface{}{
+		"parent": "p",
+		"ns":     map[string]interface{}{"clone": "c"},
+	}, decode(clone), "Unexpected output from clone.")
+	assert.Equal(t, map[string]interface{}{
+		"parent": "p",
+		"ns":     map[string]interface{}{"other": "o"},
+	}, decode(parent), "Clone modified its parent.")
+}
+
+func TestMsgpackEncoderLargeMap(t *testing.T) {
+	enc := NewMsgpackEncoder(EncoderConfig{})
+	for i := 0; i < 100; i++ {
+		enc.AddInt(fmt.Sprint("key", i), i)
+	}
+	buf, err := enc.EncodeEntry(Entry{}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	defer buf.Free()
+
+	bs := buf.Bytes()
+	require.True(t, len(bs) >= 3, "Output too short.")
+	assert.Equal(t, byte(0xde), bs[0], "Expected a map16 header.")
+	assert.Equal(t, uint16(100), binary.BigEndian.Uint16(bs[1:3]), "Unexpected map length.")
+	assert.Len(t, decodeMsgpackEntry(t, bs), 100, "Unexpected number of decoded keys.")
+}
+
+func TestMsgpackEncoderErrors(t *testing.T) {
+	enc := NewMsgpackEncoder(EncoderConfig{})
+	assert.Error(t, enc.AddReflected("k", make(chan int)), "Expected error reflecting a channel.")
+
+	errFail := errors.New("fail")
+	err := enc.AddObject("obj", ObjectMarshalerFunc(func(ObjectEncoder) error { return errFail }))
+	assert.Equal(t, errFail, err, "Expected marshaling errors to propagate.")
+
+	buf, err := enc.EncodeEntry(Entry{}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	defer buf.Free()
+	assert.Equal(t, map[string]interface{}{
+		"obj": map[string]interface{}{},
+	}, decodeMsgpackEntry(t, buf.Bytes()), "Expected the failed reflection to be omitted.")
+}
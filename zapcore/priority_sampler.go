@@ -0,0 +1,125 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+// A SampleRate is the fraction of entries a sampler keeps, from 0 (drop
+// everything) to 1 (keep everything).
+type SampleRate float64
+
+// _sampleRateScale is the resolution of sample rates, which are converted to
+// integers to keep the sampling arithmetic exact. Positive rates are rounded
+// to the nearest part per million, and never below one.
+const _sampleRateScale = 1000000
+
+type prioritySampler struct {
+	Core
+	rate     func(Entry, []Field) SampleRate
+	counters *rateCounters
+}
+
+// NewPrioritySampler creates a Core that samples each entry at a rate derived
+// from its contents, so that (for example) entries with a high priority field
+// are always kept while low-priority entries are kept only 1% of the time.
+// For each rate, the sampler keeps entries deterministically rather than at
+// random: at a rate of 0.01, it keeps the first entry and every hundredth one
+// after that. Rates have a resolution of one part per million, so any
+// positive rate below 1e-6 is treated as 1e-6.
+//
+// Since the fields passed at the log site aren't known until an entry is
+// written, the rate is only computed for entries that the wrapped Core
+// accepts, and before any of them are encoded. It's given the fields passed at
+// the log site, but not those added via With.
+func NewPrioritySampler(core Core, rate func(Entry, []Field) SampleRate) Core {
+	return &prioritySampler{
+		Core:     core,
+		rate:     rate,
+		counters: &rateCounters{counts: make(map[SampleRate]*atomic.Uint64)},
+	}
+}
+
+func (s *prioritySampler) With(fields []Field) Core {
+	return &prioritySampler{
+		Core:     s.Core.With(fields),
+		rate:     s.rate,
+		counters: s.counters,
+	}
+}
+
+func (s *prioritySampler) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkFilteringFields(s.Core, ent, ce, s.keep)
+}
+
+func (s *prioritySampler) Write(ent Entry, fields []Field) error {
+	if !s.keep(ent, fields) {
+		return nil
+	}
+	return s.Core.Write(ent, fields)
+}
+
+func (s *prioritySampler) keep(ent Entry, fields []Field) bool {
+	rate := s.rate(ent, fields)
+	if rate >= 1 {
+		return true
+	}
+	if !(rate > 0) {
+		// Also catches NaN.
+		return false
+	}
+	// Keep an entry each time the running total of the rate crosses an
+	// integer, starting with the first entry.
+	r := uint64(float64(rate)*_sampleRateScale + 0.5)
+	if r == 0 {
+		r = 1
+	}
+	n := s.counters.get(rate).Inc()
+	return (n-1)*r%_sampleRateScale < r
+}
+
+// rateCounters counts the entries sampled at each rate. Since most samplers
+// use only a handful of distinct rates, the counters are created lazily.
+type rateCounters struct {
+	sync.RWMutex
+	counts map[SampleRate]*atomic.Uint64
+}
+
+func (rc *rateCounters) get(rate SampleRate) *atomic.Uint64 {
+	rc.RLock()
+	c, ok := rc.counts[rate]
+	rc.RUnlock()
+	if ok {
+		return c
+	}
+
+	rc.Lock()
+	defer rc.Unlock()
+	if c, ok := rc.counts[rate]; ok {
+		return c
+	}
+	c = atomic.NewUint64(0)
+	rc.counts[rate] = c
+	return c
+}
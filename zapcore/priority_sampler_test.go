@@ -0,0 +1,125 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"math"
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func priorityRate(_ Entry, fields []Field) SampleRate {
+	for _, f := range fields {
+		if f.Key != "priority" {
+			continue
+		}
+		switch f.String {
+		case "high":
+			return 1
+		case "low":
+			return 0.01
+		case "never":
+			return 0
+		case "nan":
+			return SampleRate(math.NaN())
+		}
+	}
+	return 0.5
+}
+
+func TestPrioritySampler(t *testing.T) {
+	fac, logs := observer.New(InfoLevel)
+	core := NewPrioritySampler(fac, priorityRate).With([]Field{makeInt64Field("ctx", 1)})
+
+	priorities := []string{"high", "low", "never", "nan", "unset"}
+	for i := 0; i < 1000; i++ {
+		for _, p := range priorities {
+			if ce := core.Check(Entry{Level: InfoLevel, Message: p}, nil); ce != nil {
+				ce.Write(makeStringField("priority", p))
+			}
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, ent := range logs.AllUntimed() {
+		counts[ent.Message]++
+	}
+	assert.Equal(t, map[string]int{
+		"high":  1000,
+		"low":   10,
+		"unset": 500,
+	}, counts, "Unexpected number of entries kept for each priority.")
+}
+
+func TestPrioritySamplerKeepsFirst(t *testing.T) {
+	fac, logs := observer.New(InfoLevel)
+	core := NewPrioritySampler(fac, priorityRate)
+
+	for i := 0; i < 101; i++ {
+		core.Write(Entry{Message: "low"}, []Field{makeStringField("priority", "low")})
+	}
+	assert.Equal(t, 2, logs.Len(), "Expected the first and 101st low-priority entries to be kept.")
+}
+
+func TestPrioritySamplerTinyRate(t *testing.T) {
+	fac, logs := observer.New(InfoLevel)
+	core := NewPrioritySampler(fac, func(Entry, []Field) SampleRate { return 1e-9 })
+
+	for i := 0; i < 1000001; i++ {
+		core.Write(Entry{Message: "tiny"}, nil)
+	}
+	assert.Equal(t, 2, logs.Len(), "Expected tiny positive rates to be treated as one part per million.")
+}
+
+func TestPrioritySamplerDisabledLevels(t *testing.T) {
+	fac, logs := observer.New(InfoLevel)
+	called := 0
+	core := NewPrioritySampler(fac, func(Entry, []Field) SampleRate {
+		called++
+		return 1
+	})
+
+	assert.Nil(t, core.Check(Entry{Level: DebugLevel}, nil), "Expected disabled entries to be dropped.")
+	assert.Equal(t, 0, called, "Expected disabled entries to skip the rate computation.")
+	if ce := core.Check(Entry{Level: InfoLevel}, nil); ce != nil {
+		ce.Write()
+	}
+	assert.Equal(t, 1, called, "Expected the rate to be computed once per written entry.")
+	assert.Equal(t, 1, logs.Len(), "Unexpected number of entries.")
+}
+
+func TestPrioritySamplerTee(t *testing.T) {
+	sampled, sampledLogs := observer.New(InfoLevel)
+	unsampled, unsampledLogs := observer.New(InfoLevel)
+	core := NewTee(NewPrioritySampler(sampled, priorityRate), unsampled)
+
+	for i := 0; i < 10; i++ {
+		if ce := core.Check(Entry{Level: InfoLevel}, nil); ce != nil {
+			ce.Write(makeStringField("priority", "never"))
+		}
+	}
+	assert.Equal(t, 0, sampledLogs.Len(), "Expected the sampled Core to drop everything.")
+	assert.Equal(t, 10, unsampledLogs.Len(), "Expected sampling not to affect other Cores.")
+}
@@ -0,0 +1,246 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"container/list"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SuppressedKey is the key under which the Core returned by
+// NewRateLimitCore reports how many entries it dropped.
+const SuppressedKey = "suppressed"
+
+type rateLimitCore struct {
+	Core
+	state *rateLimitState
+}
+
+// rateLimitState is shared by a rateLimitCore and the Cores derived from it
+// with With.
+type rateLimitState struct {
+	sync.Mutex
+	key     func(Entry, []Field) string
+	rate    float64
+	burst   float64
+	max     int
+	clock   Clock
+	buckets map[string]*list.Element
+	// lru holds the *rateBuckets, least recently used first.
+	lru *list.List
+}
+
+// A rateBucket is the token bucket of one key.
+type rateBucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+	// suppressed counts the entries dropped since the last one written. The
+	// latest of them, and the Core that checked it, are kept so that Sync
+	// can report the count.
+	suppressed int64
+	core       Core
+	ent        Entry
+	fields     []Field
+}
+
+// NewRateLimitCore wraps a Core so that entries with the same key are
+// written at most rate times per second on average, with bursts of up to
+// burst entries, using a token bucket per key. It's a last line of defense
+// against error loops that would otherwise fill disks and overwhelm
+// collectors: unlike sampling, which thins out all entries in proportion,
+// it leaves entries alone until their key's rate is exceeded.
+//
+// Entries over the limit are dropped. The next entry with the same key that's
+// written carries an additional field reporting how many were dropped under
+// SuppressedKey, and Sync writes the latest dropped entry of each key with a
+// pending count, so that counts aren't lost when the program exits.
+//
+// To bound memory use, at most max keys are tracked at once (and at least
+// one). Once the limit is reached, the least recently used key is forgotten
+// to make room, provided its bucket is full and it has no dropped entries;
+// keys with dropped entries pending are skipped over, so making room costs
+// time proportional to their number. If no room can be made, entries with new
+// keys are written as usual. Cores derived from the returned Core with With
+// share its buckets.
+func NewRateLimitCore(core Core, key func(Entry, []Field) string, rate float64, burst int, max int, clock Clock) Core {
+	if burst < 1 {
+		burst = 1
+	}
+	if max < 1 {
+		max = 1
+	}
+	return &rateLimitCore{
+		Core: core,
+		state: &rateLimitState{
+			key:     key,
+			rate:    rate,
+			burst:   float64(burst),
+			max:     max,
+			clock:   clock,
+			buckets: make(map[string]*list.Element),
+			lru:     list.New(),
+		},
+	}
+}
+
+func (c *rateLimitCore) With(fields []Field) Core {
+	return &rateLimitCore{
+		Core:  c.Core.With(fields),
+		state: c.state,
+	}
+}
+
+func (c *rateLimitCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	ce, downstream := checkWrappingCores(c.Core, ent, ce)
+	if downstream != nil {
+		// Since an entry's fields aren't known until it's written, both
+		// deciding whether to keep the entry and adding the count of
+		// suppressed entries happen then.
+		var suppressed int64
+		downstream.keep = func(ent Entry, fields []Field) bool {
+			var ok bool
+			ok, suppressed = c.allow(ent, fields)
+			return ok
+		}
+		downstream.rewrite = func(_ Entry, fields []Field) []Field {
+			return withSuppressed(fields, suppressed)
+		}
+	}
+	return ce
+}
+
+func (c *rateLimitCore) Write(ent Entry, fields []Field) error {
+	ok, suppressed := c.allow(ent, fields)
+	if !ok {
+		return nil
+	}
+	return c.Core.Write(ent, withSuppressed(fields, suppressed))
+}
+
+func (c *rateLimitCore) Sync() error {
+	s := c.state
+	s.Lock()
+	keys := make([]string, 0, len(s.buckets))
+	for k, e := range s.buckets {
+		if e.Value.(*rateBucket).suppressed > 0 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pending := make([]rateBucket, len(keys))
+	for i, k := range keys {
+		b := s.buckets[k].Value.(*rateBucket)
+		pending[i] = *b
+		b.suppressed = 0
+		b.core, b.ent, b.fields = nil, Entry{}, nil
+	}
+	s.Unlock()
+
+	for _, b := range pending {
+		if ce := b.core.Check(b.ent, nil); ce != nil {
+			ce.Write(withSuppressed(b.fields, b.suppressed)...)
+		}
+	}
+	return c.Core.Sync()
+}
+
+// allow reports whether an entry's key has a token to spare, taking it if
+// so, along with the number of entries with the key that were dropped since
+// the last one written. Dropped entries are recorded for Sync.
+func (c *rateLimitCore) allow(ent Entry, fields []Field) (bool, int64) {
+	s := c.state
+	key := s.key(ent, fields)
+	now := s.clock.Now()
+
+	s.Lock()
+	defer s.Unlock()
+	var b *rateBucket
+	if e, ok := s.buckets[key]; ok {
+		b = e.Value.(*rateBucket)
+		s.lru.MoveToBack(e)
+	} else {
+		if len(s.buckets) >= s.max && !s.evict(now) {
+			return true, 0
+		}
+		b = &rateBucket{key: key, tokens: s.burst, last: now}
+		s.buckets[key] = s.lru.PushBack(b)
+	}
+	s.refill(b, now)
+	if b.tokens < 1 {
+		b.suppressed++
+		b.core = c.Core
+		b.ent = ent
+		// Copy the fields, since the caller may reuse their backing array.
+		b.fields = append(b.fields[:0], fields...)
+		return false, 0
+	}
+	b.tokens--
+	suppressed := b.suppressed
+	b.suppressed = 0
+	b.core, b.ent, b.fields = nil, Entry{}, nil
+	return true, suppressed
+}
+
+// refill adds the tokens earned since the bucket was last used. It must be
+// called with the lock held.
+func (s *rateLimitState) refill(b *rateBucket, now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * s.rate
+		if b.tokens > s.burst {
+			b.tokens = s.burst
+		}
+		b.last = now
+	}
+}
+
+// evict forgets the least recently used key without dropped entries, if its
+// bucket is full, since tracking it again later loses nothing. It reports
+// whether room was made, and must be called with the lock held.
+func (s *rateLimitState) evict(now time.Time) bool {
+	for e := s.lru.Front(); e != nil; e = e.Next() {
+		b := e.Value.(*rateBucket)
+		if b.suppressed > 0 {
+			continue
+		}
+		s.refill(b, now)
+		if b.tokens < s.burst {
+			// More recently used keys are unlikely to have refilled.
+			return false
+		}
+		s.lru.Remove(e)
+		delete(s.buckets, b.key)
+		return true
+	}
+	return false
+}
+
+func withSuppressed(fields []Field, suppressed int64) []Field {
+	if suppressed == 0 {
+		return fields
+	}
+	// Copy the fields so that we never write into the caller's backing array.
+	all := make([]Field, len(fields), len(fields)+1)
+	copy(all, fields)
+	return append(all, Field{Key: SuppressedKey, Type: Int64Type, Integer: suppressed})
+}
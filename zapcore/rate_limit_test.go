@@ -0,0 +1,149 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func byMessage(ent Entry, _ []Field) string { return ent.Message }
+
+func TestRateLimitCore(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewRateLimitCore(obs, byMessage, 1, 2, 10, clock).With([]Field{makeInt64Field("ctx", 1)})
+
+	write := func(msg string, fields ...Field) {
+		if ce := core.Check(Entry{Level: InfoLevel, Message: msg}, nil); ce != nil {
+			ce.Write(fields...)
+		}
+	}
+	for i := 0; i < 5; i++ {
+		write("loop", makeInt64Field("i", i))
+	}
+	write("other")
+	clock.Advance(time.Second)
+	write("loop", makeInt64Field("i", 5))
+	write("loop", makeInt64Field("i", 6))
+
+	entries := logs.AllUntimed()
+	require.Equal(t, 4, len(entries), "Unexpected number of entries.")
+	assert.Equal(t, []Field{makeInt64Field("ctx", 1), makeInt64Field("i", 0)}, entries[0].Context, "Unexpected first entry.")
+	assert.Equal(t, []Field{makeInt64Field("ctx", 1), makeInt64Field("i", 1)}, entries[1].Context, "Expected a burst of two.")
+	assert.Equal(t, "other", entries[2].Message, "Expected keys to be limited separately.")
+	assert.Equal(t, []Field{
+		makeInt64Field("ctx", 1),
+		makeInt64Field("i", 5),
+		makeInt64Field(SuppressedKey, 3),
+	}, entries[3].Context, "Expected the next entry written to report the suppressed count.")
+
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	entries = logs.AllUntimed()
+	require.Equal(t, 5, len(entries), "Expected Sync to write the pending count.")
+	assert.Equal(t, []Field{
+		makeInt64Field("ctx", 1),
+		makeInt64Field("i", 6),
+		makeInt64Field(SuppressedKey, 1),
+	}, entries[4].Context, "Expected Sync to write the latest dropped entry.")
+
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, 5, logs.Len(), "Expected Sync to report each count once.")
+}
+
+func TestRateLimitCoreWrite(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewRateLimitCore(obs, byMessage, 1, 1, 10, clock)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, core.Write(Entry{Message: "direct"}, nil), "Unexpected error writing directly.")
+	}
+	assert.Equal(t, 1, logs.Len(), "Expected direct writes to be limited too.")
+}
+
+func TestRateLimitCoreMaxKeys(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewRateLimitCore(obs, byMessage, 1, 1, 1, clock)
+
+	write := func(msg string) {
+		if ce := core.Check(Entry{Level: InfoLevel, Message: msg}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	write("a")
+	write("a")
+	// "a" has a dropped entry pending, so it can't be forgotten, and "b" isn't
+	// limited at all.
+	write("b")
+	write("b")
+	assert.Equal(t, 3, logs.Len(), "Expected entries with untracked keys to be written.")
+
+	clock.Advance(time.Second)
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	// With its bucket full and nothing pending, "a" makes room for "b".
+	write("b")
+	write("b")
+	assert.Equal(t, 5, logs.Len(), "Expected idle keys to be forgotten.")
+}
+
+func TestRateLimitCoreEvictsLeastRecentlyUsed(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewRateLimitCore(obs, byMessage, 1, 1, 2, clock)
+
+	write := func(msg string) {
+		if ce := core.Check(Entry{Level: InfoLevel, Message: msg}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	write("a")
+	write("b")
+	clock.Advance(time.Second)
+	write("a")
+	// "b" is the least recently used key, so it makes room for "c", while "a"
+	// keeps its empty bucket.
+	write("c")
+	write("a")
+	var msgs []string
+	for _, ent := range logs.AllUntimed() {
+		msgs = append(msgs, ent.Message)
+	}
+	assert.Equal(t, []string{"a", "b", "a", "c"}, msgs, "Expected the least recently used key to be forgotten.")
+}
+
+func TestRateLimitCoreNonPositiveMaxKeys(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	obs, logs := observer.New(InfoLevel)
+	core := NewRateLimitCore(obs, byMessage, 1, 1, 0, clock)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, core.Write(Entry{Message: "loop"}, nil), "Unexpected error writing.")
+	}
+	assert.Equal(t, 1, logs.Len(), "Expected at least one key to be limited.")
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"fmt"
+	"regexp"
+)
+
+type redactingCore struct {
+	Core
+	r *redactor
+}
+
+// redactor replaces the matches of a regular expression in strings.
+type redactor struct {
+	re          *regexp.Regexp
+	replacement string
+	messages    bool
+}
+
+// NewRedactingCore wraps a Core so that matches of re in string values are
+// replaced before they're written, as with re.ReplaceAllString. Unlike
+// redacting fields by key, this catches sensitive data (like credit card
+// numbers, email addresses, and tokens) that slips into free-form strings
+// regardless of the field's name. If messages is true, the entry's message
+// is redacted too.
+//
+// Strings, byte strings, Stringers, and errors are redacted, including
+// those nested in objects and arrays. Values logged via reflection are
+// passed through unchanged.
+//
+// Redaction isn't free: every string that's written is scanned by the
+// regular expression, so the cost grows with the length of the logged
+// strings and usually dwarfs the cost of encoding them. Fields added with
+// With are redacted once, when they're added.
+func NewRedactingCore(core Core, re *regexp.Regexp, replacement string, messages bool) Core {
+	return &redactingCore{
+		Core: core,
+		r: &redactor{
+			re:          re,
+			replacement: replacement,
+			messages:    messages,
+		},
+	}
+}
+
+func (c *redactingCore) With(fields []Field) Core {
+	return &redactingCore{
+		Core: c.Core.With(c.r.redactFields(Entry{}, fields)),
+		r:    c.r,
+	}
+}
+
+func (c *redactingCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	ce, downstream := checkWrappingCores(c.Core, ent, ce)
+	if downstream != nil {
+		if c.r.messages {
+			downstream.entry = c.r.redactEntry
+		}
+		downstream.rewrite = c.r.redactFields
+	}
+	return ce
+}
+
+func (c *redactingCore) Write(ent Entry, fields []Field) error {
+	if c.r.messages {
+		ent = c.r.redactEntry(ent)
+	}
+	return c.Core.Write(ent, c.r.redactFields(ent, fields))
+}
+
+func (r *redactor) redact(s string) string {
+	return r.re.ReplaceAllString(s, r.replacement)
+}
+
+func (r *redactor) redactEntry(ent Entry) Entry {
+	ent.Message = r.redact(ent.Message)
+	return ent
+}
+
+func (r *redactor) redactFields(_ Entry, fields []Field) []Field {
+	// Copy the fields so that we never write into the caller's backing array.
+	redacted := make([]Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = r.redactField(f)
+	}
+	return redacted
+}
+
+func (r *redactor) redactField(f Field) Field {
+	switch f.Type {
+	case StringType, TagType:
+		f.String = r.redact(f.String)
+	case ByteStringType:
+		f.Interface = r.re.ReplaceAll(f.Interface.([]byte), []byte(r.replacement))
+	case StringerType:
+		f.Type = StringType
+		f.String = r.redact(f.Interface.(fmt.Stringer).String())
+		f.Interface = nil
+	case ErrorType:
+		f.Type = InlineMarshalerType
+		f.Interface = redactedError{f.Key, f.Interface.(error), r}
+	case ObjectMarshalerType, InlineMarshalerType:
+		f.Interface = redactedObject{f.Interface.(ObjectMarshaler), r}
+	case ArrayMarshalerType:
+		f.Interface = redactedArray{f.Interface.(ArrayMarshaler), r}
+	}
+	return f
+}
+
+// redactedError encodes an error as usual, but through a redacting encoder,
+// so that its message, verbose message, causes, and fields are all redacted.
+type redactedError struct {
+	key string
+	err error
+	r   *redactor
+}
+
+func (e redactedError) MarshalLogObject(enc ObjectEncoder) error {
+	encodeError(e.key, e.err, redactingObjectEncoder{enc, e.r})
+	return nil
+}
+
+type redactedObject struct {
+	ObjectMarshaler
+	r *redactor
+}
+
+func (o redactedObject) MarshalLogObject(enc ObjectEncoder) error {
+	return o.ObjectMarshaler.MarshalLogObject(redactingObjectEncoder{enc, o.r})
+}
+
+type redactedArray struct {
+	ArrayMarshaler
+	r *redactor
+}
+
+func (a redactedArray) MarshalLogArray(enc ArrayEncoder) error {
+	return a.ArrayMarshaler.MarshalLogArray(redactingArrayEncoder{enc, a.r})
+}
+
+type redactingObjectEncoder struct {
+	ObjectEncoder
+	r *redactor
+}
+
+func (enc redactingObjectEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	return enc.ObjectEncoder.AddArray(key, redactedArray{arr, enc.r})
+}
+
+func (enc redactingObjectEncoder) AddObject(key string, obj ObjectMarshaler) error {
+	return enc.ObjectEncoder.AddObject(key, redactedObject{obj, enc.r})
+}
+
+func (enc redactingObjectEncoder) AddByteString(key string, val []byte) {
+	enc.ObjectEncoder.AddByteString(key, enc.r.re.ReplaceAll(val, []byte(enc.r.replacement)))
+}
+
+func (enc redactingObjectEncoder) AddString(key, val string) {
+	enc.ObjectEncoder.AddString(key, enc.r.redact(val))
+}
+
+type redactingArrayEncoder struct {
+	ArrayEncoder
+	r *redactor
+}
+
+func (enc redactingArrayEncoder) AppendArray(arr ArrayMarshaler) error {
+	return enc.ArrayEncoder.AppendArray(redactedArray{arr, enc.r})
+}
+
+func (enc redactingArrayEncoder) AppendObject(obj ObjectMarshaler) error {
+	return enc.ArrayEncoder.AppendObject(redactedObject{obj, enc.r})
+}
+
+func (enc redactingArrayEncoder) AppendByteString(val []byte) {
+	enc.ArrayEncoder.AppendByteString(enc.r.re.ReplaceAll(val, []byte(enc.r.replacement)))
+}
+
+func (enc redactingArrayEncoder) AppendString(val string) {
+	enc.ArrayEncoder.AppendString(enc.r.redact(val))
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// A RedactAction controls how NewKeyRedactingCore treats the value of a
+// sensitive field.
+type RedactAction uint8
+
+const (
+	// RedactMask replaces the field's value with the string "[REDACTED]".
+	RedactMask RedactAction = iota
+	// RedactHash replaces the field's value with "sha256:" followed by the
+	// hex-encoded SHA-256 hash of its JSON encoding, so that entries with the
+	// same sensitive value can still be correlated. Since the hash isn't
+	// salted, low-entropy values like social security numbers can be
+	// recovered by brute force; mask or drop those instead.
+	RedactHash
+	// RedactDrop removes the field entirely.
+	RedactDrop
+)
+
+const _redactedValue = "[REDACTED]"
+
+type keyRedactingCore struct {
+	Core
+	r *keyRedactor
+}
+
+// keyRedactor redacts the fields whose keys match.
+type keyRedactor struct {
+	match  func(key string) bool
+	action RedactAction
+}
+
+// NewKeyRedactingCore wraps a Core so that the values of fields whose keys
+// match are masked, hashed, or dropped before they're written. Unlike
+// NewRedactingCore, which scans string values for sensitive patterns, it
+// redacts values of any type, but only under known keys, like "password" or
+// "token".
+//
+// Keys nested in objects, including objects in arrays, are matched too.
+// Values logged via reflection are opaque, so keys inside them aren't. Fields
+// added with With are redacted once, when they're added.
+func NewKeyRedactingCore(core Core, match func(key string) bool, action RedactAction) Core {
+	return &keyRedactingCore{
+		Core: core,
+		r:    &keyRedactor{match: match, action: action},
+	}
+}
+
+func (c *keyRedactingCore) With(fields []Field) Core {
+	return &keyRedactingCore{
+		Core: c.Core.With(c.r.redactFields(Entry{}, fields)),
+		r:    c.r,
+	}
+}
+
+func (c *keyRedactingCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkRewritingFields(c.Core, ent, ce, c.r.redactFields)
+}
+
+func (c *keyRedactingCore) Write(ent Entry, fields []Field) error {
+	return c.Core.Write(ent, c.r.redactFields(ent, fields))
+}
+
+func (r *keyRedactor) redactFields(_ Entry, fields []Field) []Field {
+	// Copy the fields so that we never write into the caller's backing array.
+	redacted := make([]Field, len(fields))
+	for i, f := range fields {
+		redacted[i] = r.redactField(f)
+	}
+	return redacted
+}
+
+func (r *keyRedactor) redactField(f Field) Field {
+	switch f.Type {
+	case NamespaceType, SkipType:
+		return f
+	case InlineMarshalerType:
+		// Inline objects have no key of their own, but their fields do.
+		f.Interface = keyRedactedObject{f.Interface.(ObjectMarshaler), r}
+		return f
+	}
+	if r.match(f.Key) {
+		switch r.action {
+		case RedactDrop:
+			return Field{Type: SkipType}
+		case RedactHash:
+			enc := NewMapObjectEncoder()
+			f.AddTo(enc)
+			return Field{Key: f.Key, Type: StringType, String: hashValue(enc.Fields[f.Key])}
+		default:
+			return Field{Key: f.Key, Type: StringType, String: _redactedValue}
+		}
+	}
+	switch f.Type {
+	case ObjectMarshalerType:
+		f.Interface = keyRedactedObject{f.Interface.(ObjectMarshaler), r}
+	case ArrayMarshalerType:
+		f.Interface = keyRedactedArray{f.Interface.(ArrayMarshaler), r}
+	}
+	return f
+}
+
+// hashValue hashes the JSON encoding of a value, as produced by a
+// MapObjectEncoder. Encoding to JSON, rather than formatting with fmt, sorts
+// the keys of nested objects, so the same value always has the same hash.
+func hashValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b = []byte(fmt.Sprint(v))
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+type keyRedactedObject struct {
+	ObjectMarshaler
+	r *keyRedactor
+}
+
+func (o keyRedactedObject) MarshalLogObject(enc ObjectEncoder) error {
+	return o.ObjectMarshaler.MarshalLogObject(keyRedactingObjectEncoder{enc, o.r})
+}
+
+type keyRedactedArray struct {
+	ArrayMarshaler
+	r *keyRedactor
+}
+
+func (a keyRedactedArray) MarshalLogArray(enc ArrayEncoder) error {
+	return a.ArrayMarshaler.MarshalLogArray(keyRedactingArrayEncoder{enc, a.r})
+}
+
+// keyRedactingObjectEncoder checks the key of every value added to an
+// object, redacting matches and wrapping nested objects and arrays.
+type keyRedactingObjectEncoder struct {
+	ObjectEncoder
+	r *keyRedactor
+}
+
+// redacted reports whether key matches, in which case it's already added the
+// redacted form of the value produced by add.
+func (enc keyRedactingObjectEncoder) redacted(key string, add func(ObjectEncoder)) bool {
+	if !enc.r.match(key) {
+		return false
+	}
+	switch enc.r.action {
+	case RedactDrop:
+	case RedactHash:
+		m := NewMapObjectEncoder()
+		add(m)
+		enc.ObjectEncoder.AddString(key, hashValue(m.Fields[key]))
+	default:
+		enc.ObjectEncoder.AddString(key, _redactedValue)
+	}
+	return true
+}
+
+func (enc keyRedactingObjectEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	var err error
+	if !enc.redacted(key, func(e ObjectEncoder) { err = e.AddArray(key, arr) }) {
+		return enc.ObjectEncoder.AddArray(key, keyRedactedArray{arr, enc.r})
+	}
+	return err
+}
+
+func (enc keyRedactingObjectEncoder) AddObject(key string, obj ObjectMarshaler) error {
+	var err error
+	if !enc.redacted(key, func(e ObjectEncoder) { err = e.AddObject(key, obj) }) {
+		return enc.ObjectEncoder.AddObject(key, keyRedactedObject{obj, enc.r})
+	}
+	return err
+}
+
+func (enc keyRedactingObjectEncoder) AddBinary(key string, val []byte) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddBinary(key, val) }) {
+		enc.ObjectEncoder.AddBinary(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddByteString(key string, val []byte) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddByteString(key, val) }) {
+		enc.ObjectEncoder.AddByteString(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddBool(key string, val bool) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddBool(key, val) }) {
+		enc.ObjectEncoder.AddBool(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddComplex128(key string, val complex128) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddComplex128(key, val) }) {
+		enc.ObjectEncoder.AddComplex128(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddComplex64(key string, val complex64) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddComplex64(key, val) }) {
+		enc.ObjectEncoder.AddComplex64(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddDuration(key string, val time.Duration) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddDuration(key, val) }) {
+		enc.ObjectEncoder.AddDuration(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddFloat64(key string, val float64) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddFloat64(key, val) }) {
+		enc.ObjectEncoder.AddFloat64(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddFloat32(key string, val float32) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddFloat32(key, val) }) {
+		enc.ObjectEncoder.AddFloat32(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddInt(key string, val int) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddInt(key, val) }) {
+		enc.ObjectEncoder.AddInt(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddInt64(key string, val int64) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddInt64(key, val) }) {
+		enc.ObjectEncoder.AddInt64(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddInt32(key string, val int32) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddInt32(key, val) }) {
+		enc.ObjectEncoder.AddInt32(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddInt16(key string, val int16) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddInt16(key, val) }) {
+		enc.ObjectEncoder.AddInt16(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddInt8(key string, val int8) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddInt8(key, val) }) {
+		enc.ObjectEncoder.AddInt8(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddString(key, val string) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddString(key, val) }) {
+		enc.ObjectEncoder.AddString(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddTime(key string, val time.Time) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddTime(key, val) }) {
+		enc.ObjectEncoder.AddTime(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddUint(key string, val uint) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddUint(key, val) }) {
+		enc.ObjectEncoder.AddUint(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddUint64(key string, val uint64) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddUint64(key, val) }) {
+		enc.ObjectEncoder.AddUint64(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddUint32(key string, val uint32) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddUint32(key, val) }) {
+		enc.ObjectEncoder.AddUint32(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddUint16(key string, val uint16) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddUint16(key, val) }) {
+		enc.ObjectEncoder.AddUint16(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddUint8(key string, val uint8) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddUint8(key, val) }) {
+		enc.ObjectEncoder.AddUint8(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddUintptr(key string, val uintptr) {
+	if !enc.redacted(key, func(e ObjectEncoder) { e.AddUintptr(key, val) }) {
+		enc.ObjectEncoder.AddUintptr(key, val)
+	}
+}
+
+func (enc keyRedactingObjectEncoder) AddReflected(key string, val interface{}) error {
+	var err error
+	if !enc.redacted(key, func(e ObjectEncoder) { err = e.AddReflected(key, val) }) {
+		return enc.ObjectEncoder.AddReflected(key, val)
+	}
+	return err
+}
+
+// keyRedactingArrayEncoder wraps the objects and arrays nested in an array,
+// so that their keys are checked too.
+type keyRedactingArrayEncoder struct {
+	ArrayEncoder
+	r *keyRedactor
+}
+
+func (enc keyRedactingArrayEncoder) AppendArray(arr ArrayMarshaler) error {
+	return enc.ArrayEncoder.AppendArray(keyRedactedArray{arr, enc.r})
+}
+
+func (enc keyRedactingArrayEncoder) AppendObject(obj ObjectMarshaler) error {
+	return enc.ArrayEncoder.AppendObject(keyRedactedObject{obj, enc.r})
+}
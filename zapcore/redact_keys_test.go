@@ -0,0 +1,125 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func isSecret(key string) bool { return key == "secret" }
+
+func secretHash(json string) string {
+	sum := sha256.Sum256([]byte(json))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestKeyRedactingCore(t *testing.T) {
+	nested := ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		enc.AddInt("secret", 42)
+		enc.AddString("public", "ok")
+		return enc.AddArray("items", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+			return arr.AppendObject(ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+				enc.AddBool("secret", true)
+				return nil
+			}))
+		}))
+	})
+	inline := ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		enc.AddString("secret", "inline")
+		return nil
+	})
+	fields := []Field{
+		makeStringField("secret", "top"),
+		{Key: "obj", Type: ObjectMarshalerType, Interface: nested},
+		{Type: InlineMarshalerType, Interface: inline},
+		makeStringField("other", "visible"),
+	}
+
+	tests := []struct {
+		action   RedactAction
+		expected map[string]interface{}
+	}{
+		{
+			action: RedactMask,
+			expected: map[string]interface{}{
+				"secret": "[REDACTED]",
+				"obj": map[string]interface{}{
+					"secret": "[REDACTED]",
+					"public": "ok",
+					"items":  []interface{}{map[string]interface{}{"secret": "[REDACTED]"}},
+				},
+				"other": "visible",
+			},
+		},
+		{
+			action: RedactHash,
+			expected: map[string]interface{}{
+				"secret": secretHash(`"inline"`),
+				"obj": map[string]interface{}{
+					"secret": secretHash(`42`),
+					"public": "ok",
+					"items":  []interface{}{map[string]interface{}{"secret": secretHash(`true`)}},
+				},
+				"other": "visible",
+			},
+		},
+		{
+			action: RedactDrop,
+			expected: map[string]interface{}{
+				"obj": map[string]interface{}{
+					"public": "ok",
+					"items":  []interface{}{map[string]interface{}{}},
+				},
+				"other": "visible",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		obs, logs := observer.New(InfoLevel)
+		core := NewKeyRedactingCore(obs, isSecret, tt.action)
+		if ce := core.Check(Entry{Level: InfoLevel}, nil); ce != nil {
+			ce.Write(fields...)
+		}
+		require.Equal(t, 1, logs.Len(), "Expected an entry for action %v.", tt.action)
+		// The inline object's secret replaces the top-level one in the map.
+		assert.Equal(t, tt.expected, logs.AllUntimed()[0].ContextMap(), "Unexpected fields for action %v.", tt.action)
+	}
+	assert.Equal(t, "top", fields[0].String, "Expected the caller's fields to be left alone.")
+}
+
+func TestKeyRedactingCoreWith(t *testing.T) {
+	obs, logs := observer.New(InfoLevel)
+	core := NewKeyRedactingCore(obs, isSecret, RedactMask).With([]Field{makeStringField("secret", "ctx")})
+	require.NoError(t, core.Write(Entry{}, []Field{makeStringField("secret", "site")}), "Unexpected error writing.")
+	assert.Equal(t, []Field{
+		makeStringField("secret", "[REDACTED]"),
+		makeStringField("secret", "[REDACTED]"),
+	}, logs.AllUntimed()[0].Context, "Expected context and log-site fields to be redacted.")
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stringerFunc func() string
+
+func (f stringerFunc) String() string { return f() }
+
+func TestRedactingCore(t *testing.T) {
+	re := regexp.MustCompile(`[a-z]+@example\.com`)
+	obs, logs := observer.New(InfoLevel)
+	core := NewRedactingCore(obs, re, "<email>", true).With([]Field{makeStringField("user", "bob@example.com")})
+
+	nested := ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		enc.AddString("to", "ann@example.com")
+		enc.AddByteString("cc", []byte("cat@example.com"))
+		return enc.AddArray("bcc", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+			arr.AppendString("dan@example.com")
+			return arr.AppendObject(ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+				enc.AddString("name", "eve@example.com")
+				return nil
+			}))
+		}))
+	})
+	fields := []Field{
+		{Key: "mail", Type: ObjectMarshalerType, Interface: nested},
+		{Key: "raw", Type: ByteStringType, Interface: []byte("fay@example.com")},
+		{Key: "addr", Type: StringerType, Interface: stringerFunc(func() string { return "gus@example.com" })},
+		{Key: "error", Type: ErrorType, Interface: errors.New("bounced: hal@example.com")},
+		{Key: "reflected", Type: ReflectType, Interface: "ivy@example.com"},
+		makeInt64Field("count", 3),
+	}
+
+	ce := core.Check(Entry{Level: InfoLevel, Message: "mailed joe@example.com"}, nil)
+	require.NotNil(t, ce, "Expected the entry to be logged.")
+	ce.Write(fields...)
+	assert.Equal(t, StringerType, fields[2].Type, "Expected the caller's fields to be untouched.")
+	assert.Equal(t, []byte("fay@example.com"), fields[1].Interface, "Expected the caller's byte strings to be untouched.")
+	assert.Nil(t, core.Check(Entry{Level: DebugLevel}, nil), "Expected the wrapped Core's level to be respected.")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1, "Expected exactly one entry.")
+	assert.Equal(t, "mailed <email>", entries[0].Message, "Expected the message to be redacted.")
+	assert.Equal(t, map[string]interface{}{
+		"user": "<email>",
+		"mail": map[string]interface{}{
+			"to":  "<email>",
+			"cc":  "<email>",
+			"bcc": []interface{}{"<email>", map[string]interface{}{"name": "<email>"}},
+		},
+		"raw":       "<email>",
+		"addr":      "<email>",
+		"error":     "bounced: <email>",
+		"reflected": "ivy@example.com",
+		"count":     int64(3),
+	}, entries[0].ContextMap(), "Unexpected redacted context.")
+}
+
+func TestRedactingCoreWrite(t *testing.T) {
+	obs, logs := observer.New(InfoLevel)
+	core := NewRedactingCore(obs, regexp.MustCompile(`secret`), "***", false)
+
+	require.NoError(t, core.Write(Entry{Message: "secret"}, []Field{makeStringField("k", "a secret")}), "Unexpected error writing.")
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1, "Expected exactly one entry.")
+	assert.Equal(t, "secret", entries[0].Message, "Expected the message to be left alone.")
+	assert.Equal(t, map[string]interface{}{"k": "a ***"}, entries[0].ContextMap(), "Expected fields to be redacted.")
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sync"
+
+	"go.uber.org/multierr"
+)
+
+type replayCore struct {
+	Core
+	trigger LevelEnabler
+	buf     *replayBuffer
+}
+
+// replayBuffer is a ring of the most recent entries below the trigger level,
+// shared by a replaying Core and its children.
+type replayBuffer struct {
+	mu      sync.Mutex
+	entries []replayedEntry
+	next    int
+	full    bool
+}
+
+// replayedEntry remembers an entry along with the Core that held it back, so
+// that it's replayed with that Core's context.
+type replayedEntry struct {
+	core   Core
+	ent    Entry
+	fields []Field
+}
+
+// NewReplayCore wraps a Core so that entries below the trigger level are held
+// back in a ring of the most recent size entries, and written only when an
+// entry enabled by trigger is accepted by the wrapped Core. The held-back
+// entries are then written, oldest first, just before the triggering entry,
+// and the ring is emptied. This gives failures their full debug context
+// without paying to write debug logs on the happy path.
+//
+// The wrapped Core decides which entries are enabled, so it should enable the
+// levels that are worth replaying, typically DebugLevel. Entries are held
+// back after being checked but replayed through the wrapped Core's Check
+// method, so samplers and filters see them when they're written.
+//
+// A replaying Core and all of its children (see With) share one ring. For a
+// ring per request, wrap each request's Core separately, for example with
+// Logger.WithOptions and zap.ReplayOnError. Held-back entries that are never
+// triggered are simply discarded, even by Sync.
+func NewReplayCore(core Core, size int, trigger LevelEnabler) Core {
+	if size < 0 {
+		size = 0
+	}
+	return &replayCore{
+		Core:    core,
+		trigger: trigger,
+		buf:     &replayBuffer{entries: make([]replayedEntry, size)},
+	}
+}
+
+func (c *replayCore) With(fields []Field) Core {
+	return &replayCore{
+		Core:    c.Core.With(fields),
+		trigger: c.trigger,
+		buf:     c.buf,
+	}
+}
+
+func (c *replayCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	if c.trigger.Enabled(ent.Level) {
+		return c.checkTrigger(ent, ce)
+	}
+	if c.Core.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// checkTrigger replays the held-back entries only if the wrapped Core accepts
+// the triggering entry; otherwise, they'd be written without the entry that
+// explains them. It registers the replaying Core ahead of the Cores the
+// wrapped Core registered, so that the held-back entries are replayed before
+// the triggering entry is written.
+func (c *replayCore) checkTrigger(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	var start int
+	if ce != nil {
+		start = len(ce.cores)
+	}
+	ce = c.Core.Check(ent, ce)
+	if ce == nil || len(ce.cores) == start {
+		return ce
+	}
+	ce.cores = append(ce.cores, nil)
+	copy(ce.cores[start+1:], ce.cores[start:])
+	ce.cores[start] = c
+	return ce
+}
+
+func (c *replayCore) Write(ent Entry, fields []Field) error {
+	if c.trigger.Enabled(ent.Level) {
+		return c.buf.replay()
+	}
+	// Copy the fields, since the caller may reuse their backing array.
+	held := make([]Field, len(fields))
+	copy(held, fields)
+	c.buf.add(replayedEntry{core: c.Core, ent: ent, fields: held})
+	return nil
+}
+
+func (b *replayBuffer) add(e replayedEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.entries) == 0 {
+		return
+	}
+	b.entries[b.next] = e
+	b.next++
+	if b.next == len(b.entries) {
+		b.next = 0
+		b.full = true
+	}
+}
+
+func (b *replayBuffer) replay() error {
+	b.mu.Lock()
+	held := make([]replayedEntry, 0, len(b.entries))
+	if b.full {
+		held = append(held, b.entries[b.next:]...)
+	}
+	held = append(held, b.entries[:b.next]...)
+	for i := range b.entries {
+		// don't keep references to replayed entries
+		b.entries[i] = replayedEntry{}
+	}
+	b.next = 0
+	b.full = false
+	b.mu.Unlock()
+
+	// Write the checked entries by hand, rather than with CheckedEntry.Write,
+	// so that errors are returned to the triggering entry's CheckedEntry,
+	// which reports them.
+	var err error
+	for _, e := range held {
+		ce := e.core.Check(e.ent, nil)
+		if ce == nil {
+			continue
+		}
+		for i := range ce.cores {
+			err = multierr.Append(err, ce.cores[i].Write(ce.Entry, e.fields))
+		}
+		putCheckedEntry(ce)
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayCore(t *testing.T) {
+	obs, logs := observer.New(InfoLevel)
+	core := NewReplayCore(obs, 2, ErrorLevel)
+	child := core.With([]Field{makeInt64Field("child", 1)})
+
+	write := func(c Core, lvl Level, msg string, fields ...Field) {
+		if ce := c.Check(Entry{Level: lvl, Message: msg}, nil); ce != nil {
+			ce.Write(fields...)
+		}
+	}
+	write(core, DebugLevel, "disabled")
+	write(core, InfoLevel, "dropped from the ring")
+	write(child, InfoLevel, "child")
+	write(core, WarnLevel, "warn", makeStringField("k", "v"))
+	assert.Equal(t, 0, logs.Len(), "Expected entries below the trigger level to be held back.")
+
+	write(core, ErrorLevel, "error")
+	entries := logs.AllUntimed()
+	require.Equal(t, 3, len(entries), "Expected held-back entries to be replayed.")
+	assert.Equal(t, "child", entries[0].Message, "Expected the oldest entries to be dropped.")
+	assert.Equal(t, []Field{makeInt64Field("child", 1)}, entries[0].Context, "Expected replayed entries to keep their Core's context.")
+	assert.Equal(t, "warn", entries[1].Message, "Unexpected replayed entry.")
+	assert.Equal(t, []Field{makeStringField("k", "v")}, entries[1].Context, "Expected replayed entries to keep their fields.")
+	assert.Equal(t, "error", entries[2].Message, "Expected the triggering entry to follow the replayed ones.")
+
+	write(child, FatalLevel, "fatal")
+	assert.Equal(t, 4, logs.Len(), "Expected the ring to be emptied by replaying.")
+	assert.Equal(t, "fatal", logs.AllUntimed()[3].Message, "Unexpected entry.")
+}
+
+func TestReplayCoreEmptyRing(t *testing.T) {
+	obs, logs := observer.New(DebugLevel)
+	core := NewReplayCore(obs, -1, ErrorLevel)
+	for _, lvl := range []Level{InfoLevel, ErrorLevel} {
+		if ce := core.Check(Entry{Level: lvl}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	assert.Equal(t, 1, logs.Len(), "Expected a ring of size zero to hold nothing back.")
+}
+
+// allButError enables every level except ErrorLevel.
+type allButError struct{}
+
+func (allButError) Enabled(lvl Level) bool { return lvl != ErrorLevel }
+
+func TestReplayCoreRejectedTrigger(t *testing.T) {
+	obs, logs := observer.New(allButError{})
+	core := NewReplayCore(obs, 2, ErrorLevel)
+	write := func(lvl Level, msg string) {
+		if ce := core.Check(Entry{Level: lvl, Message: msg}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+
+	write(InfoLevel, "held")
+	write(ErrorLevel, "rejected")
+	assert.Equal(t, 0, logs.Len(), "Expected no replay when the wrapped Core rejects the trigger.")
+
+	write(FatalLevel, "fatal")
+	entries := logs.AllUntimed()
+	require.Equal(t, 2, len(entries), "Expected the held-back entry to be replayed by an accepted trigger.")
+	assert.Equal(t, "held", entries[0].Message, "Unexpected replayed entry.")
+	assert.Equal(t, "fatal", entries[1].Message, "Expected the triggering entry to follow the replayed one.")
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A ReservedKeyPolicy controls what NewReservedKeyCore does with fields whose
+// keys collide with the keys an encoder reserves for the entry's own
+// metadata, like the level and timestamp.
+type ReservedKeyPolicy int8
+
+const (
+	// RenameReservedKeys appends an underscore to colliding keys (repeatedly,
+	// if necessary), so "level" is logged as "level_". It's the zero value.
+	RenameReservedKeys ReservedKeyPolicy = iota
+	// DropReservedKeys silently omits colliding fields.
+	DropReservedKeys
+	// ErrorOnReservedKeys omits colliding fields and reports them as write
+	// errors, which loggers send to their ErrorOutput.
+	ErrorOnReservedKeys
+)
+
+// ReservedKeys returns the non-empty entry metadata keys in the EncoderConfig:
+// the message, level, time, name, caller, function, and stacktrace keys.
+func (cfg EncoderConfig) ReservedKeys() []string {
+	all := []string{cfg.MessageKey, cfg.LevelKey, cfg.TimeKey, cfg.NameKey, cfg.CallerKey, cfg.FunctionKey, cfg.StacktraceKey}
+	keys := all[:0]
+	for _, k := range all {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+type reservedKeyCore struct {
+	Core
+	policy ReservedKeyPolicy
+	keys   []string
+	// nested is set once the context has opened a namespace; fields added
+	// after that can't collide with anything at the top level.
+	nested bool
+	// dropped holds the colliding keys removed from the context, which
+	// ErrorOnReservedKeys reports on every write.
+	dropped []string
+}
+
+// NewReservedKeyCore wraps a Core so that fields (whether added via With or
+// passed at the log site) can't collide with the supplied reserved keys,
+// which would otherwise produce objects with duplicate keys. Colliding fields
+// are handled according to the policy. Typically, the reserved keys are those
+// of the wrapped Core's encoder; see EncoderConfig.ReservedKeys.
+//
+// Only the top-level keys of an entry are checked: fields nested inside an
+// object or a namespace can't collide with the entry's metadata.
+func NewReservedKeyCore(core Core, policy ReservedKeyPolicy, keys ...string) Core {
+	return &reservedKeyCore{
+		Core:   core,
+		policy: policy,
+		keys:   append([]string(nil), keys...),
+	}
+}
+
+func (c *reservedKeyCore) With(fields []Field) Core {
+	clone := *c
+	fs, dropped := c.resolve(fields)
+	clone.Core = c.Core.With(fs)
+	clone.nested = c.nested || hasNamespace(fields)
+	if len(dropped) > 0 {
+		clone.dropped = append(c.dropped[:len(c.dropped):len(c.dropped)], dropped...)
+	}
+	return &clone
+}
+
+func (c *reservedKeyCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	var start int
+	if ce != nil {
+		start = len(ce.cores)
+	}
+	ce = checkRewritingFields(c.Core, ent, ce, c.rewrite)
+	if c.policy == ErrorOnReservedKeys && ce != nil && len(ce.cores) > start {
+		// Since the rewritten fields no longer collide, report the
+		// collisions directly from the fields passed at the log site.
+		ce = ce.AddCore(ent, reservedKeyReporter{c})
+	}
+	return ce
+}
+
+func (c *reservedKeyCore) Write(ent Entry, fields []Field) error {
+	fs, dropped := c.resolve(fields)
+	err := c.Core.Write(ent, fs)
+	if c.policy == ErrorOnReservedKeys && err == nil {
+		err = c.collisionError(dropped)
+	}
+	return err
+}
+
+func (c *reservedKeyCore) rewrite(_ Entry, fields []Field) []Field {
+	fs, _ := c.resolve(fields)
+	return fs
+}
+
+// resolve applies the policy to fields, returning the resolved fields and the
+// keys of any colliding fields that were dropped. It doesn't allocate if
+// nothing collides.
+func (c *reservedKeyCore) resolve(fields []Field) ([]Field, []string) {
+	n := c.collisions(fields)
+	if n == len(fields) {
+		return fields, nil
+	}
+
+	// Copy the fields so that we never write into the caller's backing array.
+	out := make([]Field, 0, len(fields))
+	out = append(out, fields[:n]...)
+	var dropped []string
+	nested := false
+	for _, f := range fields[n:] {
+		if !nested && c.isReserved(f.Key) && f.Type != SkipType {
+			if c.policy != RenameReservedKeys {
+				dropped = append(dropped, f.Key)
+				continue
+			}
+			for c.isReserved(f.Key) {
+				f.Key += "_"
+			}
+		}
+		nested = nested || f.Type == NamespaceType
+		out = append(out, f)
+	}
+	return out, dropped
+}
+
+// collisions returns the index of the first field that collides with a
+// reserved key, or len(fields) if none do.
+func (c *reservedKeyCore) collisions(fields []Field) int {
+	if c.nested {
+		return len(fields)
+	}
+	for i := range fields {
+		if fields[i].Type != SkipType && c.isReserved(fields[i].Key) {
+			return i
+		}
+		if fields[i].Type == NamespaceType {
+			break
+		}
+	}
+	return len(fields)
+}
+
+func (c *reservedKeyCore) isReserved(key string) bool {
+	for _, k := range c.keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *reservedKeyCore) collisionError(dropped []string) error {
+	if len(c.dropped) == 0 && len(dropped) == 0 {
+		return nil
+	}
+	all := make([]string, 0, len(c.dropped)+len(dropped))
+	all = append(all, c.dropped...)
+	all = append(all, dropped...)
+	return fmt.Errorf("dropped fields with reserved keys: %s", strings.Join(all, ", "))
+}
+
+func hasNamespace(fields []Field) bool {
+	for i := range fields {
+		if fields[i].Type == NamespaceType {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedKeyReporter reports the colliding fields dropped by a
+// reservedKeyCore. It doesn't write anything itself.
+type reservedKeyReporter struct {
+	c *reservedKeyCore
+}
+
+func (r reservedKeyReporter) Enabled(Level) bool                            { return true }
+func (r reservedKeyReporter) With([]Field) Core                             { return r }
+func (r reservedKeyReporter) Sync() error                                   { return nil }
+func (r reservedKeyReporter) Check(_ Entry, ce *CheckedEntry) *CheckedEntry { return ce }
+
+func (r reservedKeyReporter) Write(_ Entry, fields []Field) error {
+	_, dropped := r.c.resolve(fields)
+	return r.c.collisionError(dropped)
+}
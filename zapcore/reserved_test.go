@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderConfigReservedKeys(t *testing.T) {
+	assert.Equal(t, []string{"msg", "level", "ts", "name", "caller", "stacktrace"}, testEncoderConfig().ReservedKeys(), "Unexpected reserved keys.")
+	assert.Empty(t, EncoderConfig{}.ReservedKeys(), "Expected no reserved keys in an empty config.")
+	assert.Equal(t, []string{"M", "L"}, EncoderConfig{MessageKey: "M", LevelKey: "L"}.ReservedKeys(), "Expected empty keys to be omitted.")
+}
+
+func TestReservedKeyCore(t *testing.T) {
+	tests := []struct {
+		policy   ReservedKeyPolicy
+		expected string
+		errs     int
+	}{
+		{
+			policy:   RenameReservedKeys,
+			expected: `{"level":"info","msg":"hello","ts_":"ctx","level_":"site","name__":"renamed","ok":1,"ns":{"level":"nested"}}` + "\n",
+		},
+		{
+			policy:   DropReservedKeys,
+			expected: `{"level":"info","msg":"hello","ok":1,"ns":{"level":"nested"}}` + "\n",
+		},
+		{
+			policy:   ErrorOnReservedKeys,
+			expected: `{"level":"info","msg":"hello","ok":1,"ns":{"level":"nested"}}` + "\n",
+			errs:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		cfg := testEncoderConfig()
+		cfg.TimeKey = ""
+		buf := &ztest.Buffer{}
+		errOut := &ztest.Buffer{}
+		core := NewReservedKeyCore(
+			NewCore(NewJSONEncoder(cfg), buf, DebugLevel),
+			tt.policy,
+			"level", "ts", "name", "name_",
+		).With([]Field{makeStringField("ts", "ctx")})
+
+		ce := core.Check(Entry{Level: InfoLevel, Message: "hello"}, nil)
+		require.NotNil(t, ce, "Expected the entry to be logged.")
+		ce.ErrorOutput = errOut
+		ce.Write(
+			makeStringField("level", "site"),
+			makeStringField("name", "renamed"),
+			makeInt64Field("ok", 1),
+			Field{Key: "ns", Type: NamespaceType},
+			makeStringField("level", "nested"),
+		)
+
+		assert.Equal(t, tt.expected, buf.String(), "Unexpected output with policy %v.", tt.policy)
+		assert.Equal(t, tt.errs, len(errOut.Lines()), "Unexpected number of errors with policy %v.", tt.policy)
+		if tt.errs > 0 {
+			assert.Contains(t, errOut.String(), "dropped fields with reserved keys: ts, level, name", "Unexpected error message.")
+		}
+	}
+}
+
+func TestReservedKeyCoreWrite(t *testing.T) {
+	fac, logs := observer.New(DebugLevel)
+	core := NewReservedKeyCore(fac, ErrorOnReservedKeys, "level")
+
+	err := core.Write(Entry{Message: "clean"}, []Field{makeInt64Field("ok", 1)})
+	assert.NoError(t, err, "Unexpected error writing fields without collisions.")
+
+	err = core.Write(Entry{Message: "collides"}, []Field{makeStringField("level", "site"), makeInt64Field("ok", 1)})
+	assert.EqualError(t, err, "dropped fields with reserved keys: level", "Expected collisions to be reported.")
+
+	entries := logs.AllUntimed()
+	require.Equal(t, 2, len(entries), "Unexpected number of entries.")
+	for _, ent := range entries {
+		assert.Equal(t, map[string]interface{}{"ok": int64(1)}, ent.ContextMap(), "Unexpected context for entry %q.", ent.Message)
+	}
+}
+
+func TestReservedKeyCoreNoCollisions(t *testing.T) {
+	fac, logs := observer.New(InfoLevel)
+	core := NewReservedKeyCore(fac, ErrorOnReservedKeys, "level").
+		With([]Field{{Key: "ns", Type: NamespaceType}}).
+		With([]Field{makeStringField("level", "nested")})
+
+	assert.Nil(t, core.Check(Entry{Level: DebugLevel}, nil), "Expected disabled entries to be dropped.")
+	ce := core.Check(Entry{Level: InfoLevel, Time: time.Now()}, nil)
+	require.NotNil(t, ce, "Expected the entry to be logged.")
+	errOut := &ztest.Buffer{}
+	ce.ErrorOutput = errOut
+	ce.Write(makeStringField("level", "also nested"))
+
+	require.Equal(t, 1, logs.Len(), "Unexpected number of entries.")
+	assert.Equal(t, []Field{
+		{Key: "ns", Type: NamespaceType},
+		makeStringField("level", "nested"),
+		makeStringField("level", "also nested"),
+	}, logs.AllUntimed()[0].Context, "Expected fields inside a namespace to be left alone.")
+	assert.Empty(t, errOut.String(), "Unexpected collision errors.")
+}
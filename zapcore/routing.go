@@ -0,0 +1,194 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sort"
+
+	"go.uber.org/multierr"
+)
+
+// NewRoutingCore creates a Core that sends each entry to exactly one of
+// several Cores, chosen by its contents. For example, audit events could be
+// encoded as JSON and written to a file while operational logs are encoded
+// as text and written to the console:
+//   core := zapcore.NewRoutingCore(
+//     func(ent zapcore.Entry, fields []zapcore.Field) string {
+//       for _, f := range fields {
+//         if f.Key == "audit" && f.Type == zapcore.BoolType && f.Integer == 1 {
+//           return "audit"
+//         }
+//       }
+//       return ""
+//     },
+//     map[string]zapcore.Core{"audit": jsonFileCore},
+//     textConsoleCore,
+//   )
+//
+// The route function returns the name of a Core in routes. Entries routed to
+// a name that isn't in routes are sent to the fallback Core, which may be nil
+// to drop them. Since the fields passed at the log site aren't known until an
+// entry is written, the function sees only those fields, not the ones added
+// via With. It's called exactly once for each entry that at least one Core
+// accepts, and the entry is written only if the chosen Core accepted it.
+func NewRoutingCore(route func(Entry, []Field) string, routes map[string]Core, fallback Core) Core {
+	names := make([]string, 0, len(routes))
+	for name := range routes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := &routingCore{route: route, fallback: fallback}
+	for _, name := range names {
+		c.names = append(c.names, name)
+		c.cores = append(c.cores, routes[name])
+	}
+	return c
+}
+
+// routingCore routes entries to one of its cores, which parallel its names,
+// or to its fallback.
+type routingCore struct {
+	route    func(Entry, []Field) string
+	names    []string
+	cores    []Core
+	fallback Core
+}
+
+func (c *routingCore) Enabled(lvl Level) bool {
+	for _, core := range c.cores {
+		if core.Enabled(lvl) {
+			return true
+		}
+	}
+	return c.fallback != nil && c.fallback.Enabled(lvl)
+}
+
+func (c *routingCore) With(fields []Field) Core {
+	clone := &routingCore{
+		route: c.route,
+		names: c.names,
+		cores: make([]Core, len(c.cores)),
+	}
+	for i := range c.cores {
+		clone.cores[i] = c.cores[i].With(fields)
+	}
+	if c.fallback != nil {
+		clone.fallback = c.fallback.With(fields)
+	}
+	return clone
+}
+
+// Check lets every destination check the entry, so that sampling and the
+// like behave as usual, but registers only a single routeWriter with the
+// CheckedEntry. Once the fields are known, it calls the route function
+// exactly once and writes to the chosen destination.
+func (c *routingCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	var w *routeWriter
+	add := func(i int, core Core) {
+		var downstream *rewritingWriter
+		ce, downstream = checkWrappingCores(core, ent, ce)
+		if downstream == nil {
+			return
+		}
+		// Take the destination back out of the CheckedEntry; the routeWriter
+		// decides whether it's written.
+		ce.cores[len(ce.cores)-1] = nil
+		ce.cores = ce.cores[:len(ce.cores)-1]
+		if w == nil {
+			w = &routeWriter{routes: c, writers: make([]*rewritingWriter, len(c.cores)+1)}
+		}
+		w.writers[i] = downstream
+	}
+	for i, core := range c.cores {
+		add(i, core)
+	}
+	if c.fallback != nil {
+		add(len(c.cores), c.fallback)
+	}
+	if w == nil {
+		return ce
+	}
+	return ce.AddCore(ent, w)
+}
+
+func (c *routingCore) Write(ent Entry, fields []Field) error {
+	if core := c.choose(c.route(ent, fields)); core != nil {
+		return core.Write(ent, fields)
+	}
+	return nil
+}
+
+func (c *routingCore) Sync() error {
+	var err error
+	for _, core := range c.cores {
+		err = multierr.Append(err, core.Sync())
+	}
+	if c.fallback != nil {
+		err = multierr.Append(err, c.fallback.Sync())
+	}
+	return err
+}
+
+// index returns the index of the destination that name routes to, where
+// len(c.cores) is the fallback.
+func (c *routingCore) index(name string) int {
+	i := sort.SearchStrings(c.names, name)
+	if i < len(c.names) && c.names[i] == name {
+		return i
+	}
+	return len(c.cores)
+}
+
+func (c *routingCore) choose(name string) Core {
+	if i := c.index(name); i < len(c.cores) {
+		return c.cores[i]
+	}
+	return c.fallback
+}
+
+// routeWriter writes a checked entry to the destination it's routed to,
+// among those that agreed to log it.
+type routeWriter struct {
+	routes  *routingCore
+	writers []*rewritingWriter // parallel to routes.cores, plus the fallback
+}
+
+func (w *routeWriter) Enabled(Level) bool                            { return true }
+func (w *routeWriter) With([]Field) Core                             { return w }
+func (w *routeWriter) Sync() error                                   { return nil }
+func (w *routeWriter) Check(_ Entry, ce *CheckedEntry) *CheckedEntry { return ce }
+
+func (w *routeWriter) Write(ent Entry, fields []Field) error {
+	chosen := w.routes.index(w.routes.route(ent, fields))
+	var err error
+	for i, rw := range w.writers {
+		if rw == nil {
+			continue
+		}
+		if i == chosen {
+			err = rw.Write(ent, fields)
+		} else {
+			putRewritingWriter(rw)
+		}
+	}
+	return err
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func routeAudit(_ Entry, fields []Field) string {
+	for _, f := range fields {
+		if f.Key == "audit" && f.Type == BoolType && f.Integer == 1 {
+			return "audit"
+		}
+	}
+	return ""
+}
+
+func TestRoutingCore(t *testing.T) {
+	cfg := testEncoderConfig()
+	cfg.TimeKey = ""
+	audit := &ztest.Buffer{}
+	ops := &ztest.Buffer{}
+	core := NewRoutingCore(
+		routeAudit,
+		map[string]Core{"audit": NewCore(NewJSONEncoder(cfg), audit, DebugLevel)},
+		NewCore(NewConsoleEncoder(cfg), ops, InfoLevel),
+	).With([]Field{makeStringField("service", "api")})
+
+	write := func(ent Entry, fields ...Field) {
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write(fields...)
+		}
+	}
+	write(Entry{Level: InfoLevel, Message: "login"}, Field{Key: "audit", Type: BoolType, Integer: 1})
+	write(Entry{Level: InfoLevel, Message: "cache miss"}, makeInt64Field("shard", 3))
+	write(Entry{Level: DebugLevel, Message: "not audited"})
+	core.Write(Entry{Level: InfoLevel, Message: "direct"}, []Field{{Key: "audit", Type: BoolType, Integer: 1}})
+
+	assert.Equal(t, []string{
+		`{"level":"info","msg":"login","service":"api","audit":true}`,
+		`{"level":"info","msg":"direct","service":"api","audit":true}`,
+	}, audit.Lines(), "Unexpected audit output.")
+	assert.Equal(t, []string{
+		"info\tcache miss\t" + `{"service": "api", "shard": 3}`,
+	}, ops.Lines(), "Unexpected operational output.")
+
+	assert.True(t, core.Enabled(DebugLevel), "Expected the routing Core to be enabled if any route is.")
+	assert.NoError(t, core.Sync(), "Unexpected error syncing.")
+}
+
+func TestRoutingCoreWithoutFallback(t *testing.T) {
+	audit := &ztest.Buffer{}
+	core := NewRoutingCore(
+		routeAudit,
+		map[string]Core{"audit": NewCore(NewJSONEncoder(testEncoderConfig()), audit, DebugLevel)},
+		nil,
+	)
+	if ce := core.Check(Entry{Level: InfoLevel, Message: "dropped"}, nil); ce != nil {
+		ce.Write()
+	}
+	assert.Empty(t, audit.String(), "Expected unmatched entries to be dropped without a fallback.")
+}
+
+func TestRoutingCoreRoutesOnce(t *testing.T) {
+	var calls int
+	// A route function that isn't deterministic must still send each entry to
+	// exactly one destination.
+	route := func(Entry, []Field) string {
+		calls++
+		if calls%2 == 1 {
+			return "a"
+		}
+		return "b"
+	}
+	a, b, fallback := &ztest.Buffer{}, &ztest.Buffer{}, &ztest.Buffer{}
+	enc := NewJSONEncoder(EncoderConfig{MessageKey: "msg"})
+	core := NewRoutingCore(
+		route,
+		map[string]Core{
+			"a": NewCore(enc.Clone(), a, DebugLevel),
+			"b": NewCore(enc.Clone(), b, DebugLevel),
+		},
+		NewCore(enc.Clone(), fallback, DebugLevel),
+	)
+
+	for _, msg := range []string{"one", "two", "three"} {
+		if ce := core.Check(Entry{Message: msg}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	assert.Equal(t, 3, calls, "Expected the route function to be called once per entry.")
+	assert.Equal(t, []string{`{"msg":"one"}`, `{"msg":"three"}`}, a.Lines(), "Unexpected output routed to a.")
+	assert.Equal(t, []string{`{"msg":"two"}`}, b.Lines(), "Unexpected output routed to b.")
+	assert.Empty(t, fallback.String(), "Expected no output to reach the fallback.")
+
+	assert.Nil(t, NewRoutingCore(route, nil, nil).Check(Entry{}, nil), "Expected a routing Core without destinations to drop entries.")
+	assert.Equal(t, 3, calls, "Expected the route function not to be called for entries no Core accepts.")
+}
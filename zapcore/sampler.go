@@ -87,6 +87,46 @@ type sampler struct {
 	counts            *counters
 	tick              time.Duration
 	first, thereafter uint64
+	hook              func(Entry, SamplingDecision)
+}
+
+// SamplingDecision records whether the sampler logged or dropped an entry.
+type SamplingDecision uint32
+
+const (
+	// LogDropped indicates that the sampler dropped an entry.
+	LogDropped SamplingDecision = 1 << iota
+	// LogSampled indicates that the sampler passed an entry on to the wrapped
+	// Core, which may still decline to log it.
+	LogSampled
+)
+
+// A SamplerOption configures a sampler created by NewSamplerWithOptions.
+type SamplerOption interface {
+	apply(*sampler)
+}
+
+// samplerOptionFunc wraps a func so it satisfies the SamplerOption interface.
+type samplerOptionFunc func(*sampler)
+
+func (f samplerOptionFunc) apply(s *sampler) {
+	f(s)
+}
+
+// SamplerHook registers a function to be called with each sampling decision,
+// which makes it easy to count dropped entries. For example,
+//   var dropped atomic.Int64
+//   hook := zapcore.SamplerHook(func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+//     if dec&zapcore.LogDropped > 0 {
+//       dropped.Inc()
+//     }
+//   })
+// The hook runs synchronously in Check, so it should be cheap and safe for
+// concurrent use. It's only called for entries at enabled levels.
+func SamplerHook(hook func(entry Entry, dec SamplingDecision)) SamplerOption {
+	return samplerOptionFunc(func(s *sampler) {
+		s.hook = hook
+	})
 }
 
 // NewSampler creates a Core that samples incoming entries, which caps the CPU
@@ -101,15 +141,30 @@ type sampler struct {
 // absolute precision; under load, each tick may be slightly over- or
 // under-sampled.
 func NewSampler(core Core, tick time.Duration, first, thereafter int) Core {
-	return &sampler{
+	return NewSamplerWithOptions(core, tick, first, thereafter)
+}
+
+// NewSamplerWithOptions is like NewSampler, but accepts SamplerOptions.
+func NewSamplerWithOptions(core Core, tick time.Duration, first, thereafter int, opts ...SamplerOption) Core {
+	s := &sampler{
 		Core:       core,
 		tick:       tick,
 		counts:     newCounters(),
 		first:      uint64(first),
 		thereafter: uint64(thereafter),
+		hook:       nopSamplingHook,
+	}
+	for _, opt := range opts {
+		opt.apply(s)
 	}
+	if s.hook == nil {
+		s.hook = nopSamplingHook
+	}
+	return s
 }
 
+func nopSamplingHook(Entry, SamplingDecision) {}
+
 func (s *sampler) With(fields []Field) Core {
 	return &sampler{
 		Core:       s.Core.With(fields),
@@ -117,6 +172,7 @@ func (s *sampler) With(fields []Field) Core {
 		counts:     s.counts,
 		first:      s.first,
 		thereafter: s.thereafter,
+		hook:       s.hook,
 	}
 }
 
@@ -128,7 +184,9 @@ func (s *sampler) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
 	counter := s.counts.get(ent.Level, ent.Message)
 	n := counter.IncCheckReset(ent.Time, s.tick)
 	if n > s.first && (n-s.first)%s.thereafter != 0 {
+		s.hook(ent, LogDropped)
 		return ce
 	}
+	s.hook(ent, LogSampled)
 	return s.Core.Check(ent, ce)
 }
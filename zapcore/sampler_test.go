@@ -223,3 +223,38 @@ func TestSamplerRaces(t *testing.T) {
 	close(start)
 	wg.Wait()
 }
+
+func TestSamplerHook(t *testing.T) {
+	var dropped, sampled []string
+	hook := SamplerHook(func(ent Entry, dec SamplingDecision) {
+		switch dec {
+		case LogDropped:
+			dropped = append(dropped, ent.Message)
+		case LogSampled:
+			sampled = append(sampled, ent.Message)
+		default:
+			t.Errorf("Unexpected sampling decision %v.", dec)
+		}
+	})
+	core, logs := observer.New(InfoLevel)
+	sampler := NewSamplerWithOptions(core, time.Minute, 1, 2, hook).With([]Field{makeInt64Field("iter", 1)})
+
+	for _, msg := range []string{"a", "a", "a", "b"} {
+		if ce := sampler.Check(Entry{Level: InfoLevel, Time: time.Now(), Message: msg}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	sampler.Check(Entry{Level: DebugLevel, Time: time.Now(), Message: "disabled"}, nil)
+
+	assert.Equal(t, []string{"a"}, dropped, "Unexpected dropped entries.")
+	assert.Equal(t, []string{"a", "a", "b"}, sampled, "Unexpected sampled entries.")
+	assert.Equal(t, 3, logs.Len(), "Expected sampled entries to be logged.")
+}
+
+func TestSamplerNilHook(t *testing.T) {
+	core, logs := observer.New(DebugLevel)
+	sampler := NewSamplerWithOptions(core, time.Minute, 1, 100, SamplerHook(nil))
+	writeSequence(sampler, 1, InfoLevel)
+	writeSequence(sampler, 2, InfoLevel)
+	assertSequence(t, logs.TakeAll(), InfoLevel, 1)
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap/buffer"
+)
+
+type sortedEncoder struct {
+	// Encoder is the wrapped encoder, which never holds any context: the
+	// context is recorded in fields and encoded with each entry instead.
+	Encoder
+	rank   map[string]int
+	fields fieldRecorder
+}
+
+// NewSortedEncoder wraps an Encoder so that each entry's fields, including
+// those added via With, are written in a deterministic order rather than the
+// order they were added in. The keys listed in first come first, in the order
+// given; the rest follow, sorted lexically. That keeps diffs, golden tests,
+// and schema validators from tripping over incidental changes in field order.
+// Entry metadata, like the message and level, is written by the wrapped
+// encoder as usual.
+//
+// Fields are sorted within their namespace, and namespaces stay where they
+// were opened. Inline marshalers are expanded, so their keys are sorted along
+// with the rest, but the keys of nested objects keep their order.
+//
+// Sorting isn't free: context fields are recorded unserialized and encoded
+// anew with every entry, and each entry's fields are copied and sorted.
+func NewSortedEncoder(enc Encoder, first ...string) Encoder {
+	rank := make(map[string]int, len(first))
+	for i, key := range first {
+		if _, ok := rank[key]; !ok {
+			rank[key] = i
+		}
+	}
+	return &sortedEncoder{Encoder: enc, rank: rank}
+}
+
+func (e *sortedEncoder) Clone() Encoder {
+	return &sortedEncoder{
+		Encoder: e.Encoder,
+		rank:    e.rank,
+		fields:  append(fieldRecorder(nil), e.fields...),
+	}
+}
+
+func (e *sortedEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	all := make(fieldRecorder, len(e.fields), len(e.fields)+len(fields))
+	copy(all, e.fields)
+	for i := range fields {
+		fields[i].AddTo(&all)
+	}
+	// Sort each namespace separately.
+	start := 0
+	for i := 0; i <= len(all); i++ {
+		if i == len(all) || all[i].Type == NamespaceType {
+			e.sort(all[start:i])
+			start = i + 1
+		}
+	}
+	return e.Encoder.EncodeEntry(ent, all)
+}
+
+func (e *sortedEncoder) sort(fields []Field) {
+	sort.SliceStable(fields, func(i, j int) bool {
+		ri, iok := e.rank[fields[i].Key]
+		rj, jok := e.rank[fields[j].Key]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok || jok:
+			return iok
+		default:
+			return fields[i].Key < fields[j].Key
+		}
+	})
+}
+
+func (e *sortedEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	return e.fields.AddArray(key, arr)
+}
+
+func (e *sortedEncoder) AddObject(key string, obj ObjectMarshaler) error {
+	return e.fields.AddObject(key, obj)
+}
+
+func (e *sortedEncoder) AddBinary(key string, val []byte)          { e.fields.AddBinary(key, val) }
+func (e *sortedEncoder) AddByteString(key string, val []byte)      { e.fields.AddByteString(key, val) }
+func (e *sortedEncoder) AddBool(key string, val bool)              { e.fields.AddBool(key, val) }
+func (e *sortedEncoder) AddComplex128(key string, val complex128)  { e.fields.AddComplex128(key, val) }
+func (e *sortedEncoder) AddComplex64(key string, val complex64)    { e.fields.AddComplex64(key, val) }
+func (e *sortedEncoder) AddDuration(key string, val time.Duration) { e.fields.AddDuration(key, val) }
+func (e *sortedEncoder) AddFloat64(key string, val float64)        { e.fields.AddFloat64(key, val) }
+func (e *sortedEncoder) AddFloat32(key string, val float32)        { e.fields.AddFloat32(key, val) }
+func (e *sortedEncoder) AddInt(key string, val int)                { e.fields.AddInt(key, val) }
+func (e *sortedEncoder) AddInt64(key string, val int64)            { e.fields.AddInt64(key, val) }
+func (e *sortedEncoder) AddInt32(key string, val int32)            { e.fields.AddInt32(key, val) }
+func (e *sortedEncoder) AddInt16(key string, val int16)            { e.fields.AddInt16(key, val) }
+func (e *sortedEncoder) AddInt8(key string, val int8)              { e.fields.AddInt8(key, val) }
+func (e *sortedEncoder) AddString(key, val string)                 { e.fields.AddString(key, val) }
+func (e *sortedEncoder) AddTime(key string, val time.Time)         { e.fields.AddTime(key, val) }
+func (e *sortedEncoder) AddUint(key string, val uint)              { e.fields.AddUint(key, val) }
+func (e *sortedEncoder) AddUint64(key string, val uint64)          { e.fields.AddUint64(key, val) }
+func (e *sortedEncoder) AddUint32(key string, val uint32)          { e.fields.AddUint32(key, val) }
+func (e *sortedEncoder) AddUint16(key string, val uint16)          { e.fields.AddUint16(key, val) }
+func (e *sortedEncoder) AddUint8(key string, val uint8)            { e.fields.AddUint8(key, val) }
+func (e *sortedEncoder) AddUintptr(key string, val uintptr)        { e.fields.AddUintptr(key, val) }
+func (e *sortedEncoder) OpenNamespace(key string)                  { e.fields.OpenNamespace(key) }
+
+func (e *sortedEncoder) AddReflected(key string, val interface{}) error {
+	return e.fields.AddReflected(key, val)
+}
+
+// fieldRecorder is an ObjectEncoder that records what's added to it as
+// Fields, so that they can be reordered before they're encoded. Inline
+// marshalers add their keys directly to it, which expands them.
+type fieldRecorder []Field
+
+func (r *fieldRecorder) add(f Field) {
+	*r = append(*r, f)
+}
+
+func (r *fieldRecorder) AddArray(key string, arr ArrayMarshaler) error {
+	r.add(Field{Key: key, Type: ArrayMarshalerType, Interface: arr})
+	return nil
+}
+
+func (r *fieldRecorder) AddObject(key string, obj ObjectMarshaler) error {
+	r.add(Field{Key: key, Type: ObjectMarshalerType, Interface: obj})
+	return nil
+}
+
+func (r *fieldRecorder) AddBinary(key string, val []byte) {
+	r.add(Field{Key: key, Type: BinaryType, Interface: val})
+}
+
+func (r *fieldRecorder) AddByteString(key string, val []byte) {
+	r.add(Field{Key: key, Type: ByteStringType, Interface: val})
+}
+
+func (r *fieldRecorder) AddBool(key string, val bool) {
+	var i int64
+	if val {
+		i = 1
+	}
+	r.add(Field{Key: key, Type: BoolType, Integer: i})
+}
+
+func (r *fieldRecorder) AddComplex128(key string, val complex128) {
+	r.add(Field{Key: key, Type: Complex128Type, Interface: val})
+}
+
+func (r *fieldRecorder) AddComplex64(key string, val complex64) {
+	r.add(Field{Key: key, Type: Complex64Type, Interface: val})
+}
+
+func (r *fieldRecorder) AddDuration(key string, val time.Duration) {
+	r.add(Field{Key: key, Type: DurationType, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddFloat64(key string, val float64) {
+	r.add(Field{Key: key, Type: Float64Type, Integer: int64(math.Float64bits(val))})
+}
+
+func (r *fieldRecorder) AddFloat32(key string, val float32) {
+	r.add(Field{Key: key, Type: Float32Type, Integer: int64(math.Float32bits(val))})
+}
+
+func (r *fieldRecorder) AddInt(key string, val int) {
+	r.AddInt64(key, int64(val))
+}
+
+func (r *fieldRecorder) AddInt64(key string, val int64) {
+	r.add(Field{Key: key, Type: Int64Type, Integer: val})
+}
+
+func (r *fieldRecorder) AddInt32(key string, val int32) {
+	r.add(Field{Key: key, Type: Int32Type, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddInt16(key string, val int16) {
+	r.add(Field{Key: key, Type: Int16Type, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddInt8(key string, val int8) {
+	r.add(Field{Key: key, Type: Int8Type, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddString(key, val string) {
+	r.add(Field{Key: key, Type: StringType, String: val})
+}
+
+func (r *fieldRecorder) AddTime(key string, val time.Time) {
+	// Record the time in full, since it may not fit in nanoseconds since the
+	// epoch.
+	r.add(Field{Key: key, Type: TimeFullType, Interface: val})
+}
+
+func (r *fieldRecorder) AddUint(key string, val uint) {
+	r.AddUint64(key, uint64(val))
+}
+
+func (r *fieldRecorder) AddUint64(key string, val uint64) {
+	r.add(Field{Key: key, Type: Uint64Type, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddUint32(key string, val uint32) {
+	r.add(Field{Key: key, Type: Uint32Type, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddUint16(key string, val uint16) {
+	r.add(Field{Key: key, Type: Uint16Type, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddUint8(key string, val uint8) {
+	r.add(Field{Key: key, Type: Uint8Type, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddUintptr(key string, val uintptr) {
+	r.add(Field{Key: key, Type: UintptrType, Integer: int64(val)})
+}
+
+func (r *fieldRecorder) AddReflected(key string, val interface{}) error {
+	r.add(Field{Key: key, Type: ReflectType, Interface: val})
+	return nil
+}
+
+func (r *fieldRecorder) OpenNamespace(key string) {
+	r.add(Field{Key: key, Type: NamespaceType})
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortedEncoder(t *testing.T) {
+	ns := Field{Key: "ns", Type: NamespaceType}
+	inline := Field{Type: InlineMarshalerType, Interface: users(2)}
+
+	tests := []struct {
+		desc     string
+		first    []string
+		context  []Field
+		fields   []Field
+		expected string
+	}{
+		{
+			desc:     "sorted",
+			context:  []Field{makeStringField("c", "ctx"), makeInt64Field("a", 1)},
+			fields:   []Field{makeInt64Field("b", 2), inline},
+			expected: `{"a":1,"b":2,"c":"ctx","users":2}`,
+		},
+		{
+			desc:     "priority keys first",
+			first:    []string{"z", "c"},
+			context:  []Field{makeStringField("c", "ctx"), makeInt64Field("a", 1)},
+			fields:   []Field{makeInt64Field("b", 2), makeInt64Field("z", 26)},
+			expected: `{"z":26,"c":"ctx","a":1,"b":2}`,
+		},
+		{
+			desc:     "duplicate keys keep their order",
+			fields:   []Field{makeInt64Field("b", 1), makeInt64Field("a", 2), makeInt64Field("b", 3)},
+			expected: `{"a":2,"b":1,"b":3}`,
+		},
+		{
+			desc:     "namespaces",
+			context:  []Field{makeInt64Field("b", 1), ns, makeInt64Field("d", 2)},
+			fields:   []Field{makeInt64Field("c", 3), makeInt64Field("a", 4)},
+			expected: `{"b":1,"ns":{"a":4,"c":3,"d":2}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			enc := NewSortedEncoder(NewJSONEncoder(EncoderConfig{}), tt.first...)
+			enc = enc.Clone()
+			for _, f := range tt.context {
+				f.AddTo(enc)
+			}
+			buf, err := enc.EncodeEntry(Entry{}, tt.fields)
+			require.NoError(t, err, "Unexpected error encoding entry.")
+			assert.Equal(t, tt.expected+"\n", buf.String(), "Unexpected output.")
+			buf.Free()
+		})
+	}
+}
+
+func TestSortedEncoderTypes(t *testing.T) {
+	// Every type added to the encoder should survive recording unchanged.
+	add := func(enc ObjectEncoder) {
+		enc.AddBinary("binary", []byte("b"))
+		enc.AddByteString("bytes", []byte("s"))
+		enc.AddBool("bool", true)
+		enc.AddComplex128("c128", 1+2i)
+		enc.AddComplex64("c64", 3+4i)
+		enc.AddDuration("duration", time.Second)
+		enc.AddFloat64("f64", 1.5)
+		enc.AddFloat32("f32", 2.5)
+		enc.AddInt("int", -1)
+		enc.AddInt64("i64", -2)
+		enc.AddInt32("i32", -3)
+		enc.AddInt16("i16", -4)
+		enc.AddInt8("i8", -5)
+		enc.AddString("string", "s")
+		enc.AddTime("time", time.Unix(0, 1).UTC())
+		enc.AddUint("uint", 1)
+		enc.AddUint64("u64", 2)
+		enc.AddUint32("u32", 3)
+		enc.AddUint16("u16", 4)
+		enc.AddUint8("u8", 5)
+		enc.AddUintptr("uptr", 6)
+		assert.NoError(t, enc.AddReflected("reflect", []int{1}), "Unexpected error adding reflected value.")
+		assert.NoError(t, enc.AddArray("array", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+			arr.AppendInt(1)
+			return nil
+		})), "Unexpected error adding array.")
+		assert.NoError(t, enc.AddObject("object", users(1)), "Unexpected error adding object.")
+	}
+
+	enc := NewSortedEncoder(NewJSONEncoder(EncoderConfig{
+		EncodeDuration: StringDurationEncoder,
+		EncodeTime:     EpochNanosTimeEncoder,
+	})).Clone()
+	add(enc)
+	buf, err := enc.EncodeEntry(Entry{}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		`{"array":[1],"binary":"Yg==","bool":true,"bytes":"s","c128":"1+2i","c64":"3+4i","duration":"1s",`+
+			`"f32":2.5,"f64":1.5,"i16":-4,"i32":-3,"i64":-2,"i8":-5,"int":-1,"object":{"users":1},"reflect":[1],`+
+			`"string":"s","time":1,"u16":4,"u32":3,"u64":2,"u8":5,"uint":1,"uptr":6}`+"\n",
+		buf.String(),
+		"Unexpected output.",
+	)
+	buf.Free()
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import "go.uber.org/multierr"
+
+type splitCore struct {
+	low, high Core
+	split     Level
+}
+
+// NewSplitCore creates a Core that writes entries below the split level to
+// low and the rest to high, both encoded with enc. For example,
+//   zapcore.NewSplitCore(enc, zapcore.Lock(os.Stdout), zapcore.Lock(os.Stderr), zapcore.WarnLevel, zapcore.DebugLevel)
+// sends debug and info logs to standard out and warnings and errors to
+// standard error, as container orchestrators often expect.
+//
+// The split doesn't affect which entries are enabled, which is still up to
+// enab. Syncing the returned Core syncs both outputs.
+func NewSplitCore(enc Encoder, low, high WriteSyncer, split Level, enab LevelEnabler) Core {
+	return &splitCore{
+		low:   NewCore(enc, low, enab),
+		high:  NewCore(enc.Clone(), high, enab),
+		split: split,
+	}
+}
+
+func (c *splitCore) route(lvl Level) Core {
+	if lvl < c.split {
+		return c.low
+	}
+	return c.high
+}
+
+func (c *splitCore) Enabled(lvl Level) bool {
+	return c.route(lvl).Enabled(lvl)
+}
+
+func (c *splitCore) With(fields []Field) Core {
+	return &splitCore{
+		low:   c.low.With(fields),
+		high:  c.high.With(fields),
+		split: c.split,
+	}
+}
+
+func (c *splitCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return c.route(ent.Level).Check(ent, ce)
+}
+
+func (c *splitCore) Write(ent Entry, fields []Field) error {
+	return c.route(ent.Level).Write(ent, fields)
+}
+
+func (c *splitCore) Sync() error {
+	return multierr.Append(c.low.Sync(), c.high.Sync())
+}
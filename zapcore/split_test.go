@@ -0,0 +1,64 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"errors"
+	"testing"
+
+	"go.uber.org/zap/internal/ztest"
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCore(t *testing.T) {
+	low, high := &ztest.Buffer{}, &ztest.Buffer{}
+	enc := NewJSONEncoder(EncoderConfig{MessageKey: "msg"})
+	core := NewSplitCore(enc, low, high, WarnLevel, InfoLevel).With([]Field{makeInt64Field("k", 1)})
+
+	assert.False(t, core.Enabled(DebugLevel), "Expected the split not to enable more levels.")
+	assert.True(t, core.Enabled(InfoLevel), "Expected info to be enabled.")
+	assert.True(t, core.Enabled(ErrorLevel), "Expected error to be enabled.")
+
+	for _, lvl := range []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel} {
+		if ce := core.Check(Entry{Level: lvl, Message: lvl.String()}, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	require.NoError(t, core.Write(Entry{Level: DPanicLevel, Message: "direct"}, nil), "Unexpected error writing.")
+
+	assert.Equal(t, []string{`{"msg":"info","k":1}`}, low.Lines(), "Unexpected low-level output.")
+	assert.Equal(
+		t,
+		[]string{`{"msg":"warn","k":1}`, `{"msg":"error","k":1}`, `{"msg":"direct","k":1}`},
+		high.Lines(),
+		"Unexpected high-level output.",
+	)
+
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	assert.True(t, low.Called(), "Expected the low output to be synced.")
+	assert.True(t, high.Called(), "Expected the high output to be synced.")
+
+	high.SetError(errors.New("fail"))
+	assert.Error(t, core.Sync(), "Expected sync errors to propagate.")
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sync"
+	"time"
+)
+
+// NewStatsCore wraps a Core and counts the entries it writes at each level.
+// Once per tick, it logs a summary of the previous window's counts at
+// InfoLevel with the supplied message, like
+//   {"level":"info","msg":"log_stats","debug":1000,"info":200,"error":5}
+// Levels with no entries in the window are omitted.
+//
+// Like the sampler, the core doesn't start any goroutines: a window's summary
+// is written just before the first entry of a later window, and nothing is
+// written while no entries are logged. The summary goes straight to the
+// wrapped Core (without any context added via With), so it's never counted
+// itself. It's still filtered like any other entry, though: if the wrapped
+// Core doesn't enable InfoLevel, or its Check drops the summary (for example,
+// because it's sampled), no summary is written for that window.
+func NewStatsCore(core Core, tick time.Duration, msg string) Core {
+	return &statsCore{
+		Core:  core,
+		stats: &logStats{out: core, tick: tick, msg: msg},
+	}
+}
+
+type statsCore struct {
+	Core
+	stats *logStats
+}
+
+func (c *statsCore) With(fields []Field) Core {
+	return &statsCore{
+		Core:  c.Core.With(fields),
+		stats: c.stats,
+	}
+}
+
+func (c *statsCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkRewritingFields(c.Core, ent, ce, c.count)
+}
+
+func (c *statsCore) Write(ent Entry, fields []Field) error {
+	return c.Core.Write(ent, c.count(ent, fields))
+}
+
+func (c *statsCore) count(ent Entry, fields []Field) []Field {
+	c.stats.record(ent)
+	return fields
+}
+
+// logStats tracks the entry counts for the current window and is shared by
+// a statsCore and its children.
+type logStats struct {
+	out  Core
+	tick time.Duration
+	msg  string
+
+	mu     sync.Mutex
+	start  time.Time
+	counts [_numLevels]uint64
+}
+
+func (s *logStats) record(ent Entry) {
+	s.mu.Lock()
+	if s.start.IsZero() {
+		s.start = ent.Time
+	}
+	var (
+		prev    [_numLevels]uint64
+		flushed bool
+	)
+	if ent.Time.Sub(s.start) >= s.tick {
+		prev, flushed = s.counts, true
+		s.counts = [_numLevels]uint64{}
+		s.start = ent.Time
+	}
+	if ent.Level >= _minLevel && ent.Level <= _maxLevel {
+		s.counts[ent.Level-_minLevel]++
+	}
+	s.mu.Unlock()
+
+	if flushed {
+		// Write outside the lock, since the wrapped Core may be slow.
+		s.emit(ent.Time, prev)
+	}
+}
+
+func (s *logStats) emit(t time.Time, counts [_numLevels]uint64) {
+	if !s.out.Enabled(InfoLevel) {
+		return
+	}
+	ent := Entry{Level: InfoLevel, Time: t, Message: s.msg}
+	ce := s.out.Check(ent, nil)
+	if ce == nil {
+		return
+	}
+	fields := make([]Field, 0, _numLevels)
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		lvl := Level(i) + _minLevel
+		fields = append(fields, Field{Key: lvl.String(), Type: Uint64Type, Integer: int64(n)})
+	}
+	ce.Write(fields...)
+}
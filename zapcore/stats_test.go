@@ -0,0 +1,108 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsCore(t *testing.T) {
+	obs, logs := observer.New(DebugLevel)
+	core := NewStatsCore(obs, time.Second, "log_stats").With([]Field{makeInt64Field("ctx", 1)})
+
+	start := time.Unix(0, 0)
+	write := func(lvl Level, offset time.Duration) {
+		ent := Entry{Level: lvl, Time: start.Add(offset), Message: "msg"}
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write()
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		write(DebugLevel, 0)
+	}
+	write(InfoLevel, 100*time.Millisecond)
+	write(ErrorLevel, 999*time.Millisecond)
+	assert.Equal(t, 5, logs.Len(), "Expected no stats before the window ends.")
+	logs.TakeAll()
+
+	// The first entry in the next window flushes the previous one's counts.
+	write(WarnLevel, time.Second)
+	write(WarnLevel, 1500*time.Millisecond)
+	entries := logs.TakeAll()
+	require.Equal(t, 3, len(entries), "Expected a stats entry and two regular entries.")
+	assert.Equal(t, Entry{Level: InfoLevel, Time: start.Add(time.Second), Message: "log_stats"}, entries[0].Entry, "Unexpected stats entry.")
+	assert.Equal(t, map[string]interface{}{
+		"debug": uint64(3),
+		"info":  uint64(1),
+		"error": uint64(1),
+	}, entries[0].ContextMap(), "Unexpected stats counts.")
+	assert.Equal(t, WarnLevel, entries[1].Level, "Expected the triggering entry after the stats.")
+
+	// The stats entry isn't counted into the next window.
+	write(DebugLevel, 3*time.Second)
+	entries = logs.TakeAll()
+	require.Equal(t, 2, len(entries), "Expected a stats entry and a regular entry.")
+	assert.Equal(t, map[string]interface{}{"warn": uint64(2)}, entries[0].ContextMap(), "Unexpected stats counts.")
+}
+
+func TestStatsCoreCountsOnlyWrittenEntries(t *testing.T) {
+	obs, logs := observer.New(InfoLevel)
+	core := NewStatsCore(obs, time.Second, "stats")
+
+	start := time.Unix(0, 0)
+	for _, ent := range []Entry{
+		{Level: DebugLevel, Time: start},
+		{Level: InfoLevel, Time: start},
+		{Level: DebugLevel, Time: start.Add(2 * time.Second)},
+		{Level: InfoLevel, Time: start.Add(2 * time.Second)},
+	} {
+		if ce := core.Check(ent, nil); ce != nil {
+			ce.Write()
+		}
+	}
+
+	entries := logs.TakeAll()
+	require.Equal(t, 3, len(entries), "Expected two regular entries and one stats entry.")
+	assert.Equal(t, "stats", entries[1].Message, "Expected stats to be written before the entry that ended the window.")
+	assert.Equal(t, map[string]interface{}{"info": uint64(1)}, entries[1].ContextMap(), "Expected disabled entries not to be counted.")
+}
+
+func TestStatsCoreDisabledStats(t *testing.T) {
+	obs, logs := observer.New(WarnLevel)
+	core := NewStatsCore(obs, time.Second, "stats")
+
+	start := time.Unix(0, 0)
+	for _, ent := range []Entry{
+		{Level: ErrorLevel, Time: start},
+		{Level: ErrorLevel, Time: start.Add(time.Minute)},
+	} {
+		require.NoError(t, core.Write(ent, nil), "Unexpected error writing entry.")
+	}
+	assert.Equal(t, 2, logs.Len(), "Expected stats to respect the wrapped core's level.")
+}
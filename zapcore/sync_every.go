@@ -0,0 +1,149 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"sync"
+	"time"
+)
+
+type syncEveryCore struct {
+	Core
+	state *syncState
+}
+
+// syncState is shared by a syncEveryCore and the Cores derived from it with
+// With.
+type syncState struct {
+	sync.Mutex
+	core     Core
+	interval time.Duration
+	clock    Clock
+	last     time.Time
+	dirty    bool
+	// cancel cancels the pending sync, if any.
+	cancel func() bool
+}
+
+// NewSyncEveryCore wraps a Core so that it's synced in the background after
+// it writes an entry, but at most once per interval. Every write is synced
+// within an interval of being written, but an idle Core is never synced.
+// This strikes a balance between syncing after every entry, which is
+// durable but slow, and syncing on a fixed timer, which wastes work when
+// there's nothing to sync.
+//
+// Since syncs happen in the background, their errors are dropped; errors
+// from explicit calls to Sync are returned as usual. Cores derived from the
+// returned Core with With share its schedule.
+func NewSyncEveryCore(core Core, interval time.Duration, clock Clock) Core {
+	return &syncEveryCore{
+		Core: core,
+		state: &syncState{
+			core:     core,
+			interval: interval,
+			clock:    clock,
+		},
+	}
+}
+
+func (c *syncEveryCore) With(fields []Field) Core {
+	return &syncEveryCore{
+		Core:  c.Core.With(fields),
+		state: c.state,
+	}
+}
+
+func (c *syncEveryCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	var start int
+	if ce != nil {
+		start = len(ce.cores)
+	}
+	ce = c.Core.Check(ent, ce)
+	if ce != nil && len(ce.cores) > start {
+		// Run after the wrapped Core, so that we see its writes.
+		ce = ce.AddCore(ent, syncScheduler{c.state})
+	}
+	return ce
+}
+
+func (c *syncEveryCore) Write(ent Entry, fields []Field) error {
+	err := c.Core.Write(ent, fields)
+	c.state.wrote()
+	return err
+}
+
+func (c *syncEveryCore) Sync() error {
+	s := c.state
+	s.Lock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.dirty = false
+	s.last = s.clock.Now()
+	s.Unlock()
+	return c.Core.Sync()
+}
+
+// wrote schedules a sync, unless one is already pending.
+func (s *syncState) wrote() {
+	s.Lock()
+	defer s.Unlock()
+	if s.dirty {
+		return
+	}
+	s.dirty = true
+	wait := s.last.Add(s.interval).Sub(s.clock.Now())
+	if wait < 0 {
+		wait = 0
+	}
+	s.cancel = s.clock.AfterFunc(wait, s.sync)
+}
+
+func (s *syncState) sync() {
+	s.Lock()
+	if !s.dirty {
+		// Someone synced explicitly after this sync was scheduled.
+		s.Unlock()
+		return
+	}
+	s.dirty = false
+	s.cancel = nil
+	s.last = s.clock.Now()
+	s.Unlock()
+	s.core.Sync()
+}
+
+// syncScheduler schedules a sync after an entry is written. It doesn't write
+// anything itself.
+type syncScheduler struct {
+	state *syncState
+}
+
+func (s syncScheduler) Enabled(Level) bool                            { return true }
+func (s syncScheduler) With([]Field) Core                             { return s }
+func (s syncScheduler) Check(_ Entry, ce *CheckedEntry) *CheckedEntry { return ce }
+func (s syncScheduler) Sync() error                                   { return nil }
+
+func (s syncScheduler) Write(Entry, []Field) error {
+	s.state.wrote()
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock runs scheduled functions synchronously as it's advanced.
+type fakeClock struct {
+	sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) func() bool {
+	c.Lock()
+	defer c.Unlock()
+	t := &fakeTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.Lock()
+		defer c.Unlock()
+		stopped := !t.stopped
+		t.stopped = true
+		return stopped
+	}
+}
+
+// NewTicker returns a ticker that never ticks, since none of the Cores under
+// test use tickers.
+func (c *fakeClock) NewTicker(time.Duration) (<-chan time.Time, func()) {
+	return nil, func() {}
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	c.now = c.now.Add(d)
+	var due []*fakeTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.at.After(c.now):
+			t.stopped = true
+			due = append(due, t)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	c.timers = pending
+	c.Unlock()
+	for _, t := range due {
+		t.f()
+	}
+}
+
+type countingSyncer struct {
+	sync.Mutex
+	writes, syncs int
+}
+
+func (s *countingSyncer) Write(p []byte) (int, error) {
+	s.Lock()
+	s.writes++
+	s.Unlock()
+	return len(p), nil
+}
+
+func (s *countingSyncer) Sync() error {
+	s.Lock()
+	s.syncs++
+	s.Unlock()
+	return nil
+}
+
+func (s *countingSyncer) Syncs() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.syncs
+}
+
+func TestSyncEveryCore(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ws := &countingSyncer{}
+	core := NewSyncEveryCore(NewCore(NewJSONEncoder(testEncoderConfig()), ws, DebugLevel), time.Second, clock).
+		With([]Field{makeInt64Field("k", 1)})
+
+	write := func(n int) {
+		for i := 0; i < n; i++ {
+			if ce := core.Check(Entry{Level: InfoLevel, Message: "hello"}, nil); ce != nil {
+				ce.Write()
+			}
+		}
+	}
+
+	// The first write of a burst is synced right away.
+	write(10)
+	clock.Advance(0)
+	assert.Equal(t, 1, ws.Syncs(), "Expected a burst of writes to be synced once.")
+
+	// Writes within the interval are synced once the interval elapses.
+	clock.Advance(100 * time.Millisecond)
+	write(10)
+	clock.Advance(800 * time.Millisecond)
+	assert.Equal(t, 1, ws.Syncs(), "Expected at most one sync per interval.")
+	clock.Advance(100 * time.Millisecond)
+	assert.Equal(t, 2, ws.Syncs(), "Expected writes to be synced within an interval.")
+
+	// Idle Cores aren't synced.
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, 2, ws.Syncs(), "Expected idle Cores not to be synced.")
+
+	// After an idle period, writes are synced right away again.
+	write(1)
+	clock.Advance(0)
+	assert.Equal(t, 3, ws.Syncs(), "Expected writes after an idle period to be synced right away.")
+	assert.Equal(t, 21, ws.writes, "Unexpected number of writes.")
+}
+
+func TestSyncEveryCoreExplicitSync(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ws := &countingSyncer{}
+	core := NewSyncEveryCore(NewCore(NewJSONEncoder(testEncoderConfig()), ws, DebugLevel), time.Second, clock)
+
+	require.NoError(t, core.Write(Entry{Message: "hello"}, nil), "Unexpected error writing entry.")
+	require.NoError(t, core.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, 1, ws.Syncs(), "Expected an explicit sync.")
+	clock.Advance(0)
+	assert.Equal(t, 1, ws.Syncs(), "Expected an explicit sync to cancel the pending sync.")
+
+	core.Write(Entry{Message: "hello"}, nil)
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, 1, ws.Syncs(), "Expected explicit syncs to count toward the interval.")
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, 2, ws.Syncs(), "Expected the write to be synced within an interval.")
+}
+
+func TestSyncEveryCoreDisabled(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	ws := &countingSyncer{}
+	core := NewSyncEveryCore(NewCore(NewJSONEncoder(testEncoderConfig()), ws, InfoLevel), time.Second, clock)
+
+	assert.Nil(t, core.Check(Entry{Level: DebugLevel}, nil), "Expected disabled entries to be dropped.")
+	clock.Advance(time.Second)
+	assert.Equal(t, 0, ws.Syncs(), "Expected disabled entries not to trigger syncs.")
+}
+
+func TestDefaultClock(t *testing.T) {
+	before := time.Now()
+	assert.False(t, DefaultClock.Now().Before(before), "Expected the default clock to use the system time.")
+
+	done := make(chan struct{})
+	DefaultClock.AfterFunc(time.Millisecond, func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected AfterFunc to call the function.")
+	}
+	assert.True(t, DefaultClock.AfterFunc(time.Hour, func() {})(), "Expected to stop a pending call.")
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+type tagCore struct {
+	Core
+	key  string
+	tags []Field
+}
+
+// NewTagCore wraps a Core so that tags (fields of TagType) are gathered into
+// a single nested object under the supplied key, separate from the entry's
+// other fields. This suits backends that index low-cardinality tags
+// differently from the high-cardinality fields stored alongside them.
+//
+// Tags added via With are kept by the returned Core rather than serialized
+// by the wrapped Core, so they end up in the same nested object as tags
+// passed at the log site. Entries without any tags don't get an empty tags
+// object.
+func NewTagCore(core Core, key string) Core {
+	return &tagCore{
+		Core: core,
+		key:  key,
+	}
+}
+
+func (c *tagCore) With(fields []Field) Core {
+	tags, rest := partitionTags(fields)
+	return &tagCore{
+		Core: c.Core.With(rest),
+		key:  c.key,
+		tags: append(c.tags[:len(c.tags):len(c.tags)], tags...),
+	}
+}
+
+func (c *tagCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkRewritingFields(c.Core, ent, ce, c.groupTags)
+}
+
+func (c *tagCore) Write(ent Entry, fields []Field) error {
+	return c.Core.Write(ent, c.groupTags(ent, fields))
+}
+
+func (c *tagCore) groupTags(_ Entry, fields []Field) []Field {
+	tags, rest := partitionTags(fields)
+	if len(tags) == 0 && len(c.tags) == 0 {
+		return fields
+	}
+	all := make(tagSet, 0, len(c.tags)+len(tags))
+	all = append(all, c.tags...)
+	all = append(all, tags...)
+
+	// Copy the remaining fields so that we never write into the caller's
+	// backing array.
+	out := make([]Field, len(rest), len(rest)+1)
+	copy(out, rest)
+	return append(out, Field{Key: c.key, Type: ObjectMarshalerType, Interface: all})
+}
+
+// partitionTags splits fields into tags and everything else, preserving
+// order. It doesn't allocate if there are no tags.
+func partitionTags(fields []Field) (tags []Field, rest []Field) {
+	for i := range fields {
+		if fields[i].Type == TagType {
+			tags = append(tags, fields[i])
+		}
+	}
+	if len(tags) == 0 {
+		return nil, fields
+	}
+	rest = make([]Field, 0, len(fields)-len(tags))
+	for i := range fields {
+		if fields[i].Type != TagType {
+			rest = append(rest, fields[i])
+		}
+	}
+	return tags, rest
+}
+
+type tagSet []Field
+
+func (ts tagSet) MarshalLogObject(enc ObjectEncoder) error {
+	for i := range ts {
+		ts[i].AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTagField(key, val string) Field {
+	return Field{Key: key, Type: TagType, String: val}
+}
+
+func TestTagCore(t *testing.T) {
+	fac, logs := observer.New(InfoLevel)
+	core := NewTagCore(fac, "tags").With([]Field{
+		makeTagField("region", "us-east"),
+		makeInt64Field("attempt", 1),
+	})
+
+	for _, lvl := range []Level{DebugLevel, InfoLevel} {
+		if ce := core.Check(Entry{Level: lvl, Message: "hello"}, nil); ce != nil {
+			ce.Write(makeTagField("service", "api"), makeInt64Field("latency", 42))
+		}
+	}
+	if ce := core.Check(Entry{Level: InfoLevel, Message: "no site tags"}, nil); ce != nil {
+		ce.Write()
+	}
+
+	entries := logs.AllUntimed()
+	require.Equal(t, 2, len(entries), "Unexpected number of entries.")
+
+	tests := []struct {
+		ent      observer.LoggedEntry
+		expected map[string]interface{}
+	}{
+		{
+			ent: entries[0],
+			expected: map[string]interface{}{
+				"attempt": int64(1),
+				"latency": int64(42),
+				"tags":    map[string]interface{}{"region": "us-east", "service": "api"},
+			},
+		},
+		{
+			ent: entries[1],
+			expected: map[string]interface{}{
+				"attempt": int64(1),
+				"tags":    map[string]interface{}{"region": "us-east"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.ent.ContextMap(), "Unexpected context for entry %q.", tt.ent.Message)
+	}
+}
+
+func TestTagCoreNoTags(t *testing.T) {
+	fac, logs := observer.New(InfoLevel)
+	core := NewTagCore(fac, "tags")
+	if ce := core.Check(Entry{Level: InfoLevel}, nil); ce != nil {
+		ce.Write(makeInt64Field("k", 1))
+	}
+	assert.Equal(
+		t,
+		[]observer.LoggedEntry{{Entry: Entry{Level: InfoLevel}, Context: []Field{makeInt64Field("k", 1)}}},
+		logs.AllUntimed(),
+		"Expected fields to pass through unchanged without tags.",
+	)
+}
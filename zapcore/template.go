@@ -0,0 +1,140 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+type templateCore struct {
+	Core
+	context   []Field
+	templates []Field
+}
+
+// NewTemplateCore wraps a Core so that templates (fields of TemplateType) are
+// resolved against the entry's other fields before they're written. In a
+// template, each reference of the form {key} is replaced with the value of
+// the field with that key, whether it was added via With or at the log site;
+// if several fields share a key, the last one wins. References to missing
+// fields (including other templates) are left in place, so that they're easy
+// to spot. For example,
+//   Template("summary", "{user} did {action} to {target}")
+// logged with String("user", "alice") and String("action", "delete") becomes
+//   "alice did delete to {target}"
+//
+// Resolving templates requires rendering the referenced fields a second
+// time, so it's considerably slower than logging the fields alone. Entries
+// without any templates are passed through untouched.
+func NewTemplateCore(core Core) Core {
+	return &templateCore{Core: core}
+}
+
+func (c *templateCore) With(fields []Field) Core {
+	templates, rest := partitionTemplates(fields)
+	return &templateCore{
+		Core:      c.Core.With(rest),
+		context:   append(c.context[:len(c.context):len(c.context)], rest...),
+		templates: append(c.templates[:len(c.templates):len(c.templates)], templates...),
+	}
+}
+
+func (c *templateCore) Check(ent Entry, ce *CheckedEntry) *CheckedEntry {
+	return checkRewritingFields(c.Core, ent, ce, c.resolve)
+}
+
+func (c *templateCore) Write(ent Entry, fields []Field) error {
+	return c.Core.Write(ent, c.resolve(ent, fields))
+}
+
+func (c *templateCore) resolve(_ Entry, fields []Field) []Field {
+	templates, rest := partitionTemplates(fields)
+	if len(templates) == 0 && len(c.templates) == 0 {
+		return fields
+	}
+
+	enc := NewMapObjectEncoder()
+	addFields(enc, c.context)
+	addFields(enc, rest)
+
+	// Copy the fields so that we never write into the caller's backing array.
+	out := make([]Field, len(fields), len(fields)+len(c.templates))
+	copy(out, fields)
+	for i := range out {
+		if out[i].Type == TemplateType {
+			out[i] = resolveTemplate(out[i], enc.Fields)
+		}
+	}
+	for i := range c.templates {
+		out = append(out, resolveTemplate(c.templates[i], enc.Fields))
+	}
+	return out
+}
+
+func resolveTemplate(f Field, values map[string]interface{}) Field {
+	return Field{Key: f.Key, Type: StringType, String: expandTemplate(f.String, values)}
+}
+
+func expandTemplate(tmpl string, values map[string]interface{}) string {
+	var buf bytes.Buffer
+	for {
+		open := strings.IndexByte(tmpl, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(tmpl[open+1:], '}')
+		if end < 0 {
+			break
+		}
+		end += open + 1
+		buf.WriteString(tmpl[:open])
+		if v, ok := values[tmpl[open+1:end]]; ok {
+			fmt.Fprint(&buf, v)
+		} else {
+			buf.WriteString(tmpl[open : end+1])
+		}
+		tmpl = tmpl[end+1:]
+	}
+	buf.WriteString(tmpl)
+	return buf.String()
+}
+
+// partitionTemplates splits fields into templates and everything else,
+// preserving order. It doesn't allocate if there are no templates.
+func partitionTemplates(fields []Field) (templates []Field, rest []Field) {
+	for i := range fields {
+		if fields[i].Type == TemplateType {
+			templates = append(templates, fields[i])
+		}
+	}
+	if len(templates) == 0 {
+		return nil, fields
+	}
+	rest = make([]Field, 0, len(fields)-len(templates))
+	for i := range fields {
+		if fields[i].Type != TemplateType {
+			rest = append(rest, fields[i])
+		}
+	}
+	return templates, rest
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTemplateField(key, tmpl string) Field {
+	return Field{Key: key, Type: TemplateType, String: tmpl}
+}
+
+func makeStringField(key, val string) Field {
+	return Field{Key: key, Type: StringType, String: val}
+}
+
+func TestTemplateCore(t *testing.T) {
+	tests := []struct {
+		desc     string
+		context  []Field
+		fields   []Field
+		expected map[string]interface{}
+	}{
+		{
+			desc: "no templates",
+			fields: []Field{
+				makeStringField("user", "alice"),
+			},
+			expected: map[string]interface{}{"user": "alice"},
+		},
+		{
+			desc: "substitution",
+			fields: []Field{
+				makeStringField("user", "alice"),
+				makeTemplateField("summary", "{user} did {action} {n} times"),
+				makeStringField("action", "delete"),
+				makeInt64Field("n", 3),
+			},
+			expected: map[string]interface{}{
+				"user":    "alice",
+				"action":  "delete",
+				"n":       int64(3),
+				"summary": "alice did delete 3 times",
+			},
+		},
+		{
+			desc:    "context fields and templates",
+			context: []Field{makeStringField("user", "bob"), makeTemplateField("summary", "{user} logged in")},
+			fields:  []Field{makeTemplateField("site", "hi {user}")},
+			expected: map[string]interface{}{
+				"user":    "bob",
+				"site":    "hi bob",
+				"summary": "bob logged in",
+			},
+		},
+		{
+			desc: "missing references",
+			fields: []Field{
+				makeTemplateField("a", "{missing} and {b}"),
+				makeTemplateField("b", "{a}"),
+				makeTemplateField("c", "unclosed {brace and {}"),
+			},
+			expected: map[string]interface{}{
+				"a": "{missing} and {b}",
+				"b": "{a}",
+				"c": "unclosed {brace and {}",
+			},
+		},
+		{
+			desc:    "site fields win",
+			context: []Field{makeStringField("user", "bob")},
+			fields:  []Field{makeStringField("user", "carol"), makeTemplateField("summary", "{user}")},
+			expected: map[string]interface{}{
+				"user":    "carol",
+				"summary": "carol",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			obs, logs := observer.New(DebugLevel)
+			core := NewTemplateCore(obs).With(tt.context)
+			fields := append([]Field(nil), tt.fields...)
+
+			ce := core.Check(Entry{Level: InfoLevel}, nil)
+			require.NotNil(t, ce, "Expected entry to be enabled.")
+			ce.Write(fields...)
+
+			entries := logs.TakeAll()
+			require.Equal(t, 1, len(entries), "Expected exactly one entry.")
+			assert.Equal(t, tt.expected, entries[0].ContextMap(), "Unexpected fields.")
+			assert.Equal(t, tt.fields, fields, "Expected the caller's fields to be unchanged.")
+		})
+	}
+}
+
+func TestTemplateCoreWrite(t *testing.T) {
+	obs, logs := observer.New(DebugLevel)
+	core := NewTemplateCore(obs)
+	require.NoError(t, core.Write(Entry{}, []Field{
+		makeStringField("k", "v"),
+		makeTemplateField("t", "k={k}"),
+	}), "Unexpected error writing entry.")
+	assert.Equal(t, "k=v", logs.AllUntimed()[0].ContextMap()["t"], "Expected template to be resolved.")
+}
@@ -0,0 +1,421 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+import (
+	"encoding/base64"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/internal/bufferpool"
+)
+
+var _textPool = sync.Pool{New: func() interface{} {
+	return &textEncoder{}
+}}
+
+func getTextEncoder(cfg *EncoderConfig) *textEncoder {
+	enc := _textPool.Get().(*textEncoder)
+	enc.EncoderConfig = cfg
+	enc.buf = bufferpool.Get()
+	return enc
+}
+
+func putTextEncoder(enc *textEncoder) {
+	if enc.buf != nil {
+		enc.buf.Free()
+	}
+	enc.EncoderConfig = nil
+	enc.buf = nil
+	enc.namespace = enc.namespace[:0]
+	_textPool.Put(enc)
+}
+
+type textEncoder struct {
+	*EncoderConfig
+	buf *buffer.Buffer
+	// namespace prefixes the keys of fields added to a namespace or nested
+	// object, including its trailing dot. It's a byte slice so that pooled
+	// encoders can reuse its storage.
+	namespace []byte
+}
+
+// NewTextEncoder creates an encoder whose output is designed for reading by
+// eye. Like the console encoder, it serializes the entry's metadata and
+// message in plain text, separated by tabs; unlike the console encoder, it
+// serializes the structured context as space-separated key=value pairs, in
+// the order they were added. For example,
+//   2016-10-01T12:00:00.000Z	INFO	served request	path=/ status=200 took=1.5ms
+//
+// Strings (and keys) are quoted only if they're empty or contain spaces,
+// quotes, equals signs, or non-printable characters. Nested objects and namespaces are
+// flattened into dotted keys (e.g., req.method=GET), while arrays and values
+// added via reflection are serialized as JSON. Times and durations are handed
+// to the configured TimeEncoder and DurationEncoder, falling back to RFC3339
+// timestamps and strings like "1.5ms". As with the console encoder, the keys in
+// the encoder configuration are ignored, except that elements whose key is
+// empty are omitted.
+func NewTextEncoder(cfg EncoderConfig) Encoder {
+	return getTextEncoder(&cfg)
+}
+
+func (enc *textEncoder) addKey(key string) {
+	if enc.buf.Len() > 0 {
+		enc.buf.AppendByte(' ')
+	}
+	if !needsTextKeyQuotes(enc.namespace, key) {
+		enc.buf.Write(enc.namespace)
+		enc.buf.AppendString(key)
+		enc.buf.AppendByte('=')
+		return
+	}
+	// Quote and escape keys the same way as values, so that the line stays
+	// splittable on spaces and equals signs.
+	enc.buf.AppendByte('"')
+	for i := 0; i < len(enc.namespace); {
+		r, size := utf8.DecodeRune(enc.namespace[i:])
+		enc.appendTextRune(r, size, enc.namespace[i])
+		i += size
+	}
+	for i := 0; i < len(key); {
+		r, size := utf8.DecodeRuneInString(key[i:])
+		enc.appendTextRune(r, size, key[i])
+		i += size
+	}
+	enc.buf.AppendString(`"=`)
+}
+
+func (enc *textEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	enc.addKey(key)
+	return enc.appendJSON(func(json *jsonEncoder) error {
+		return json.AppendArray(arr)
+	})
+}
+
+func (enc *textEncoder) AddObject(key string, obj ObjectMarshaler) error {
+	outer := len(enc.namespace)
+	enc.OpenNamespace(key)
+	err := obj.MarshalLogObject(enc)
+	enc.namespace = enc.namespace[:outer]
+	return err
+}
+
+func (enc *textEncoder) AddBinary(key string, val []byte) {
+	enc.AddString(key, base64.StdEncoding.EncodeToString(val))
+}
+
+func (enc *textEncoder) AddByteString(key string, val []byte) {
+	enc.addKey(key)
+	enc.AppendByteString(val)
+}
+
+func (enc *textEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.AppendBool(val)
+}
+
+func (enc *textEncoder) AddComplex128(key string, val complex128) {
+	enc.addKey(key)
+	enc.AppendComplex128(val)
+}
+
+func (enc *textEncoder) AddDuration(key string, val time.Duration) {
+	enc.addKey(key)
+	enc.AppendDuration(val)
+}
+
+func (enc *textEncoder) AddFloat64(key string, val float64) {
+	enc.addKey(key)
+	enc.AppendFloat64(val)
+}
+
+func (enc *textEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.AppendInt64(val)
+}
+
+func (enc *textEncoder) AddReflected(key string, obj interface{}) error {
+	enc.addKey(key)
+	return enc.appendJSON(func(json *jsonEncoder) error {
+		return json.AppendReflected(obj)
+	})
+}
+
+func (enc *textEncoder) OpenNamespace(key string) {
+	enc.namespace = append(enc.namespace, key...)
+	enc.namespace = append(enc.namespace, '.')
+}
+
+func (enc *textEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	enc.AppendString(val)
+}
+
+func (enc *textEncoder) AddTime(key string, val time.Time) {
+	enc.addKey(key)
+	enc.AppendTime(val)
+}
+
+func (enc *textEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.AppendUint64(val)
+}
+
+// appendJSON serializes a value that has no natural key=value form by
+// borrowing a JSON encoder.
+func (enc *textEncoder) appendJSON(f func(*jsonEncoder) error) error {
+	json := getJSONEncoder()
+	json.EncoderConfig = enc.EncoderConfig
+	json.buf = bufferpool.Get()
+	err := f(json)
+	enc.buf.Write(json.buf.Bytes())
+	json.buf.Free()
+	putJSONEncoder(json)
+	return err
+}
+
+// The Append methods implement PrimitiveArrayEncoder, so that the encoders
+// in the EncoderConfig can write values directly into the line.
+
+func (enc *textEncoder) AppendBool(val bool) {
+	enc.buf.AppendBool(val)
+}
+
+func (enc *textEncoder) AppendByteString(val []byte) {
+	if !needsTextQuotesBytes(val) {
+		enc.buf.Write(val)
+		return
+	}
+	enc.buf.AppendByte('"')
+	for i := 0; i < len(val); {
+		r, size := utf8.DecodeRune(val[i:])
+		enc.appendTextRune(r, size, val[i])
+		i += size
+	}
+	enc.buf.AppendByte('"')
+}
+
+func (enc *textEncoder) AppendComplex128(val complex128) {
+	// Cast to a platform-independent, fixed-size type.
+	r, i := float64(real(val)), float64(imag(val))
+	enc.buf.AppendFloat(r, 64)
+	if i >= 0 {
+		enc.buf.AppendByte('+')
+	}
+	enc.buf.AppendFloat(i, 64)
+	enc.buf.AppendByte('i')
+}
+
+func (enc *textEncoder) AppendDuration(val time.Duration) {
+	cur := enc.buf.Len()
+	if enc.EncodeDuration != nil {
+		enc.EncodeDuration(val, enc)
+	}
+	if cur == enc.buf.Len() {
+		enc.buf.AppendString(val.String())
+	}
+}
+
+func (enc *textEncoder) AppendInt64(val int64) {
+	enc.buf.AppendInt(val)
+}
+
+func (enc *textEncoder) AppendString(val string) {
+	if !needsTextQuotes(val) {
+		enc.buf.AppendString(val)
+		return
+	}
+	enc.buf.AppendByte('"')
+	for i := 0; i < len(val); {
+		r, size := utf8.DecodeRuneInString(val[i:])
+		enc.appendTextRune(r, size, val[i])
+		i += size
+	}
+	enc.buf.AppendByte('"')
+}
+
+// appendTextRune writes a rune into a quoted string, escaping it the same way
+// strconv.Quote would, but without allocating. The first byte of the rune's
+// encoding is used to escape invalid UTF-8.
+func (enc *textEncoder) appendTextRune(r rune, size int, first byte) {
+	switch {
+	case r == utf8.RuneError && size == 1:
+		enc.appendTextHex(`\x`, rune(first), 2)
+	case r == '"' || r == '\\':
+		enc.buf.AppendByte('\\')
+		enc.buf.AppendByte(byte(r))
+	case strconv.IsPrint(r):
+		var b [utf8.UTFMax]byte
+		n := utf8.EncodeRune(b[:], r)
+		enc.buf.Write(b[:n])
+	case r == '\a':
+		enc.buf.AppendString(`\a`)
+	case r == '\b':
+		enc.buf.AppendString(`\b`)
+	case r == '\f':
+		enc.buf.AppendString(`\f`)
+	case r == '\n':
+		enc.buf.AppendString(`\n`)
+	case r == '\r':
+		enc.buf.AppendString(`\r`)
+	case r == '\t':
+		enc.buf.AppendString(`\t`)
+	case r == '\v':
+		enc.buf.AppendString(`\v`)
+	case r < ' ' || r == 0x7f:
+		enc.appendTextHex(`\x`, r, 2)
+	case r < 0x10000:
+		enc.appendTextHex(`\u`, r, 4)
+	default:
+		enc.appendTextHex(`\U`, r, 8)
+	}
+}
+
+// appendTextHex writes an escape sequence with the given number of
+// hexadecimal digits.
+func (enc *textEncoder) appendTextHex(prefix string, r rune, digits uint) {
+	const hex = "0123456789abcdef"
+	enc.buf.AppendString(prefix)
+	for shift := 4 * (digits - 1); ; shift -= 4 {
+		enc.buf.AppendByte(hex[r>>shift&0xF])
+		if shift == 0 {
+			return
+		}
+	}
+}
+
+func (enc *textEncoder) AppendTime(val time.Time) {
+	cur := enc.buf.Len()
+	if enc.EncodeTime != nil {
+		enc.EncodeTime(val, enc)
+	}
+	if cur == enc.buf.Len() {
+		enc.buf.AppendString(val.Format(time.RFC3339Nano))
+	}
+}
+
+func (enc *textEncoder) AppendUint64(val uint64) {
+	enc.buf.AppendUint(val)
+}
+
+func (enc *textEncoder) AddComplex64(k string, v complex64) { enc.AddComplex128(k, complex128(v)) }
+func (enc *textEncoder) AddFloat32(k string, v float32)     { enc.AddFloat64(k, float64(v)) }
+func (enc *textEncoder) AddInt(k string, v int)             { enc.AddInt64(k, int64(v)) }
+func (enc *textEncoder) AddInt32(k string, v int32)         { enc.AddInt64(k, int64(v)) }
+func (enc *textEncoder) AddInt16(k string, v int16)         { enc.AddInt64(k, int64(v)) }
+func (enc *textEncoder) AddInt8(k string, v int8)           { enc.AddInt64(k, int64(v)) }
+func (enc *textEncoder) AddUint(k string, v uint)           { enc.AddUint64(k, uint64(v)) }
+func (enc *textEncoder) AddUint32(k string, v uint32)       { enc.AddUint64(k, uint64(v)) }
+func (enc *textEncoder) AddUint16(k string, v uint16)       { enc.AddUint64(k, uint64(v)) }
+func (enc *textEncoder) AddUint8(k string, v uint8)         { enc.AddUint64(k, uint64(v)) }
+func (enc *textEncoder) AddUintptr(k string, v uintptr)     { enc.AddUint64(k, uint64(v)) }
+func (enc *textEncoder) AppendComplex64(v complex64)        { enc.AppendComplex128(complex128(v)) }
+func (enc *textEncoder) AppendFloat64(v float64)            { enc.buf.AppendFloat(v, 64) }
+func (enc *textEncoder) AppendFloat32(v float32)            { enc.buf.AppendFloat(float64(v), 32) }
+func (enc *textEncoder) AppendInt(v int)                    { enc.AppendInt64(int64(v)) }
+func (enc *textEncoder) AppendInt32(v int32)                { enc.AppendInt64(int64(v)) }
+func (enc *textEncoder) AppendInt16(v int16)                { enc.AppendInt64(int64(v)) }
+func (enc *textEncoder) AppendInt8(v int8)                  { enc.AppendInt64(int64(v)) }
+func (enc *textEncoder) AppendUint(v uint)                  { enc.AppendUint64(uint64(v)) }
+func (enc *textEncoder) AppendUint32(v uint32)              { enc.AppendUint64(uint64(v)) }
+func (enc *textEncoder) AppendUint16(v uint16)              { enc.AppendUint64(uint64(v)) }
+func (enc *textEncoder) AppendUint8(v uint8)                { enc.AppendUint64(uint64(v)) }
+func (enc *textEncoder) AppendUintptr(v uintptr)            { enc.AppendUint64(uint64(v)) }
+
+func (enc *textEncoder) Clone() Encoder {
+	clone := getTextEncoder(enc.EncoderConfig)
+	clone.namespace = append(clone.namespace, enc.namespace...)
+	clone.buf.Write(enc.buf.Bytes())
+	return clone
+}
+
+func (enc *textEncoder) EncodeEntry(ent Entry, fields []Field) (*buffer.Buffer, error) {
+	line := bufferpool.Get()
+	appendConsoleMetadata(enc.EncoderConfig, line, ent)
+
+	context := enc.Clone().(*textEncoder)
+	addFields(context, fields)
+	if context.buf.Len() > 0 {
+		if line.Len() > 0 {
+			line.AppendByte('\t')
+		}
+		line.Write(context.buf.Bytes())
+	}
+	putTextEncoder(context)
+
+	// As in the console encoder, a missing stacktrace key forces single-line
+	// output.
+	if ent.Stack != "" && enc.StacktraceKey != "" {
+		line.AppendByte('\n')
+		line.AppendString(ent.Stack)
+	}
+
+	if enc.LineEnding != "" {
+		line.AppendString(enc.LineEnding)
+	} else {
+		line.AppendString(DefaultLineEnding)
+	}
+	return line, nil
+}
+
+// needsTextQuotes reports whether a string would be ambiguous in a key=value
+// line without quotes.
+func needsTextQuotes(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '"' || r == '=' || r == utf8.RuneError || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsTextKeyQuotes reports whether a key, prefixed by the open namespaces,
+// would be ambiguous in a key=value line without quotes.
+func needsTextKeyQuotes(namespace []byte, key string) bool {
+	if len(namespace) > 0 && needsTextQuotesBytes(namespace) {
+		return true
+	}
+	if key == "" {
+		return len(namespace) == 0
+	}
+	return needsTextQuotes(key)
+}
+
+// needsTextQuotesBytes is needsTextQuotes for byte slices.
+func needsTextQuotesBytes(s []byte) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRune(s[i:])
+		if r <= ' ' || r == '"' || r == '=' || r == utf8.RuneError || !strconv.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	. "go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextEncoderEntry(t *testing.T) {
+	cfg := testEncoderConfig()
+	cfg.EncodeTime = ISO8601TimeEncoder
+	cfg.EncodeLevel = CapitalLevelEncoder
+	enc := NewTextEncoder(cfg)
+	enc.AddString("service", "api")
+
+	ent := Entry{
+		Level:      InfoLevel,
+		Time:       time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC),
+		LoggerName: "main",
+		Message:    "served request",
+		Stack:      "fake-stack",
+	}
+	buf, err := enc.EncodeEntry(ent, []Field{makeStringField("path", "/"), makeInt64Field("status", 200)})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		"2016-10-01T12:00:00.000Z\tINFO\tmain\tserved request\tservice=api path=/ status=200\nfake-stack\n",
+		buf.String(),
+		"Unexpected encoded entry.",
+	)
+	buf.Free()
+
+	// Encoding an entry shouldn't change the encoder's context.
+	buf, err = enc.EncodeEntry(Entry{Message: "again"}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "0001-01-01T00:00:00.000Z\tINFO\tagain\tservice=api\n", buf.String(), "Unexpected encoded entry.")
+	buf.Free()
+}
+
+func TestTextEncoderOmitsEmptyKeys(t *testing.T) {
+	enc := NewTextEncoder(EncoderConfig{LineEnding: "\r\n"})
+	buf, err := enc.EncodeEntry(Entry{Message: "invisible", Stack: "fake-stack"}, []Field{makeInt64Field("k", 1)})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "k=1\r\n", buf.String(), "Expected only fields without configured keys.")
+	buf.Free()
+}
+
+func TestTextEncoderFields(t *testing.T) {
+	ts := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		desc     string
+		cfg      EncoderConfig
+		f        func(Encoder)
+		expected string
+	}{
+		{
+			desc:     "plain string",
+			f:        func(e Encoder) { e.AddString("k", "hello") },
+			expected: "k=hello",
+		},
+		{
+			desc:     "string with spaces",
+			f:        func(e Encoder) { e.AddString("k", "hello world") },
+			expected: `k="hello world"`,
+		},
+		{
+			desc:     "empty string",
+			f:        func(e Encoder) { e.AddString("k", "") },
+			expected: `k=""`,
+		},
+		{
+			desc:     "string with quotes and equals signs",
+			f:        func(e Encoder) { e.AddString("k", `a="b"`) },
+			expected: `k="a=\"b\""`,
+		},
+		{
+			desc:     "string with control characters",
+			f:        func(e Encoder) { e.AddString("k", "a\nb") },
+			expected: `k="a\nb"`,
+		},
+		{
+			desc:     "unicode string",
+			f:        func(e Encoder) { e.AddString("k", "héllo") },
+			expected: "k=héllo",
+		},
+		{
+			desc:     "invalid UTF-8",
+			f:        func(e Encoder) { e.AddByteString("k", []byte("\xff")) },
+			expected: `k="\xff"`,
+		},
+		{
+			desc:     "binary",
+			f:        func(e Encoder) { e.AddBinary("k", []byte("foo")) },
+			expected: "k=Zm9v",
+		},
+		{
+			desc: "numbers and bools",
+			f: func(e Encoder) {
+				e.AddInt("i", -1)
+				e.AddUint8("u", 2)
+				e.AddFloat64("f", 1.5)
+				e.AddFloat32("f32", 0.1)
+				e.AddComplex128("c", 1-2i)
+				e.AddBool("b", true)
+			},
+			expected: "i=-1 u=2 f=1.5 f32=0.10000000149011612 c=1-2i b=true",
+		},
+		{
+			desc:     "duration with configured encoder",
+			cfg:      EncoderConfig{EncodeDuration: SecondsDurationEncoder},
+			f:        func(e Encoder) { e.AddDuration("d", 1500*time.Millisecond) },
+			expected: "d=1.5",
+		},
+		{
+			desc:     "duration without encoder",
+			f:        func(e Encoder) { e.AddDuration("d", 1500*time.Microsecond) },
+			expected: "d=1.5ms",
+		},
+		{
+			desc:     "time with configured encoder",
+			cfg:      EncoderConfig{EncodeTime: EpochMillisTimeEncoder},
+			f:        func(e Encoder) { e.AddTime("t", ts) },
+			expected: "t=1475323200000",
+		},
+		{
+			desc:     "time without encoder",
+			f:        func(e Encoder) { e.AddTime("t", ts) },
+			expected: "t=2016-10-01T12:00:00Z",
+		},
+		{
+			desc: "array",
+			f: func(e Encoder) {
+				assert.NoError(t, e.AddArray("a", ArrayMarshalerFunc(func(arr ArrayEncoder) error {
+					arr.AppendInt(1)
+					arr.AppendString("two")
+					return nil
+				})))
+			},
+			expected: `a=[1,"two"]`,
+		},
+		{
+			desc: "reflected",
+			f: func(e Encoder) {
+				assert.NoError(t, e.AddReflected("r", map[string]int{"x": 1}))
+			},
+			expected: `r={"x":1}`,
+		},
+		{
+			desc: "nested object",
+			f: func(e Encoder) {
+				assert.NoError(t, e.AddObject("req", ObjectMarshalerFunc(func(obj ObjectEncoder) error {
+					obj.AddString("method", "GET")
+					return obj.AddObject("url", ObjectMarshalerFunc(func(obj ObjectEncoder) error {
+						obj.AddString("path", "/")
+						return nil
+					}))
+				})))
+				e.AddString("after", "x")
+			},
+			expected: "req.method=GET req.url.path=/ after=x",
+		},
+		{
+			desc: "namespace",
+			f: func(e Encoder) {
+				e.AddString("outer", "x")
+				e.OpenNamespace("ns")
+				e.AddString("inner", "y")
+			},
+			expected: "outer=x ns.inner=y",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tt.cfg.LineEnding = "\n"
+			enc := NewTextEncoder(tt.cfg)
+			tt.f(enc)
+			buf, err := enc.EncodeEntry(Entry{}, nil)
+			require.NoError(t, err, "Unexpected error encoding entry.")
+			assert.Equal(t, tt.expected+"\n", buf.String(), "Unexpected encoded fields.")
+			buf.Free()
+		})
+	}
+}
+
+func TestTextEncoderClone(t *testing.T) {
+	enc := NewTextEncoder(EncoderConfig{})
+	enc.OpenNamespace("ns")
+	enc.AddString("a", "1")
+
+	clone := enc.Clone()
+	clone.AddString("b", "2")
+	enc.AddString("c", "3")
+
+	buf, err := clone.EncodeEntry(Entry{}, []Field{makeStringField("d", "4")})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "ns.a=1 ns.b=2 ns.d=4\n", buf.String(), "Unexpected output from clone.")
+	buf.Free()
+
+	buf, err = enc.EncodeEntry(Entry{}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "ns.a=1 ns.c=3\n", buf.String(), "Clone shouldn't affect the original encoder.")
+	buf.Free()
+}
+
+func TestTextEncoderMarshalerErrors(t *testing.T) {
+	enc := NewTextEncoder(EncoderConfig{})
+	err := enc.AddObject("obj", ObjectMarshalerFunc(func(ObjectEncoder) error {
+		return errors.New("fail")
+	}))
+	assert.Error(t, err, "Expected object marshaling errors to propagate.")
+	enc.AddString("after", "x")
+
+	buf, err := enc.EncodeEntry(Entry{}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, "after=x\n", buf.String(), "Expected a failed object to leave its namespace.")
+	buf.Free()
+}
+
+func TestTextEncoderQuoting(t *testing.T) {
+	// Quoted strings and byte strings should match strconv.Quote.
+	inputs := []string{
+		"a b",
+		`back\slash "quotes"`,
+		"\a\b\f\n\r\t\v",
+		"\x00\x1f\x7f",
+		"\xff\xfe",
+		"   ",
+		"\U000e0001",
+		"�=",
+		"héllo wörld 😀",
+	}
+	for _, s := range inputs {
+		enc := NewTextEncoder(EncoderConfig{})
+		enc.AddString("s", s)
+		enc.AddByteString("b", []byte(s))
+		buf, err := enc.EncodeEntry(Entry{}, nil)
+		require.NoError(t, err, "Unexpected error encoding entry.")
+		q := strconv.Quote(s)
+		assert.Equal(t, "s="+q+" b="+q+"\n", buf.String(), "Unexpected quoting of %q.", s)
+		buf.Free()
+	}
+}
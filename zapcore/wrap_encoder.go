@@ -0,0 +1,110 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore
+
+// WrapEncoder returns a copy of core whose entries are encoded by
+// wrap(enc), where enc is the Encoder the core was built with. It's how
+// options that change the framing of every entry, like zap.BulkHeader, reach
+// the Encoder of an existing Core.
+//
+// WrapEncoder understands the Cores built by NewCore, NewSplitCore, and
+// NewMirrorCore, tees of them (see NewTee), and the samplers, reserved-key
+// guards, field hooks, and deduplicating Cores that zap.Config wraps them in. Since other
+// Cores don't expose their Encoders, they're returned unchanged.
+func WrapEncoder(core Core, wrap func(Encoder) Encoder) Core {
+	return wrapEncoder(core, func(enc Encoder, _ WriteSyncer) Encoder {
+		return wrap(enc)
+	})
+}
+
+// WrapTerminalEncoder is like WrapEncoder, but it only wraps the Encoders of
+// Cores that write to a terminal (see IsTerminal). It suits wrappers that
+// add escape codes, like NewColorEncoder, which are noise anywhere else.
+func WrapTerminalEncoder(core Core, wrap func(Encoder) Encoder) Core {
+	return wrapEncoder(core, func(enc Encoder, out WriteSyncer) Encoder {
+		if !IsTerminal(out) {
+			return enc
+		}
+		return wrap(enc)
+	})
+}
+
+// WrapHumanEncoder is like WrapEncoder, but it only wraps the Encoders built
+// by NewConsoleEncoder and NewTextEncoder, whose output is designed for
+// humans rather than machines. It suits wrappers that change the content of
+// entries for readability, like NewMessagePrefixEncoder.
+func WrapHumanEncoder(core Core, wrap func(Encoder) Encoder) Core {
+	return wrapEncoder(core, func(enc Encoder, _ WriteSyncer) Encoder {
+		switch enc.(type) {
+		case consoleEncoder, *textEncoder:
+			return wrap(enc)
+		default:
+			return enc
+		}
+	})
+}
+
+func wrapEncoder(core Core, wrap func(Encoder, WriteSyncer) Encoder) Core {
+	switch c := core.(type) {
+	case *ioCore:
+		return wrapIOCore(c, wrap)
+	case *syncingCore:
+		return &syncingCore{wrapIOCore(c.ioCore, wrap)}
+	case *splitCore:
+		return &splitCore{
+			low:   wrapEncoder(c.low, wrap),
+			high:  wrapEncoder(c.high, wrap),
+			split: c.split,
+		}
+	case multiCore:
+		wrapped := make(multiCore, len(c))
+		for i := range c {
+			wrapped[i] = wrapEncoder(c[i], wrap)
+		}
+		return wrapped
+	case *sampler:
+		wrapped := *c
+		wrapped.Core = wrapEncoder(c.Core, wrap)
+		return &wrapped
+	case *reservedKeyCore:
+		wrapped := *c
+		wrapped.Core = wrapEncoder(c.Core, wrap)
+		return &wrapped
+	case *dedupCore:
+		wrapped := *c
+		wrapped.Core = wrapEncoder(c.Core, wrap)
+		return &wrapped
+	case *fieldHooked:
+		wrapped := *c
+		wrapped.Core = wrapEncoder(c.Core, wrap)
+		return &wrapped
+	default:
+		return core
+	}
+}
+
+func wrapIOCore(c *ioCore, wrap func(Encoder, WriteSyncer) Encoder) *ioCore {
+	return &ioCore{
+		LevelEnabler: c.LevelEnabler,
+		enc:          wrap(c.enc.Clone(), c.out),
+		out:          c.out,
+	}
+}
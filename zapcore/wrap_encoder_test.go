@@ -0,0 +1,56 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapcore_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapEncoder(t *testing.T) {
+	prefix := func(enc Encoder) Encoder {
+		return NewHeaderEncoder(enc, func(Entry) []byte { return []byte("#") })
+	}
+	newEnc := func() Encoder { return NewJSONEncoder(EncoderConfig{MessageKey: "msg"}) }
+
+	var low, high, mirror bytes.Buffer
+	core := NewTee(
+		NewSplitCore(newEnc(), AddSync(&low), AddSync(&high), ErrorLevel, DebugLevel),
+		NewMirrorCore(NewNopCore(), newEnc(), AddSync(&mirror), ErrorLevel),
+	)
+	wrapped := WrapEncoder(core.With([]Field{makeInt64Field("k", 1)}), prefix)
+	for _, ent := range []Entry{{Level: InfoLevel, Message: "info"}, {Level: ErrorLevel, Message: "error"}} {
+		if ce := wrapped.Check(ent, nil); ce != nil {
+			ce.Write()
+		}
+	}
+	assert.Equal(t, "#\n"+`{"msg":"info","k":1}`+"\n", low.String(), "Unexpected low-severity output.")
+	assert.Equal(t, "#\n"+`{"msg":"error","k":1}`+"\n", high.String(), "Unexpected high-severity output.")
+	assert.Equal(t, "#\n"+`{"msg":"error","k":1}`+"\n", mirror.String(), "Unexpected mirrored output.")
+
+	obs, _ := observer.New(DebugLevel)
+	assert.Equal(t, obs, WrapEncoder(obs, prefix), "Expected unknown Cores to be returned unchanged.")
+}
@@ -62,17 +62,17 @@ func Lock(ws WriteSyncer) WriteSyncer {
 }
 
 func (s *lockedWriteSyncer) Write(bs []byte) (int, error) {
+	// Unlock even if the wrapped WriteSyncer panics, since CheckedEntry
+	// recovers from panicking writes and the next write would deadlock.
 	s.Lock()
-	n, err := s.ws.Write(bs)
-	s.Unlock()
-	return n, err
+	defer s.Unlock()
+	return s.ws.Write(bs)
 }
 
 func (s *lockedWriteSyncer) Sync() error {
 	s.Lock()
-	err := s.ws.Sync()
-	s.Unlock()
-	return err
+	defer s.Unlock()
+	return s.ws.Sync()
 }
 
 type writerWrapper struct {
@@ -86,7 +86,10 @@ func (w writerWrapper) Sync() error {
 type multiWriteSyncer []WriteSyncer
 
 // NewMultiWriteSyncer creates a WriteSyncer that duplicates its writes
-// and sync calls, much like io.MultiWriter.
+// and sync calls, much like io.MultiWriter. Unlike io.MultiWriter, it doesn't
+// stop at the first failure: every write and sync is attempted on all the
+// underlying WriteSyncers, and any errors are combined (see
+// go.uber.org/multierr).
 func NewMultiWriteSyncer(ws ...WriteSyncer) WriteSyncer {
 	if len(ws) == 1 {
 		return ws[0]
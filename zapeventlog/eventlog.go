@@ -0,0 +1,157 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapeventlog provides a Core that writes entries to the Windows
+// Event Log, so that Windows services show up natively in the Event Viewer.
+// Each entry becomes an event whose type (error, warning, or information)
+// follows its level and whose description is the entry as rendered by an
+// Encoder; a JSON encoder keeps the fields structured:
+//
+//   if err := zapeventlog.InstallSource("myservice"); err != nil {
+//     // Usually means the installer isn't running as an administrator.
+//   }
+//   enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+//   core, err := zapeventlog.NewCore("myservice", enc, zapcore.InfoLevel)
+//
+// The Event Log already records each event's time and type, so there's
+// rarely any need to encode the entry's time or level as well.
+//
+// The Event Log is only available on Windows; elsewhere, NewCore,
+// InstallSource, and RemoveSource return an error.
+package zapeventlog // import "go.uber.org/zap/zapeventlog"
+
+import (
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// An EventType is the type of an Event Log event, which the Event Viewer
+// displays as its level.
+type EventType uint16
+
+// The event types used by DefaultEventType. Their values match the Windows
+// API's EVENTLOG_*_TYPE constants.
+const (
+	Error       EventType = 0x0001
+	Warning     EventType = 0x0002
+	Information EventType = 0x0004
+)
+
+// DefaultEventType maps zap's levels to event types: warnings become Warning
+// events, errors and more severe levels become Error events, and the rest
+// become Information events.
+func DefaultEventType(lvl zapcore.Level) EventType {
+	switch {
+	case lvl >= zapcore.ErrorLevel:
+		return Error
+	case lvl == zapcore.WarnLevel:
+		return Warning
+	default:
+		return Information
+	}
+}
+
+// A reporter delivers an event to the Event Log.
+type reporter interface {
+	report(typ EventType, id uint32, msg string) error
+}
+
+// An Option configures an Event Log Core.
+type Option interface {
+	apply(*core)
+}
+
+type optionFunc func(*core)
+
+func (f optionFunc) apply(c *core) {
+	f(c)
+}
+
+// WithEventID sets the event ID of every event. It defaults to 1. Sources
+// registered with InstallSource accept IDs from 1 to 1000.
+func WithEventID(id uint32) Option {
+	return optionFunc(func(c *core) {
+		c.eventID = id
+	})
+}
+
+// WithEventTypes replaces DefaultEventType as the mapping from levels to
+// event types.
+func WithEventTypes(f func(zapcore.Level) EventType) Option {
+	return optionFunc(func(c *core) {
+		c.eventType = f
+	})
+}
+
+type core struct {
+	zapcore.LevelEnabler
+	enc       zapcore.Encoder
+	out       reporter
+	eventID   uint32
+	eventType func(zapcore.Level) EventType
+}
+
+func newCore(enc zapcore.Encoder, enab zapcore.LevelEnabler, out reporter, opts ...Option) zapcore.Core {
+	c := &core{
+		LevelEnabler: enab,
+		enc:          enc,
+		out:          out,
+		eventID:      1,
+		eventType:    DefaultEventType,
+	}
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+	return c
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write reports the entry as an event. Its description is the encoded entry,
+// less the trailing line ending.
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimRight(buf.String(), "\r\n")
+	buf.Free()
+	return c.out.report(c.eventType(ent.Level), c.eventID, msg)
+}
+
+// Sync is a no-op, since events are reported as they're written.
+func (c *core) Sync() error {
+	return nil
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build !windows
+// +build !windows
+
+package zapeventlog
+
+import (
+	"errors"
+
+	"go.uber.org/zap/zapcore"
+)
+
+var errUnsupported = errors.New("the Windows Event Log is only available on Windows")
+
+// NewCore creates a Core that reports entries as events from the named
+// source. On this platform, it always returns an error.
+func NewCore(source string, enc zapcore.Encoder, enab zapcore.LevelEnabler, opts ...Option) (zapcore.Core, error) {
+	return nil, errUnsupported
+}
+
+// InstallSource registers an event source. On this platform, it always
+// returns an error.
+func InstallSource(source string) error {
+	return errUnsupported
+}
+
+// RemoveSource removes an event source's registration. On this platform, it
+// always returns an error.
+func RemoveSource(source string) error {
+	return errUnsupported
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapeventlog
+
+import (
+	"errors"
+	"runtime"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type event struct {
+	typ EventType
+	id  uint32
+	msg string
+}
+
+type fakeReporter struct {
+	events []event
+	err    error
+}
+
+func (r *fakeReporter) report(typ EventType, id uint32, msg string) error {
+	r.events = append(r.events, event{typ, id, msg})
+	return r.err
+}
+
+func newTestEncoder() zapcore.Encoder {
+	return zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+}
+
+func TestCoreWrite(t *testing.T) {
+	out := &fakeReporter{}
+	logger := zap.New(newCore(newTestEncoder(), zapcore.InfoLevel, out, WithEventID(42))).With(zap.String("service", "api"))
+
+	logger.Debug("disabled")
+	logger.Info("started", zap.Int("port", 8080))
+	logger.Warn("slow")
+	logger.Error("failed")
+
+	assert.Equal(t, []event{
+		{Information, 42, `{"msg":"started","service":"api","port":8080}`},
+		{Warning, 42, `{"msg":"slow","service":"api"}`},
+		{Error, 42, `{"msg":"failed","service":"api"}`},
+	}, out.events, "Unexpected events.")
+	assert.NoError(t, logger.Sync(), "Unexpected error syncing.")
+}
+
+func TestCoreEventTypes(t *testing.T) {
+	out := &fakeReporter{}
+	core := newCore(newTestEncoder(), zapcore.DebugLevel, out, WithEventTypes(func(zapcore.Level) EventType {
+		return Warning
+	}))
+	require.NoError(t, core.Write(zapcore.Entry{Level: zapcore.DebugLevel, Message: "m"}, nil), "Unexpected error writing.")
+	require.Equal(t, 1, len(out.events), "Expected one event.")
+	assert.Equal(t, Warning, out.events[0].typ, "Expected the custom mapping to be used.")
+	assert.Equal(t, uint32(1), out.events[0].id, "Unexpected default event ID.")
+}
+
+func TestCoreWriteError(t *testing.T) {
+	errReport := errors.New("fail")
+	core := newCore(newTestEncoder(), zapcore.DebugLevel, &fakeReporter{err: errReport})
+	assert.Equal(t, errReport, core.Write(zapcore.Entry{}, nil), "Expected reporting errors to be returned.")
+}
+
+func TestDefaultEventType(t *testing.T) {
+	tests := []struct {
+		lvl  zapcore.Level
+		want EventType
+	}{
+		{zapcore.DebugLevel, Information},
+		{zapcore.InfoLevel, Information},
+		{zapcore.WarnLevel, Warning},
+		{zapcore.ErrorLevel, Error},
+		{zapcore.DPanicLevel, Error},
+		{zapcore.FatalLevel, Error},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DefaultEventType(tt.lvl), "Unexpected event type for %v.", tt.lvl)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("The Event Log is available on Windows.")
+	}
+	_, err := NewCore("test", newTestEncoder(), zapcore.InfoLevel)
+	assert.Error(t, err, "Expected an error creating a Core.")
+	assert.Error(t, InstallSource("test"), "Expected an error installing a source.")
+	assert.Error(t, RemoveSource("test"), "Expected an error removing a source.")
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build windows
+// +build windows
+
+package zapeventlog
+
+import (
+	"os"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// _maxMessageLen is the longest string, in UTF-16 code units, that
+	// ReportEvent accepts.
+	_maxMessageLen = 31839
+	// _sourcesKey is where event sources for the Application log are
+	// registered.
+	_sourcesKey = `SYSTEM\CurrentControlSet\Services\EventLog\Application\`
+	// _messageFile is the message file that InstallSource registers. Its
+	// messages for event IDs 1 through 1000 display the event's string as
+	// is.
+	_messageFile = `%SystemRoot%\System32\EventCreate.exe`
+
+	_regExpandSZ = 2
+	_regDWORD    = 4
+)
+
+var (
+	_advapi32 = syscall.NewLazyDLL("advapi32.dll")
+
+	_procRegisterEventSource = _advapi32.NewProc("RegisterEventSourceW")
+	_procReportEvent         = _advapi32.NewProc("ReportEventW")
+	_procRegCreateKeyEx      = _advapi32.NewProc("RegCreateKeyExW")
+	_procRegSetValueEx       = _advapi32.NewProc("RegSetValueExW")
+	_procRegDeleteKey        = _advapi32.NewProc("RegDeleteKeyW")
+)
+
+// NewCore creates a Core that reports entries as events from the named
+// source, which should be registered with InstallSource first; otherwise,
+// the Event Viewer shows a warning about the missing message file alongside
+// each event. The source's handle stays open for the life of the process.
+func NewCore(source string, enc zapcore.Encoder, enab zapcore.LevelEnabler, opts ...Option) (zapcore.Core, error) {
+	name, err := syscall.UTF16PtrFromString(source)
+	if err != nil {
+		return nil, err
+	}
+	h, _, err := _procRegisterEventSource.Call(0, uintptr(unsafe.Pointer(name)))
+	if h == 0 {
+		return nil, os.NewSyscallError("RegisterEventSource", err)
+	}
+	return newCore(enc, enab, eventSource(h), opts...), nil
+}
+
+// InstallSource registers an event source in the Application log, using a
+// message file that ships with Windows, so that its events' descriptions
+// display correctly in the Event Viewer. It's usually called once, by an
+// installer running as an administrator. Registering a source that's
+// already registered updates its registration.
+func InstallSource(source string) error {
+	key, err := createKey(_sourcesKey + source)
+	if err != nil {
+		return err
+	}
+	defer syscall.RegCloseKey(key)
+
+	if err := setString(key, "EventMessageFile", _messageFile); err != nil {
+		return err
+	}
+	if err := setDWORD(key, "TypesSupported", uint32(Error|Warning|Information)); err != nil {
+		return err
+	}
+	return setDWORD(key, "CustomSource", 1)
+}
+
+// RemoveSource removes an event source's registration, as an uninstaller
+// would. Events it already reported are kept.
+func RemoveSource(source string) error {
+	name, err := syscall.UTF16PtrFromString(_sourcesKey + source)
+	if err != nil {
+		return err
+	}
+	r, _, _ := _procRegDeleteKey.Call(uintptr(syscall.HKEY_LOCAL_MACHINE), uintptr(unsafe.Pointer(name)))
+	if r != 0 {
+		return os.NewSyscallError("RegDeleteKey", syscall.Errno(r))
+	}
+	return nil
+}
+
+// An eventSource is a handle returned by RegisterEventSource.
+type eventSource syscall.Handle
+
+func (s eventSource) report(typ EventType, id uint32, msg string) error {
+	str := utf16.Encode([]rune(msg))
+	if len(str) > _maxMessageLen {
+		str = str[:_maxMessageLen]
+	}
+	for i := range str {
+		if str[i] == 0 {
+			// A NUL would end the string early.
+			str[i] = ' '
+		}
+	}
+	str = append(str, 0)
+	strs := []*uint16{&str[0]}
+	r, _, err := _procReportEvent.Call(
+		uintptr(s),
+		uintptr(typ),
+		0, // category
+		uintptr(id),
+		0, // user SID
+		uintptr(len(strs)),
+		0, // raw data size
+		uintptr(unsafe.Pointer(&strs[0])),
+		0, // raw data
+	)
+	if r == 0 {
+		return os.NewSyscallError("ReportEvent", err)
+	}
+	return nil
+}
+
+func createKey(path string) (syscall.Handle, error) {
+	name, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var key syscall.Handle
+	r, _, _ := _procRegCreateKeyEx.Call(
+		uintptr(syscall.HKEY_LOCAL_MACHINE),
+		uintptr(unsafe.Pointer(name)),
+		0, // reserved
+		0, // class
+		0, // options
+		uintptr(syscall.KEY_WRITE),
+		0, // security attributes
+		uintptr(unsafe.Pointer(&key)),
+		0, // disposition
+	)
+	if r != 0 {
+		return 0, os.NewSyscallError("RegCreateKeyEx", syscall.Errno(r))
+	}
+	return key, nil
+}
+
+func setString(key syscall.Handle, name, value string) error {
+	data, err := syscall.UTF16FromString(value)
+	if err != nil {
+		return err
+	}
+	return setValue(key, name, _regExpandSZ, (*byte)(unsafe.Pointer(&data[0])), len(data)*2)
+}
+
+func setDWORD(key syscall.Handle, name string, value uint32) error {
+	return setValue(key, name, _regDWORD, (*byte)(unsafe.Pointer(&value)), 4)
+}
+
+func setValue(key syscall.Handle, name string, typ uint32, data *byte, size int) error {
+	n, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	r, _, _ := _procRegSetValueEx.Call(
+		uintptr(key),
+		uintptr(unsafe.Pointer(n)),
+		0, // reserved
+		uintptr(typ),
+		uintptr(unsafe.Pointer(data)),
+		uintptr(size),
+	)
+	if r != 0 {
+		return os.NewSyscallError("RegSetValueEx", syscall.Errno(r))
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapfluent sends zap's output to Fluentd and Fluent Bit using the
+// Fluentd Forward protocol, so logs can be shipped straight to an aggregator
+// without going through a file. Entries must be encoded as MessagePack maps,
+// which zapcore.NewMsgpackEncoder produces:
+//
+//   sink, err := zapfluent.Dial("fluentd:24224", "app.access", zapfluent.RequireAck())
+//   if err != nil {
+//     return err
+//   }
+//   defer sink.Close()
+//   enc := zapcore.NewMsgpackEncoder(zapcore.EncoderConfig{MessageKey: "msg", LevelKey: "level"})
+//   core := zapcore.NewCore(enc, sink, zap.InfoLevel)
+//
+// Since the protocol carries each entry's time separately, an EncoderConfig
+// with an empty TimeKey avoids sending it twice.
+package zapfluent // import "go.uber.org/zap/zapfluent"
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	_defaultTimeout    = 5 * time.Second
+	_defaultSpoolBytes = 1024 * 1024
+	_defaultBackoff    = 100 * time.Millisecond
+	_defaultMaxBackoff = 30 * time.Second
+)
+
+// An Option configures a Sink returned by Dial.
+type Option interface {
+	apply(*sink)
+}
+
+type optionFunc func(*sink)
+
+func (f optionFunc) apply(s *sink) {
+	f(s)
+}
+
+// RequireAck asks the aggregator to acknowledge each chunk of entries, as
+// described by the protocol's at-least-once delivery mode. A chunk that isn't
+// acknowledged within the write timeout is resent over a new connection; the
+// aggregator uses the chunk's ID to discard duplicates.
+func RequireAck() Option {
+	return optionFunc(func(s *sink) {
+		s.ack = true
+	})
+}
+
+// BatchSize makes the sink collect entries until they take up at least n
+// bytes, then send them as a single chunk. Sync sends any partial batch. By
+// default, each entry is sent as soon as it's written.
+func BatchSize(n int) Option {
+	return optionFunc(func(s *sink) {
+		s.batchSize = n
+	})
+}
+
+// DialTimeout limits how long each attempt to connect may take. It defaults
+// to 5 seconds; a timeout of zero or less disables it.
+func DialTimeout(timeout time.Duration) Option {
+	return optionFunc(func(s *sink) {
+		s.dialTimeout = timeout
+	})
+}
+
+// WriteTimeout limits how long sending each chunk, and waiting for its
+// acknowledgement, may take before the connection is considered broken. It
+// defaults to 5 seconds; a timeout of zero or less disables it.
+func WriteTimeout(timeout time.Duration) Option {
+	return optionFunc(func(s *sink) {
+		s.writeTimeout = timeout
+	})
+}
+
+// SpoolSize sets how many bytes of chunks are kept in memory while they can't
+// be delivered. It defaults to 1 MB.
+func SpoolSize(bytes int) Option {
+	return optionFunc(func(s *sink) {
+		s.maxSpool = bytes
+	})
+}
+
+// RetryBackoff sets how long to wait between attempts to reconnect. After n
+// consecutive failures, the next attempt is made no sooner than
+// zap.BackoffDelay(n-1, base, max). It defaults to a base of 100 milliseconds
+// and a max of 30 seconds.
+func RetryBackoff(base, max time.Duration) Option {
+	return optionFunc(func(s *sink) {
+		s.backoffBase = base
+		s.backoffMax = max
+	})
+}
+
+// Dial returns a Sink that sends entries to the Fluentd Forward input at addr
+// over TCP, tagged with tag. Each write must be a single MessagePack map,
+// which is how Cores use a zapcore.NewMsgpackEncoder; it's sent as a record
+// stamped with the time of the write.
+//
+// Like zap.NetworkSink, the sink reconnects whenever the connection breaks.
+// Chunks that can't be delivered are kept in a bounded in-memory spool and
+// resent, in order, on later writes and syncs, backing off after each failed
+// attempt to connect. When the spool is full, the oldest chunks are dropped
+// and Write reports an error; otherwise, spooled writes succeed. Sync reports
+// an error if the spool can't be emptied, and Close makes a last attempt to
+// send it.
+//
+// Since the first connection is established the same way, Dial doesn't fail
+// if the address is unreachable. It's safe for concurrent use.
+func Dial(addr, tag string, opts ...Option) (zap.Sink, error) {
+	if tag == "" {
+		return nil, errors.New("zapfluent: tag must not be empty")
+	}
+	s := &sink{
+		addr:         addr,
+		tag:          tag,
+		dialTimeout:  _defaultTimeout,
+		writeTimeout: _defaultTimeout,
+		maxSpool:     _defaultSpoolBytes,
+		backoffBase:  _defaultBackoff,
+		backoffMax:   _defaultMaxBackoff,
+		dial:         net.DialTimeout,
+		now:          time.Now,
+		newChunkID:   newChunkID,
+	}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+	s.connect()
+	return s, nil
+}
+
+// A chunk is a complete Forward-mode message, ready to send.
+type chunk struct {
+	msg []byte
+	id  string // empty unless acknowledgements are required
+}
+
+type sink struct {
+	mu           sync.Mutex
+	addr         string
+	tag          string
+	ack          bool
+	batchSize    int
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+	maxSpool     int
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	dial         func(network, addr string, timeout time.Duration) (net.Conn, error)
+	now          func() time.Time
+	newChunkID   func() string
+
+	conn     net.Conn // nil while disconnected
+	failures int      // consecutive failed attempts to connect
+	nextDial time.Time
+	dialErr  error
+	batch    []byte // encoded [time, record] entries
+	batchN   int
+	spool    []chunk
+	spooled  int // bytes in spool
+	closed   bool
+}
+
+func (s *sink) Write(p []byte) (int, error) {
+	if !isMsgpackMap(p) {
+		return 0, errors.New("zapfluent: each write must be a single MessagePack map")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, os.ErrClosed
+	}
+	s.batch = appendEntry(s.batch, s.now(), p)
+	s.batchN++
+	if len(s.batch) < s.batchSize {
+		return len(p), nil
+	}
+	err := s.seal()
+	// Entries that can't be sent yet stay spooled, so the write succeeds.
+	s.flush()
+	return len(p), err
+}
+
+func (s *sink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.seal()
+	if len(s.spool) == 0 {
+		return err
+	}
+	if ferr := s.flush(); ferr != nil {
+		err = multierr.Append(err, fmt.Errorf("couldn't send %d spooled chunks to %s: %v", len(s.spool), s.addr, ferr))
+	}
+	return err
+}
+
+// Close makes a last attempt to send any batched and spooled entries, then
+// closes the connection.
+func (s *sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	err := s.seal()
+	if len(s.spool) > 0 {
+		if ferr := s.flush(); ferr != nil {
+			err = multierr.Append(err, fmt.Errorf("dropped %d spooled chunks to %s: %v", len(s.spool), s.addr, ferr))
+		}
+		s.spool, s.spooled = nil, 0
+	}
+	if s.conn != nil {
+		err = multierr.Append(err, s.conn.Close())
+		s.conn = nil
+	}
+	return err
+}
+
+// seal turns the current batch, if any, into a chunk and adds it to the
+// spool. It must be called with the lock held.
+func (s *sink) seal() error {
+	if s.batchN == 0 {
+		return nil
+	}
+	c := chunk{}
+	if s.ack {
+		c.id = s.newChunkID()
+	}
+	c.msg = encodeMessage(s.tag, s.batch, s.batchN, c.id)
+	s.batch, s.batchN = s.batch[:0], 0
+	return s.enqueue(c)
+}
+
+// connect establishes a connection if there isn't one, unless the last
+// attempt failed too recently. It must be called with the lock held.
+func (s *sink) connect() error {
+	if s.conn != nil {
+		return nil
+	}
+	now := s.now()
+	if now.Before(s.nextDial) {
+		return s.dialErr
+	}
+	conn, err := s.dial("tcp", s.addr, s.dialTimeout)
+	if err != nil {
+		s.failures++
+		s.nextDial = now.Add(zap.BackoffDelay(s.failures-1, s.backoffBase, s.backoffMax))
+		s.dialErr = err
+		return err
+	}
+	s.conn = conn
+	s.failures = 0
+	s.dialErr = nil
+	return nil
+}
+
+// flush connects if necessary and sends the spooled chunks, oldest first. It
+// must be called with the lock held.
+func (s *sink) flush() error {
+	for len(s.spool) > 0 {
+		if err := s.connect(); err != nil {
+			return err
+		}
+		if err := s.send(s.spool[0]); err != nil {
+			return err
+		}
+		s.spooled -= len(s.spool[0].msg)
+		s.spool[0] = chunk{}
+		s.spool = s.spool[1:]
+	}
+	return nil
+}
+
+// send writes a chunk to the current connection and waits for its
+// acknowledgement, if required. It drops the connection if either fails. It
+// must be called with the lock held.
+func (s *sink) send(c chunk) error {
+	if s.writeTimeout > 0 {
+		s.conn.SetDeadline(time.Now().Add(s.writeTimeout))
+	}
+	_, err := s.conn.Write(c.msg)
+	if err == nil && c.id != "" {
+		err = readAck(s.conn, c.id)
+	}
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+// enqueue adds a chunk to the spool, dropping the oldest chunks to make room.
+// It must be called with the lock held.
+func (s *sink) enqueue(c chunk) error {
+	if len(c.msg) > s.maxSpool {
+		return fmt.Errorf("dropped a %d-byte chunk to %s, which is larger than the spool", len(c.msg), s.addr)
+	}
+	var dropped int
+	for s.spooled+len(c.msg) > s.maxSpool {
+		s.spooled -= len(s.spool[0].msg)
+		s.spool[0] = chunk{}
+		s.spool = s.spool[1:]
+		dropped++
+	}
+	s.spool = append(s.spool, c)
+	s.spooled += len(c.msg)
+	if dropped > 0 {
+		return fmt.Errorf("spool of chunks to %s is full, dropped %d chunks", s.addr, dropped)
+	}
+	return nil
+}
+
+// newChunkID returns a random chunk ID, base64-encoded as the protocol
+// recommends.
+func newChunkID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		// The system's source of randomness is broken, which is exceedingly
+		// rare; the current time is the next best thing.
+		binary.BigEndian.PutUint64(id[:], uint64(time.Now().UnixNano()))
+	}
+	return base64.StdEncoding.EncodeToString(id[:])
+}
+
+// readAck reads the aggregator's response to a chunk, which must be the map
+// {"ack": id}.
+func readAck(r io.Reader, id string) error {
+	var head [5]byte // fixmap header and the fixstr "ack"
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return fmt.Errorf("couldn't read acknowledgement: %v", err)
+	}
+	if head != [5]byte{0x81, 0xa3, 'a', 'c', 'k'} {
+		return fmt.Errorf("unexpected acknowledgement %x", head)
+	}
+	got, err := readString(r)
+	if err != nil {
+		return fmt.Errorf("couldn't read acknowledgement: %v", err)
+	}
+	if got != id {
+		return fmt.Errorf("got acknowledgement for chunk %q, expected %q", got, id)
+	}
+	return nil
+}
+
+func readString(r io.Reader) (string, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return "", err
+	}
+	var n int
+	switch {
+	case b[0]&0xe0 == 0xa0:
+		n = int(b[0] & 0x1f)
+	case b[0] == 0xd9:
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return "", err
+		}
+		n = int(b[0])
+	default:
+		return "", fmt.Errorf("expected a string, got type %#x", b[0])
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapfluent
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var _epoch = time.Unix(1500000000, 42)
+
+// listen starts a fake aggregator that reads messages of the expected size,
+// responding to each with ack, if any. Received messages are sent on the
+// returned channel.
+func listen(t testing.TB, size int, ack []byte) (string, <-chan []byte) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen.")
+	msgs := make(chan []byte, 8)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			msg := make([]byte, size)
+			if _, err := io.ReadFull(conn, msg); err != nil {
+				close(msgs)
+				return
+			}
+			msgs <- msg
+			if ack != nil {
+				conn.Write(ack)
+			}
+		}
+	}()
+	return ln.Addr().String(), msgs
+}
+
+func dial(t testing.TB, addr string, opts ...Option) *sink {
+	s, err := Dial(addr, "app", opts...)
+	require.NoError(t, err, "Unexpected error dialing.")
+	fs := s.(*sink)
+	fs.now = func() time.Time { return _epoch }
+	fs.newChunkID = func() string { return "id" }
+	return fs
+}
+
+func record(t testing.TB, msg string) []byte {
+	enc := zapcore.NewMsgpackEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: msg}, nil)
+	require.NoError(t, err, "Failed to encode entry.")
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+func entry(rec []byte) []byte {
+	return append([]byte{
+		0x92,
+		0xd7, 0x00, 0x59, 0x68, 0x2f, 0x00, 0x00, 0x00, 0x00, 0x2a,
+	}, rec...)
+}
+
+func message(entries ...[]byte) []byte {
+	msg := []byte{0x92, 0xa3, 'a', 'p', 'p', 0x90 | byte(len(entries))}
+	return append(msg, bytes.Join(entries, nil)...)
+}
+
+func TestSinkWrite(t *testing.T) {
+	rec := record(t, "hello")
+	expected := message(entry(rec))
+	addr, msgs := listen(t, len(expected), nil)
+
+	s := dial(t, addr)
+	n, err := s.Write(rec)
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, len(rec), n, "Unexpected number of bytes written.")
+	assert.Equal(t, expected, <-msgs, "Unexpected message.")
+	assert.NoError(t, s.Sync(), "Unexpected error syncing.")
+	assert.NoError(t, s.Close(), "Unexpected error closing.")
+
+	_, err = s.Write(rec)
+	assert.Equal(t, os.ErrClosed, err, "Expected an error writing to a closed sink.")
+}
+
+func TestSinkBatch(t *testing.T) {
+	foo, bar := record(t, "foo"), record(t, "bar")
+	expected := message(entry(foo), entry(bar))
+	addr, msgs := listen(t, len(expected), nil)
+
+	s := dial(t, addr, BatchSize(1024))
+	defer s.Close()
+	for _, rec := range [][]byte{foo, bar} {
+		_, err := s.Write(rec)
+		require.NoError(t, err, "Unexpected error writing.")
+	}
+	select {
+	case <-msgs:
+		t.Fatal("Expected entries to be batched until Sync.")
+	case <-time.After(10 * time.Millisecond):
+	}
+	require.NoError(t, s.Sync(), "Unexpected error syncing.")
+	assert.Equal(t, expected, <-msgs, "Unexpected message.")
+}
+
+func TestSinkAck(t *testing.T) {
+	rec := record(t, "hello")
+	expected := append(message(entry(rec)), 0x81, 0xa5, 'c', 'h', 'u', 'n', 'k', 0xa2, 'i', 'd')
+	expected[0] = 0x93
+
+	tests := []struct {
+		desc string
+		ack  []byte
+		err  string
+	}{
+		{"acknowledged", []byte{0x81, 0xa3, 'a', 'c', 'k', 0xa2, 'i', 'd'}, ""},
+		{"wrong chunk", []byte{0x81, 0xa3, 'a', 'c', 'k', 0xa2, 'n', 'o'}, `got acknowledgement for chunk "no", expected "id"`},
+		{"malformed", []byte{0x80, 0x80, 0x80, 0x80, 0x80}, "unexpected acknowledgement"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			addr, msgs := listen(t, len(expected), tt.ack)
+			s := dial(t, addr, RequireAck(), BatchSize(1024))
+			defer s.Close()
+
+			_, err := s.Write(rec)
+			require.NoError(t, err, "Unexpected error writing.")
+			err = s.Sync()
+			assert.Equal(t, expected, <-msgs, "Unexpected message.")
+			if tt.err == "" {
+				assert.NoError(t, err, "Unexpected error syncing.")
+				return
+			}
+			require.Error(t, err, "Expected an error syncing unacknowledged chunks.")
+			assert.Contains(t, err.Error(), tt.err, "Unexpected error syncing.")
+			assert.Len(t, s.spool, 1, "Expected the chunk to stay spooled.")
+		})
+	}
+}
+
+func TestSinkSpool(t *testing.T) {
+	rec := record(t, "hello")
+	s := dial(t, "127.0.0.1:1", SpoolSize(len(message(entry(rec)))))
+	s.dial = func(string, string, time.Duration) (net.Conn, error) {
+		return nil, errors.New("unreachable")
+	}
+
+	_, err := s.Write(rec)
+	assert.NoError(t, err, "Expected spooled writes to succeed.")
+	_, err = s.Write(rec)
+	assert.Error(t, err, "Expected an error dropping spooled chunks.")
+	assert.Len(t, s.spool, 1, "Unexpected number of spooled chunks.")
+	assert.Error(t, s.Sync(), "Expected an error syncing undeliverable chunks.")
+	assert.Error(t, s.Close(), "Expected an error dropping spooled chunks on close.")
+}
+
+func TestSinkErrors(t *testing.T) {
+	_, err := Dial("127.0.0.1:1", "")
+	assert.Error(t, err, "Expected an error with an empty tag.")
+
+	s := dial(t, "127.0.0.1:1")
+	defer s.Close()
+	_, err = s.Write([]byte(`{"msg":"json"}`))
+	assert.Error(t, err, "Expected an error writing something other than a MessagePack map.")
+}
+
+func TestAppendString(t *testing.T) {
+	for _, n := range []int{0, 31, 32, 255, 256, 65535, 65536} {
+		s := string(bytes.Repeat([]byte{'x'}, n))
+		b := appendString(nil, s)
+		got, err := readString(bytes.NewReader(b))
+		if n <= 255 {
+			require.NoError(t, err, "Unexpected error reading %d-byte string.", n)
+			assert.Equal(t, s, got, "Unexpected string round trip.")
+		}
+		assert.True(t, bytes.HasSuffix(b, []byte(s)), "Expected the string to follow its header.")
+		assert.True(t, len(b)-n <= 5, "Unexpectedly long header for %d-byte string.", n)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapfluent
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// isMsgpackMap reports whether p starts with a MessagePack map header.
+func isMsgpackMap(p []byte) bool {
+	return len(p) > 0 && (p[0]&0xf0 == 0x80 || p[0] == 0xde || p[0] == 0xdf)
+}
+
+// appendEntry appends a Forward-mode entry, the array [time, record], to b.
+// The time is encoded as the protocol's EventTime extension, which keeps
+// nanosecond precision.
+func appendEntry(b []byte, t time.Time, record []byte) []byte {
+	b = append(b, 0x92, 0xd7, 0x00)
+	b = appendUint32(b, uint32(t.Unix()))
+	b = appendUint32(b, uint32(t.Nanosecond()))
+	return append(b, record...)
+}
+
+// encodeMessage encodes a Forward-mode message: the array [tag, entries] or,
+// when a chunk ID is given, [tag, entries, {"chunk": id}].
+func encodeMessage(tag string, entries []byte, n int, id string) []byte {
+	b := make([]byte, 0, len(entries)+len(tag)+len(id)+32)
+	if id == "" {
+		b = append(b, 0x92)
+	} else {
+		b = append(b, 0x93)
+	}
+	b = appendString(b, tag)
+	b = appendArrayHeader(b, n)
+	b = append(b, entries...)
+	if id != "" {
+		b = append(b, 0x81)
+		b = appendString(b, "chunk")
+		b = appendString(b, id)
+	}
+	return b
+}
+
+func appendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= 0xff:
+		b = append(b, 0xd9, byte(n))
+	case n <= 0xffff:
+		b = append(b, 0xda)
+		b = appendUint16(b, uint16(n))
+	default:
+		b = append(b, 0xdb)
+		b = appendUint32(b, uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= 0xffff:
+		return appendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return appendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+func appendUint16(b []byte, n uint16) []byte {
+	var buf [2]byte
+	binary.BigEndian.PutUint16(buf[:], n)
+	return append(b, buf[:]...)
+}
+
+func appendUint32(b []byte, n uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], n)
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapgelf encodes zap's output in the Graylog Extended Log Format
+// (GELF), version 1.1, and sends it to Graylog over UDP. NewEncoder produces
+// GELF messages, and DialUDP sends them as datagrams, splitting large
+// messages into GELF chunks:
+//
+//   sink, err := zapgelf.DialUDP("graylog:12201")
+//   if err != nil {
+//     return err
+//   }
+//   core := zapcore.NewCore(zapgelf.NewEncoder(zapgelf.Config{}), sink, zap.InfoLevel)
+//
+// Since Graylog also accepts GELF over TCP and HTTP, the encoder works with
+// other sinks, too.
+package zapgelf // import "go.uber.org/zap/zapgelf"
+
+import (
+	"os"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config describes the fields common to every message.
+type Config struct {
+	// Host identifies the machine sending the messages. If empty, it
+	// defaults to os.Hostname.
+	Host string
+}
+
+type encoder struct {
+	zapcore.Encoder
+	// prefix is prepended to the keys of all fields. It's an underscore at
+	// the top level, and grows as objects and namespaces are flattened.
+	prefix string
+}
+
+// NewEncoder creates an Encoder for GELF 1.1 messages. The entry's message
+// is the short_message, its level is the syslog severity at level (see
+// zapcore.SeverityLevelEncoder), and its time is the timestamp, in seconds
+// since the Unix epoch. If the entry has a stack trace, the full_message
+// repeats the message, followed by the stack trace.
+//
+// Everything else becomes an additional field, prefixed with an underscore:
+// the logger's name is _logger, the caller is _caller, and a field "user" is
+// _user. Since GELF messages are flat, the fields of objects and namespaces
+// are flattened too, joining their keys with underscores. Arrays and
+// reflected values, which GELF can't represent, are encoded as JSON.
+//
+// Note that Graylog ignores the additional field _id, so don't use "id" as a
+// top-level key.
+func NewEncoder(cfg Config) zapcore.Encoder {
+	if cfg.Host == "" {
+		cfg.Host, _ = os.Hostname()
+	}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		TimeKey:        "timestamp",
+		LevelKey:       "level",
+		NameKey:        "_logger",
+		CallerKey:      "_caller",
+		MessageKey:     "short_message",
+		StacktraceKey:  "full_message",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.SeverityLevelEncoder,
+		EncodeTime:     zapcore.EpochTimeEncoder,
+		EncodeDuration: zapcore.SecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	})
+	enc.AddString("version", "1.1")
+	enc.AddString("host", cfg.Host)
+	return &encoder{Encoder: enc, prefix: "_"}
+}
+
+func (e *encoder) Clone() zapcore.Encoder {
+	return &encoder{Encoder: e.Encoder.Clone(), prefix: e.prefix}
+}
+
+func (e *encoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	// The wrapped Encoder would add the fields without prefixing their keys,
+	// so add them to a clone of ourselves instead.
+	final := &encoder{Encoder: e.Encoder.Clone(), prefix: e.prefix}
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+	if ent.Stack != "" {
+		ent.Stack = ent.Message + "\n" + ent.Stack
+	}
+	return final.Encoder.EncodeEntry(ent, nil)
+}
+
+func (e *encoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.Encoder.AddArray(e.prefix+key, arr)
+}
+
+func (e *encoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	return obj.MarshalLogObject(&encoder{Encoder: e.Encoder, prefix: e.prefix + key + "_"})
+}
+
+func (e *encoder) AddBinary(key string, val []byte) {
+	e.Encoder.AddBinary(e.prefix+key, val)
+}
+
+func (e *encoder) AddByteString(key string, val []byte) {
+	e.Encoder.AddByteString(e.prefix+key, val)
+}
+
+func (e *encoder) AddBool(key string, val bool) {
+	e.Encoder.AddBool(e.prefix+key, val)
+}
+
+func (e *encoder) AddComplex128(key string, val complex128) {
+	e.Encoder.AddComplex128(e.prefix+key, val)
+}
+
+func (e *encoder) AddComplex64(key string, val complex64) {
+	e.Encoder.AddComplex64(e.prefix+key, val)
+}
+
+func (e *encoder) AddDuration(key string, val time.Duration) {
+	e.Encoder.AddDuration(e.prefix+key, val)
+}
+
+func (e *encoder) AddFloat64(key string, val float64) {
+	e.Encoder.AddFloat64(e.prefix+key, val)
+}
+
+func (e *encoder) AddFloat32(key string, val float32) {
+	e.Encoder.AddFloat32(e.prefix+key, val)
+}
+
+func (e *encoder) AddInt(key string, val int) {
+	e.Encoder.AddInt(e.prefix+key, val)
+}
+
+func (e *encoder) AddInt64(key string, val int64) {
+	e.Encoder.AddInt64(e.prefix+key, val)
+}
+
+func (e *encoder) AddInt32(key string, val int32) {
+	e.Encoder.AddInt32(e.prefix+key, val)
+}
+
+func (e *encoder) AddInt16(key string, val int16) {
+	e.Encoder.AddInt16(e.prefix+key, val)
+}
+
+func (e *encoder) AddInt8(key string, val int8) {
+	e.Encoder.AddInt8(e.prefix+key, val)
+}
+
+func (e *encoder) AddString(key, val string) {
+	e.Encoder.AddString(e.prefix+key, val)
+}
+
+func (e *encoder) AddTime(key string, val time.Time) {
+	e.Encoder.AddTime(e.prefix+key, val)
+}
+
+func (e *encoder) AddUint(key string, val uint) {
+	e.Encoder.AddUint(e.prefix+key, val)
+}
+
+func (e *encoder) AddUint64(key string, val uint64) {
+	e.Encoder.AddUint64(e.prefix+key, val)
+}
+
+func (e *encoder) AddUint32(key string, val uint32) {
+	e.Encoder.AddUint32(e.prefix+key, val)
+}
+
+func (e *encoder) AddUint16(key string, val uint16) {
+	e.Encoder.AddUint16(e.prefix+key, val)
+}
+
+func (e *encoder) AddUint8(key string, val uint8) {
+	e.Encoder.AddUint8(e.prefix+key, val)
+}
+
+func (e *encoder) AddUintptr(key string, val uintptr) {
+	e.Encoder.AddUintptr(e.prefix+key, val)
+}
+
+func (e *encoder) AddReflected(key string, val interface{}) error {
+	return e.Encoder.AddReflected(e.prefix+key, val)
+}
+
+func (e *encoder) OpenNamespace(key string) {
+	e.prefix += key + "_"
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapgelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct{ name string }
+
+func (u user) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	return nil
+}
+
+func TestEncoder(t *testing.T) {
+	enc := NewEncoder(Config{Host: "host"})
+	enc.AddString("service", "api")
+	ent := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Unix(1, 500000000),
+		LoggerName: "db",
+		Message:    "hello",
+	}
+
+	buf, err := enc.EncodeEntry(ent, []zapcore.Field{
+		zap.Int("n", 1),
+		zap.Object("user", user{"jane"}),
+		zap.Namespace("req"),
+		zap.Strings("tags", []string{"a"}),
+	})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		`{"level":4,"timestamp":1.5,"_logger":"db","short_message":"hello","version":"1.1","host":"host",`+
+			`"_service":"api","_n":1,"_user_name":"jane","_req_tags":["a"]}`+"\n",
+		buf.String(),
+		"Unexpected output.",
+	)
+
+	buf, err = enc.EncodeEntry(zapcore.Entry{Message: "oops", Stack: "trace"}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &msg), "Expected valid JSON.")
+	assert.Equal(t, "oops\ntrace", msg["full_message"], "Expected the full message to include the stack trace.")
+}
+
+func TestEncoderNamespacedContext(t *testing.T) {
+	enc := NewEncoder(Config{Host: "host"})
+	enc.OpenNamespace("ctx")
+	clone := enc.Clone()
+	clone.AddInt("a", 1)
+	enc.AddInt("b", 2)
+
+	buf, err := clone.EncodeEntry(zapcore.Entry{Message: "m"}, []zapcore.Field{zap.Int("c", 3)})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Contains(t, buf.String(), `"_ctx_a":1,"_ctx_c":3}`, "Expected namespaced keys to be flattened.")
+	assert.NotContains(t, buf.String(), "_ctx_b", "Expected the clone to be independent.")
+}
+
+func listenUDP(t testing.TB) (*net.UDPConn, func() []byte) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err, "Failed to listen.")
+	return conn, func() []byte {
+		buf := make([]byte, 65536)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)), "Failed to set deadline.")
+		n, err := conn.Read(buf)
+		require.NoError(t, err, "Failed to read datagram.")
+		return buf[:n]
+	}
+}
+
+func TestUDPSink(t *testing.T) {
+	conn, read := listenUDP(t)
+	defer conn.Close()
+
+	sink, err := DialUDP(conn.LocalAddr().String())
+	require.NoError(t, err, "Unexpected error dialing.")
+	defer sink.Close()
+
+	_, err = sink.Write([]byte(`{"short_message":"hi"}` + "\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, `{"short_message":"hi"}`, string(read()), "Expected an unchunked message without the newline.")
+	assert.NoError(t, sink.Sync(), "Unexpected error syncing.")
+}
+
+func TestUDPSinkChunks(t *testing.T) {
+	conn, read := listenUDP(t)
+	defer conn.Close()
+
+	sink, err := DialUDP(conn.LocalAddr().String(), ChunkSize(20))
+	require.NoError(t, err, "Unexpected error dialing.")
+	defer sink.Close()
+
+	msg := strings.Repeat("abcd", 5) + "e"
+	_, err = sink.Write([]byte(msg))
+	require.NoError(t, err, "Unexpected error writing.")
+
+	var (
+		id          []byte
+		reassembled []byte
+	)
+	for i := 0; i < 3; i++ {
+		chunk := read()
+		require.True(t, len(chunk) <= 20, "Chunk too large.")
+		assert.Equal(t, []byte{0x1e, 0x0f}, chunk[:2], "Unexpected magic bytes.")
+		if id == nil {
+			id = chunk[2:10]
+		}
+		assert.Equal(t, id, chunk[2:10], "Expected all chunks to share an ID.")
+		assert.Equal(t, []byte{byte(i), 3}, chunk[10:12], "Unexpected sequence number or count.")
+		reassembled = append(reassembled, chunk[12:]...)
+	}
+	assert.Equal(t, msg, string(reassembled), "Unexpected reassembled message.")
+
+	_, err = sink.Write(bytes.Repeat([]byte("x"), 8*128+1))
+	require.Error(t, err, "Expected an error for messages needing too many chunks.")
+	assert.Contains(t, err.Error(), "more than the limit of 128", "Unexpected error.")
+}
+
+func TestUDPSinkCompress(t *testing.T) {
+	conn, read := listenUDP(t)
+	defer conn.Close()
+
+	sink, err := DialUDP(conn.LocalAddr().String(), Compress())
+	require.NoError(t, err, "Unexpected error dialing.")
+	defer sink.Close()
+
+	_, err = sink.Write([]byte("hello\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	zr, err := gzip.NewReader(bytes.NewReader(read()))
+	require.NoError(t, err, "Expected a gzipped datagram.")
+	out, err := ioutil.ReadAll(zr)
+	require.NoError(t, err, "Unexpected error decompressing.")
+	assert.Equal(t, "hello", string(out), "Unexpected decompressed message.")
+}
+
+type failingConn struct{ net.Conn }
+
+func (failingConn) Write([]byte) (int, error) { return 0, errors.New("fail") }
+
+func TestUDPSinkWriteError(t *testing.T) {
+	s := &udpSink{Conn: failingConn{}, chunkSize: _defaultChunkSize}
+	n, err := s.Write([]byte("hello"))
+	assert.Equal(t, 0, n, "Expected no bytes written.")
+	assert.Error(t, err, "Expected the connection's error.")
+}
+
+func TestDialUDPError(t *testing.T) {
+	_, err := DialUDP("not a valid address")
+	assert.Error(t, err, "Expected an error dialing an invalid address.")
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapgelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/internal/bufferpool"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// _defaultChunkSize is the largest datagram Graylog recommends for
+	// local networks.
+	_defaultChunkSize = 1420
+	// _chunkHeaderSize is the size of the magic bytes, message ID, sequence
+	// number, and sequence count that precede each chunk.
+	_chunkHeaderSize = 12
+	// _maxChunks is the most chunks a message may be split into.
+	_maxChunks = 128
+)
+
+var _chunkMagic = []byte{0x1e, 0x0f}
+
+// An Option configures a UDP sink.
+type Option interface {
+	apply(*udpSink)
+}
+
+type optionFunc func(*udpSink)
+
+func (f optionFunc) apply(s *udpSink) {
+	f(s)
+}
+
+// ChunkSize sets the largest datagram the sink sends, including the chunk
+// header; larger messages are split into chunks of at most this size. It
+// defaults to 1420 bytes, which suits most local networks. Graylog
+// recommends 8154 bytes across the internet.
+func ChunkSize(n int) Option {
+	return optionFunc(func(s *udpSink) {
+		if n > _chunkHeaderSize {
+			s.chunkSize = n
+		}
+	})
+}
+
+// Compress gzips each message before sending it, which Graylog detects
+// automatically.
+func Compress() Option {
+	return optionFunc(func(s *udpSink) {
+		s.compress = true
+	})
+}
+
+// DialUDP returns a Sink that sends each write to a Graylog GELF UDP input
+// at addr, without any trailing newline. Messages larger than the chunk size
+// are split into at most 128 chunks; writing a message too large for that
+// fails. As with other datagram sinks, each message must be a single write,
+// which is how Cores use their WriteSyncers.
+func DialUDP(addr string, opts ...Option) (zap.Sink, error) {
+	c, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &udpSink{Conn: c, chunkSize: _defaultChunkSize}
+	for _, opt := range opts {
+		opt.apply(s)
+	}
+	// Start numbering messages at a random ID, so that messages from
+	// processes that restart, or run side by side, are unlikely to collide.
+	var seed [8]byte
+	if _, err := rand.Read(seed[:]); err == nil {
+		s.ids.Store(binary.BigEndian.Uint64(seed[:]))
+	}
+	return s, nil
+}
+
+type udpSink struct {
+	net.Conn
+	chunkSize int
+	compress  bool
+	ids       atomic.Uint64
+}
+
+func (s *udpSink) Write(p []byte) (int, error) {
+	msg := bytes.TrimSuffix(p, []byte(zapcore.DefaultLineEnding))
+	if s.compress {
+		buf := bufferpool.Get()
+		defer buf.Free()
+		zw := gzip.NewWriter(buf)
+		if _, err := zw.Write(msg); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		msg = buf.Bytes()
+	}
+	if err := s.send(msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *udpSink) send(msg []byte) error {
+	if len(msg) <= s.chunkSize {
+		_, err := s.Conn.Write(msg)
+		return err
+	}
+
+	size := s.chunkSize - _chunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > _maxChunks {
+		return fmt.Errorf("GELF message of %d bytes needs %d chunks, more than the limit of %d", len(msg), count, _maxChunks)
+	}
+
+	buf := bufferpool.Get()
+	defer buf.Free()
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], s.ids.Inc())
+	for i := 0; i < count; i++ {
+		buf.Reset()
+		buf.Write(_chunkMagic)
+		buf.Write(id[:])
+		buf.AppendByte(byte(i))
+		buf.AppendByte(byte(count))
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		buf.Write(msg[i*size : end])
+		if _, err := s.Conn.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync is a no-op, since messages are sent as they're written.
+func (s *udpSink) Sync() error {
+	return nil
+}
@@ -21,7 +21,26 @@
 // Package zapgrpc provides a logger that is compatible with grpclog.
 package zapgrpc // import "go.uber.org/zap/zapgrpc"
 
-import "go.uber.org/zap"
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// gRPC's severity levels, which grpclog.LoggerV2 uses as verbosity levels.
+const (
+	grpcLvlInfo = iota
+	grpcLvlWarn
+	grpcLvlError
+	grpcLvlFatal
+)
+
+// _grpcToZapLevel maps gRPC log levels to zap log levels.
+var _grpcToZapLevel = map[int]zapcore.Level{
+	grpcLvlInfo:  zapcore.InfoLevel,
+	grpcLvlWarn:  zapcore.WarnLevel,
+	grpcLvlError: zapcore.ErrorLevel,
+	grpcLvlFatal: zapcore.FatalLevel,
+}
 
 // An Option overrides a Logger's default configuration.
 type Option interface {
@@ -48,11 +67,12 @@ func WithDebug() Option {
 // By default, Loggers print at zap's InfoLevel.
 func NewLogger(l *zap.Logger, options ...Option) *Logger {
 	logger := &Logger{
-		log:    l.Sugar(),
-		fatal:  (*zap.SugaredLogger).Fatal,
-		fatalf: (*zap.SugaredLogger).Fatalf,
-		print:  (*zap.SugaredLogger).Info,
-		printf: (*zap.SugaredLogger).Infof,
+		log:          l.Sugar(),
+		levelEnabler: l.Core(),
+		fatal:        (*zap.SugaredLogger).Fatal,
+		fatalf:       (*zap.SugaredLogger).Fatalf,
+		print:        (*zap.SugaredLogger).Info,
+		printf:       (*zap.SugaredLogger).Infof,
 	}
 	for _, option := range options {
 		option.apply(logger)
@@ -60,13 +80,15 @@ func NewLogger(l *zap.Logger, options ...Option) *Logger {
 	return logger
 }
 
-// Logger adapts zap's Logger to be compatible with grpclog.Logger.
+// Logger adapts zap's Logger to be compatible with grpclog.Logger and
+// grpclog.LoggerV2.
 type Logger struct {
-	log    *zap.SugaredLogger
-	fatal  func(*zap.SugaredLogger, ...interface{})
-	fatalf func(*zap.SugaredLogger, string, ...interface{})
-	print  func(*zap.SugaredLogger, ...interface{})
-	printf func(*zap.SugaredLogger, string, ...interface{})
+	log          *zap.SugaredLogger
+	levelEnabler zapcore.LevelEnabler
+	fatal        func(*zap.SugaredLogger, ...interface{})
+	fatalf       func(*zap.SugaredLogger, string, ...interface{})
+	print        func(*zap.SugaredLogger, ...interface{})
+	printf       func(*zap.SugaredLogger, string, ...interface{})
 }
 
 // Fatal implements grpclog.Logger.
@@ -98,3 +120,56 @@ func (l *Logger) Printf(format string, args ...interface{}) {
 func (l *Logger) Println(args ...interface{}) {
 	l.print(l.log, args...)
 }
+
+// Info implements grpclog.LoggerV2.
+func (l *Logger) Info(args ...interface{}) {
+	l.log.Info(args...)
+}
+
+// Infoln implements grpclog.LoggerV2.
+func (l *Logger) Infoln(args ...interface{}) {
+	l.log.Infoln(args...)
+}
+
+// Infof implements grpclog.LoggerV2.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.log.Infof(format, args...)
+}
+
+// Warning implements grpclog.LoggerV2.
+func (l *Logger) Warning(args ...interface{}) {
+	l.log.Warn(args...)
+}
+
+// Warningln implements grpclog.LoggerV2.
+func (l *Logger) Warningln(args ...interface{}) {
+	l.log.Warnln(args...)
+}
+
+// Warningf implements grpclog.LoggerV2.
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.log.Warnf(format, args...)
+}
+
+// Error implements grpclog.LoggerV2.
+func (l *Logger) Error(args ...interface{}) {
+	l.log.Error(args...)
+}
+
+// Errorln implements grpclog.LoggerV2.
+func (l *Logger) Errorln(args ...interface{}) {
+	l.log.Errorln(args...)
+}
+
+// Errorf implements grpclog.LoggerV2.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.log.Errorf(format, args...)
+}
+
+// V implements grpclog.LoggerV2. It maps gRPC's verbosity levels 0 through 3
+// (info, warning, error, and fatal) to the corresponding zap levels, and
+// reports whether the underlying Logger is enabled at that level. Unknown
+// verbosity levels are treated as info.
+func (l *Logger) V(level int) bool {
+	return l.levelEnabler.Enabled(_grpcToZapLevel[level])
+}
@@ -113,3 +113,50 @@ func withWarn() Option {
 		logger.fatalf = (*zap.SugaredLogger).Warnf
 	})
 }
+
+func TestLoggerV2(t *testing.T) {
+	core, observedLogs := observer.New(zapcore.InfoLevel)
+	logger := NewLogger(zap.New(core))
+
+	logger.Info("hello", 1)
+	logger.Infof("%s", "world")
+	logger.Infoln("foo", 1)
+	logger.Warning("warn", 2)
+	logger.Warningf("%d", 3)
+	logger.Warningln("bar", 4)
+	logger.Error("err", 5)
+	logger.Errorf("%d", 6)
+	logger.Errorln("baz", 7)
+
+	expected := []struct {
+		level zapcore.Level
+		msg   string
+	}{
+		{zapcore.InfoLevel, "hello1"},
+		{zapcore.InfoLevel, "world"},
+		{zapcore.InfoLevel, "foo 1"},
+		{zapcore.WarnLevel, "warn2"},
+		{zapcore.WarnLevel, "3"},
+		{zapcore.WarnLevel, "bar 4"},
+		{zapcore.ErrorLevel, "err5"},
+		{zapcore.ErrorLevel, "6"},
+		{zapcore.ErrorLevel, "baz 7"},
+	}
+	logEntries := observedLogs.All()
+	require.Equal(t, len(expected), len(logEntries))
+	for i, logEntry := range logEntries {
+		require.Equal(t, expected[i].level, logEntry.Level)
+		require.Equal(t, expected[i].msg, logEntry.Message)
+	}
+}
+
+func TestLoggerV2Verbosity(t *testing.T) {
+	core, _ := observer.New(zapcore.WarnLevel)
+	logger := NewLogger(zap.New(core))
+
+	require.False(t, logger.V(0), "Expected info verbosity to be disabled.")
+	require.True(t, logger.V(1), "Expected warning verbosity to be enabled.")
+	require.True(t, logger.V(2), "Expected error verbosity to be enabled.")
+	require.True(t, logger.V(3), "Expected fatal verbosity to be enabled.")
+	require.False(t, logger.V(99), "Expected unknown verbosity to be treated as info.")
+}
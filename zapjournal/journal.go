@@ -0,0 +1,286 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapjournal provides a Core that writes entries to the systemd
+// journal using its native protocol, so that structured fields become
+// journal fields (visible with, for example, journalctl -o json) rather than
+// being flattened into a line of text.
+//
+// The journal is only available on Linux; elsewhere, NewCore returns an
+// error.
+package zapjournal // import "go.uber.org/zap/zapjournal"
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/internal/bufferpool"
+	"go.uber.org/zap/zapcore"
+)
+
+// A sender delivers a serialized entry to the journal.
+type sender interface {
+	send([]byte) error
+	close() error
+}
+
+// _reservedNames are the journal fields that Write sets itself or that the
+// journal gives a special meaning. Fields that would be stored under one of
+// these names are prefixed with "F_" instead, so that they can't override
+// the entry's message, priority, and so on.
+var _reservedNames = map[string]bool{
+	"MESSAGE":           true,
+	"MESSAGE_ID":        true,
+	"PRIORITY":          true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+	"ERRNO":             true,
+	"SYSLOG_FACILITY":   true,
+	"SYSLOG_IDENTIFIER": true,
+	"SYSLOG_PID":        true,
+	"SYSLOG_TIMESTAMP":  true,
+	"LOGGER":            true,
+	"STACKTRACE":        true,
+}
+
+// An Option configures a journal Core.
+type Option interface {
+	apply(*core)
+}
+
+type optionFunc func(*core)
+
+func (f optionFunc) apply(c *core) {
+	f(c)
+}
+
+// WithIdentifier sets the SYSLOG_IDENTIFIER of every entry, which
+// journalctl's -t flag filters by. It defaults to the name of the running
+// executable; an empty identifier leaves the field out.
+func WithIdentifier(id string) Option {
+	return optionFunc(func(c *core) {
+		c.identifier = id
+	})
+}
+
+type core struct {
+	zapcore.LevelEnabler
+	identifier string
+	context    []byte // serialized context fields
+	out        sender
+}
+
+func newCore(enab zapcore.LevelEnabler, out sender, opts ...Option) zapcore.Core {
+	c := &core{LevelEnabler: enab, out: out}
+	if len(os.Args) > 0 {
+		c.identifier = filepath.Base(os.Args[0])
+	}
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+	return c
+}
+
+func (c *core) With(fields []zapcore.Field) zapcore.Core {
+	buf := bufferpool.Get()
+	buf.Write(c.context)
+	appendFields(buf, fields)
+	context := append([]byte(nil), buf.Bytes()...)
+	buf.Free()
+	return &core{
+		LevelEnabler: c.LevelEnabler,
+		identifier:   c.identifier,
+		context:      context,
+		out:          c.out,
+	}
+}
+
+func (c *core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write sends the entry to the journal. The message is stored in MESSAGE, the
+// level in PRIORITY (as a syslog severity), the identifier configured with
+// WithIdentifier in SYSLOG_IDENTIFIER, the logger's name in LOGGER, the
+// caller in CODE_FILE, CODE_LINE, and CODE_FUNC, and the stacktrace, if any,
+// in STACKTRACE. Fields are stored under their keys, converted to valid
+// journal field names (see fieldName).
+func (c *core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf := bufferpool.Get()
+	defer buf.Free()
+	appendVar(buf, "MESSAGE", ent.Message)
+	appendVar(buf, "PRIORITY", strconv.Itoa(priority(ent.Level)))
+	if c.identifier != "" {
+		appendVar(buf, "SYSLOG_IDENTIFIER", c.identifier)
+	}
+	if ent.LoggerName != "" {
+		appendVar(buf, "LOGGER", ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		appendVar(buf, "CODE_FILE", ent.Caller.File)
+		appendVar(buf, "CODE_LINE", strconv.Itoa(ent.Caller.Line))
+		if fn := runtime.FuncForPC(ent.Caller.PC); fn != nil {
+			appendVar(buf, "CODE_FUNC", fn.Name())
+		}
+	}
+	buf.Write(c.context)
+	appendFields(buf, fields)
+	if ent.Stack != "" {
+		appendVar(buf, "STACKTRACE", ent.Stack)
+	}
+	return c.out.send(buf.Bytes())
+}
+
+// Sync is a no-op, since entries are sent to the journal as they're written.
+func (c *core) Sync() error {
+	return nil
+}
+
+// Close closes the connection to the journal, which is shared with the Cores
+// derived from this one by With. Writes after Close fail.
+func (c *core) Close() error {
+	return c.out.close()
+}
+
+// priority maps zap's levels to syslog severities.
+func priority(lvl zapcore.Level) int {
+	switch lvl {
+	case zapcore.DebugLevel:
+		return 7 // debug
+	case zapcore.InfoLevel:
+		return 6 // info
+	case zapcore.WarnLevel:
+		return 4 // warning
+	case zapcore.ErrorLevel:
+		return 3 // err
+	default:
+		return 2 // crit
+	}
+}
+
+func appendFields(buf *buffer.Buffer, fields []zapcore.Field) {
+	if len(fields) == 0 {
+		return
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for i := range fields {
+		fields[i].AddTo(enc)
+	}
+	// Map iteration order is random, but the journal doesn't preserve field
+	// order anyway.
+	for k, v := range enc.Fields {
+		appendVar(buf, fieldName(k), fieldValue(v))
+	}
+}
+
+// fieldName converts a key into a valid journal field name: journal fields
+// contain only uppercase letters, digits, and underscores, don't start with
+// an underscore (which is reserved for fields set by the journal itself) or
+// digit, and are at most 64 characters long. Other characters are replaced
+// with underscores, names that would start with a digit or be empty are
+// prefixed with "F", and reserved names (see _reservedNames) with "F_".
+func fieldName(key string) string {
+	const maxLen = 64
+	name := make([]byte, 0, len(key)+1)
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'a' <= c && c <= 'z':
+			c -= 'a' - 'A'
+		case 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		default:
+			c = '_'
+		}
+		if c == '_' && len(name) == 0 {
+			continue
+		}
+		name = append(name, c)
+	}
+	if len(name) == 0 || ('0' <= name[0] && name[0] <= '9') {
+		name = append([]byte{'F'}, name...)
+	} else if _reservedNames[string(name)] {
+		name = append([]byte("F_"), name...)
+	}
+	if len(name) > maxLen {
+		name = name[:maxLen]
+	}
+	return string(name)
+}
+
+// fieldValue renders a value produced by the MapObjectEncoder.
+func fieldValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case fmt.Stringer:
+		return v.String()
+	case map[string]interface{}, []interface{}:
+		if bs, err := json.Marshal(v); err == nil {
+			return string(bs)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// appendVar serializes a single field using the journal's native format:
+// NAME=value on a line of its own or, for values containing newlines, the
+// name and a newline followed by the value's length as a 64-bit little-endian
+// integer and the value itself.
+func appendVar(buf *buffer.Buffer, name, value string) {
+	buf.AppendString(name)
+	if !containsNewline(value) {
+		buf.AppendByte('=')
+		buf.AppendString(value)
+		buf.AppendByte('\n')
+		return
+	}
+	buf.AppendByte('\n')
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], uint64(len(value)))
+	buf.Write(size[:])
+	buf.AppendString(value)
+	buf.AppendByte('\n')
+}
+
+func containsNewline(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\n' {
+			return true
+		}
+	}
+	return false
+}
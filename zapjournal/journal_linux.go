@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build linux
+// +build linux
+
+package zapjournal
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// _journalSocket is where journald listens for the native protocol.
+const _journalSocket = "/run/systemd/journal/socket"
+
+// Available reports whether the systemd journal's socket exists, which is a
+// good indication that the program is running under systemd.
+func Available() bool {
+	fi, err := os.Stat(_journalSocket)
+	return err == nil && fi.Mode()&os.ModeSocket != 0
+}
+
+// NewCore creates a Core that sends entries enabled by enab to the systemd
+// journal. It returns an error if the journal's socket can't be reached.
+//
+// The Core also implements io.Closer. Closing it, or any Core derived from
+// it, closes the socket once the journal is no longer needed.
+func NewCore(enab zapcore.LevelEnabler, opts ...Option) (zapcore.Core, error) {
+	s, err := dialSocket(_journalSocket)
+	if err != nil {
+		return nil, err
+	}
+	return newCore(enab, s, opts...), nil
+}
+
+// socketSender sends entries to the journal as datagrams. It uses the
+// syscall package directly, since the net package doesn't support passing
+// file descriptors over connected datagram sockets.
+type socketSender struct {
+	mu     sync.RWMutex
+	fd     int
+	closed bool
+}
+
+func dialSocket(path string) (*socketSender, error) {
+	fd, err := syscall.Socket(syscall.AF_UNIX, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := syscall.Connect(fd, &syscall.SockaddrUnix{Name: path}); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("connect", err)
+	}
+	return &socketSender{fd: fd}, nil
+}
+
+func (s *socketSender) send(bs []byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return os.ErrClosed
+	}
+
+	err := s.sendmsg(bs, nil)
+	if err == nil || !isTooLarge(err) {
+		return err
+	}
+	// The entry doesn't fit in a datagram. The journal also accepts entries
+	// written to a file, with the file descriptor passed over the socket.
+	return s.sendFile(bs)
+}
+
+func (s *socketSender) sendFile(bs []byte) error {
+	f, err := ioutil.TempFile("/dev/shm", "zapjournal")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(bs); err != nil {
+		return err
+	}
+	return s.sendmsg(nil, syscall.UnixRights(int(f.Fd())))
+}
+
+func (s *socketSender) sendmsg(p, oob []byte) error {
+	for {
+		err := syscall.Sendmsg(s.fd, p, oob, nil, 0)
+		if err != syscall.EINTR {
+			return err
+		}
+	}
+}
+
+func (s *socketSender) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return os.NewSyscallError("close", syscall.Close(s.fd))
+}
+
+func isTooLarge(err error) bool {
+	return err == syscall.EMSGSIZE || err == syscall.ENOBUFS
+}
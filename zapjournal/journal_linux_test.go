@@ -0,0 +1,134 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//go:build linux
+// +build linux
+
+package zapjournal
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func listenJournal(t testing.TB) (*net.UnixConn, string, func()) {
+	dir, err := ioutil.TempDir("", "zapjournal")
+	require.NoError(t, err, "Failed to create temporary directory.")
+	path := filepath.Join(dir, "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err, "Failed to listen on a unixgram socket.")
+	return conn, path, func() {
+		conn.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// receive reads an entry from the socket, following passed file descriptors.
+func receive(t testing.TB, conn *net.UnixConn) []byte {
+	buf := make([]byte, 1<<16)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err, "Failed to read from socket.")
+	if oobn == 0 {
+		return buf[:n]
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err, "Failed to parse control message.")
+	require.Equal(t, 1, len(msgs), "Expected one control message.")
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	require.NoError(t, err, "Failed to parse file descriptors.")
+	require.Equal(t, 1, len(fds), "Expected one file descriptor.")
+
+	f := os.NewFile(uintptr(fds[0]), "entry")
+	defer f.Close()
+	// The journal maps the file, so the sender leaves the offset at the end.
+	_, err = f.Seek(0, 0)
+	require.NoError(t, err, "Failed to seek passed file.")
+	bs, err := ioutil.ReadAll(f)
+	require.NoError(t, err, "Failed to read passed file.")
+	return bs
+}
+
+func TestSocketSender(t *testing.T) {
+	conn, path, cleanup := listenJournal(t)
+	defer cleanup()
+
+	s, err := dialSocket(path)
+	require.NoError(t, err, "Failed to dial socket.")
+	core := newCore(zapcore.DebugLevel, s, WithIdentifier(""))
+
+	ent := zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello"}
+	require.NoError(t, core.Write(ent, nil), "Unexpected error writing entry.")
+	assert.Equal(t, map[string]string{
+		"MESSAGE":  "hello",
+		"PRIORITY": "6",
+	}, parseEntry(t, receive(t, conn)), "Unexpected journal fields.")
+}
+
+func TestSocketSenderClose(t *testing.T) {
+	_, path, cleanup := listenJournal(t)
+	defer cleanup()
+
+	s, err := dialSocket(path)
+	require.NoError(t, err, "Failed to dial socket.")
+	core := newCore(zapcore.DebugLevel, s).With(nil)
+
+	closer, ok := core.(io.Closer)
+	require.True(t, ok, "Expected the Core to implement io.Closer.")
+	require.NoError(t, closer.Close(), "Unexpected error closing.")
+	assert.NoError(t, closer.Close(), "Expected closing twice to be a no-op.")
+	assert.Equal(t, os.ErrClosed, core.Write(zapcore.Entry{Message: "late"}, nil), "Expected writes after Close to fail.")
+}
+
+func TestSocketSenderLargeEntry(t *testing.T) {
+	conn, path, cleanup := listenJournal(t)
+	defer cleanup()
+	if _, err := os.Stat("/dev/shm"); err != nil {
+		t.Skip("/dev/shm isn't available")
+	}
+
+	s, err := dialSocket(path)
+	require.NoError(t, err, "Failed to dial socket.")
+	core := newCore(zapcore.DebugLevel, s)
+
+	// Larger than the maximum datagram size.
+	msg := strings.Repeat("x", 1<<22)
+	require.NoError(t, core.Write(zapcore.Entry{Message: msg}, nil), "Unexpected error writing large entry.")
+	got := parseEntry(t, receive(t, conn))["MESSAGE"]
+	assert.Equal(t, len(msg), len(got), "Unexpected message length.")
+	assert.True(t, msg == got, "Unexpected message contents.")
+}
+
+func TestNewCoreMissingSocket(t *testing.T) {
+	_, err := dialSocket(filepath.Join(os.TempDir(), "zapjournal-missing"))
+	assert.Error(t, err, "Expected an error dialing a missing socket.")
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapjournal
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct {
+	sent [][]byte
+	err  error
+}
+
+func (s *fakeSender) send(bs []byte) error {
+	s.sent = append(s.sent, append([]byte(nil), bs...))
+	return s.err
+}
+
+func (s *fakeSender) close() error {
+	return s.err
+}
+
+// parseEntry decodes an entry serialized with the journal's native protocol.
+func parseEntry(t testing.TB, bs []byte) map[string]string {
+	fields := make(map[string]string)
+	for len(bs) > 0 {
+		i := strings.IndexAny(string(bs), "=\n")
+		require.True(t, i > 0, "Malformed entry: %q", bs)
+		name := string(bs[:i])
+		if bs[i] == '=' {
+			end := strings.IndexByte(string(bs[i:]), '\n')
+			require.True(t, end >= 0, "Unterminated field %s.", name)
+			fields[name] = string(bs[i+1 : i+end])
+			bs = bs[i+end+1:]
+			continue
+		}
+		bs = bs[i+1:]
+		require.True(t, len(bs) >= 8, "Missing length for field %s.", name)
+		n := int(binary.LittleEndian.Uint64(bs))
+		bs = bs[8:]
+		require.True(t, len(bs) > n && bs[n] == '\n', "Malformed binary field %s.", name)
+		fields[name] = string(bs[:n])
+		bs = bs[n+1:]
+	}
+	return fields
+}
+
+func TestCoreWrite(t *testing.T) {
+	out := &fakeSender{}
+	core := newCore(zapcore.InfoLevel, out, WithIdentifier("myapp")).With([]zapcore.Field{zap.String("service", "api")})
+	logger := zap.New(core).Named("http")
+
+	logger.Debug("dropped")
+	logger.Warn(
+		"slow request",
+		zap.Int("status", 200),
+		zap.Duration("latency", 1500*time.Millisecond),
+		zap.Strings("tags", []string{"a", "b"}),
+		zap.String("multi", "line one\nline two"),
+		zap.Error(errors.New("timeout")),
+	)
+
+	require.Equal(t, 1, len(out.sent), "Expected only enabled entries to be sent.")
+	assert.Equal(t, map[string]string{
+		"MESSAGE":           "slow request",
+		"PRIORITY":          "4",
+		"LOGGER":            "http",
+		"SYSLOG_IDENTIFIER": "myapp",
+		"SERVICE":           "api",
+		"STATUS":            "200",
+		"LATENCY":           "1.5s",
+		"TAGS":              `["a","b"]`,
+		"MULTI":             "line one\nline two",
+		"ERROR":             "timeout",
+	}, parseEntry(t, out.sent[0]), "Unexpected journal fields.")
+}
+
+func TestCoreWriteCallerAndStack(t *testing.T) {
+	out := &fakeSender{}
+	logger := zap.New(newCore(zapcore.DebugLevel, out), zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	logger.Error("failed")
+
+	require.Equal(t, 1, len(out.sent), "Expected an entry to be sent.")
+	fields := parseEntry(t, out.sent[0])
+	assert.Equal(t, "3", fields["PRIORITY"], "Unexpected priority.")
+	assert.Equal(t, filepath.Base(os.Args[0]), fields["SYSLOG_IDENTIFIER"], "Expected the identifier to default to the executable's name.")
+	assert.True(t, strings.HasSuffix(fields["CODE_FILE"], "journal_test.go"), "Unexpected CODE_FILE %q.", fields["CODE_FILE"])
+	assert.NotEmpty(t, fields["CODE_LINE"], "Expected CODE_LINE.")
+	assert.Contains(t, fields["CODE_FUNC"], "TestCoreWriteCallerAndStack", "Unexpected CODE_FUNC.")
+	assert.NotEmpty(t, fields["STACKTRACE"], "Expected a stacktrace.")
+}
+
+func TestCoreWriteReservedFields(t *testing.T) {
+	out := &fakeSender{}
+	logger := zap.New(newCore(zapcore.DebugLevel, out, WithIdentifier("")))
+	logger.Info("real message", zap.String("message", "spoofed"), zap.Int("priority", 0))
+
+	require.Equal(t, 1, len(out.sent), "Expected an entry to be sent.")
+	assert.Equal(t, map[string]string{
+		"MESSAGE":    "real message",
+		"PRIORITY":   "6",
+		"F_MESSAGE":  "spoofed",
+		"F_PRIORITY": "0",
+	}, parseEntry(t, out.sent[0]), "Expected fields not to override the entry's own.")
+}
+
+func TestCoreWriteError(t *testing.T) {
+	errSend := errors.New("fail")
+	core := newCore(zapcore.DebugLevel, &fakeSender{err: errSend})
+	assert.Equal(t, errSend, core.Write(zapcore.Entry{}, nil), "Expected send errors to be returned.")
+	assert.NoError(t, core.Sync(), "Unexpected error syncing.")
+}
+
+func TestPriority(t *testing.T) {
+	tests := map[zapcore.Level]int{
+		zapcore.DebugLevel:  7,
+		zapcore.InfoLevel:   6,
+		zapcore.WarnLevel:   4,
+		zapcore.ErrorLevel:  3,
+		zapcore.DPanicLevel: 2,
+		zapcore.PanicLevel:  2,
+		zapcore.FatalLevel:  2,
+	}
+	for lvl, want := range tests {
+		assert.Equal(t, want, priority(lvl), "Unexpected priority for %v.", lvl)
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	tests := map[string]string{
+		"user":                  "USER",
+		"requestID":             "REQUESTID",
+		"http.status-code":      "HTTP_STATUS_CODE",
+		"_private":              "PRIVATE",
+		"__":                    "F",
+		"":                      "F",
+		"2fa":                   "F2FA",
+		"ünicode":               "NICODE",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+		"message":               "F_MESSAGE",
+		"priority":              "F_PRIORITY",
+		"_code_line":            "F_CODE_LINE",
+		"messages":              "MESSAGES",
+	}
+	for key, want := range tests {
+		assert.Equal(t, want, fieldName(key), "Unexpected field name for %q.", key)
+	}
+}
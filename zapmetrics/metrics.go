@@ -0,0 +1,170 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapmetrics counts logging activity, so that dashboards can alert on
+// the rate of error logs without parsing them. Counters tracks the entries
+// written and dropped by sampling at each level and for each logger name,
+// and its counts can be exported with expvar or in the Prometheus text
+// format:
+//
+//   counters := zapmetrics.New()
+//   cfg := zap.NewProductionConfig()
+//   cfg.Sampling.Hook = counters.SamplerHook
+//   logger, err := cfg.Build(counters.Option())
+//   ...
+//   expvar.Publish("logging", counters.Var())
+//   http.Handle("/metrics/logging", counters.PrometheusHandler())
+package zapmetrics // import "go.uber.org/zap/zapmetrics"
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Counters counts the entries written and dropped at each level, for each
+// logger name. It's safe for concurrent use, and counting an entry doesn't
+// allocate once its level and logger name have been seen.
+type Counters struct {
+	mu     sync.RWMutex
+	counts map[countKey]*counts
+}
+
+type countKey struct {
+	level zapcore.Level
+	name  string
+}
+
+type counts struct {
+	logged  atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// A Count is a snapshot of the counts for one level and logger name.
+type Count struct {
+	Level  zapcore.Level
+	Logger string
+	// Logged is the number of entries written.
+	Logged uint64
+	// Dropped is the number of entries dropped by sampling.
+	Dropped uint64
+}
+
+// New creates a Counters with all counts at zero.
+func New() *Counters {
+	return &Counters{counts: make(map[countKey]*counts)}
+}
+
+// Core wraps a Core so that each entry it writes is counted.
+func (c *Counters) Core(core zapcore.Core) zapcore.Core {
+	return zapcore.RegisterHooks(core, c.logged)
+}
+
+// Option configures a Logger to count the entries it writes. Since options
+// wrap the Core built from a Config, entries that its sampling drops aren't
+// counted as written; use SamplerHook to count those.
+func (c *Counters) Option() zap.Option {
+	return zap.WrapCore(c.Core)
+}
+
+// SamplerHook counts the entries dropped by sampling. Use it as the Hook of a
+// zap.SamplingConfig, or pass it to zapcore.SamplerHook.
+func (c *Counters) SamplerHook(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+	if dec&zapcore.LogDropped > 0 {
+		c.get(ent).dropped.Inc()
+	}
+}
+
+// Snapshot returns the current counts, sorted by logger name and then by
+// level.
+func (c *Counters) Snapshot() []Count {
+	c.mu.RLock()
+	snap := make([]Count, 0, len(c.counts))
+	for k, v := range c.counts {
+		snap = append(snap, Count{
+			Level:   k.level,
+			Logger:  k.name,
+			Logged:  v.logged.Load(),
+			Dropped: v.dropped.Load(),
+		})
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(snap, func(i, j int) bool {
+		if snap[i].Logger != snap[j].Logger {
+			return snap[i].Logger < snap[j].Logger
+		}
+		return snap[i].Level < snap[j].Level
+	})
+	return snap
+}
+
+// Var exports the counts with expvar. Publish it under a name of your choice:
+//
+//   expvar.Publish("logging", counters.Var())
+//
+// Its value is an object keyed by logger name, then level, like
+//
+//   {"http": {"error": {"logged": 5, "dropped": 0}}}
+func (c *Counters) Var() expvar.Var {
+	return expvar.Func(func() interface{} {
+		byName := make(map[string]map[string]map[string]uint64)
+		for _, n := range c.Snapshot() {
+			byLevel, ok := byName[n.Logger]
+			if !ok {
+				byLevel = make(map[string]map[string]uint64)
+				byName[n.Logger] = byLevel
+			}
+			byLevel[n.Level.String()] = map[string]uint64{
+				"logged":  n.Logged,
+				"dropped": n.Dropped,
+			}
+		}
+		return byName
+	})
+}
+
+func (c *Counters) logged(ent zapcore.Entry) error {
+	c.get(ent).logged.Inc()
+	return nil
+}
+
+func (c *Counters) get(ent zapcore.Entry) *counts {
+	k := countKey{level: ent.Level, name: ent.LoggerName}
+	c.mu.RLock()
+	n, ok := c.counts[k]
+	c.mu.RUnlock()
+	if ok {
+		return n
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if n, ok := c.counts[k]; ok {
+		return n
+	}
+	n = &counts{}
+	c.counts[k] = n
+	return n
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapmetrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLogger(c *Counters) *zap.Logger {
+	core, _ := observer.New(zapcore.InfoLevel)
+	core = zapcore.NewSamplerWithOptions(core, time.Minute, 2, 100, zapcore.SamplerHook(c.SamplerHook))
+	return zap.New(core, c.Option())
+}
+
+func TestCounters(t *testing.T) {
+	c := New()
+	logger := newLogger(c)
+	for i := 0; i < 3; i++ {
+		logger.Error("oops")
+	}
+	logger.Debug("disabled")
+	logger.Named("http").Info("request")
+
+	assert.Equal(t, []Count{
+		{Level: zapcore.ErrorLevel, Logged: 2, Dropped: 1},
+		{Level: zapcore.InfoLevel, Logger: "http", Logged: 1},
+	}, c.Snapshot(), "Unexpected counts.")
+}
+
+func TestCountersVar(t *testing.T) {
+	c := New()
+	logger := newLogger(c)
+	logger.Named("http").Error("oops")
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(c.Var().String()), &got), "Expected the expvar to be valid JSON.")
+	assert.Equal(t, map[string]interface{}{
+		"http": map[string]interface{}{
+			"error": map[string]interface{}{"logged": 1.0, "dropped": 0.0},
+		},
+	}, got, "Unexpected expvar value.")
+}
+
+func TestCountersPrometheus(t *testing.T) {
+	c := New()
+	logger := newLogger(c)
+	logger.Named(`a"b`).Warn("careful")
+	for i := 0; i < 3; i++ {
+		logger.Info("hello")
+	}
+
+	want := `# HELP zap_log_entries_total Log entries written, by level and logger name.
+# TYPE zap_log_entries_total counter
+zap_log_entries_total{level="info",logger=""} 2
+zap_log_entries_total{level="warn",logger="a\"b"} 1
+# HELP zap_log_entries_dropped_total Log entries dropped by sampling, by level and logger name.
+# TYPE zap_log_entries_dropped_total counter
+zap_log_entries_dropped_total{level="info",logger=""} 1
+zap_log_entries_dropped_total{level="warn",logger="a\"b"} 0
+`
+	var buf bytes.Buffer
+	require.NoError(t, c.WritePrometheus(&buf), "Unexpected error writing metrics.")
+	assert.Equal(t, want, buf.String(), "Unexpected Prometheus output.")
+
+	rec := httptest.NewRecorder()
+	c.PrometheusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, want, rec.Body.String(), "Unexpected response body.")
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain", "Unexpected content type.")
+}
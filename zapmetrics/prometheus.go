@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapmetrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	_loggedMetric  = "zap_log_entries_total"
+	_droppedMetric = "zap_log_entries_dropped_total"
+)
+
+var _labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WritePrometheus writes the counts in the Prometheus text exposition format,
+// as two counters labeled with the level and logger name:
+//
+//   zap_log_entries_total{level="error",logger="http"} 5
+//   zap_log_entries_dropped_total{level="error",logger="http"} 0
+//
+// It's useful for adding the counts to an existing metrics endpoint; to serve
+// them on their own, use PrometheusHandler.
+func (c *Counters) WritePrometheus(w io.Writer) error {
+	snap := c.Snapshot()
+	buf := bufio.NewWriter(w)
+	buf.WriteString("# HELP " + _loggedMetric + " Log entries written, by level and logger name.\n")
+	buf.WriteString("# TYPE " + _loggedMetric + " counter\n")
+	for _, n := range snap {
+		writeSample(buf, _loggedMetric, n, n.Logged)
+	}
+	buf.WriteString("# HELP " + _droppedMetric + " Log entries dropped by sampling, by level and logger name.\n")
+	buf.WriteString("# TYPE " + _droppedMetric + " counter\n")
+	for _, n := range snap {
+		writeSample(buf, _droppedMetric, n, n.Dropped)
+	}
+	return buf.Flush()
+}
+
+// PrometheusHandler returns an http.Handler that serves the counts in the
+// Prometheus text exposition format. See WritePrometheus for details.
+func (c *Counters) PrometheusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		c.WritePrometheus(w)
+	})
+}
+
+func writeSample(buf *bufio.Writer, metric string, n Count, v uint64) {
+	buf.WriteString(metric)
+	buf.WriteString(`{level="`)
+	buf.WriteString(n.Level.String())
+	buf.WriteString(`",logger="`)
+	buf.WriteString(_labelEscaper.Replace(n.Logger))
+	buf.WriteString(`"} `)
+	buf.WriteString(strconv.FormatUint(v, 10))
+	buf.WriteByte('\n')
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapmiddleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Headers constructs a field that logs the allow-listed HTTP headers as a
+// nested object. Header names are matched case-insensitively and logged in
+// lowercase, in the order they're allowed; headers with several values are
+// logged as a single comma-separated string, and allowed headers that aren't
+// present are omitted. For example,
+//   zapmiddleware.Headers("headers", r.Header, "User-Agent", "Accept")
+// might be logged as
+//   {"user-agent":"curl/7.54.0","accept":"*/*"}
+//
+// Headers that aren't allow-listed are never logged, so sensitive headers
+// like Authorization and Cookie stay out of the logs unless they're allowed
+// explicitly.
+func Headers(key string, h http.Header, allow ...string) zap.Field {
+	return zap.Object(key, headers{h: h, allow: allow})
+}
+
+type headers struct {
+	h     http.Header
+	allow []string
+}
+
+func (hs headers) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	seen := make(map[string]struct{}, len(hs.allow))
+	for _, name := range hs.allow {
+		name = http.CanonicalHeaderKey(name)
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		if vals, ok := hs.h[name]; ok {
+			enc.AddString(strings.ToLower(name), strings.Join(vals, ", "))
+		}
+	}
+	return nil
+}
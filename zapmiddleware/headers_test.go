@@ -0,0 +1,78 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapmiddleware
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer secret")
+	h.Set("User-Agent", "curl/7.54.0")
+	h.Add("Accept", "text/html")
+	h.Add("Accept", "application/json")
+
+	tests := []struct {
+		desc     string
+		allow    []string
+		expected map[string]interface{}
+	}{
+		{
+			desc:     "no allowed headers",
+			expected: map[string]interface{}{},
+		},
+		{
+			desc:  "authorization not allowed",
+			allow: []string{"user-agent", "Accept", "X-Missing"},
+			expected: map[string]interface{}{
+				"user-agent": "curl/7.54.0",
+				"accept":     "text/html, application/json",
+			},
+		},
+		{
+			desc:     "authorization allowed",
+			allow:    []string{"authorization", "AUTHORIZATION"},
+			expected: map[string]interface{}{"authorization": "Bearer secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		enc := zapcore.NewMapObjectEncoder()
+		Headers("headers", h, tt.allow...).AddTo(enc)
+		assert.Equal(t, tt.expected, enc.Fields["headers"], "Unexpected headers logged with %s.", tt.desc)
+	}
+}
+
+func TestHeadersJSONOrder(t *testing.T) {
+	h := http.Header{"B": {"2"}, "A": {"1"}}
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, []zapcore.Field{Headers("headers", h, "b", "a")})
+	if assert.NoError(t, err, "Unexpected error encoding entry.") {
+		assert.Equal(t, `{"headers":{"b":"2","a":"1"}}`+"\n", buf.String(), "Expected headers in allow-list order.")
+		buf.Free()
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapmiddleware provides HTTP middleware that gives each request its
+// own logger and logs the request's completion, along with fields for logging
+// parts of HTTP requests safely.
+package zapmiddleware // import "go.uber.org/zap/zapmiddleware"
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RequestIDHeader is the request header from which the default fields take
+// the request ID.
+const RequestIDHeader = "X-Request-Id"
+
+// An Option overrides the middleware's default configuration.
+type Option interface {
+	apply(*middleware)
+}
+
+type optionFunc func(*middleware)
+
+func (f optionFunc) apply(m *middleware) {
+	f(m)
+}
+
+// FieldNames are the keys of the fields the middleware logs. By default,
+// they're "method", "path", "request_id", "remote_addr", "status", "bytes",
+// and "latency".
+type FieldNames struct {
+	// Method, Path, and RequestID name the fields added to each request's
+	// logger, unless they're replaced with WithFields.
+	Method    string
+	Path      string
+	RequestID string
+	// RemoteAddr, Status, Bytes, and Latency name the fields added to the
+	// completion entry.
+	RemoteAddr string
+	Status     string
+	Bytes      string
+	Latency    string
+}
+
+var _defaultFieldNames = FieldNames{
+	Method:     "method",
+	Path:       "path",
+	RequestID:  "request_id",
+	RemoteAddr: "remote_addr",
+	Status:     "status",
+	Bytes:      "bytes",
+	Latency:    "latency",
+}
+
+// WithFieldNames overrides the names of the fields the middleware logs, for
+// consistency with existing dashboards or access log formats. Empty names
+// keep their current values.
+func WithFieldNames(names FieldNames) Option {
+	return optionFunc(func(m *middleware) {
+		m.names = m.names.merge(names)
+	})
+}
+
+func (n FieldNames) merge(o FieldNames) FieldNames {
+	pick := func(cur, override string) string {
+		if override != "" {
+			return override
+		}
+		return cur
+	}
+	return FieldNames{
+		Method:     pick(n.Method, o.Method),
+		Path:       pick(n.Path, o.Path),
+		RequestID:  pick(n.RequestID, o.RequestID),
+		RemoteAddr: pick(n.RemoteAddr, o.RemoteAddr),
+		Status:     pick(n.Status, o.Status),
+		Bytes:      pick(n.Bytes, o.Bytes),
+		Latency:    pick(n.Latency, o.Latency),
+	}
+}
+
+// WithFields replaces the fields added to each request's logger. By default,
+// the logger gets the request's method and path, and the value of the
+// X-Request-Id header (if any) under "request_id".
+func WithFields(f func(*http.Request) []zap.Field) Option {
+	return optionFunc(func(m *middleware) {
+		m.fields = f
+	})
+}
+
+// WithCompletionFields adds the fields returned by f to the completion
+// entry. It runs after the wrapped handler returns, with the request passed
+// to the handler, so it can add fields from values that earlier middleware
+// stored in the request's context, like an authenticated user's ID.
+func WithCompletionFields(f func(*http.Request) []zap.Field) Option {
+	return optionFunc(func(m *middleware) {
+		m.completion = f
+	})
+}
+
+// WithLevel chooses the level of the completion entry from the response's
+// status code. By default, server errors (5xx) are logged at ErrorLevel,
+// client errors (4xx) at WarnLevel, and everything else at InfoLevel.
+func WithLevel(f func(status int) zapcore.Level) Option {
+	return optionFunc(func(m *middleware) {
+		m.level = f
+	})
+}
+
+type middleware struct {
+	base       *zap.Logger
+	names      FieldNames
+	fields     func(*http.Request) []zap.Field
+	completion func(*http.Request) []zap.Field
+	level      func(int) zapcore.Level
+}
+
+// Inject returns middleware that creates a child of the base logger for each
+// request, adds it to the request's context (see FromContext), and logs a
+// "request completed" entry once the wrapped handler returns. Along with the
+// logger's fields, the entry has the client's address, the response's status
+// code and body size in bytes, and the request's latency.
+func Inject(base *zap.Logger, options ...Option) func(http.Handler) http.Handler {
+	m := &middleware{
+		base:  base,
+		names: _defaultFieldNames,
+		level: defaultLevel,
+	}
+	m.fields = m.defaultFields
+	for _, opt := range options {
+		opt.apply(m)
+	}
+	return m.wrap
+}
+
+func (m *middleware) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		logger := m.base.With(m.fields(r)...)
+		rec := &statusRecorder{ResponseWriter: w}
+
+		r = r.WithContext(NewContext(r.Context(), logger))
+		next.ServeHTTP(rec, r)
+
+		status := rec.Status()
+		if ce := logger.Check(m.level(status), "request completed"); ce != nil {
+			fields := []zap.Field{
+				zap.String(m.names.RemoteAddr, r.RemoteAddr),
+				zap.Int(m.names.Status, status),
+				zap.Int64(m.names.Bytes, rec.bytes),
+				zap.Duration(m.names.Latency, time.Since(start)),
+			}
+			if m.completion != nil {
+				fields = append(fields, m.completion(r)...)
+			}
+			ce.Write(fields...)
+		}
+	})
+}
+
+func (m *middleware) defaultFields(r *http.Request) []zap.Field {
+	fields := []zap.Field{
+		zap.String(m.names.Method, r.Method),
+		zap.String(m.names.Path, r.URL.Path),
+	}
+	if id := r.Header.Get(RequestIDHeader); id != "" {
+		fields = append(fields, zap.String(m.names.RequestID, id))
+	}
+	return fields
+}
+
+func defaultLevel(status int) zapcore.Level {
+	switch {
+	case status >= 500:
+		return zapcore.ErrorLevel
+	case status >= 400:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// NewContext returns a copy of the parent context that carries the logger.
+// It's equivalent to zap.NewContext.
+func NewContext(ctx context.Context, logger *zap.Logger) context.Context {
+	return zap.NewContext(ctx, logger)
+}
+
+// FromContext returns the logger carried by the context. If the context
+// doesn't carry a logger, it returns the global logger (see zap.L). It's
+// equivalent to zap.FromContext, so it adds the fields of any context
+// extractors.
+func FromContext(ctx context.Context) *zap.Logger {
+	return zap.FromContext(ctx)
+}
+
+// statusRecorder remembers the status code written to a ResponseWriter, and
+// counts the bytes of the response body.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying ResponseWriter does.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.status == 0 {
+			r.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, so that handlers behind the middleware can
+// take over the connection (for example, to upgrade it to a WebSocket). It
+// returns http.ErrNotSupported if the underlying ResponseWriter can't be
+// hijacked.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	return h.Hijack()
+}
+
+// Push implements http.Pusher for HTTP/2 server push. It returns
+// http.ErrNotSupported if the underlying ResponseWriter doesn't support push.
+func (r *statusRecorder) Push(target string, opts *http.PushOptions) error {
+	p, ok := r.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return p.Push(target, opts)
+}
+
+// Unwrap returns the underlying ResponseWriter, so that
+// http.ResponseController can reach its optional methods.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Status returns the response's status code; handlers that never write a
+// response implicitly respond with 200 OK.
+func (r *statusRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapmiddleware
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestInject(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	handler := Inject(zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest("POST", "/users?debug=1", nil)
+	req.Header.Set(RequestIDHeader, "abc123")
+	rec := serve(handler, req)
+	assert.Equal(t, http.StatusCreated, rec.Code, "Unexpected response status.")
+
+	entries := logs.AllUntimed()
+	require.Equal(t, 2, len(entries), "Expected a handler entry and a completion entry.")
+
+	requestFields := map[string]interface{}{
+		"method":     "POST",
+		"path":       "/users",
+		"request_id": "abc123",
+	}
+	assert.Equal(t, "handling", entries[0].Message, "Unexpected handler message.")
+	assert.Equal(t, requestFields, entries[0].ContextMap(), "Expected the handler's logger to carry request fields.")
+
+	done := entries[1]
+	assert.Equal(t, "request completed", done.Message, "Unexpected completion message.")
+	assert.Equal(t, zapcore.InfoLevel, done.Level, "Unexpected completion level.")
+	ctx := done.ContextMap()
+	assert.Equal(t, int64(http.StatusCreated), ctx["status"], "Unexpected logged status.")
+	assert.Equal(t, int64(5), ctx["bytes"], "Unexpected logged response size.")
+	assert.Equal(t, req.RemoteAddr, ctx["remote_addr"], "Unexpected logged remote address.")
+	assert.IsType(t, time.Duration(0), ctx["latency"], "Expected latency to be a duration.")
+	for _, k := range []string{"status", "bytes", "remote_addr", "latency"} {
+		delete(ctx, k)
+	}
+	assert.Equal(t, requestFields, ctx, "Expected the completion entry to carry request fields.")
+}
+
+func TestInjectDefaultLevels(t *testing.T) {
+	tests := []struct {
+		write    func(http.ResponseWriter)
+		status   int
+		expected zapcore.Level
+	}{
+		{func(http.ResponseWriter) {}, http.StatusOK, zapcore.InfoLevel},
+		{func(w http.ResponseWriter) { w.Write([]byte("ok")) }, http.StatusOK, zapcore.InfoLevel},
+		{func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) }, http.StatusNotFound, zapcore.WarnLevel},
+		{func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) }, http.StatusBadGateway, zapcore.ErrorLevel},
+		{
+			func(w http.ResponseWriter) {
+				w.Write([]byte("ok"))
+				w.WriteHeader(http.StatusInternalServerError) // superfluous
+			},
+			http.StatusOK,
+			zapcore.InfoLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		core, logs := observer.New(zapcore.DebugLevel)
+		handler := Inject(zap.New(core))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			tt.write(w)
+		}))
+		serve(handler, httptest.NewRequest("GET", "/", nil))
+
+		entries := logs.AllUntimed()
+		require.Equal(t, 1, len(entries), "Expected only a completion entry.")
+		assert.Equal(t, tt.expected, entries[0].Level, "Unexpected level for status %d.", tt.status)
+		assert.Equal(t, int64(tt.status), entries[0].ContextMap()["status"], "Unexpected logged status.")
+		_, ok := entries[0].ContextMap()["request_id"]
+		assert.False(t, ok, "Expected no request ID without the header.")
+	}
+}
+
+func TestInjectOptions(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	handler := Inject(
+		zap.New(core),
+		WithFields(func(r *http.Request) []zap.Field {
+			return []zap.Field{zap.String("host", r.Host)}
+		}),
+		WithLevel(func(status int) zapcore.Level {
+			if status == http.StatusOK {
+				return zapcore.DebugLevel
+			}
+			return zapcore.ErrorLevel
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+
+	serve(handler, httptest.NewRequest("GET", "http://example.com/", nil))
+	assert.Equal(t, 0, logs.Len(), "Expected completion entry at a disabled level to be dropped.")
+
+	serve(handler, httptest.NewRequest("GET", "http://example.com/fail", nil))
+	entries := logs.AllUntimed()
+	require.Equal(t, 1, len(entries), "Expected one completion entry.")
+	assert.Equal(t, zapcore.ErrorLevel, entries[0].Level, "Unexpected completion level.")
+	ctx := entries[0].ContextMap()
+	assert.Equal(t, "example.com", ctx["host"], "Expected custom fields.")
+	_, ok := ctx["method"]
+	assert.False(t, ok, "Expected custom fields to replace the defaults.")
+}
+
+func TestInjectFieldNames(t *testing.T) {
+	type userKey struct{}
+	core, logs := observer.New(zapcore.DebugLevel)
+	handler := Inject(
+		zap.New(core),
+		WithFieldNames(FieldNames{Path: "url.path", Status: "http.status_code"}),
+		WithFieldNames(FieldNames{Bytes: "http.response_size"}),
+		WithCompletionFields(func(r *http.Request) []zap.Field {
+			return []zap.Field{zap.String("user", r.Context().Value(userKey{}).(string))}
+		}),
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userKey{}, "jane"))
+	serve(handler, req)
+
+	entries := logs.AllUntimed()
+	require.Equal(t, 1, len(entries), "Expected one completion entry.")
+	ctx := entries[0].ContextMap()
+	delete(ctx, "latency")
+	assert.Equal(t, map[string]interface{}{
+		"method":             "GET",
+		"url.path":           "/",
+		"remote_addr":        req.RemoteAddr,
+		"http.status_code":   int64(http.StatusOK),
+		"http.response_size": int64(0),
+		"user":               "jane",
+	}, ctx, "Unexpected completion fields.")
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, zap.L(), FromContext(context.Background()), "Expected the global logger without a logger in the context.")
+
+	logger := zap.NewNop()
+	assert.Equal(t, logger, FromContext(NewContext(context.Background(), logger)), "Expected the context's logger.")
+}
+
+func TestStatusRecorderFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sr := &statusRecorder{ResponseWriter: rec}
+	sr.Flush()
+	assert.True(t, rec.Flushed, "Expected flush to be passed through.")
+	assert.Equal(t, http.StatusOK, sr.Status(), "Expected flushing to imply a 200 OK.")
+}
+
+func TestStatusRecorderHijack(t *testing.T) {
+	h := Inject(zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if !assert.NoError(t, err, "Unexpected error hijacking the connection.") {
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 101 Switching Protocols\r\n\r\nhijacked")
+		buf.Flush()
+	}))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err, "Failed to dial test server.")
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	require.NoError(t, err, "Failed to write request.")
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, nil)
+	require.NoError(t, err, "Failed to read response.")
+	assert.Equal(t, http.StatusSwitchingProtocols, res.StatusCode, "Unexpected status code.")
+	body, err := ioutil.ReadAll(br)
+	require.NoError(t, err, "Failed to read hijacked connection.")
+	assert.Equal(t, "hijacked", string(body), "Unexpected data from hijacked connection.")
+}
+
+func TestStatusRecorderUnsupported(t *testing.T) {
+	rec := httptest.NewRecorder()
+	sr := &statusRecorder{ResponseWriter: rec}
+	_, _, err := sr.Hijack()
+	assert.Equal(t, http.ErrNotSupported, err, "Expected hijacking a recorder to be unsupported.")
+	assert.Equal(t, http.ErrNotSupported, sr.Push("/style.css", nil), "Expected pushing to a recorder to be unsupported.")
+	assert.Equal(t, rec, sr.Unwrap(), "Expected Unwrap to return the underlying ResponseWriter.")
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapproto provides fields for logging protocol buffer messages. It's
+// a separate package so that programs that don't use protocol buffers don't
+// depend on them.
+package zapproto // import "go.uber.org/zap/zapproto"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Proto constructs a field that lazily marshals a protocol buffer message with
+// protojson and logs the result as a nested object. The message uses the
+// canonical JSON mapping, including its lowerCamelCase field names: unlike the
+// output of the message's String method, it's concise and machine-readable.
+// Well-known types whose JSON mapping isn't an object, like Timestamp,
+// Duration, and the wrapper types, are logged as the corresponding string,
+// number, or array instead.
+//
+// A nil message (including a typed nil pointer) is logged as null. If the
+// message can't be marshaled, the error is logged under key+"Error".
+func Proto(key string, msg proto.Message) zap.Field {
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return zap.Reflect(key, nil)
+	}
+	// The field adds itself to the encoder, since the shape of the value isn't
+	// known until the message is marshaled.
+	return zap.Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: protoMessage{key, msg}}
+}
+
+type protoMessage struct {
+	key string
+	msg proto.Message
+}
+
+func (m protoMessage) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	bs, err := protojson.Marshal(m.msg)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	return addJSONValue(dec, m.key, enc)
+}
+
+// addJSONObject streams the members of the JSON object being decoded into
+// enc, preserving their order, and consumes the closing brace.
+func addJSONObject(dec *json.Decoder, enc zapcore.ObjectEncoder) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("unexpected JSON object key %v", tok)
+		}
+		if err := addJSONValue(dec, key, enc); err != nil {
+			return err
+		}
+	}
+	_, err := dec.Token()
+	return err
+}
+
+func addJSONValue(dec *json.Decoder, key string, enc zapcore.ObjectEncoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			return enc.AddObject(key, jsonObject{dec})
+		}
+		return enc.AddArray(key, jsonArray{dec})
+	case string:
+		enc.AddString(key, v)
+	case bool:
+		enc.AddBool(key, v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			enc.AddInt64(key, i)
+		} else if f, err := v.Float64(); err == nil {
+			enc.AddFloat64(key, f)
+		} else {
+			enc.AddString(key, v.String())
+		}
+	case nil:
+		return enc.AddReflected(key, nil)
+	}
+	return nil
+}
+
+func appendJSONValue(dec *json.Decoder, arr zapcore.ArrayEncoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			return arr.AppendObject(jsonObject{dec})
+		}
+		return arr.AppendArray(jsonArray{dec})
+	case string:
+		arr.AppendString(v)
+	case bool:
+		arr.AppendBool(v)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			arr.AppendInt64(i)
+		} else if f, err := v.Float64(); err == nil {
+			arr.AppendFloat64(f)
+		} else {
+			arr.AppendString(v.String())
+		}
+	case nil:
+		return arr.AppendReflected(nil)
+	}
+	return nil
+}
+
+// jsonObject and jsonArray encode the JSON object or array whose opening
+// delimiter was just consumed from the decoder.
+type jsonObject struct{ dec *json.Decoder }
+
+func (o jsonObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return addJSONObject(o.dec, enc)
+}
+
+type jsonArray struct{ dec *json.Decoder }
+
+func (a jsonArray) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for a.dec.More() {
+		if err := appendJSONValue(a.dec, arr); err != nil {
+			return err
+		}
+	}
+	_, err := a.dec.Token()
+	return err
+}
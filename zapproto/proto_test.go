@@ -0,0 +1,155 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapproto
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func encode(t testing.TB, fields ...zap.Field) string {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{})
+	buf, err := enc.EncodeEntry(zapcore.Entry{}, fields)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	defer buf.Free()
+	return buf.String()
+}
+
+func TestProto(t *testing.T) {
+	api := &apipb.Api{
+		Name:    "Greeter",
+		Version: "v1",
+		Methods: []*apipb.Method{
+			{Name: "Hello", RequestTypeUrl: "type.googleapis.com/HelloRequest", ResponseStreaming: true},
+		},
+	}
+	value, err := structpb.NewValue(map[string]interface{}{
+		"list":   []interface{}{1, "two", nil, true, map[string]interface{}{"n": 1.5}},
+		"nested": map[string]interface{}{},
+	})
+	require.NoError(t, err, "Unexpected error constructing a structpb.Value.")
+
+	tests := []struct {
+		desc     string
+		field    zap.Field
+		expected string
+	}{
+		{
+			desc:  "message",
+			field: Proto("api", api),
+			expected: `{"api":{"name":"Greeter","methods":[` +
+				`{"name":"Hello","requestTypeUrl":"type.googleapis.com/HelloRequest","responseStreaming":true}` +
+				`],"version":"v1"}}`,
+		},
+		{
+			desc:     "nested values",
+			field:    Proto("value", value.GetStructValue()),
+			expected: `{"value":{"list":[1,"two",null,true,{"n":1.5}],"nested":{}}}`,
+		},
+		{
+			desc:     "empty message",
+			field:    Proto("api", &apipb.Api{}),
+			expected: `{"api":{}}`,
+		},
+		{
+			desc:     "timestamp",
+			field:    Proto("ts", timestamppb.New(time.Date(2018, 6, 1, 12, 30, 0, 0, time.UTC))),
+			expected: `{"ts":"2018-06-01T12:30:00Z"}`,
+		},
+		{
+			desc:     "duration",
+			field:    Proto("d", durationpb.New(1500*time.Millisecond)),
+			expected: `{"d":"1.500s"}`,
+		},
+		{
+			desc:     "string wrapper",
+			field:    Proto("s", wrapperspb.String("foo")),
+			expected: `{"s":"foo"}`,
+		},
+		{
+			desc:     "bool wrapper",
+			field:    Proto("b", wrapperspb.Bool(true)),
+			expected: `{"b":true}`,
+		},
+		{
+			desc:     "double wrapper",
+			field:    Proto("f", wrapperspb.Double(1.5)),
+			expected: `{"f":1.5}`,
+		},
+		{
+			desc:     "int32 wrapper",
+			field:    Proto("i", wrapperspb.Int32(42)),
+			expected: `{"i":42}`,
+		},
+		{
+			desc:     "list value",
+			field:    Proto("l", value.GetStructValue().GetFields()["list"]),
+			expected: `{"l":[1,"two",null,true,{"n":1.5}]}`,
+		},
+		{
+			desc:     "null value",
+			field:    Proto("v", structpb.NewNullValue()),
+			expected: `{"v":null}`,
+		},
+		{
+			desc:     "nil message",
+			field:    Proto("api", nil),
+			expected: `{"api":null}`,
+		},
+		{
+			desc:     "typed nil message",
+			field:    Proto("api", (*apipb.Api)(nil)),
+			expected: `{"api":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.JSONEq(t, tt.expected, encode(t, tt.field), "Unexpected output.")
+			// The field may be logged more than once.
+			assert.JSONEq(t, tt.expected, encode(t, tt.field), "Unexpected output when re-using field.")
+		})
+	}
+}
+
+func TestProtoFieldOrder(t *testing.T) {
+	api := &apipb.Api{Name: "Greeter", Version: "v1"}
+	assert.Equal(t, `{"api":{"name":"Greeter","version":"v1"}}`+"\n", encode(t, Proto("api", api)), "Expected fields in protojson order.")
+}
+
+func TestProtoMapEncoder(t *testing.T) {
+	enc := zapcore.NewMapObjectEncoder()
+	Proto("m", &apipb.Method{Name: "Hello", RequestStreaming: true}).AddTo(enc)
+	assert.Equal(t, map[string]interface{}{
+		"m": map[string]interface{}{"name": "Hello", "requestStreaming": true},
+	}, enc.Fields, "Unexpected encoded fields.")
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapsyslog
+
+import (
+	"bytes"
+	"errors"
+	"net"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/internal/bufferpool"
+	"go.uber.org/zap/zapcore"
+)
+
+// _localPaths are the usual locations of the local syslog daemon's socket.
+var _localPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// A DialOption configures a Sink returned by Dial.
+type DialOption interface {
+	apply(*conn)
+}
+
+type dialOptionFunc func(*conn)
+
+func (f dialOptionFunc) apply(c *conn) {
+	f(c)
+}
+
+// LineEnding sets the line ending that's stripped from the end of each
+// write. It should match the LineEnding of the wrapped Encoder's
+// EncoderConfig, and defaults to zapcore.DefaultLineEnding.
+func LineEnding(le string) DialOption {
+	return dialOptionFunc(func(c *conn) {
+		if le != "" {
+			c.lineEnding = []byte(le)
+		}
+	})
+}
+
+// Dial connects to a syslog daemon and returns a Sink that sends each write
+// as a message, without its trailing line ending (see LineEnding). The
+// network and address are as for net.Dial; if both are empty, Dial connects
+// to the local daemon's Unix socket instead.
+//
+// Over datagram networks ("udp" and "unixgram"), each message is sent as a
+// datagram of its own. Over stream networks ("tcp" and "unix"), messages are
+// framed by prefixing them with their length, as described in RFC 6587. In
+// both cases, each message must be a single write, which is how Cores use
+// their WriteSyncers.
+func Dial(network, addr string, opts ...DialOption) (zap.Sink, error) {
+	if network == "" && addr == "" {
+		return dialLocal(opts)
+	}
+	c, err := net.Dial(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return newConn(c, network, opts), nil
+}
+
+func dialLocal(opts []DialOption) (zap.Sink, error) {
+	for _, path := range _localPaths {
+		for _, network := range []string{"unixgram", "unix"} {
+			if c, err := net.Dial(network, path); err == nil {
+				return newConn(c, network, opts), nil
+			}
+		}
+	}
+	return nil, errors.New("couldn't connect to the local syslog daemon")
+}
+
+type conn struct {
+	net.Conn
+	framed     bool
+	lineEnding []byte
+}
+
+func newConn(c net.Conn, network string, opts []DialOption) *conn {
+	sc := &conn{Conn: c, lineEnding: []byte(zapcore.DefaultLineEnding)}
+	switch network {
+	case "udp", "udp4", "udp6", "unixgram":
+	default:
+		sc.framed = true
+	}
+	for _, opt := range opts {
+		opt.apply(sc)
+	}
+	return sc
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	msg := bytes.TrimSuffix(p, c.lineEnding)
+	if !c.framed {
+		if _, err := c.Conn.Write(msg); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	// Frame and send the message with a single write, so that concurrent
+	// messages can't be interleaved.
+	buf := bufferpool.Get()
+	defer buf.Free()
+	buf.AppendInt(int64(len(msg)))
+	buf.AppendByte(' ')
+	buf.Write(msg)
+	if _, err := c.Conn.Write(buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Sync is a no-op, since messages are sent as they're written.
+func (c *conn) Sync() error {
+	return nil
+}
@@ -0,0 +1,180 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+// Package zapsyslog feeds zap's output to syslog. NewEncoder wraps any
+// zapcore.Encoder, prefixing each entry with an RFC 5424 header that maps the
+// entry's level to a syslog severity, and Dial connects to a local or remote
+// syslog daemon. Together, they make a Core that writes to syslog:
+//
+//   sink, err := zapsyslog.Dial("udp", "collector:514")
+//   if err != nil {
+//     return err
+//   }
+//   enc := zapsyslog.NewEncoder(
+//     zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+//     zapsyslog.Config{Facility: zapsyslog.Local0, AppName: "myapp"},
+//   )
+//   core := zapcore.NewCore(enc, sink, zap.InfoLevel)
+//
+// Since the header already carries a timestamp, host name, and severity, the
+// wrapped Encoder usually needn't encode the entry's time or level.
+package zapsyslog // import "go.uber.org/zap/zapsyslog"
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/internal/bufferpool"
+	"go.uber.org/zap/zapcore"
+)
+
+// A Facility identifies the part of the system that produced a message.
+type Facility int
+
+// The facilities defined by RFC 5424.
+const (
+	Kern Facility = iota
+	User
+	Mail
+	Daemon
+	Auth
+	Syslog
+	LPR
+	News
+	UUCP
+	Cron
+	AuthPriv
+	FTP
+	NTP
+	Security
+	Console
+	SolarisCron
+	Local0
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// Config describes the header of each message.
+type Config struct {
+	// Facility is the facility of every message. The zero value is Kern, so
+	// most applications should set a facility like User or Local0.
+	Facility Facility
+	// Hostname identifies the machine sending the messages. If empty, it
+	// defaults to os.Hostname.
+	Hostname string
+	// AppName identifies the application sending the messages. If empty, it
+	// defaults to the name of the running executable.
+	AppName string
+	// PID is the process ID of the application. If zero, it defaults to
+	// os.Getpid.
+	PID int
+}
+
+type encoder struct {
+	zapcore.Encoder
+	facility Facility
+	// header is the part of the header after the timestamp, from the
+	// hostname through the process ID, with a leading space.
+	header string
+}
+
+// NewEncoder wraps an Encoder so that each entry is preceded by an RFC 5424
+// header:
+//
+//   <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID - MSG
+//
+// PRI combines the Config's facility with a severity derived from the
+// entry's level (see zapcore.SeverityLevelEncoder), MSGID is the logger's name, and MSG is the
+// output of the wrapped Encoder. Header fields that are empty, or can't be
+// represented, are replaced with "-", as the RFC specifies.
+func NewEncoder(enc zapcore.Encoder, cfg Config) zapcore.Encoder {
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	if cfg.AppName == "" && len(os.Args) > 0 {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+	if cfg.PID == 0 {
+		cfg.PID = os.Getpid()
+	}
+	header := " " + headerField(cfg.Hostname, 255) +
+		" " + headerField(cfg.AppName, 48) +
+		" " + strconv.Itoa(cfg.PID)
+	return encoder{Encoder: enc, facility: cfg.Facility, header: header}
+}
+
+func (e encoder) Clone() zapcore.Encoder {
+	return encoder{Encoder: e.Encoder.Clone(), facility: e.facility, header: e.header}
+}
+
+func (e encoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	body, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	line := bufferpool.Get()
+	line.AppendByte('<')
+	line.AppendInt(int64(e.facility)*8 + int64(zapcore.SyslogSeverity(ent.Level)))
+	line.AppendString(">1 ")
+	line.AppendString(ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"))
+	line.AppendString(e.header)
+	line.AppendByte(' ')
+	line.AppendString(headerField(ent.LoggerName, 32))
+	line.AppendString(" - ")
+	line.Write(body.Bytes())
+	body.Free()
+	return line, nil
+}
+
+// headerField returns s as an RFC 5424 header field: at most max printable
+// ASCII characters, other than spaces. Other characters are replaced with
+// underscores, and empty fields with "-".
+func headerField(s string, max int) string {
+	if s == "" {
+		return "-"
+	}
+	if len(s) > max {
+		s = s[:max]
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < '!' || c > '~' {
+			return sanitizeHeaderField(s)
+		}
+	}
+	return s
+}
+
+func sanitizeHeaderField(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c < '!' || c > '~' {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
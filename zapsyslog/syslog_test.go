@@ -0,0 +1,185 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zapsyslog
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoder(t *testing.T) {
+	enc := NewEncoder(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+		Config{Facility: Local0, Hostname: "host", AppName: "my app", PID: 42},
+	)
+	ent := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Date(2000, time.January, 2, 3, 4, 5, 6000, time.UTC),
+		LoggerName: "db",
+		Message:    "hello",
+	}
+
+	buf, err := enc.EncodeEntry(ent, []zapcore.Field{zap.Int("n", 1)})
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(
+		t,
+		`<132>1 2000-01-02T03:04:05.000006Z host my_app 42 db - {"msg":"hello","n":1}`+"\n",
+		buf.String(),
+		"Unexpected output.",
+	)
+	buf.Free()
+
+	ent.LoggerName = ""
+	buf, err = enc.Clone().EncodeEntry(ent, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	assert.Equal(t, `<132>1 2000-01-02T03:04:05.000006Z host my_app 42 - - {"msg":"hello"}`+"\n", buf.String(), "Expected a nil MSGID for unnamed loggers.")
+	buf.Free()
+}
+
+func TestEncoderDefaults(t *testing.T) {
+	hostname, err := os.Hostname()
+	require.NoError(t, err, "Failed to get hostname.")
+	enc := NewEncoder(zapcore.NewJSONEncoder(zapcore.EncoderConfig{}), Config{})
+
+	buf, err := enc.EncodeEntry(zapcore.Entry{Time: time.Now()}, nil)
+	require.NoError(t, err, "Unexpected error encoding entry.")
+	parts := strings.SplitN(buf.String(), " ", 7)
+	require.Equal(t, 7, len(parts), "Unexpected number of header fields.")
+	assert.Equal(t, "<6>1", parts[0], "Expected the kern facility and informational severity.")
+	assert.Equal(t, headerField(hostname, 255), parts[2], "Expected the hostname to default to os.Hostname.")
+	assert.Equal(t, headerField(filepath.Base(os.Args[0]), 48), parts[3], "Expected the app name to default to the executable's name.")
+	assert.Equal(t, strconv.Itoa(os.Getpid()), parts[4], "Expected the PID to default to os.Getpid.")
+}
+
+func TestHeaderField(t *testing.T) {
+	assert.Equal(t, "-", headerField("", 5), "Expected empty fields to be nil.")
+	assert.Equal(t, "abc", headerField("abc", 5), "Expected valid fields to be left alone.")
+	assert.Equal(t, "abcde", headerField("abcdefg", 5), "Expected long fields to be truncated.")
+	assert.Equal(t, "a_b_c", headerField("a b\tc", 5), "Expected invalid characters to be replaced.")
+}
+
+func TestDialUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen.")
+	defer pc.Close()
+
+	sink, err := Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err, "Unexpected error dialing.")
+	defer sink.Close()
+
+	n, err := sink.Write([]byte("<14>1 message\n"))
+	require.NoError(t, err, "Unexpected error writing.")
+	assert.Equal(t, 14, n, "Unexpected number of bytes written.")
+	assert.NoError(t, sink.Sync(), "Unexpected error syncing.")
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err = pc.ReadFrom(buf)
+	require.NoError(t, err, "Failed to read datagram.")
+	assert.Equal(t, "<14>1 message", string(buf[:n]), "Expected the trailing newline to be dropped.")
+}
+
+func TestDialTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen.")
+	defer ln.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			received <- err.Error()
+			return
+		}
+		defer c.Close()
+		contents, _ := ioutil.ReadAll(bufio.NewReader(c))
+		received <- string(contents)
+	}()
+
+	sink, err := Dial("tcp", ln.Addr().String())
+	require.NoError(t, err, "Unexpected error dialing.")
+	sink.Write([]byte("<14>1 foo\n"))
+	sink.Write([]byte("<14>1 hello world\n"))
+	require.NoError(t, sink.Close(), "Unexpected error closing.")
+	assert.Equal(t, "9 <14>1 foo17 <14>1 hello world", <-received, "Expected octet-counted framing.")
+}
+
+func TestDialLineEnding(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err, "Failed to listen.")
+	defer pc.Close()
+
+	sink, err := Dial("udp", pc.LocalAddr().String(), LineEnding("\r\n"))
+	require.NoError(t, err, "Unexpected error dialing.")
+	defer sink.Close()
+	sink.Write([]byte("<14>1 message\r\n"))
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err, "Failed to read datagram.")
+	assert.Equal(t, "<14>1 message", string(buf[:n]), "Expected the configured line ending to be dropped.")
+}
+
+func TestDialLocal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix sockets aren't available on Windows.")
+	}
+	dir, err := ioutil.TempDir("", "zapsyslog")
+	require.NoError(t, err, "Failed to create temp dir.")
+	defer os.RemoveAll(dir)
+
+	defer func(paths []string) { _localPaths = paths }(_localPaths)
+	path := filepath.Join(dir, "log")
+	_localPaths = []string{filepath.Join(dir, "missing"), path}
+
+	_, err = Dial("", "")
+	assert.Error(t, err, "Expected an error without a local daemon.")
+
+	pc, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err, "Failed to listen.")
+	defer pc.Close()
+
+	sink, err := Dial("", "")
+	require.NoError(t, err, "Unexpected error dialing the local daemon.")
+	defer sink.Close()
+	sink.Write([]byte("<14>1 local\n"))
+
+	buf := make([]byte, 1024)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err, "Failed to read datagram.")
+	assert.Equal(t, "<14>1 local", string(buf[:n]), "Unexpected message.")
+}
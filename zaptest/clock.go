@@ -0,0 +1,179 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zaptest
+
+import (
+	"sync"
+	"time"
+)
+
+// A Clock is a zapcore.Clock that returns predictable times, for tests that
+// care about timestamps. Each call to Now returns the clock's current time and
+// then advances the clock by a fixed step, so successive entries logged with
+// the clock (see zap.WithClock) have evenly-spaced, increasing timestamps. A
+// step of zero stops the clock, so that every entry has the same timestamp.
+// Tests can also move the clock explicitly with Add.
+//
+// Functions scheduled with AfterFunc run once the clock reaches their
+// deadline. Unlike the system clock's, they run synchronously, on the
+// goroutine that advanced the clock, which keeps tests deterministic.
+// Similarly, tickers created with NewTicker tick only as the clock advances
+// past each interval.
+//
+// A Clock is safe for concurrent use.
+type Clock struct {
+	mu      sync.Mutex
+	now     time.Time
+	step    time.Duration
+	timers  []*clockTimer
+	tickers []*clockTicker
+}
+
+type clockTimer struct {
+	at      time.Time
+	f       func()
+	stopped bool
+}
+
+type clockTicker struct {
+	c       chan time.Time
+	period  time.Duration
+	next    time.Time
+	stopped bool
+}
+
+// NewClock returns a Clock that starts at start and advances by step with
+// every call to Now.
+func NewClock(start time.Time, step time.Duration) *Clock {
+	return &Clock{now: start, step: step}
+}
+
+// Now returns the clock's current time, then advances it by its step.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	now := c.now
+	due := c.advance(c.step)
+	c.mu.Unlock()
+	run(due)
+	return now
+}
+
+// Add advances the clock by d, running any functions scheduled with AfterFunc
+// that become due.
+func (c *Clock) Add(d time.Duration) {
+	c.mu.Lock()
+	due := c.advance(d)
+	c.mu.Unlock()
+	run(due)
+}
+
+// AfterFunc schedules f to run once the clock has advanced by d. The returned
+// function cancels the call, reporting whether it stopped f from running.
+func (c *Clock) AfterFunc(d time.Duration, f func()) (stop func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &clockTimer{at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		stopped := !t.stopped
+		t.stopped = true
+		return stopped
+	}
+}
+
+// NewTicker returns a ticker that delivers the clock's time whenever the
+// clock advances past the next multiple of d since the ticker was created.
+// Like a time.Ticker, it buffers a single tick and drops the rest until it's
+// received, so advancing the clock by several intervals at once delivers only
+// one tick. It panics if d isn't positive.
+func (c *Clock) NewTicker(d time.Duration) (ticks <-chan time.Time, stop func()) {
+	if d <= 0 {
+		panic("non-positive interval for zaptest.Clock.NewTicker")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &clockTicker{c: make(chan time.Time, 1), period: d, next: c.now.Add(d)}
+	c.tickers = append(c.tickers, t)
+	return t.c, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		t.stopped = true
+	}
+}
+
+// advance moves the clock forward and returns the timers that became due, in
+// the order they were scheduled. It must be called with the lock held.
+func (c *Clock) advance(d time.Duration) []*clockTimer {
+	c.now = c.now.Add(d)
+	var due []*clockTimer
+	pending := c.timers[:0]
+	for _, t := range c.timers {
+		switch {
+		case t.stopped:
+		case !t.at.After(c.now):
+			t.stopped = true
+			due = append(due, t)
+		default:
+			pending = append(pending, t)
+		}
+	}
+	for i := len(pending); i < len(c.timers); i++ {
+		// don't keep references to finished timers
+		c.timers[i] = nil
+	}
+	c.timers = pending
+	c.tick()
+	return due
+}
+
+// tick delivers ticks to the tickers that became due and drops the stopped
+// ones. It must be called with the lock held.
+func (c *Clock) tick() {
+	running := c.tickers[:0]
+	for _, t := range c.tickers {
+		if t.stopped {
+			continue
+		}
+		if !t.next.After(c.now) {
+			select {
+			case t.c <- c.now:
+			default:
+			}
+			for !t.next.After(c.now) {
+				t.next = t.next.Add(t.period)
+			}
+		}
+		running = append(running, t)
+	}
+	for i := len(running); i < len(c.tickers); i++ {
+		// don't keep references to stopped tickers
+		c.tickers[i] = nil
+	}
+	c.tickers = running
+}
+
+func run(timers []*clockTimer) {
+	for _, t := range timers {
+		t.f()
+	}
+}
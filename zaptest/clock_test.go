@@ -0,0 +1,121 @@
+// Copyright (c) 2018 Uber Technologies, Inc.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package zaptest
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockSteps(t *testing.T) {
+	base := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewClock(base, time.Second)
+	log, logs := NewObservedLogger(WrapOptions(zap.WithClock(clock)))
+
+	log.Info("first")
+	log.Info("second")
+	clock.Add(time.Minute)
+	log.Info("third")
+
+	var times []time.Time
+	for _, e := range logs.All() {
+		times = append(times, e.Time)
+	}
+	assert.Equal(
+		t,
+		[]time.Time{base, base.Add(time.Second), base.Add(2*time.Second + time.Minute)},
+		times,
+		"Expected predictable, increasing timestamps.",
+	)
+}
+
+func TestClockStopped(t *testing.T) {
+	base := time.Unix(0, 0)
+	clock := NewClock(base, 0)
+	assert.Equal(t, base, clock.Now(), "Unexpected time.")
+	assert.Equal(t, base, clock.Now(), "Expected a zero step to stop the clock.")
+}
+
+func TestClockAfterFunc(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0), 0)
+	var fired []string
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, "b") })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, "a") })
+	stop := clock.AfterFunc(time.Second, func() { fired = append(fired, "stopped") })
+	assert.True(t, stop(), "Expected to stop a pending timer.")
+	assert.False(t, stop(), "Expected stopping a timer twice to report false.")
+
+	clock.Add(500 * time.Millisecond)
+	assert.Empty(t, fired, "Expected no timers to fire early.")
+	clock.Add(2 * time.Second)
+	assert.Equal(t, []string{"b", "a"}, fired, "Expected due timers to fire in the order they were scheduled.")
+
+	clock.Add(time.Hour)
+	assert.Equal(t, []string{"b", "a"}, fired, "Expected timers to fire only once.")
+
+	// Stepping the clock via Now also fires timers.
+	stepping := NewClock(time.Unix(0, 0), time.Second)
+	var ran bool
+	stepping.AfterFunc(time.Second, func() { ran = true })
+	stepping.Now()
+	assert.True(t, ran, "Expected Now to fire timers that its step makes due.")
+}
+
+func TestClockNewTicker(t *testing.T) {
+	clock := NewClock(time.Unix(0, 0), 0)
+	ticks, stop := clock.NewTicker(time.Second)
+
+	assertTick := func(want time.Time, msg string) {
+		select {
+		case got := <-ticks:
+			assert.Equal(t, want, got, "Unexpected tick time.")
+		default:
+			t.Errorf("Expected a tick: %s", msg)
+		}
+	}
+	assertNoTick := func(msg string) {
+		select {
+		case <-ticks:
+			t.Error(msg)
+		default:
+		}
+	}
+
+	clock.Add(500 * time.Millisecond)
+	assertNoTick("Expected no tick before the first interval elapses.")
+	clock.Add(500 * time.Millisecond)
+	assertTick(time.Unix(1, 0), "after the first interval")
+
+	clock.Add(3 * time.Second)
+	clock.Add(time.Second)
+	assertTick(time.Unix(4, 0), "after several intervals")
+	assertNoTick("Expected the ticker to drop ticks that aren't received.")
+
+	stop()
+	clock.Add(time.Hour)
+	assertNoTick("Expected no ticks after stopping the ticker.")
+
+	assert.Panics(t, func() { clock.NewTicker(0) }, "Expected a non-positive interval to panic.")
+}
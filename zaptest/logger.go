@@ -25,6 +25,7 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // LoggerOption configures the test logger built by NewLogger.
@@ -34,6 +35,7 @@ type LoggerOption interface {
 
 type loggerOptions struct {
 	Level      zapcore.LevelEnabler
+	failLevel  zapcore.LevelEnabler
 	zapOptions []zap.Option
 }
 
@@ -51,6 +53,21 @@ func Level(enab zapcore.LevelEnabler) LoggerOption {
 	})
 }
 
+// FailOnLevel marks the test as failed whenever a test Logger built by
+// NewLogger writes an entry enabled by enab, so that tests fail if the code
+// under test logs unexpected errors:
+//
+//   logger := zaptest.NewLogger(t, zaptest.FailOnLevel(zap.ErrorLevel))
+//
+// The entry is still logged, and the test keeps running. If enab enables
+// FatalLevel, Fatal-level entries stop the test with t.FailNow rather than
+// exiting the test binary, so they must be logged from the test's goroutine.
+func FailOnLevel(enab zapcore.LevelEnabler) LoggerOption {
+	return loggerOptionFunc(func(opts *loggerOptions) {
+		opts.failLevel = enab
+	})
+}
+
 // WrapOptions adds zap.Option's to a test Logger built by NewLogger.
 func WrapOptions(zapOpts ...zap.Option) LoggerOption {
 	return loggerOptionFunc(func(opts *loggerOptions) {
@@ -88,6 +105,17 @@ func NewLogger(t TestingT, opts ...LoggerOption) *zap.Logger {
 		// that happens.
 		zap.ErrorOutput(writer.WithMarkFailed(true)),
 	}
+	if fail := cfg.failLevel; fail != nil {
+		zapOptions = append(zapOptions, zap.Hooks(func(ent zapcore.Entry) error {
+			if fail.Enabled(ent.Level) {
+				t.Fail()
+			}
+			return nil
+		}))
+		if fail.Enabled(zapcore.FatalLevel) {
+			zapOptions = append(zapOptions, zap.OnFatal(t.FailNow))
+		}
+	}
 	zapOptions = append(zapOptions, cfg.zapOptions...)
 
 	return zap.New(
@@ -100,6 +128,30 @@ func NewLogger(t TestingT, opts ...LoggerOption) *zap.Logger {
 	)
 }
 
+// NewObservedLogger builds a new Logger that records every entry in memory,
+// so that tests can assert on the level, message, and fields of each entry
+// directly, without parsing encoded output.
+//
+//   logger, logs := zaptest.NewObservedLogger()
+//   logger.Info("hello", zap.String("user", "alice"))
+//   entry := logs.All()[0] // entry.Message == "hello"
+//
+// Entries are recorded in the order they're written, and the Logger is safe
+// for concurrent use. As with NewLogger, the Logger defaults to recording
+// debug level messages and above, and accepts zaptest.Level and
+// zaptest.WrapOptions.
+func NewObservedLogger(opts ...LoggerOption) (*zap.Logger, *observer.ObservedLogs) {
+	cfg := loggerOptions{
+		Level: zapcore.DebugLevel,
+	}
+	for _, o := range opts {
+		o.applyLoggerOption(&cfg)
+	}
+
+	core, logs := observer.New(cfg.Level)
+	return zap.New(core, cfg.zapOptions...), logs
+}
+
 // testingWriter is a WriteSyncer that writes to the given testing.TB.
 type testingWriter struct {
 	t TestingT
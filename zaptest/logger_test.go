@@ -191,3 +191,76 @@ func (t *testLogSpy) AssertFailed() {
 func (t *testLogSpy) assertFailed(v bool, msg string) {
 	assert.Equal(t.TB, v, t.failed, msg)
 }
+
+func TestObservedLogger(t *testing.T) {
+	log, logs := NewObservedLogger()
+	log.Debug("starting work")
+	log.With(zap.String("user", "alice")).Warn("work may fail", zap.Int("attempt", 2))
+
+	entries := logs.All()
+	if assert.Len(t, entries, 2, "Unexpected number of observed entries.") {
+		assert.Equal(t, zap.DebugLevel, entries[0].Level, "Unexpected level.")
+		assert.Equal(t, "starting work", entries[0].Message, "Unexpected message.")
+		assert.Equal(t, "work may fail", entries[1].Message, "Unexpected message.")
+		assert.Equal(
+			t,
+			map[string]interface{}{"user": "alice", "attempt": int64(2)},
+			entries[1].ContextMap(),
+			"Unexpected fields.",
+		)
+	}
+}
+
+func TestObservedLoggerOptions(t *testing.T) {
+	log, logs := NewObservedLogger(Level(zap.WarnLevel), WrapOptions(zap.Fields(zap.String("k", "v"))))
+	log.Info("dropped")
+	log.Error("kept")
+
+	entries := logs.AllUntimed()
+	if assert.Len(t, entries, 1, "Expected the level to be honored.") {
+		assert.Equal(t, []zapcore.Field{zap.String("k", "v")}, entries[0].Context, "Expected zap options to be applied.")
+	}
+}
+
+func TestTestLoggerFailOnLevel(t *testing.T) {
+	ts := newTestLogSpy(t)
+	log := NewLogger(ts, FailOnLevel(zap.ErrorLevel))
+
+	log.Warn("fine")
+	ts.AssertPassed()
+	log.Error("broken")
+	ts.AssertFailed()
+	ts.AssertMessages("WARN\tfine", "ERROR\tbroken")
+}
+
+// errStopped is the panic value fatalSpy uses to stop a test.
+var errStopped = errors.New("test stopped")
+
+// fatalSpy is a testLogSpy whose FailNow stops the test by panicking, rather
+// than by stopping the real test's goroutine.
+type fatalSpy struct {
+	*testLogSpy
+	stopped bool
+}
+
+func (t *fatalSpy) FailNow() {
+	t.Fail()
+	t.stopped = true
+	panic(errStopped)
+}
+
+func TestTestLoggerFailOnFatal(t *testing.T) {
+	ts := &fatalSpy{testLogSpy: newTestLogSpy(t)}
+	log := NewLogger(ts, FailOnLevel(zap.ErrorLevel))
+
+	var returned bool
+	assert.PanicsWithValue(t, errStopped, func() {
+		log.Fatal("boom")
+		returned = true
+	}, "Expected Fatal to call FailNow.")
+
+	assert.True(t, ts.stopped, "Expected Fatal to stop the test.")
+	assert.False(t, returned, "Expected Fatal not to return.")
+	ts.AssertFailed()
+	ts.AssertMessages("FATAL\tboom")
+}
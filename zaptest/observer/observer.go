@@ -104,6 +104,40 @@ func (o *ObservedLogs) FilterField(field zapcore.Field) *ObservedLogs {
 	})
 }
 
+// FilterLevelExact filters entries to those logged at exactly the given level.
+func (o *ObservedLogs) FilterLevelExact(level zapcore.Level) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		return e.Level == level
+	})
+}
+
+// FilterLoggerName filters entries to those logged through a logger with the
+// specified name.
+func (o *ObservedLogs) FilterLoggerName(name string) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		return e.LoggerName == name
+	})
+}
+
+// FilterFieldKey filters entries to those that have a field with the
+// specified key, regardless of its value.
+func (o *ObservedLogs) FilterFieldKey(key string) *ObservedLogs {
+	return o.filter(func(e LoggedEntry) bool {
+		for _, ctxField := range e.Context {
+			if ctxField.Key == key {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Filter filters entries to those for which keep returns true. It's useful
+// for conditions that the other filters can't express.
+func (o *ObservedLogs) Filter(keep func(LoggedEntry) bool) *ObservedLogs {
+	return o.filter(keep)
+}
+
 func (o *ObservedLogs) filter(match func(LoggedEntry) bool) *ObservedLogs {
 	o.mu.RLock()
 	defer o.mu.RUnlock()
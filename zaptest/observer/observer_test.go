@@ -149,6 +149,14 @@ func TestFilters(t *testing.T) {
 			Entry:   zapcore.Entry{Level: zap.InfoLevel, Message: "any slice"},
 			Context: []zapcore.Field{zap.Any("slice", []string{"a"})},
 		},
+		{
+			Entry:   zapcore.Entry{Level: zap.WarnLevel, LoggerName: "my.logger", Message: "warn"},
+			Context: []zapcore.Field{zap.Int("c", 3)},
+		},
+		{
+			Entry:   zapcore.Entry{Level: zap.ErrorLevel, LoggerName: "other", Message: "error"},
+			Context: []zapcore.Field{zap.Int("c", 3)},
+		},
 	}
 
 	logger, sink := New(zap.InfoLevel)
@@ -206,6 +214,33 @@ func TestFilters(t *testing.T) {
 			filtered: sink.FilterField(zap.Any("slice", []string{"a"})),
 			want:     logs[6:7],
 		},
+		{
+			msg:      "filter by exact level",
+			filtered: sink.FilterLevelExact(zap.WarnLevel),
+			want:     logs[7:8],
+		},
+		{
+			msg:      "filter by logger name",
+			filtered: sink.FilterLoggerName("my.logger"),
+			want:     logs[7:8],
+		},
+		{
+			msg:      "filter by field key",
+			filtered: sink.FilterFieldKey("fStr"),
+			want:     logs[0:2],
+		},
+		{
+			msg:      "filter by namespace key",
+			filtered: sink.FilterFieldKey("ns"),
+			want:     logs[3:5],
+		},
+		{
+			msg: "filter by function",
+			filtered: sink.Filter(func(e LoggedEntry) bool {
+				return e.Level >= zap.WarnLevel
+			}),
+			want: logs[7:9],
+		},
 	}
 
 	for _, tt := range tests {